@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/backup"
+	"github.com/nahtao97/scribble/internal/db"
+)
+
+func main() {
+	storageURL := flag.String("storage", os.Getenv("BACKUP_STORAGE_URL"), "backup storage URL (file:///var/backups or s3://bucket/prefix)")
+	schedule := flag.String("schedule", os.Getenv("BACKUP_SCHEDULE"), "cron expression to run on a ticker; if empty, takes one snapshot and exits (suitable for a K8s CronJob)")
+	restoreID := flag.String("restore", "", "snapshot ID to restore instead of taking a backup")
+	flag.Parse()
+
+	if *storageURL == "" {
+		fmt.Fprintln(os.Stderr, "backup: -storage (or BACKUP_STORAGE_URL) is required")
+		os.Exit(1)
+	}
+
+	database, err := db.NewDatabase()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup: failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	storage, err := backup.NewStorage(*storageURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup: failed to initialize storage: %v\n", err)
+		os.Exit(1)
+	}
+
+	service := backup.NewService(database.GetConnection(), storage, backup.DefaultConfig())
+
+	if *restoreID != "" {
+		if err := service.Restore(context.Background(), *restoreID); err != nil {
+			fmt.Fprintf(os.Stderr, "backup: restore of %s failed: %v\n", *restoreID, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restored snapshot %s\n", *restoreID)
+		return
+	}
+
+	if *schedule == "" {
+		snapshotID, err := service.Run(context.Background(), time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "backup: snapshot failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created snapshot %s\n", snapshotID)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Starting backup ticker on schedule %q\n", *schedule)
+	if err := service.StartTicker(ctx, *schedule, func(err error) {
+		fmt.Fprintf(os.Stderr, "backup: scheduled run failed: %v\n", err)
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "backup: failed to start ticker: %v\n", err)
+		os.Exit(1)
+	}
+
+	<-ctx.Done()
+	fmt.Println("Shutting down backup ticker")
+}
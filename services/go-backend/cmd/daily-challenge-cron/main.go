@@ -21,7 +21,7 @@ func main() {
 	defer database.Close()
 
 	// Create daily challenge service
-	challengeService := services.NewDailyChallengeService(database.GetConnection())
+	challengeService := services.NewDailyChallengeService(database.GetConnection(), services.DefaultDailyChallengeServiceConfig())
 
 	// Select next daily challenge
 	challenge, err := challengeService.SelectNextChallenge()
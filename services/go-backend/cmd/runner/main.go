@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/runner"
+)
+
+// cmd/runner is the standalone execution runner: it owns the
+// k8s.JobManager execution path and exposes it over HTTP so one or more
+// instances can be load-balanced behind the API server's WorkerPool via
+// runner.Pool, scaling and redeploying independently of the API.
+func main() {
+	jobManager, err := k8s.NewJobManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create job manager: %v\n", err)
+		os.Exit(1)
+	}
+
+	if os.Getenv("GO_ENV") == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.Default()
+	server := runner.NewServer(jobManager)
+	server.RegisterRoutes(router)
+
+	port := os.Getenv("RUNNER_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+		// No WriteTimeout: Execute's response streams for as long as the
+		// job runs, which would otherwise be cut off by a fixed deadline.
+		ReadTimeout: 15 * time.Second,
+		IdleTimeout: 60 * time.Second,
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		fmt.Printf("Starting scribble runner on port %s\n", port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "Runner server error: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+
+	<-quit
+	fmt.Println("\nReceived shutdown signal, initiating graceful shutdown...")
+
+	// Jobs already streaming can run past this deadline; Jobs API
+	// ActiveDeadlineSeconds is what ultimately bounds them.
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error during graceful shutdown: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Runner shutdown complete.")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nahtao97/scribble/internal/load"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to the load test JSON config file")
+	reportPath := flag.String("report", "", "path to write the machine-readable JSON report (stdout if empty)")
+	flag.Parse()
+
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "scribble-loadtest: -config is required")
+		os.Exit(1)
+	}
+
+	cfg, err := load.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scribble-loadtest: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := load.NewRunner()
+	results, err := runner.Run(context.Background(), cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scribble-loadtest: run failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := load.NewReport(results)
+	if err := report.WriteSummary(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "scribble-loadtest: failed to write summary: %v\n", err)
+		os.Exit(1)
+	}
+
+	reportOut := os.Stdout
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "scribble-loadtest: failed to create report file: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		reportOut = file
+	}
+	if err := report.WriteJSON(reportOut); err != nil {
+		fmt.Fprintf(os.Stderr, "scribble-loadtest: failed to write report: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
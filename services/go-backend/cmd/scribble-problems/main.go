@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nahtao97/scribble/internal/problems"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: scribble-problems validate <dir>")
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "validate":
+		if len(args) != 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		runValidate(args[1])
+	default:
+		fmt.Fprintf(os.Stderr, "scribble-problems: unknown subcommand %q\n", args[0])
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func runValidate(dir string) {
+	defs, err := problems.NewLoader(dir).Load()
+	for _, def := range defs {
+		fmt.Printf("  ok  %s (%s)\n", def.Slug, def.Difficulty)
+	}
+	fmt.Printf("%d problem(s) valid\n", len(defs))
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scribble-problems: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -11,16 +11,57 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/nahtao97/scribble/internal/cache"
 	"github.com/nahtao97/scribble/internal/db"
 	"github.com/nahtao97/scribble/internal/handlers"
+	"github.com/nahtao97/scribble/internal/middleware/scheduler"
+	"github.com/nahtao97/scribble/internal/server"
 	"github.com/nahtao97/scribble/internal/services"
 )
 
+// startupMargin is how long after boot RequireReady/ReadyHandler report the
+// normal startup window before falling back to a flat 1-second retry hint.
+const startupMargin = 10 * time.Second
+
+// hotReadCache holds entries for the read-heavy, singleflight-deduplicated
+// endpoints below (top users, user metrics, today's daily challenge).
+const hotReadCacheSize = 1000
+
+// Bounded-concurrency limits for the expensive aggregation endpoints most
+// likely to be overwhelmed. These run a handful of DB-heavy queries per
+// request, so they're capped well below the server's overall connection
+// limits instead of being left to contend freely with cheaper endpoints.
+var (
+	topUsersLimiter     = scheduler.New("users.top", scheduler.Config{MaxConcurrency: 4, MaxQueueSize: 20, Timeout: 3 * time.Second})
+	userLanguageLimiter = scheduler.New("users.languages", scheduler.Config{MaxConcurrency: 4, MaxQueueSize: 20, Timeout: 3 * time.Second})
+	testCasesLimiter    = scheduler.New("problems.test_cases", scheduler.Config{MaxConcurrency: 8, MaxQueueSize: 40, Timeout: 3 * time.Second})
+)
+
 func init() {
 	// Load .env file if it exists (for local development)
 	_ = godotenv.Load()
 }
 
+// newLeaderboardCache selects the LeaderboardHandler cache backend based on
+// CACHE_BACKEND: "redis" shares cached pages across every scribble
+// replica via REDIS_ADDR, anything else (including unset) falls back to an
+// in-process LRU, which is enough for a single-replica deployment or local
+// development.
+func newLeaderboardCache() services.LeaderboardCache {
+	if os.Getenv("CACHE_BACKEND") != "redis" {
+		return services.NewInProcessLeaderboardCache(0)
+	}
+
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := goredis.NewClient(&goredis.Options{Addr: addr})
+	return services.NewRedisLeaderboardCache(client, "scribble:cache")
+}
+
 func main() {
 	// Initialize database connection
 	database, err := db.NewDatabase()
@@ -37,6 +78,20 @@ func main() {
 	}
 	fmt.Println("Database connection established")
 
+	// readyStorage gates handlers that can't tolerate a half-started server
+	// (e.g. POST /internal/leaderboards/compute) behind migrations actually
+	// having finished, rather than just the raw connection above being up.
+	// Set once the startup goroutine below completes.
+	readyStorage := server.NewReadyStorage(startupMargin)
+	go func() {
+		if err := database.Migrate(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return
+		}
+		readyStorage.Set(database.GetConnection())
+		fmt.Println("Migrations applied, storage ready")
+	}()
+
 	// Set Gin mode based on environment
 	if os.Getenv("GO_ENV") == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -45,7 +100,8 @@ func main() {
 	// Initialize router
 	router := gin.Default()
 
-	// Health check endpoint for Kubernetes probes
+	// Health check endpoint for Kubernetes probes - process liveness only,
+	// independent of whether storage has finished migrating.
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "ok",
@@ -53,11 +109,21 @@ func main() {
 		})
 	})
 
+	// Readiness endpoint for Kubernetes probes - storage + migrations applied.
+	router.GET("/ready", readyStorage.ReadyHandler)
+
 	// Initialize services
 	problemService := services.NewProblemService(database.GetConnection())
+	userService := services.NewUserService(database.GetConnection())
+	leaderboardService := services.NewLeaderboardService(database.GetConnection())
+	hotReadCache := cache.New(hotReadCacheSize)
+	leaderboardCache := newLeaderboardCache()
 
 	// Initialize handlers
-	problemHandler := handlers.NewProblemHandler(problemService)
+	problemHandler := handlers.NewProblemHandler(problemService, hotReadCache)
+	userHandler := handlers.NewUserHandler(userService, hotReadCache)
+	cacheHandler := handlers.NewCacheHandler(hotReadCache)
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService, leaderboardCache)
 
 	// Register API routes under /internal prefix
 	// These endpoints are called by the Node.js proxy (internal service-to-service)
@@ -75,11 +141,54 @@ func main() {
 
 			// GET /internal/problems/:id/test-cases - Get test cases for a problem
 			// Query param: all=true to include hidden tests (for code executor)
-			problems.GET("/:id/test-cases", problemHandler.GetTestCasesByProblemID)
+			problems.GET("/:id/test-cases", testCasesLimiter.Middleware(), problemHandler.GetTestCasesByProblemID)
+
+			// POST /internal/problems/import - Import problem definitions from a
+			// gzipped tarball (problem.yaml + tests/ per problem directory)
+			problems.POST("/import", problemHandler.ImportProblems)
+		}
+
+		// User endpoints
+		users := internal.Group("/users")
+		{
+			// GET /internal/users/top - Top users by problems solved or streak
+			users.GET("/top", topUsersLimiter.Middleware(), userHandler.GetTopUsers)
+
+			// GET /internal/users/:user_id/metrics - Aggregate metrics for a user
+			users.GET("/:user_id/metrics", userHandler.GetUserMetrics)
+
+			// GET /internal/users/:user_id/languages - Language usage stats for a user
+			users.GET("/:user_id/languages", userLanguageLimiter.Middleware(), userHandler.GetUserLanguageStats)
+
+			// GET /internal/users/username/:username/metrics - Aggregate metrics by username
+			users.GET("/username/:username/metrics", userHandler.GetUserMetricsByUsername)
+		}
+
+		// Cache admin endpoints - expected to be gated by an
+		// operator-only auth middleware in front of them.
+		cacheGroup := internal.Group("/cache")
+		{
+			cacheGroup.GET("", cacheHandler.List)
+			cacheGroup.DELETE("/:key", cacheHandler.Bust)
+		}
+
+		// Leaderboard endpoints
+		leaderboards := internal.Group("/leaderboards")
+		{
+			// POST /internal/leaderboards/compute - Recompute leaderboard
+			// rankings. Gated on readyStorage since it's the first write-heavy
+			// aggregation query hit after boot, and running it against a
+			// not-yet-migrated schema would fail in a confusing way.
+			leaderboards.POST("/compute", readyStorage.RequireReady(), leaderboardHandler.ComputeLeaderboards)
+			leaderboards.GET("/metrics", leaderboardHandler.GetAvailableMetrics)
+			leaderboards.GET("/user/:user_id", leaderboardHandler.GetUserRanks)
+			leaderboards.GET("/user/:user_id/history", leaderboardHandler.GetUserHistoricalRanks)
+			leaderboards.GET("/:metric", leaderboardHandler.GetLeaderboard)
+			leaderboards.POST("/seasons", readyStorage.RequireReady(), leaderboardHandler.OpenSeason)
+			leaderboards.POST("/seasons/:season_id/close", readyStorage.RequireReady(), leaderboardHandler.CloseSeason)
 		}
 
 		// TODO: Add submission endpoints
-		// TODO: Add leaderboard endpoints
 		// TODO: Add streak endpoints
 	}
 
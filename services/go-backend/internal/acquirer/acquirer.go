@@ -0,0 +1,267 @@
+// Package acquirer durably queues execution requests in the
+// provisioner_jobs table and hands them out to a pool of worker processes,
+// instead of running them synchronously in the API process the way
+// JobManager.ExecuteAndWait does. A submission queued this way survives an
+// API pod restart, and executor workers can scale independently of the
+// API.
+package acquirer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// NotifyChannel is the Postgres LISTEN/NOTIFY channel Enqueue posts to and
+// AcquireJob listens on, so a waiting worker wakes immediately instead of
+// only discovering new work on its next poll.
+const NotifyChannel = "provisioner_job_posted"
+
+const (
+	// DefaultHeartbeatInterval is how often RunWorker refreshes an
+	// in-progress job's heartbeat_at.
+	DefaultHeartbeatInterval = 5 * time.Second
+	// DefaultStaleAfter is how long a running job can go without a
+	// heartbeat before RequeueStale puts it back in the queue.
+	DefaultStaleAfter = 30 * time.Second
+	// defaultPollInterval bounds how long AcquireJob waits for a
+	// notification before retrying anyway, in case a NOTIFY was dropped
+	// (e.g. delivered while no connection was listening).
+	defaultPollInterval = 10 * time.Second
+)
+
+// Job is one unit of work claimed by AcquireJob.
+type Job struct {
+	ID           uint
+	SubmissionID string
+	Params       k8s.ExecutionJobParams
+	Tags         []string
+}
+
+// Acquirer queues execution requests durably and hands them out to workers
+// via SELECT ... FOR UPDATE SKIP LOCKED, so concurrent workers never claim
+// the same row twice.
+type Acquirer struct {
+	db       *gorm.DB
+	listener *pq.Listener
+}
+
+// NewAcquirer creates an Acquirer against db, opening a dedicated
+// LISTEN/NOTIFY connection to databaseURL. That connection is separate
+// from db's own pool, since a listening connection stays open and blocked
+// waiting for notifications rather than being returned between queries.
+func NewAcquirer(db *gorm.DB, databaseURL string) (*Acquirer, error) {
+	listener := pq.NewListener(databaseURL, 10*time.Second, time.Minute, nil)
+	if err := listener.Listen(NotifyChannel); err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", NotifyChannel, err)
+	}
+	return &Acquirer{db: db, listener: listener}, nil
+}
+
+// Close stops listening for notifications.
+func (a *Acquirer) Close() error {
+	return a.listener.Close()
+}
+
+// Enqueue persists params as a queued provisioner_jobs row tagged with
+// tags, notifies any worker waiting in AcquireJob, and returns the new
+// row - typically for an HTTP handler to hand the submission ID back to
+// the client immediately instead of blocking on execution.
+func (a *Acquirer) Enqueue(ctx context.Context, params k8s.ExecutionJobParams, tags []string) (*models.ProvisionerJob, error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job := &models.ProvisionerJob{
+		SubmissionID: params.SubmissionID,
+		Tags:         strings.Join(tags, ","),
+		Payload:      payload,
+		Status:       models.ProvisionerJobQueued,
+	}
+
+	err = a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		return tx.Exec("SELECT pg_notify(?, ?)", NotifyChannel, job.SubmissionID).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enqueue provisioner job: %w", err)
+	}
+
+	return job, nil
+}
+
+// AcquireJob claims the oldest queued job that is untagged or whose tags
+// overlap with tags (any queued job at all, if tags is empty), marking it
+// running under workerID. If nothing is immediately available, it waits for a
+// notification on NotifyChannel - falling back to a plain poll every
+// defaultPollInterval in case a notification was missed - until ctx is
+// cancelled.
+func (a *Acquirer) AcquireJob(ctx context.Context, workerID string, tags []string) (*Job, error) {
+	for {
+		job, err := a.tryAcquire(ctx, workerID, tags)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-a.listener.Notify:
+		case <-time.After(defaultPollInterval):
+		}
+	}
+}
+
+func (a *Acquirer) tryAcquire(ctx context.Context, workerID string, tags []string) (*Job, error) {
+	var acquired *Job
+
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.ProvisionerJobQueued)
+
+		if len(tags) > 0 {
+			conds := make([]string, 0, len(tags))
+			args := make([]interface{}, 0, len(tags))
+			for _, tag := range tags {
+				conds = append(conds, "tags LIKE ?")
+				args = append(args, "%"+tag+"%")
+			}
+			query = query.Where("(tags = '' OR "+strings.Join(conds, " OR ")+")", args...)
+		}
+
+		var row models.ProvisionerJob
+		err := query.Order("created_at").First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		row.Status = models.ProvisionerJobRunning
+		row.WorkerID = workerID
+		row.LockedAt = &now
+		row.HeartbeatAt = &now
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+
+		var params k8s.ExecutionJobParams
+		if err := json.Unmarshal(row.Payload, &params); err != nil {
+			return fmt.Errorf("failed to decode job %d payload: %w", row.ID, err)
+		}
+
+		var jobTags []string
+		if row.Tags != "" {
+			jobTags = strings.Split(row.Tags, ",")
+		}
+
+		acquired = &Job{ID: row.ID, SubmissionID: row.SubmissionID, Params: params, Tags: jobTags}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire job: %w", err)
+	}
+
+	return acquired, nil
+}
+
+// GetJobStatus looks up the provisioner_jobs row for submissionID, for a
+// handler polling a queued submission's progress.
+func (a *Acquirer) GetJobStatus(ctx context.Context, submissionID string) (*models.ProvisionerJob, error) {
+	var job models.ProvisionerJob
+	if err := a.db.WithContext(ctx).Where("submission_id = ?", submissionID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Heartbeat refreshes jobID's heartbeat_at, so RequeueStale doesn't treat
+// a still-running job as abandoned.
+func (a *Acquirer) Heartbeat(ctx context.Context, jobID uint, workerID string) error {
+	return a.db.WithContext(ctx).Model(&models.ProvisionerJob{}).
+		Where("id = ? AND worker_id = ?", jobID, workerID).
+		Update("heartbeat_at", time.Now()).Error
+}
+
+// Complete marks jobID completed with result.
+func (a *Acquirer) Complete(ctx context.Context, jobID uint, result *k8s.ExecutionResult) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job %d result: %w", jobID, err)
+	}
+	return a.db.WithContext(ctx).Model(&models.ProvisionerJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{"status": models.ProvisionerJobCompleted, "result": payload}).Error
+}
+
+// Fail marks jobID failed with execErr's message.
+func (a *Acquirer) Fail(ctx context.Context, jobID uint, execErr error) error {
+	return a.db.WithContext(ctx).Model(&models.ProvisionerJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{"status": models.ProvisionerJobFailed, "error": execErr.Error()}).Error
+}
+
+// RequeueStale puts every running job whose heartbeat_at is older than
+// staleAfter back into the queue, for when a worker crashes or is killed
+// mid-job, and notifies waiting workers if anything was requeued. It
+// returns how many jobs were requeued.
+func (a *Acquirer) RequeueStale(ctx context.Context, staleAfter time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-staleAfter)
+
+	result := a.db.WithContext(ctx).Model(&models.ProvisionerJob{}).
+		Where("status = ? AND heartbeat_at < ?", models.ProvisionerJobRunning, cutoff).
+		Updates(map[string]interface{}{
+			"status":       models.ProvisionerJobQueued,
+			"worker_id":    "",
+			"heartbeat_at": sql.NullTime{},
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		_ = a.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", NotifyChannel, "requeued").Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// StartStaleRequeuer calls RequeueStale every interval until ctx is
+// cancelled, logging failures to errs rather than stopping - a single
+// failed sweep shouldn't stop future ones from catching abandoned jobs.
+func (a *Acquirer) StartStaleRequeuer(ctx context.Context, interval, staleAfter time.Duration, errs func(error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.RequeueStale(ctx, staleAfter); err != nil && errs != nil {
+					errs(err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,56 @@
+package acquirer
+
+import (
+	"context"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// RunWorker repeatedly acquires a job tagged with one of tags, executes it
+// against executor, and reports the outcome back to Postgres, until ctx is
+// cancelled. Multiple workers (in this process or others) can call
+// RunWorker concurrently against the same Acquirer/database - SKIP LOCKED
+// guarantees no two of them acquire the same job.
+func (a *Acquirer) RunWorker(ctx context.Context, workerID string, tags []string, executor services.JobExecutor, heartbeatInterval time.Duration) error {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	for {
+		job, err := a.AcquireJob(ctx, workerID, tags)
+		if err != nil {
+			return err
+		}
+
+		a.runJob(ctx, workerID, job, executor, heartbeatInterval)
+	}
+}
+
+// runJob executes a single acquired job, heartbeating periodically for as
+// long as it runs, and reports the result (or failure) back via Complete
+// or Fail.
+func (a *Acquirer) runJob(ctx context.Context, workerID string, job *Job, executor services.JobExecutor, heartbeatInterval time.Duration) {
+	heartbeatCtx, cancelHeartbeat := context.WithCancel(ctx)
+	defer cancelHeartbeat()
+
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				_ = a.Heartbeat(ctx, job.ID, workerID)
+			}
+		}
+	}()
+
+	result, err := executor.ExecuteAndWait(ctx, job.Params)
+	if err != nil && result == nil {
+		_ = a.Fail(ctx, job.ID, err)
+		return
+	}
+	_ = a.Complete(ctx, job.ID, result)
+}
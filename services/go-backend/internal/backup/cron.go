@@ -0,0 +1,125 @@
+package backup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed standard 5-field cron expression (minute hour
+// day-of-month month day-of-week), supporting "*", single values, comma
+// lists, ranges ("1-5") and steps ("*/15"). It's deliberately small - just
+// enough for "run the backup nightly" / "every 6 hours" style schedules,
+// not a general-purpose cron implementation.
+type cronSchedule struct {
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+}
+
+// parseCronSchedule parses expr into a cronSchedule.
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	minutes, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	hours, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	days, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field: %w", err)
+	}
+	months, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	weekdays, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field: %w", err)
+	}
+
+	return &cronSchedule{minutes: minutes, hours: hours, days: days, months: months, weekdays: weekdays}, nil
+}
+
+// parseCronField parses one cron field ("*", "5", "1-5", "*/15",
+// "1,15,30") into the set of values it matches within [min, max].
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		base := part
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			base = part[:i]
+			parsedStep, err := strconv.Atoi(part[i+1:])
+			if err != nil || parsedStep <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			step = parsedStep
+		}
+
+		lo, hi := min, max
+		switch {
+		case base == "*":
+			// lo/hi already span the full range
+		case strings.Contains(base, "-"):
+			bounds := strings.SplitN(base, "-", 2)
+			parsedLo, err1 := strconv.Atoi(bounds[0])
+			parsedHi, err2 := strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || parsedLo > parsedHi {
+				return nil, fmt.Errorf("invalid range %q", base)
+			}
+			lo, hi = parsedLo, parsedHi
+		default:
+			v, err := strconv.Atoi(base)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", base)
+			}
+			lo, hi = v, v
+		}
+
+		for v := lo; v <= hi; v += step {
+			if v < min || v > max {
+				return nil, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+			}
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// matches reports whether t falls on a minute cronSchedule fires on.
+func (c *cronSchedule) matches(t time.Time) bool {
+	return c.minutes[t.Minute()] &&
+		c.hours[t.Hour()] &&
+		c.days[t.Day()] &&
+		c.months[int(t.Month())] &&
+		c.weekdays[int(t.Weekday())]
+}
+
+// next returns the next minute-aligned time after after that c fires on,
+// searching at most one year ahead.
+func (c *cronSchedule) next(after time.Time) (time.Time, error) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(1, 0, 0)
+
+	for t.Before(limit) {
+		if c.matches(t) {
+			return t, nil
+		}
+		t = t.Add(time.Minute)
+	}
+
+	return time.Time{}, fmt.Errorf("no matching time found within a year of %s", after)
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// manifestKey is the well-known key, relative to a snapshot's ID prefix,
+// that holds its Manifest.
+const manifestKey = "manifest.json"
+
+// TableManifest records one table's dump within a snapshot.
+type TableManifest struct {
+	Table    string `json:"table"`
+	Key      string `json:"key"`
+	Checksum string `json:"sha256"`
+	RowCount int64  `json:"row_count"`
+}
+
+// Manifest describes one snapshot: which tables it covers, where each
+// table's dump lives, and the schema_migrations version the database was
+// at when the snapshot was taken, so Restore can refuse a snapshot whose
+// schema predates (or postdates) the live database's.
+type Manifest struct {
+	SnapshotID    string          `json:"snapshot_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	SchemaVersion int             `json:"schema_version"`
+	Tables        []TableManifest `json:"tables"`
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verify recomputes each table's checksum against data and returns an
+// error naming the first mismatch found, so a snapshot corrupted in
+// transit or at rest fails loudly instead of silently restoring bad data.
+func (m *Manifest) verify(data map[string][]byte) error {
+	for _, t := range m.Tables {
+		blob, ok := data[t.Table]
+		if !ok {
+			return fmt.Errorf("snapshot %s is missing a dump for table %s", m.SnapshotID, t.Table)
+		}
+		if got := checksum(blob); got != t.Checksum {
+			return fmt.Errorf("snapshot %s: checksum mismatch for table %s (want %s, got %s)", m.SnapshotID, t.Table, t.Checksum, got)
+		}
+	}
+	return nil
+}
+
+func marshalManifest(m *Manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
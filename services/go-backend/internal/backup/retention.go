@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy decides which snapshots Service.Prune deletes, given
+// every existing snapshot's creation time in descending order (newest
+// first). Only one of KeepLast or KeepDailyWeeklyMonthly should be set; if
+// both are zero/false, Prune deletes nothing.
+type RetentionPolicy struct {
+	// KeepLast keeps the N most recent snapshots and deletes the rest.
+	KeepLast int
+	// KeepDailyWeeklyMonthly keeps the most recent snapshot of each of the
+	// last 7 days, the most recent of each of the last 4 weeks, and the
+	// most recent of each of the last 12 months - a coarser policy for
+	// longer retention windows without keeping every run.
+	KeepDailyWeeklyMonthly bool
+}
+
+// snapshotsToDelete returns the IDs, among snapshots (any order), that p
+// says should be removed.
+func (p RetentionPolicy) snapshotsToDelete(snapshots []snapshotMeta) []string {
+	sorted := make([]snapshotMeta, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].createdAt.After(sorted[j].createdAt) })
+
+	keep := make(map[string]bool, len(sorted))
+
+	if p.KeepLast > 0 {
+		for i := 0; i < p.KeepLast && i < len(sorted); i++ {
+			keep[sorted[i].id] = true
+		}
+	}
+
+	if p.KeepDailyWeeklyMonthly {
+		for _, id := range bucketLatest(sorted, dayBucket, 7) {
+			keep[id] = true
+		}
+		for _, id := range bucketLatest(sorted, weekBucket, 4) {
+			keep[id] = true
+		}
+		for _, id := range bucketLatest(sorted, monthBucket, 12) {
+			keep[id] = true
+		}
+	}
+
+	var toDelete []string
+	for _, s := range sorted {
+		if !keep[s.id] {
+			toDelete = append(toDelete, s.id)
+		}
+	}
+	return toDelete
+}
+
+type snapshotMeta struct {
+	id        string
+	createdAt time.Time
+}
+
+// bucketLatest keeps the newest snapshot in each of the most recent
+// maxBuckets distinct buckets (as produced by bucketFn) among sorted
+// (already newest-first) snapshots.
+func bucketLatest(sorted []snapshotMeta, bucketFn func(time.Time) string, maxBuckets int) map[string]string {
+	kept := make(map[string]string)
+	seenBuckets := make(map[string]bool)
+
+	for _, s := range sorted {
+		if len(seenBuckets) >= maxBuckets {
+			break
+		}
+		b := bucketFn(s.createdAt)
+		if seenBuckets[b] {
+			continue
+		}
+		seenBuckets[b] = true
+		kept[b] = s.id
+	}
+
+	return kept
+}
+
+func dayBucket(t time.Time) string { return t.UTC().Format("2006-01-02") }
+
+func weekBucket(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+func monthBucket(t time.Time) string { return t.UTC().Format("2006-01") }
@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Config configures a Service.
+type Config struct {
+	// Tables lists which tables Run snapshots. Defaults to DefaultTables
+	// if empty.
+	Tables []string
+	// Retention decides which snapshots Prune removes after a successful
+	// Run.
+	Retention RetentionPolicy
+}
+
+// DefaultConfig snapshots DefaultTables and keeps the 30 most recent
+// snapshots.
+func DefaultConfig() Config {
+	return Config{
+		Tables:    DefaultTables,
+		Retention: RetentionPolicy{KeepLast: 30},
+	}
+}
+
+// Service periodically snapshots a set of tables to Storage and can
+// restore a chosen snapshot back into the database.
+type Service struct {
+	db      *gorm.DB
+	storage Storage
+	cfg     Config
+}
+
+// NewService creates a Service backed by db, writing snapshots to storage.
+func NewService(db *gorm.DB, storage Storage, cfg Config) *Service {
+	if len(cfg.Tables) == 0 {
+		cfg.Tables = DefaultTables
+	}
+	return &Service{db: db, storage: storage, cfg: cfg}
+}
+
+// Run takes one snapshot of every configured table, uploads it to
+// storage under a timestamp-derived snapshot ID, then applies the
+// retention policy. It returns the new snapshot's ID.
+func (s *Service) Run(ctx context.Context, now time.Time) (string, error) {
+	snapshotID := now.UTC().Format("20060102T150405Z")
+	snapshotter := NewSnapshotter(s.db, s.cfg.Tables)
+
+	schemaVersion, err := currentSchemaVersion(s.db)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := &Manifest{
+		SnapshotID:    snapshotID,
+		CreatedAt:     now.UTC(),
+		SchemaVersion: schemaVersion,
+	}
+
+	for _, table := range s.cfg.Tables {
+		dump, rowCount, err := snapshotter.DumpTable(table)
+		if err != nil {
+			return "", fmt.Errorf("failed to dump table %s: %w", table, err)
+		}
+
+		key := fmt.Sprintf("%s/%s.jsonl", snapshotID, table)
+		if err := s.storage.Put(ctx, key, dump); err != nil {
+			return "", fmt.Errorf("failed to upload dump for table %s: %w", table, err)
+		}
+
+		manifest.Tables = append(manifest.Tables, TableManifest{
+			Table:    table,
+			Key:      key,
+			Checksum: checksum(dump),
+			RowCount: rowCount,
+		})
+	}
+
+	manifestData, err := marshalManifest(manifest)
+	if err != nil {
+		return "", err
+	}
+	if err := s.storage.Put(ctx, fmt.Sprintf("%s/%s", snapshotID, manifestKey), manifestData); err != nil {
+		return "", fmt.Errorf("failed to upload manifest: %w", err)
+	}
+
+	if err := s.Prune(ctx); err != nil {
+		return snapshotID, fmt.Errorf("snapshot %s succeeded but retention pruning failed: %w", snapshotID, err)
+	}
+
+	return snapshotID, nil
+}
+
+// Prune deletes snapshots the configured retention policy no longer wants
+// kept.
+func (s *Service) Prune(ctx context.Context) error {
+	snapshots, err := s.listSnapshots(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	for _, id := range s.cfg.Retention.snapshotsToDelete(snapshots) {
+		if err := s.deleteSnapshot(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete snapshot %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) listSnapshots(ctx context.Context) ([]snapshotMeta, error) {
+	keys, err := s.storage.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var snapshots []snapshotMeta
+
+	for _, key := range keys {
+		id := snapshotIDFromKey(key)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		createdAt, err := time.Parse("20060102T150405Z", id)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshotMeta{id: id, createdAt: createdAt})
+	}
+
+	return snapshots, nil
+}
+
+func (s *Service) deleteSnapshot(ctx context.Context, snapshotID string) error {
+	keys, err := s.storage.List(ctx, snapshotID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartTicker runs Run every time cronExpr fires, until ctx is cancelled,
+// logging failures to errs rather than stopping - a single failed backup
+// shouldn't take future ones out of service.
+func (s *Service) StartTicker(ctx context.Context, cronExpr string, errs func(error)) error {
+	schedule, err := parseCronSchedule(cronExpr)
+	if err != nil {
+		return fmt.Errorf("failed to parse backup schedule %q: %w", cronExpr, err)
+	}
+
+	go func() {
+		for {
+			next, err := schedule.next(time.Now())
+			if err != nil {
+				if errs != nil {
+					errs(err)
+				}
+				return
+			}
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case fireTime := <-timer.C:
+				if _, err := s.Run(ctx, fireTime); err != nil && errs != nil {
+					errs(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTables are the tables Service snapshots when a caller doesn't
+// override them: everything StreakService and DailyChallengeService own,
+// plus submissions once that table exists.
+var DefaultTables = []string{
+	"streaks",
+	"user_streaks",
+	"streak_history",
+	"daily_challenges",
+	"submissions",
+}
+
+// Snapshotter dumps a fixed set of tables to newline-delimited JSON, one
+// line per row, using each row's column names as JSON object keys - so a
+// dump is readable independent of this package's own Go types and survives
+// column additions/removals in either direction.
+type Snapshotter struct {
+	db     *gorm.DB
+	tables []string
+}
+
+// NewSnapshotter creates a Snapshotter for db, dumping tables.
+func NewSnapshotter(db *gorm.DB, tables []string) *Snapshotter {
+	return &Snapshotter{db: db, tables: tables}
+}
+
+// DumpTable reads every row of table as newline-delimited JSON.
+func (s *Snapshotter) DumpTable(table string) ([]byte, int64, error) {
+	rows, err := s.db.Table(table).Rows()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read columns for table %s: %w", table, err)
+	}
+
+	var buf bytes.Buffer
+	var count int64
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		scanTargets := make([]interface{}, len(columns))
+		for i := range values {
+			scanTargets[i] = &values[i]
+		}
+		if err := rows.Scan(scanTargets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan row from table %s: %w", table, err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = normalizeValue(values[i])
+		}
+
+		line, err := json.Marshal(row)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal row from table %s: %w", table, err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed reading rows from table %s: %w", table, err)
+	}
+
+	return buf.Bytes(), count, nil
+}
+
+// normalizeValue converts driver-returned []byte (the typical
+// database/sql representation of text/bytea columns) to string, so the
+// JSON encoding of a dump is readable text rather than base64.
+func normalizeValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// currentSchemaVersion reads the highest applied migration version, for
+// stamping into a snapshot's Manifest.
+func currentSchemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Raw("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read current schema version: %w", err)
+	}
+	return version, nil
+}
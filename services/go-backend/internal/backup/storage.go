@@ -0,0 +1,55 @@
+// Package backup periodically snapshots the Postgres tables owned by
+// StreakService and DailyChallengeService (and future submission storage)
+// to pluggable object storage, so a bad deploy or a bad migration doesn't
+// mean losing streak/submission history. A snapshot is a set of named
+// blobs (one per table, newline-delimited JSON) plus a Manifest recording
+// each blob's SHA-256 checksum and the schema_migrations version the
+// snapshot was taken at, so Restore can refuse a snapshot that predates a
+// schema change it doesn't know how to replay.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Storage is the pluggable object-storage backend a Service reads and
+// writes snapshots through. Keys are slash-separated, scoped under a
+// snapshot ID (e.g. "20260725T120000Z/manifest.json").
+type Storage interface {
+	// Put writes data to key, replacing any existing object there.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get reads key's contents.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns every key under prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes key. Deleting a key that doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage builds a Storage from rawURL's scheme: "file://" for a local
+// or NFS-mounted directory, "s3://bucket/prefix" for S3-compatible object
+// storage.
+func NewStorage(rawURL string) (Storage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse storage url %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewFileStorage(u.Path), nil
+	case "s3":
+		return NewS3Storage(u.Host, trimLeadingSlash(u.Path))
+	default:
+		return nil, fmt.Errorf("unsupported storage scheme %q (want file:// or s3://)", u.Scheme)
+	}
+}
+
+func trimLeadingSlash(s string) string {
+	if len(s) > 0 && s[0] == '/' {
+		return s[1:]
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStorage implements Storage on a local or NFS-mounted directory - the
+// simplest backend, and the one a K8s CronJob backing onto a PVC would use.
+type FileStorage struct {
+	root string
+}
+
+// NewFileStorage creates a FileStorage rooted at root.
+func NewFileStorage(root string) *FileStorage {
+	return &FileStorage{root: root}
+}
+
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// Put implements Storage.
+func (s *FileStorage) Put(ctx context.Context, key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get implements Storage.
+func (s *FileStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// List implements Storage.
+func (s *FileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	root := s.path(prefix)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	return keys, nil
+}
+
+// Delete implements Storage.
+func (s *FileStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// snapshotIDFromKey extracts the leading path segment ("20260725T120000Z")
+// from a key returned by List, for grouping keys back into snapshots.
+func snapshotIDFromKey(key string) string {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
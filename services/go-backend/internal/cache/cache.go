@@ -0,0 +1,164 @@
+// Package cache provides a singleflight-deduplicated, TTL-expiring cache
+// for hot read handlers (top users, user metrics, today's daily
+// challenge). On a miss, GetOrLoad ensures only one goroutine calls the
+// underlying service for a given key even when many requests race in at
+// once - the thundering-herd pattern load tests like TestUserRankLoad and
+// TestConcurrentPagination exercise against these endpoints.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is an LRU-bounded, per-key-TTL cache. It is safe for concurrent
+// use.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List // front = most recently used
+
+	group singleflight.Group
+}
+
+type entry struct {
+	key       string
+	class     string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// New creates a Cache that evicts its least recently used entry once it
+// holds more than maxEntries.
+func New(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the cached value for key if it's present and not expired.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		c.removeLocked(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// set stores value under key with the given class (for metrics labeling)
+// and TTL, evicting the least recently used entry if the cache is full.
+func (c *Cache) set(class, key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*entry).value = value
+		elem.Value.(*entry).expiresAt = time.Now().Add(ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(&entry{
+		key:       key,
+		class:     class,
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	})
+	c.items[key] = elem
+
+	for c.order.Len() > c.maxEntries {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// Delete evicts key, if present, returning whether anything was removed.
+func (c *Cache) Delete(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.removeLocked(elem)
+	return true
+}
+
+func (c *Cache) removeLocked(elem *list.Element) {
+	e := elem.Value.(*entry)
+	delete(c.items, e.key)
+	c.order.Remove(elem)
+}
+
+// EntryInfo describes one cache entry for the admin listing endpoint.
+type EntryInfo struct {
+	Key       string    `json:"key"`
+	Class     string    `json:"class"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Entries lists every unexpired entry, most recently used first.
+func (c *Cache) Entries() []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	infos := make([]EntryInfo, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*entry)
+		if now.After(e.expiresAt) {
+			continue
+		}
+		infos = append(infos, EntryInfo{Key: e.key, Class: e.class, ExpiresAt: e.expiresAt})
+	}
+	return infos
+}
+
+// GetOrLoad returns the cached value for key if present and unexpired.
+// On a miss, it calls load exactly once even if multiple goroutines ask
+// for key concurrently (via singleflight) and caches the result under
+// class/key for ttl. class is a low-cardinality label (e.g.
+// "top_users", "user_metrics") used for the hit/miss metrics below -
+// unlike key, it must not embed per-request values.
+func (c *Cache) GetOrLoad(class, key string, ttl time.Duration, load func() (interface{}, error)) (interface{}, error) {
+	if value, ok := c.Get(key); ok {
+		Hits.WithLabelValues(class).Inc()
+		return value, nil
+	}
+	Misses.WithLabelValues(class).Inc()
+
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// Re-check under singleflight: another goroutine may have
+		// populated the cache between our Get above and acquiring the
+		// singleflight lock for this key.
+		if value, ok := c.Get(key); ok {
+			return value, nil
+		}
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.set(class, key, value, ttl)
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,26 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Hits counts GetOrLoad calls served from the cache without invoking
+// load, labeled by class (e.g. "top_users", "user_metrics").
+var Hits = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "cache",
+	Name:      "hits_total",
+	Help:      "Number of cache reads served without calling the underlying loader, per key class.",
+}, []string{"class"})
+
+// Misses counts GetOrLoad calls that invoked load (whether or not they
+// were the goroutine that actually ran it - singleflight-joined callers
+// still count as a miss from their own perspective).
+var Misses = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "cache",
+	Name:      "misses_total",
+	Help:      "Number of cache reads that required calling the underlying loader, per key class.",
+}, []string{"class"})
+
+func init() {
+	prometheus.MustRegister(Hits, Misses)
+}
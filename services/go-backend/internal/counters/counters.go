@@ -0,0 +1,233 @@
+// Package counters accumulates per-problem submission statistics in
+// memory and periodically flushes them to the database, so reads no
+// longer have to scan the submissions table.
+package counters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultFlushInterval is how often a caller should invoke Tick (or
+// StartFlusher's interval) to keep problem_submission_stats current.
+const DefaultFlushInterval = 15 * time.Minute
+
+// problemCounts accumulates one window's worth of submission counts for a
+// single problem.
+type problemCounts struct {
+	mu          sync.Mutex
+	submissions int64
+	accepted    int64
+	sumTimeMs   int64
+	sumMemKb    int64
+}
+
+func (c *problemCounts) record(accepted bool, timeMs, memKb int) {
+	c.mu.Lock()
+	c.submissions++
+	if accepted {
+		c.accepted++
+	}
+	c.sumTimeMs += int64(timeMs)
+	c.sumMemKb += int64(memKb)
+	c.mu.Unlock()
+}
+
+func (c *problemCounts) snapshot() (submissions, accepted, sumTimeMs, sumMemKb int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.submissions, c.accepted, c.sumTimeMs, c.sumMemKb
+}
+
+func (c *problemCounts) set(submissions, accepted, sumTimeMs, sumMemKb int64) {
+	c.mu.Lock()
+	c.submissions, c.accepted, c.sumTimeMs, c.sumMemKb = submissions, accepted, sumTimeMs, sumMemKb
+	c.mu.Unlock()
+}
+
+func (c *problemCounts) reset() {
+	c.set(0, 0, 0, 0)
+}
+
+// Counters accumulates per-problem submission counts in two alternating
+// halves, oddCounts and evenCounts, so a periodic Tick can flush and zero
+// the half writers have stopped using without ever blocking Record. The
+// active half is whichever Record currently writes to; Tick flushes the
+// other half, then flips which half is active.
+type Counters struct {
+	db *gorm.DB
+
+	active int32 // 0 selects oddCounts as active, 1 selects evenCounts
+
+	mu         sync.RWMutex
+	oddCounts  map[uint]*problemCounts
+	evenCounts map[uint]*problemCounts
+
+	lastFlush time.Time
+}
+
+// New creates an empty Counters backed by db for flushing and warming.
+func New(db *gorm.DB) *Counters {
+	return &Counters{
+		db:         db,
+		oddCounts:  make(map[uint]*problemCounts),
+		evenCounts: make(map[uint]*problemCounts),
+		lastFlush:  time.Now(),
+	}
+}
+
+// Record folds one submission's outcome into problemID's active bucket.
+func (c *Counters) Record(problemID uint, accepted bool, timeMs, memKb int) {
+	c.countsFor(c.activeTable(), problemID).record(accepted, timeMs, memKb)
+}
+
+// Snapshot returns problemID's current active-bucket counts without
+// resetting them, so callers can compose them with already-flushed
+// history.
+func (c *Counters) Snapshot(problemID uint) (submissions, accepted, sumTimeMs, sumMemKb int64) {
+	c.mu.RLock()
+	counts, ok := c.activeTable()[problemID]
+	c.mu.RUnlock()
+	if !ok {
+		return 0, 0, 0, 0
+	}
+	return counts.snapshot()
+}
+
+// Tick flushes every problem's inactive-half counts to
+// problem_submission_stats as one row per problem for the window since the
+// last Tick, zeros the inactive half, then flips which half is active -
+// so writers immediately after a Tick land in the half that was just
+// flushed rather than the one about to be flushed next time.
+func (c *Counters) Tick() error {
+	windowStart := c.lastFlush
+
+	c.mu.RLock()
+	table := c.inactiveTable()
+	problemIDs := make([]uint, 0, len(table))
+	for problemID := range table {
+		problemIDs = append(problemIDs, problemID)
+	}
+	c.mu.RUnlock()
+
+	for _, problemID := range problemIDs {
+		counts := table[problemID]
+		submissions, accepted, sumTimeMs, sumMemKb := counts.snapshot()
+		if submissions == 0 {
+			continue
+		}
+
+		row := models.ProblemSubmissionStatsRow{
+			ProblemID:   problemID,
+			WindowStart: windowStart,
+			Submissions: submissions,
+			Accepted:    accepted,
+			SumTime:     sumTimeMs,
+			SumMem:      sumMemKb,
+		}
+		if err := c.db.Create(&row).Error; err != nil {
+			return fmt.Errorf("failed to flush problem %d submission stats: %w", problemID, err)
+		}
+		counts.reset()
+	}
+
+	c.lastFlush = time.Now()
+	atomic.StoreInt32(&c.active, 1-atomic.LoadInt32(&c.active))
+
+	return nil
+}
+
+// StartFlusher calls Tick every interval until ctx is cancelled, logging
+// flush failures to errs rather than stopping - a single failed flush
+// shouldn't take the in-memory counters out of service. Intended to be
+// called once, right after New.
+func (c *Counters) StartFlusher(ctx context.Context, interval time.Duration, errs func(error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Tick(); err != nil && errs != nil {
+					errs(err)
+				}
+			}
+		}
+	}()
+}
+
+// WarmFromDB seeds the currently-active bucket from submissions created
+// since windowStart, so a freshly started process doesn't undercount the
+// window it started partway through.
+func (c *Counters) WarmFromDB(windowStart time.Time) error {
+	type row struct {
+		ProblemID string
+		Total     int64
+		Accepted  int64
+		SumTime   int64
+		SumMem    int64
+	}
+	var rows []row
+	if err := c.db.Model(&models.Submission{}).
+		Select("problem_id, COUNT(*) as total, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) as accepted, SUM(execution_time_ms) as sum_time, SUM(memory_used_kb) as sum_mem", models.StatusAccepted).
+		Where("created_at >= ?", windowStart).
+		Group("problem_id").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to warm counters from db: %w", err)
+	}
+
+	table := c.activeTable()
+	for _, r := range rows {
+		id, err := strconv.ParseUint(r.ProblemID, 10, 32)
+		if err != nil {
+			continue
+		}
+		c.countsFor(table, uint(id)).set(r.Total, r.Accepted, r.SumTime, r.SumMem)
+	}
+
+	c.lastFlush = windowStart
+
+	return nil
+}
+
+func (c *Counters) activeTable() map[uint]*problemCounts {
+	if atomic.LoadInt32(&c.active) == 0 {
+		return c.oddCounts
+	}
+	return c.evenCounts
+}
+
+func (c *Counters) inactiveTable() map[uint]*problemCounts {
+	if atomic.LoadInt32(&c.active) == 0 {
+		return c.evenCounts
+	}
+	return c.oddCounts
+}
+
+func (c *Counters) countsFor(table map[uint]*problemCounts, problemID uint) *problemCounts {
+	c.mu.RLock()
+	counts, ok := table[problemID]
+	c.mu.RUnlock()
+	if ok {
+		return counts
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if counts, ok := table[problemID]; ok {
+		return counts
+	}
+	counts = &problemCounts{}
+	table[problemID] = counts
+	return counts
+}
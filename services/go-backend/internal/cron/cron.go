@@ -0,0 +1,122 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is the set of values (minute, hour, day-of-month, month, or
+// day-of-week) a single field of a cron expression matches.
+type field struct {
+	values map[int]struct{}
+}
+
+func (f field) has(v int) bool {
+	_, ok := f.values[v]
+	return ok
+}
+
+// schedule is a parsed standard 5-field cron expression
+// ("minute hour dom month dow").
+type schedule struct {
+	minute, hour, dom, month, dow field
+}
+
+// fieldRanges bounds each of the 5 fields, in order.
+var fieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// parseExpr parses a standard 5-field cron expression. Each field supports
+// "*", a single value, an inclusive "lo-hi" range, a comma-separated list of
+// any of those, and a "/step" suffix on any of them (e.g. "*/15", "0-30/10").
+func parseExpr(expr string) (schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return schedule{}, fmt.Errorf("cron: expression %q must have 5 fields (minute hour dom month dow)", expr)
+	}
+
+	parsed := make([]field, 5)
+	for i, f := range fields {
+		pf, err := parseField(f, fieldRanges[i][0], fieldRanges[i][1])
+		if err != nil {
+			return schedule{}, fmt.Errorf("cron: field %d (%q): %w", i, f, err)
+		}
+		parsed[i] = pf
+	}
+
+	return schedule{minute: parsed[0], hour: parsed[1], dom: parsed[2], month: parsed[3], dow: parsed[4]}, nil
+}
+
+func parseField(f string, min, max int) (field, error) {
+	values := make(map[int]struct{})
+
+	for _, part := range strings.Split(f, ",") {
+		base, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			base = part[:idx]
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return field{}, fmt.Errorf("invalid step in %q", part)
+			}
+			step = s
+		}
+
+		lo, hi, err := fieldBounds(base, min, max)
+		if err != nil {
+			return field{}, err
+		}
+		if lo < min || hi > max || lo > hi {
+			return field{}, fmt.Errorf("value %q out of range [%d,%d]", base, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			values[v] = struct{}{}
+		}
+	}
+
+	return field{values: values}, nil
+}
+
+func fieldBounds(base string, min, max int) (lo, hi int, err error) {
+	switch {
+	case base == "*":
+		return min, max, nil
+	case strings.Contains(base, "-"):
+		bounds := strings.SplitN(base, "-", 2)
+		lo, err = strconv.Atoi(bounds[0])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", base)
+		}
+		hi, err = strconv.Atoi(bounds[1])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", base)
+		}
+		return lo, hi, nil
+	default:
+		v, err := strconv.Atoi(base)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid value %q", base)
+		}
+		return v, v, nil
+	}
+}
+
+// maxLookahead bounds how far into the future Next searches before giving
+// up, so a field combination that can never match (e.g. day-of-month 31 in
+// a month field restricted to February) can't loop forever.
+const maxLookahead = 366 * 24 * 60
+
+// Next returns the earliest minute-aligned time strictly after "after"
+// (evaluated in after's own location) that matches the schedule. It returns
+// the zero time if no match is found within a year.
+func (s schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < maxLookahead; i++ {
+		if s.month.has(int(t.Month())) && s.dom.has(t.Day()) && s.dow.has(int(t.Weekday())) &&
+			s.hour.has(t.Hour()) && s.minute.has(t.Minute()) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
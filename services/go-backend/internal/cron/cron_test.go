@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExprRejectsWrongFieldCount(t *testing.T) {
+	if _, err := parseExpr("* * *"); err == nil {
+		t.Fatal("expected an error for a 3-field expression")
+	}
+}
+
+func TestParseExprRejectsOutOfRangeValue(t *testing.T) {
+	if _, err := parseExpr("60 * * * *"); err == nil {
+		t.Fatal("expected an error for minute 60")
+	}
+}
+
+func TestScheduleNextDailyAtFixedTime(t *testing.T) {
+	s, err := parseExpr("5 0 * * *")
+	if err != nil {
+		t.Fatalf("parseExpr failed: %v", err)
+	}
+
+	after := time.Date(2026, 7, 26, 10, 0, 0, 0, time.UTC)
+	next := s.Next(after)
+
+	want := time.Date(2026, 7, 27, 0, 5, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, next, want)
+	}
+}
+
+func TestScheduleNextStepExpression(t *testing.T) {
+	s, err := parseExpr("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("parseExpr failed: %v", err)
+	}
+
+	after := time.Date(2026, 7, 26, 10, 1, 0, 0, time.UTC)
+	next := s.Next(after)
+
+	want := time.Date(2026, 7, 26, 10, 15, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, next, want)
+	}
+}
+
+func TestScheduleNextIsStrictlyAfter(t *testing.T) {
+	s, err := parseExpr("0 2 * * *")
+	if err != nil {
+		t.Fatalf("parseExpr failed: %v", err)
+	}
+
+	exact := time.Date(2026, 7, 26, 2, 0, 0, 0, time.UTC)
+	next := s.Next(exact)
+
+	want := time.Date(2026, 7, 27, 2, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v (strictly after, not equal to, the input)", exact, next, want)
+	}
+}
@@ -0,0 +1,32 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// NewDailyChallengeRotationJob returns a Job that selects the next daily
+// challenge via challenges (weighted by challenges' configured
+// ChallengeSelector, e.g. services.NewWeightedRandomSelector, which already
+// accounts for difficulty distribution and last-served date). Idempotent:
+// SelectNextChallenge creates nothing and returns ErrChallengeExists if
+// today's challenge already exists, which this job treats as success, so
+// running this job more than once a day is harmless.
+func NewDailyChallengeRotationJob(challenges *services.DailyChallengeService) Job {
+	return Job{
+		Name: "daily_challenge_rotation",
+		Run: func(ctx context.Context) error {
+			_, err := challenges.SelectNextChallenge()
+			if errors.Is(err, services.ErrChallengeExists) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("daily challenge rotation: %w", err)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/services"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupJobsTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.UserStreak{}, &models.StreakHistory{}, &models.DailyChallenge{}, &models.Problem{})
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+	return db
+}
+
+func TestStreakSweepJob_BreaksExpiredStreaksAcrossBatches(t *testing.T) {
+	db := setupJobsTestDB(t)
+	streaks := services.NewStreakService(db, services.NewDailyChallengeService(db, services.DefaultDailyChallengeServiceConfig()))
+
+	expired := time.Now().UTC().AddDate(0, 0, -5).Truncate(24 * time.Hour)
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		record := models.UserStreak{
+			UserID:         userID,
+			CurrentStreak:  3,
+			LongestStreak:  3,
+			LastSolvedDate: &expired,
+		}
+		if err := db.Create(&record).Error; err != nil {
+			t.Fatalf("failed to seed streak for %s: %v", userID, err)
+		}
+	}
+
+	sched := New(2)
+	job := NewStreakSweepJob(streaks, sched, 2)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("streak sweep job failed: %v", err)
+	}
+
+	var remaining []models.UserStreak
+	if err := db.Find(&remaining).Error; err != nil {
+		t.Fatalf("failed to reload streaks: %v", err)
+	}
+	for _, s := range remaining {
+		if s.CurrentStreak != 0 {
+			t.Errorf("expected user %s's expired streak to be broken, got CurrentStreak %d", s.UserID, s.CurrentStreak)
+		}
+	}
+}
+
+func TestDailyChallengeRotationJob_CreatesTodaysChallenge(t *testing.T) {
+	db := setupJobsTestDB(t)
+	problem := models.Problem{Title: "Two Sum", Slug: "two-sum", Difficulty: "easy", Description: "...", LanguageLimits: "{}"}
+	if err := db.Create(&problem).Error; err != nil {
+		t.Fatalf("failed to seed problem: %v", err)
+	}
+
+	challenges := services.NewDailyChallengeService(db, services.DefaultDailyChallengeServiceConfig())
+	job := NewDailyChallengeRotationJob(challenges)
+
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("daily challenge rotation job failed: %v", err)
+	}
+
+	var count int64
+	if err := db.Model(&models.DailyChallenge{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count daily challenges: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 daily challenge to be created, got %d", count)
+	}
+
+	// Running it again the same day must be a no-op, not a duplicate.
+	if err := job.Run(context.Background()); err != nil {
+		t.Fatalf("second run of daily challenge rotation job failed: %v", err)
+	}
+	if err := db.Model(&models.DailyChallenge{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count daily challenges: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected re-running the job to stay idempotent, got %d daily challenges", count)
+	}
+}
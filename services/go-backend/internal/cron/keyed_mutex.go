@@ -0,0 +1,30 @@
+package cron
+
+import "sync"
+
+// keyedMutex hands out a distinct lock per key, so callers can serialize
+// work on one key (e.g. a user ID) without blocking unrelated keys against
+// each other.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock blocks until key's lock is held and returns a function that releases
+// it.
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	m, ok := k.locks[key]
+	if !ok {
+		m = &sync.Mutex{}
+		k.locks[key] = m
+	}
+	k.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
@@ -0,0 +1,22 @@
+package cron
+
+import "github.com/nahtao97/scribble/internal/services"
+
+// DefaultStreakSweepCron runs the nightly streak sweep at 02:00.
+const DefaultStreakSweepCron = "0 2 * * *"
+
+// DefaultDailyChallengeRotationCron runs daily challenge rotation at 00:05,
+// five minutes past midnight so it never races a clock skew against the
+// previous day's streak sweep.
+const DefaultDailyChallengeRotationCron = "5 0 * * *"
+
+// RegisterDefaultJobs registers the two jobs scribble ships out of the box -
+// StreakSweepJob and DailyChallengeRotationJob - onto s at their default
+// schedules. Call s.Add directly instead for any additional or
+// differently-scheduled jobs (e.g. a leaderboard refresh).
+func RegisterDefaultJobs(s *Scheduler, streaks *services.StreakService, challenges *services.DailyChallengeService) error {
+	if err := s.Add("streak_sweep", DefaultStreakSweepCron, NewStreakSweepJob(streaks, s, DefaultStreakSweepBatchSize).Run); err != nil {
+		return err
+	}
+	return s.Add("daily_challenge_rotation", DefaultDailyChallengeRotationCron, NewDailyChallengeRotationJob(challenges).Run)
+}
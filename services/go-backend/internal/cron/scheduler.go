@@ -0,0 +1,207 @@
+// Package cron runs named, periodically-scheduled jobs on a bounded pool of
+// worker goroutines, modeled on the worker/scheduler split in Wakapi's
+// AggregationService: a ticker decides what's due and hands it to a pool of
+// workers pulling Job values off a channel, instead of each job owning its
+// own goroutine and timer. Jobs registered here (see StreakSweepJob,
+// DailyChallengeRotationJob) complement, rather than replace,
+// services.Scheduler's gocron-backed leaderboard/daily-challenge jobs, and
+// internal/scheduler's submission admission queue.
+package cron
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultInterval is how often the Scheduler checks its registered jobs'
+// schedules for a due run, used unless SetInterval overrides it.
+const DefaultInterval = time.Minute
+
+// DefaultWorkers is how many goroutines pull Job values off the dispatch
+// channel, used unless New is given a different count.
+const DefaultWorkers = 2
+
+// Job is one unit of scheduled work. Run is invoked by a pool worker each
+// time the cron expression it was registered with fires; it should be
+// idempotent, since a slow run can still be in flight when the next one
+// comes due.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// entry pairs a registered Job with its parsed schedule and next fire time.
+type entry struct {
+	job     Job
+	cron    schedule
+	nextRun time.Time
+}
+
+// Scheduler owns a pool of worker goroutines and a set of cron-scheduled
+// jobs dispatched onto them. The zero value is not usable - construct one
+// with New.
+type Scheduler struct {
+	mu       sync.Mutex
+	interval time.Duration
+	location *time.Location
+	workers  int
+
+	entries []*entry
+	jobCh   chan Job
+	locks   *keyedMutex
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New creates a Scheduler with the given number of worker goroutines (or
+// DefaultWorkers if workers <= 0), DefaultInterval ticking, and UTC as its
+// default timezone.
+func New(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &Scheduler{
+		interval: DefaultInterval,
+		location: time.UTC,
+		workers:  workers,
+		jobCh:    make(chan Job),
+		locks:    newKeyedMutex(),
+	}
+}
+
+// SetInterval changes how often the Scheduler checks for due jobs. Call
+// before Start; it has no effect afterward.
+func (s *Scheduler) SetInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = d
+}
+
+// SetTimezone changes the timezone cron expressions are evaluated in,
+// re-deriving every already-registered job's next run time. Can be called
+// either before or after Start.
+func (s *Scheduler) SetTimezone(loc *time.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.location = loc
+	now := time.Now().In(loc)
+	for _, e := range s.entries {
+		e.nextRun = e.cron.Next(now)
+	}
+}
+
+// Add registers fn to run every time cronExpr matches, in the Scheduler's
+// current timezone. name identifies the job in logs only - duplicate names
+// are allowed. Returns an error if cronExpr can't be parsed.
+func (s *Scheduler) Add(name, cronExpr string, fn func(ctx context.Context) error) error {
+	parsed, err := parseExpr(cronExpr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{
+		job:     Job{Name: name, Run: fn},
+		cron:    parsed,
+		nextRun: parsed.Next(time.Now().In(s.location)),
+	})
+	return nil
+}
+
+// Start launches the worker pool and the ticking goroutine that dispatches
+// due jobs onto it. It returns immediately; call Stop to shut both down.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.stopCh = make(chan struct{})
+
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.runWorker(ctx)
+	}
+
+	s.wg.Add(1)
+	go s.runTicker(ctx)
+}
+
+// Stop signals the ticking and worker goroutines to exit and waits for them
+// to finish their current job, if any.
+func (s *Scheduler) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runTicker(ctx context.Context) {
+	defer s.wg.Done()
+
+	s.mu.Lock()
+	interval := s.interval
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.dispatchDue()
+		}
+	}
+}
+
+// dispatchDue advances every entry whose nextRun has passed and hands its
+// Job to a worker, blocking (per job) until one is free or the scheduler is
+// stopped.
+func (s *Scheduler) dispatchDue() {
+	s.mu.Lock()
+	now := time.Now().In(s.location)
+	var due []Job
+	for _, e := range s.entries {
+		if !now.Before(e.nextRun) {
+			due = append(due, e.job)
+			e.nextRun = e.cron.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, job := range due {
+		select {
+		case s.jobCh <- job:
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runWorker(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case job := <-s.jobCh:
+			if err := job.Run(ctx); err != nil {
+				log.Printf("cron: job %q failed: %v", job.Name, err)
+			}
+		}
+	}
+}
+
+// WithUserLock runs fn while holding a lock scoped to userID, blocking until
+// any other in-flight call for the same user releases it. Jobs that process
+// one user at a time across multiple workers (StreakSweepJob, etc.) should
+// wrap their per-user work in this to avoid processing the same user twice
+// concurrently.
+func (s *Scheduler) WithUserLock(userID string, fn func() error) error {
+	unlock := s.locks.lock(userID)
+	defer unlock()
+	return fn()
+}
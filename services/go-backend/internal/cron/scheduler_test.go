@@ -0,0 +1,135 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddRejectsInvalidCronExpr(t *testing.T) {
+	s := New(1)
+	if err := s.Add("bad", "not a cron expr", func(ctx context.Context) error { return nil }); err == nil {
+		t.Fatal("expected an error for an invalid cron expression")
+	}
+}
+
+func TestSchedulerDispatchesDueJobs(t *testing.T) {
+	s := New(1)
+	ran := make(chan struct{}, 1)
+	if err := s.Add("test", "* * * * *", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Force the job due right now instead of waiting for a real minute
+	// boundary.
+	s.entries[0].nextRun = time.Now().Add(-time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	defer s.Stop()
+
+	s.dispatchDue()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("expected the due job to run")
+	}
+}
+
+func TestSchedulerSkipsNotYetDueJobs(t *testing.T) {
+	s := New(1)
+	ran := make(chan struct{}, 1)
+	if err := s.Add("test", "* * * * *", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	s.entries[0].nextRun = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	defer s.Stop()
+
+	s.dispatchDue()
+
+	select {
+	case <-ran:
+		t.Fatal("did not expect a not-yet-due job to run")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWithUserLockSerializesSameUser(t *testing.T) {
+	s := New(4)
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.WithUserLock("user1", func() error {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 1 {
+		t.Errorf("expected WithUserLock to serialize same-user calls, got max concurrency %d", maxInFlight)
+	}
+}
+
+func TestWithUserLockAllowsDifferentUsersConcurrently(t *testing.T) {
+	s := New(4)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	var wg sync.WaitGroup
+	for _, userID := range []string{"user1", "user2"} {
+		userID := userID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.WithUserLock(userID, func() error {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("expected both different-user locks to be acquired concurrently")
+		}
+	}
+
+	close(release)
+	wg.Wait()
+}
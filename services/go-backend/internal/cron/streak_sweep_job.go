@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// DefaultStreakSweepBatchSize is how many streak rows StreakSweepJob loads
+// per page while walking the table.
+const DefaultStreakSweepBatchSize = 500
+
+// NewStreakSweepJob returns a Job that pages through every user's streak
+// record and calls streaks.CheckStreak on each, so a streak whose grace
+// window lapsed gets zeroed (and a TopicStreakBroken event published)
+// proactively overnight instead of waiting for that user to hit a read
+// endpoint. sched's per-user lock keeps two workers from checking the same
+// user concurrently if this job is still mid-sweep when the next tick fires.
+func NewStreakSweepJob(streaks *services.StreakService, sched *Scheduler, batchSize int) Job {
+	if batchSize <= 0 {
+		batchSize = DefaultStreakSweepBatchSize
+	}
+
+	return Job{
+		Name: "streak_sweep",
+		Run: func(ctx context.Context) error {
+			offset := 0
+			for {
+				userIDs, err := streaks.ListUserIDs(offset, batchSize)
+				if err != nil {
+					return fmt.Errorf("streak sweep: failed to list users at offset %d: %w", offset, err)
+				}
+				if len(userIDs) == 0 {
+					return nil
+				}
+
+				for _, userID := range userIDs {
+					userID := userID
+					err := sched.WithUserLock(userID, func() error {
+						_, err := streaks.CheckStreak(ctx, userID)
+						return err
+					})
+					if err != nil {
+						log.Printf("streak sweep: CheckStreak failed for user %s: %v", userID, err)
+					}
+				}
+
+				offset += batchSize
+			}
+		},
+	}
+}
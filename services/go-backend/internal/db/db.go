@@ -1,9 +1,11 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/nahtao97/scribble/internal/db/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -100,10 +102,27 @@ func (db *Database) GetConnection() *gorm.DB {
 	return db.conn
 }
 
-// AutoMigrate runs all pending migrations using GORM's auto migration
-// This is useful for development but should be controlled in production
-func (db *Database) AutoMigrate(models ...interface{}) error {
-	return db.conn.AutoMigrate(models...)
+// Migrate applies every pending versioned migration from
+// internal/db/migrations, inside a transaction guarded by a Postgres
+// advisory lock so multiple server instances starting concurrently don't
+// race the same schema change. This replaces the former reliance on GORM's
+// AutoMigrate, which can't express JSONB, array, or window-function-backed
+// columns consistently across environments.
+func (db *Database) Migrate(ctx context.Context) error {
+	return migrations.NewRunner(db.conn).MigrateTo(ctx, migrations.Latest)
+}
+
+// MigrateTo applies every pending migration up to and including version,
+// for operational use when you need to stop short of the latest schema
+// (e.g. staging a deploy one version behind).
+func (db *Database) MigrateTo(ctx context.Context, version int) error {
+	return migrations.NewRunner(db.conn).MigrateTo(ctx, version)
+}
+
+// Rollback reverts the n most recently applied migrations, for operational
+// use when a bad migration needs to be backed out.
+func (db *Database) Rollback(ctx context.Context, n int) error {
+	return migrations.NewRunner(db.conn).Rollback(ctx, n)
 }
 
 // Close closes the database connection
@@ -0,0 +1,110 @@
+// Package migrations is a minimal versioned SQL migration runner,
+// replacing GORM's AutoMigrate with explicit, reviewable schema changes.
+// Each version is a pair of embedded NNN_description.up.sql / .down.sql
+// files under a per-dialect directory (postgres/, sqlite/), since a handful
+// of columns (JSONB, text[], UUID defaults) can't be expressed the same way
+// across dialects.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version     int
+	Description string
+	Up          string
+	Down        string
+}
+
+// Load reads every migration embedded for dialect ("postgres" or "sqlite"),
+// sorted by ascending version.
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations for dialect %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		version, description, direction, ok := parseFilename(entry.Name())
+		if !ok {
+			continue
+		}
+
+		content, err := files.ReadFile(dialect + "/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Description: description}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseFilename splits "NNN_description.up.sql" / "NNN_description.down.sql"
+// into its version, description, and direction. ok is false for any
+// embedded file that doesn't match this naming convention.
+func parseFilename(name string) (version int, description string, direction string, ok bool) {
+	base := strings.TrimSuffix(name, ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", "", false
+	}
+
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", "", false
+	}
+	v, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return v, parts[1], direction, true
+}
+
+// Statements splits a migration file's SQL text into individual statements
+// on statement-terminating semicolons, since not every database driver
+// accepts a multi-statement string in a single Exec call. Migration files
+// are expected to keep one statement per semicolon (no semicolons inside
+// string literals), which holds for the DDL this package embeds.
+func Statements(sqlText string) []string {
+	raw := strings.Split(sqlText, ";")
+	statements := make([]string, 0, len(raw))
+	for _, stmt := range raw {
+		if trimmed := strings.TrimSpace(stmt); trimmed != "" {
+			statements = append(statements, trimmed)
+		}
+	}
+	return statements
+}
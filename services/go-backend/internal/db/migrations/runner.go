@@ -0,0 +1,175 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Latest, passed to Runner.MigrateTo, means "apply every pending migration".
+const Latest = -1
+
+// migrationLockKey is the pg_advisory_xact_lock key migrations take so two
+// server instances starting concurrently don't race the same schema change.
+// It's a fixed, arbitrary constant distinct from the leaderboard subsystem's
+// hashed per-metric lock keys (see leaderboardLockKey in
+// internal/services/leaderboard_service.go).
+const migrationLockKey = 911727
+
+// Runner applies versioned migrations to a database, tracking the highest
+// applied version in a schema_migrations table.
+type Runner struct {
+	db      *gorm.DB
+	dialect string
+}
+
+// NewRunner creates a Runner for db, using db.Dialector.Name() ("postgres"
+// or "sqlite") to choose which embedded migration set to apply.
+func NewRunner(db *gorm.DB) *Runner {
+	return &Runner{db: db, dialect: db.Dialector.Name()}
+}
+
+// MigrateTo applies every pending migration up to and including version
+// (or every pending migration when version is Latest), inside a single
+// transaction guarded by a Postgres advisory lock. On SQLite (used only in
+// tests), no advisory lock is taken since SQLite has no concurrent server
+// instances to race.
+func (r *Runner) MigrateTo(ctx context.Context, version int) error {
+	migrations, err := Load(r.dialect)
+	if err != nil {
+		return err
+	}
+	if version == Latest && len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.lock(tx); err != nil {
+			return err
+		}
+		if err := r.ensureSchemaMigrationsTable(tx); err != nil {
+			return err
+		}
+
+		current, err := r.currentVersion(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if m.Version <= current || m.Version > version {
+				continue
+			}
+			if err := r.applyUp(tx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Rollback reverts the n most recently applied migrations, in descending
+// version order, inside a single transaction.
+func (r *Runner) Rollback(ctx context.Context, n int) error {
+	migrations, err := Load(r.dialect)
+	if err != nil {
+		return err
+	}
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.lock(tx); err != nil {
+			return err
+		}
+		if err := r.ensureSchemaMigrationsTable(tx); err != nil {
+			return err
+		}
+
+		applied, err := r.appliedVersionsDesc(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < n && i < len(applied); i++ {
+			m, ok := byVersion[applied[i]]
+			if !ok {
+				return fmt.Errorf("no migration file found for applied version %d", applied[i])
+			}
+			if err := r.applyDown(tx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Runner) lock(tx *gorm.DB) error {
+	if r.dialect != "postgres" {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	return nil
+}
+
+func (r *Runner) ensureSchemaMigrationsTable(tx *gorm.DB) error {
+	err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func (r *Runner) currentVersion(tx *gorm.DB) (int, error) {
+	var version int
+	err := tx.Raw("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to read current schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *Runner) appliedVersionsDesc(tx *gorm.DB) ([]int, error) {
+	var versions []int
+	err := tx.Raw("SELECT version FROM schema_migrations ORDER BY version DESC").Scan(&versions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	return versions, nil
+}
+
+func (r *Runner) applyUp(tx *gorm.DB, m Migration) error {
+	for _, stmt := range Statements(m.Up) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Description, err)
+		}
+	}
+	err := tx.Exec("INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Description, time.Now().UTC()).Error
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", m.Version, m.Description, err)
+	}
+	return nil
+}
+
+func (r *Runner) applyDown(tx *gorm.DB, m Migration) error {
+	for _, stmt := range Statements(m.Down) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("rollback of migration %d_%s failed: %w", m.Version, m.Description, err)
+		}
+	}
+	if err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version).Error; err != nil {
+		return fmt.Errorf("failed to unrecord migration %d_%s: %w", m.Version, m.Description, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nahtao97/scribble/internal/backup"
+)
+
+// AdminHandler handles operator-facing maintenance endpoints.
+type AdminHandler struct {
+	backupService *backup.Service
+}
+
+// NewAdminHandler creates a new admin handler backed by backupService.
+func NewAdminHandler(backupService *backup.Service) *AdminHandler {
+	return &AdminHandler{backupService: backupService}
+}
+
+// restoreRequest is the request body for POST /internal/admin/restore.
+type restoreRequest struct {
+	SnapshotID string `json:"snapshot_id" binding:"required"`
+}
+
+// Restore handles POST /internal/admin/restore. It replays the named
+// snapshot's tables back into the database, refusing if the snapshot's
+// checksums don't match or its schema version doesn't match the live
+// database's - this is a destructive operation and is expected to be
+// gated by an operator-only auth middleware in front of it.
+func (h *AdminHandler) Restore(c *gin.Context) {
+	var req restoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := h.backupService.Restore(c.Request.Context(), req.SnapshotID)
+	if errors.Is(err, backup.ErrSchemaVersionMismatch) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Schema version mismatch",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Restore failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshot_id": req.SnapshotID,
+		"status":      "restored",
+	})
+}
@@ -1,24 +1,44 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/server/middleware"
 	"github.com/nahtao97/scribble/internal/services"
 )
 
+// flagStreamUpgrader upgrades GET /internal/admin/flags/stream to a
+// WebSocket. Origin checking is left to whatever sits in front of this
+// internal service, same as every other /internal endpoint here.
+var flagStreamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// flagStreamPingInterval is how often StreamFlags pings idle connections to
+// keep them (and any intermediate proxy) from timing out.
+const flagStreamPingInterval = 30 * time.Second
+
 // AntiCheatHandler handles HTTP requests for anti-cheat endpoints
 type AntiCheatHandler struct {
-	antiCheatService *services.AntiCheatService
+	antiCheatService  *services.AntiCheatService
+	similarityService *services.SimilarityService
+	flagBroker        *services.FlagBroker
 }
 
 // NewAntiCheatHandler creates a new anti-cheat handler instance
-func NewAntiCheatHandler(antiCheatService *services.AntiCheatService) *AntiCheatHandler {
+func NewAntiCheatHandler(antiCheatService *services.AntiCheatService, similarityService *services.SimilarityService, flagBroker *services.FlagBroker) *AntiCheatHandler {
 	return &AntiCheatHandler{
-		antiCheatService: antiCheatService,
+		antiCheatService:  antiCheatService,
+		similarityService: similarityService,
+		flagBroker:        flagBroker,
 	}
 }
 
@@ -119,6 +139,10 @@ func (h *AntiCheatHandler) GetUserFlags(c *gin.Context) {
 type ReviewFlagRequest struct {
 	Status string `json:"status" binding:"required"`
 	Notes  string `json:"notes"`
+	// ExpectedCurrentStatus, when set, makes the review fail with 409
+	// Conflict if the flag's current status has already moved on from it -
+	// optimistic concurrency for admins reviewing the same queue at once.
+	ExpectedCurrentStatus string `json:"expected_current_status"`
 }
 
 // ReviewFlag handles POST /internal/admin/flags/:flag_id/review
@@ -174,8 +198,21 @@ func (h *AntiCheatHandler) ReviewFlag(c *gin.Context) {
 		}
 	}
 
-	err = h.antiCheatService.ReviewFlag(uint(flagID), adminUserID, status, req.Notes)
+	// X-Superadmin is set by the same upstream auth layer that sets
+	// X-User-Id, once it carries an is_superadmin claim - required to move a
+	// flag out of FlagStatusBanned.
+	isSuperAdmin := c.GetHeader("X-Superadmin") == "true"
+
+	err = h.antiCheatService.ReviewFlag(uint(flagID), adminUserID, status, req.Notes, models.FlagStatus(req.ExpectedCurrentStatus), isSuperAdmin)
 	if err != nil {
+		if errors.Is(err, services.ErrFlagStatusConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "flag was already reviewed by someone else"})
+			return
+		}
+		if errors.Is(err, services.ErrSuperAdminRequired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "superadmin required to revise a banned flag"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -187,6 +224,82 @@ func (h *AntiCheatHandler) ReviewFlag(c *gin.Context) {
 	})
 }
 
+// GetFlagHistory handles GET /internal/admin/flags/:flag_id/history
+// Returns a flagged submission's ordered review event history (admin only)
+func (h *AntiCheatHandler) GetFlagHistory(c *gin.Context) {
+	if !verifyAdmin(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "admin access required"})
+		return
+	}
+
+	flagIDParam := c.Param("flag_id")
+	flagID, err := strconv.ParseUint(flagIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flag ID"})
+		return
+	}
+
+	events, err := h.antiCheatService.GetFlagReviewHistory(uint(flagID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flag_id": flagID,
+		"events":  events,
+	})
+}
+
+// RevertFlagRequest represents a flag revert request
+type RevertFlagRequest struct {
+	Notes string `json:"notes"`
+}
+
+// RevertFlag handles POST /internal/admin/flags/:flag_id/revert
+// Restores a flagged submission to the status it held before its most
+// recent review (admin only)
+func (h *AntiCheatHandler) RevertFlag(c *gin.Context) {
+	if !verifyAdmin(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "admin access required"})
+		return
+	}
+
+	flagIDParam := c.Param("flag_id")
+	flagID, err := strconv.ParseUint(flagIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flag ID"})
+		return
+	}
+
+	var req RevertFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	adminUserID := uint(0)
+	if adminIDStr := c.GetHeader("X-User-Id"); adminIDStr != "" {
+		if parsed, err := strconv.ParseUint(adminIDStr, 10, 32); err == nil {
+			adminUserID = uint(parsed)
+		}
+	}
+
+	if err := h.antiCheatService.RevertFlag(uint(flagID), adminUserID, req.Notes); err != nil {
+		if errors.Is(err, services.ErrNoReviewHistory) {
+			c.JSON(http.StatusConflict, gin.H{"error": "flag has no review history to revert"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"flag_id": flagID,
+	})
+}
+
 // CheckSubmissionRequest represents a submission check request
 type CheckSubmissionRequest struct {
 	UserID          uint   `json:"user_id" binding:"required"`
@@ -217,6 +330,15 @@ func (h *AntiCheatHandler) CheckSubmission(c *gin.Context) {
 		return
 	}
 
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	if result.RetryAfter != nil {
+		seconds := int(result.RetryAfter.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+
 	c.JSON(http.StatusOK, result)
 }
 
@@ -232,6 +354,8 @@ type FlagSubmissionRequest struct {
 // FlagSubmission handles POST /internal/anticheat/flag
 // Creates a flag record for a submission
 func (h *AntiCheatHandler) FlagSubmission(c *gin.Context) {
+	middleware.MarkSuspicious(c, middleware.ReasonFlaggedEndpoint)
+
 	var req FlagSubmissionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -239,6 +363,7 @@ func (h *AntiCheatHandler) FlagSubmission(c *gin.Context) {
 	}
 
 	err := h.antiCheatService.FlagSubmission(
+		c.Request.Context(),
 		req.SubmissionID,
 		req.UserID,
 		req.ProblemID,
@@ -257,6 +382,197 @@ func (h *AntiCheatHandler) FlagSubmission(c *gin.Context) {
 	})
 }
 
+// GetSimilarity handles GET /internal/admin/similarity/:submission_id?problem_id=...
+// Returns the submission's nearest neighbors by fingerprint similarity for
+// admin review, regardless of whether any exceed the auto-flag threshold.
+func (h *AntiCheatHandler) GetSimilarity(c *gin.Context) {
+	if !verifyAdmin(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "admin access required"})
+		return
+	}
+
+	submissionIDParam := c.Param("submission_id")
+	submissionID, err := strconv.ParseUint(submissionIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	problemIDParam := c.Query("problem_id")
+	problemID, err := strconv.ParseUint(problemIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "problem_id query parameter is required"})
+		return
+	}
+
+	matches, err := h.similarityService.NearestNeighbors(uint(submissionID), uint(problemID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submission_id": submissionID,
+		"matches":       matches,
+	})
+}
+
+// AnalyzeSimilarityRequest represents a request to run a submission through
+// AnalyzeSimilarity. Unlike GetSimilarity, this also persists fingerprints
+// and flags a match exceeding the threshold.
+type AnalyzeSimilarityRequest struct {
+	SubmissionID uint   `json:"submission_id" binding:"required"`
+	UserID       uint   `json:"user_id" binding:"required"`
+	ProblemID    uint   `json:"problem_id" binding:"required"`
+	Language     string `json:"language" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	// Async queues the job for RunSimilarityWorker instead of analyzing
+	// synchronously in this request.
+	Async bool `json:"async"`
+}
+
+// AnalyzeSimilarity handles POST /internal/admin/similarity/analyze
+// Fingerprints a submission and flags it under FlagReasonCodeSimilarity if
+// it's too similar to another user's submission for the same problem
+// (admin only). With "async": true, the job is handed to
+// AntiCheatService's background worker instead of running inline.
+func (h *AntiCheatHandler) AnalyzeSimilarity(c *gin.Context) {
+	if !verifyAdmin(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var req AnalyzeSimilarityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	job := services.SimilarityJob{
+		SubmissionID: req.SubmissionID,
+		UserID:       req.UserID,
+		ProblemID:    req.ProblemID,
+		Language:     req.Language,
+		Code:         req.Code,
+	}
+
+	if req.Async {
+		if err := h.antiCheatService.EnqueueSimilarityAnalysis(job); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"queued": true})
+		return
+	}
+
+	if err := h.antiCheatService.AnalyzeSimilarity(c.Request.Context(), job.SubmissionID, job.UserID, job.ProblemID, job.Language, job.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "submission_id": req.SubmissionID})
+}
+
+// defaultSimilarityThreshold is the Jaccard similarity FindSimilarSubmissions
+// uses when GetSimilarSubmissions isn't given a ?threshold=.
+const defaultSimilarityThreshold = 0.6
+
+// GetSimilarSubmissions handles GET /internal/anticheat/similarity/:submissionId?threshold=0.6
+// Returns the top-K prior submissions to the same problem most similar to
+// submissionId by fingerprint Jaccard similarity, each with the matched
+// source regions they share. Unlike GetSimilarity, this reads only
+// already-stored fingerprints, so it doesn't require admin credentials.
+func (h *AntiCheatHandler) GetSimilarSubmissions(c *gin.Context) {
+	submissionIDParam := c.Param("submissionId")
+	submissionID, err := strconv.ParseUint(submissionIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission ID"})
+		return
+	}
+
+	threshold := defaultSimilarityThreshold
+	if raw := c.Query("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a number"})
+			return
+		}
+	}
+
+	matches, err := h.antiCheatService.FindSimilarSubmissions(uint(submissionID), threshold, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submission_id": submissionID,
+		"threshold":     threshold,
+		"matches":       matches,
+	})
+}
+
+// StreamFlags handles GET /internal/admin/flags/stream
+// Upgrades to a WebSocket and pushes newly created FlaggedSubmission
+// records, plus periodic aggregate stats deltas, as they happen (admin
+// only). Supports ?reason= and ?min_severity= to narrow which flags this
+// connection receives.
+func (h *AntiCheatHandler) StreamFlags(c *gin.Context) {
+	if !verifyAdmin(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "admin access required"})
+		return
+	}
+
+	filter := services.FlagStreamFilter{Reason: models.FlagReason(c.Query("reason"))}
+	if minSeverityParam := c.Query("min_severity"); minSeverityParam != "" {
+		if parsed, err := strconv.Atoi(minSeverityParam); err == nil {
+			filter.MinSeverity = parsed
+		}
+	}
+
+	conn, err := flagStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("flag stream: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := h.flagBroker.Register(filter)
+	defer h.flagBroker.Unregister(sub)
+
+	// Discard anything the client sends us; we only use this connection to
+	// detect it closing, and so the client's pong replies to our pings get
+	// read off the connection.
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(flagStreamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data, ok := <-sub.Send:
+			if !ok {
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
 // CleanupRateLimits handles POST /internal/admin/cleanup/rate-limits
 // Removes stale rate limit entries (admin only, called by cron)
 func (h *AntiCheatHandler) CleanupRateLimits(c *gin.Context) {
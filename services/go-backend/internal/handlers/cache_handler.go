@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nahtao97/scribble/internal/cache"
+)
+
+// CacheHandler handles operator-facing endpoints for inspecting and
+// busting the hot-read cache.
+type CacheHandler struct {
+	cache *cache.Cache
+}
+
+// NewCacheHandler creates a new cache handler backed by c.
+func NewCacheHandler(c *cache.Cache) *CacheHandler {
+	return &CacheHandler{cache: c}
+}
+
+// List handles GET /internal/cache, returning every unexpired entry.
+func (h *CacheHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"entries": h.cache.Entries(),
+	})
+}
+
+// Bust handles DELETE /internal/cache/:key, evicting the named entry.
+func (h *CacheHandler) Bust(c *gin.Context) {
+	key := c.Param("key")
+	removed := h.cache.Delete(key)
+	c.JSON(http.StatusOK, gin.H{
+		"key":     key,
+		"removed": removed,
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// DailyChallengeHandler handles HTTP requests for daily-challenge-related
+// endpoints
+type DailyChallengeHandler struct {
+	challengeService *services.DailyChallengeService
+}
+
+// NewDailyChallengeHandler creates a new daily challenge handler instance
+func NewDailyChallengeHandler(challengeService *services.DailyChallengeService) *DailyChallengeHandler {
+	return &DailyChallengeHandler{challengeService: challengeService}
+}
+
+// PreviewSelection handles POST /internal/daily/preview?strategy=...&count=...
+// Returns the next N picks the given strategy would make without
+// persisting anything, so operators can compare strategies before
+// switching one in production.
+func (h *DailyChallengeHandler) PreviewSelection(c *gin.Context) {
+	strategy := c.DefaultQuery("strategy", "round_robin")
+
+	count := 5
+	if raw := c.Query("count"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "count must be a positive integer",
+			})
+			return
+		}
+		count = parsed
+	}
+
+	picks, err := h.challengeService.PreviewSelections(strategy, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy": strategy,
+		"picks":    picks,
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// DashboardHandler handles HTTP requests for dashboard endpoints
+type DashboardHandler struct {
+	dashboardService *services.DashboardService
+}
+
+// NewDashboardHandler creates a new dashboard handler instance
+func NewDashboardHandler(dashboardService *services.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// CreateDashboardRequest is the request body for creating a dashboard
+type CreateDashboardRequest struct {
+	Name    string                   `json:"name" binding:"required"`
+	Widgets []models.DashboardWidget `json:"widgets"`
+}
+
+// CreateDashboard handles POST /internal/dashboards
+// Creates a dashboard owned by the requesting user (X-User-Id)
+func (h *DashboardHandler) CreateDashboard(c *gin.Context) {
+	ownerID := c.GetHeader("X-User-Id")
+	if ownerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "X-User-Id header is required",
+		})
+		return
+	}
+
+	var req CreateDashboardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	dashboard, err := h.dashboardService.CreateDashboard(ownerID, req.Name, req.Widgets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dashboard)
+}
+
+// GetDashboard handles GET /internal/dashboards/:id
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	id, err := parseDashboardID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dashboard ID"})
+		return
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(id, c.GetHeader("X-User-Id"))
+	if err != nil {
+		writeDashboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// ListUserDashboards handles GET /internal/dashboards
+// Returns every dashboard the requesting user owns or has been granted access to
+func (h *DashboardHandler) ListUserDashboards(c *gin.Context) {
+	userID := c.GetHeader("X-User-Id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "X-User-Id header is required",
+		})
+		return
+	}
+
+	dashboards, err := h.dashboardService.ListUserDashboards(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dashboards": dashboards,
+	})
+}
+
+// UpdateDashboardRequest is the request body for updating a dashboard
+type UpdateDashboardRequest struct {
+	Name    string                   `json:"name" binding:"required"`
+	Widgets []models.DashboardWidget `json:"widgets"`
+}
+
+// UpdateDashboard handles PUT /internal/dashboards/:id
+func (h *DashboardHandler) UpdateDashboard(c *gin.Context) {
+	id, err := parseDashboardID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dashboard ID"})
+		return
+	}
+
+	var req UpdateDashboardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	dashboard, err := h.dashboardService.UpdateDashboard(id, c.GetHeader("X-User-Id"), req.Name, req.Widgets)
+	if err != nil {
+		writeDashboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// DeleteDashboard handles DELETE /internal/dashboards/:id
+func (h *DashboardHandler) DeleteDashboard(c *gin.Context) {
+	id, err := parseDashboardID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dashboard ID"})
+		return
+	}
+
+	if err := h.dashboardService.DeleteDashboard(id, c.GetHeader("X-User-Id")); err != nil {
+		writeDashboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// RenderDashboard handles GET /internal/dashboards/:id/render
+// Returns every widget's data batched into one response
+func (h *DashboardHandler) RenderDashboard(c *gin.Context) {
+	id, err := parseDashboardID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dashboard ID"})
+		return
+	}
+
+	rendered, err := h.dashboardService.RenderDashboard(id, c.GetHeader("X-User-Id"))
+	if err != nil {
+		writeDashboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+func parseDashboardID(c *gin.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+func writeDashboardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDashboardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDashboardForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
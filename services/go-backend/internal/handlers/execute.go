@@ -1,13 +1,31 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/nahtao97/scribble/internal/acquirer"
 	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/limits"
 	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/streaming"
+	"github.com/nahtao97/scribble/internal/submissions"
 )
 
+// maxReleaseWait bounds how long releaseOnCompletion will poll a submitted
+// job before giving up and releasing its scheduler slot anyway, so a job
+// stuck in a state GetJobStatus never resolves to terminal can't leak a
+// slot forever.
+const maxReleaseWait = 10 * time.Minute
+
 // ExecuteRequest represents the request body for code execution
 type ExecuteRequest struct {
 	Code      string `json:"code" binding:"required"`
@@ -18,27 +36,56 @@ type ExecuteRequest struct {
 
 // ExecuteResponse represents the response from code execution
 type ExecuteResponse struct {
-	SubmissionID    string              `json:"submission_id"`
-	Status          string              `json:"status"`
-	ExecutionTimeMs int64               `json:"execution_time_ms"`
-	MemoryUsedKB    int64               `json:"memory_used_kb"`
-	TestsPassed     int                 `json:"tests_passed"`
-	TestsTotal      int                 `json:"tests_total"`
-	ErrorMessage    string              `json:"error_message,omitempty"`
-	TestResults     []k8s.TestResult    `json:"test_results,omitempty"`
+	SubmissionID    string           `json:"submission_id"`
+	Status          string           `json:"status"`
+	ExecutionTimeMs int64            `json:"execution_time_ms"`
+	MemoryUsedKB    int64            `json:"memory_used_kb"`
+	TestsPassed     int              `json:"tests_passed"`
+	TestsTotal      int              `json:"tests_total"`
+	ErrorMessage    string           `json:"error_message,omitempty"`
+	TestResults     []k8s.TestResult `json:"test_results,omitempty"`
+	// Verdict is the short code (TLE, MLE, OLE, or RE:signal=<name>) for a
+	// resource-limit violation - see k8s.VerdictForStatus. Empty when
+	// Status isn't a limit violation.
+	Verdict string `json:"verdict,omitempty"`
 }
 
 // ExecuteHandler handles code execution requests
 type ExecuteHandler struct {
-	jobManager *k8s.JobManager
+	runtime  k8s.Runtime
+	acquirer *acquirer.Acquirer
+	sched    *submissions.Scheduler
+	broker   *streaming.Broker
 	// TODO: Add database connection for submission storage
 	// TODO: Add problem service for test case retrieval
 }
 
-// NewExecuteHandler creates a new execute handler
-func NewExecuteHandler(jobManager *k8s.JobManager) *ExecuteHandler {
+// NewExecuteHandler creates a new execute handler that runs submissions
+// synchronously in-process via runtime, the way Execute/Stream do. runtime
+// can be a *k8s.JobManager or any other k8s.Runtime implementation (e.g.
+// sandbox.LocalRuntime for development without a cluster).
+func NewExecuteHandler(runtime k8s.Runtime) *ExecuteHandler {
+	return &ExecuteHandler{
+		runtime: runtime,
+		broker:  streaming.NewBroker(streaming.DefaultReplaySize, streaming.DefaultRetention),
+	}
+}
+
+// NewExecuteHandlerWithAcquirer creates an execute handler whose Submit
+// method queues work durably in Postgres via acq instead of running it
+// synchronously, so a submission survives this process restarting and a
+// separate pool of acquirer workers can execute it. sched admits Submit
+// requests under a global and per-language concurrency cap; pass nil to
+// fall back to submissions.DefaultConfig().
+func NewExecuteHandlerWithAcquirer(runtime k8s.Runtime, acq *acquirer.Acquirer, sched *submissions.Scheduler) *ExecuteHandler {
+	if sched == nil {
+		sched = submissions.NewScheduler(submissions.DefaultConfig())
+	}
 	return &ExecuteHandler{
-		jobManager: jobManager,
+		runtime:  runtime,
+		acquirer: acq,
+		sched:    sched,
+		broker:   streaming.NewBroker(streaming.DefaultReplaySize, streaming.DefaultRetention),
 	}
 }
 
@@ -71,12 +118,21 @@ func (h *ExecuteHandler) Execute(c *gin.Context) {
 	// For now, use placeholder test cases
 	testCases := getPlaceholderTestCases(req.ProblemID)
 
-	// Execute code using K8s job
-	result, err := h.jobManager.ExecuteAndWait(c.Request.Context(), k8s.ExecutionJobParams{
+	// Bound this execution to its language's wall-time budget (see
+	// internal/limits), on top of whatever deadline the request already
+	// carries, so the runner - not just the HTTP client - gives up once
+	// the submission has run long enough to be a TLE.
+	execLimits := limits.Resolve(req.Language, 0, 0)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), execLimits.WallTime)
+	defer cancel()
+
+	// Execute code on whichever Runtime this handler was built with
+	result, err := k8s.ExecuteAndWaitRuntime(ctx, h.runtime, k8s.ExecutionJobParams{
 		SubmissionID: submissionID,
 		ProblemID:    req.ProblemID,
 		Code:         req.Code,
 		TestCases:    testCases,
+		Language:     req.Language,
 	})
 
 	if err != nil {
@@ -102,9 +158,292 @@ func (h *ExecuteHandler) Execute(c *gin.Context) {
 		TestsTotal:      result.TestsTotal,
 		ErrorMessage:    result.ErrorMessage,
 		TestResults:     result.TestResults,
+		Verdict:         result.Verdict,
+	})
+}
+
+// Stream handles GET /api/submissions/:id/stream over server-sent events.
+// The first caller to stream a given submission ID drives its execution
+// (binding the request body the way Execute does) and publishes every
+// event into h.broker as it arrives; any other caller streaming the same
+// ID - including one reconnecting after a drop - just subscribes, and is
+// first replayed whatever events already fired before it gets the rest
+// live. This is what lets N tabs watch one submission, and a reconnect
+// within streaming.DefaultRetention pick back up instead of missing
+// everything that already happened.
+func (h *ExecuteHandler) Stream(c *gin.Context) {
+	submissionID := c.Param("id")
+
+	events, unsubscribe := h.broker.Subscribe(submissionID)
+	defer unsubscribe()
+
+	if !h.broker.EnsureTopic(submissionID) {
+		if err := h.startStream(c, submissionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to start execution",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-events
+		if !ok {
+			return false
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return false
+		}
+
+		c.SSEvent(string(event.Type), string(payload))
+		return true
 	})
 }
 
+// startStream binds submissionID's ExecuteRequest, creates its execution,
+// and spawns the goroutine that pumps its events into h.broker. It returns
+// once the job is created; the pump itself keeps running detached from
+// this request so later subscribers (and this one, via its Subscribe
+// above) see the rest of the run even if this particular connection drops.
+func (h *ExecuteHandler) startStream(c *gin.Context, submissionID string) error {
+	var req ExecuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if !models.IsValidLanguage(req.Language) {
+		return fmt.Errorf("unsupported language %q", req.Language)
+	}
+
+	testCases := getPlaceholderTestCases(req.ProblemID)
+
+	executionID, err := h.runtime.CreateExecution(context.Background(), k8s.ExecutionJobParams{
+		SubmissionID: submissionID,
+		ProblemID:    req.ProblemID,
+		Code:         req.Code,
+		TestCases:    testCases,
+		Language:     req.Language,
+	})
+	if err != nil {
+		return err
+	}
+
+	go h.pumpEvents(submissionID, executionID)
+	return nil
+}
+
+// pumpEvents relays executionID's lifecycle events (from whichever Runtime
+// backend h.runtime is) into h.broker under submissionID, so every
+// Stream subscriber for that submission - present and future, up to
+// streaming.DefaultRetention after the run ends - receives them.
+func (h *ExecuteHandler) pumpEvents(submissionID, executionID string) {
+	ctx := context.Background()
+
+	// Only backends that implement EventStreamer (currently *k8s.JobManager)
+	// can report intermediate progress; others still work, they just jump
+	// straight from job_created to a single completed/error event.
+	var events <-chan k8s.ExecutionEvent
+	if streamer, ok := h.runtime.(k8s.EventStreamer); ok {
+		var err error
+		events, err = streamer.StreamJobEvents(ctx, executionID)
+		if err != nil {
+			h.broker.Publish(submissionID, k8s.ExecutionEvent{Type: k8s.EventError, Error: err.Error()})
+			return
+		}
+	} else {
+		events = h.waitAsSingleEvent(ctx, executionID)
+	}
+
+	for event := range events {
+		h.broker.Publish(submissionID, event)
+	}
+}
+
+// waitAsSingleEvent waits on executionID via h.runtime and adapts the
+// result into a single-event channel shaped like StreamJobEvents' output,
+// for Runtime backends that don't implement EventStreamer.
+func (h *ExecuteHandler) waitAsSingleEvent(ctx context.Context, executionID string) <-chan k8s.ExecutionEvent {
+	events := make(chan k8s.ExecutionEvent, 1)
+	go func() {
+		defer close(events)
+		result, err := h.runtime.Wait(ctx, executionID, k8s.DefaultMonitorConfig())
+		if err != nil && result == nil {
+			events <- k8s.ExecutionEvent{Type: k8s.EventError, Error: err.Error()}
+			return
+		}
+		events <- k8s.ExecutionEvent{Type: k8s.EventCompleted, Result: result}
+	}()
+	return events
+}
+
+// SubmitResponse is returned by Submit: just enough for the client to poll
+// or stream the eventual result.
+type SubmitResponse struct {
+	SubmissionID string `json:"submission_id"`
+	Status       string `json:"status"`
+
+	// QueuePosition and EstimatedWait reflect the scheduler's admission
+	// queue at the moment this submission was admitted - both zero if a
+	// slot was free immediately. They do not account for Postgres queue
+	// time beyond that point.
+	QueuePosition int           `json:"queue_position,omitempty"`
+	EstimatedWait time.Duration `json:"estimated_wait_ns,omitempty"`
+}
+
+// Submit handles POST /api/submissions. Unlike Execute, it doesn't block on
+// the job finishing: it persists the request as a queued provisioner_jobs
+// row and returns immediately, so the caller polls GetSubmissionStatus (or
+// connects to Stream) for the eventual result. Requires the handler to have
+// been built with NewExecuteHandlerWithAcquirer.
+//
+// Before enqueueing, Submit admits the request through h.sched so the
+// number of submissions actually running (not just enqueued) stays bounded
+// per language and overall: it blocks while a slot is queued for, responds
+// 429 once the wait queue itself is full, and otherwise proceeds once
+// admitted.
+func (h *ExecuteHandler) Submit(c *gin.Context) {
+	if h.acquirer == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "async submission is not configured"})
+		return
+	}
+
+	var req ExecuteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !models.IsValidLanguage(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Unsupported language",
+			"supported": models.ValidLanguages,
+		})
+		return
+	}
+
+	ticket, err := h.sched.Acquire(c.Request.Context(), req.Language)
+	if errors.Is(err, submissions.ErrQueueFull) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many submissions queued, try again shortly"})
+		return
+	}
+	if err != nil {
+		// Most likely the client gave up while queued.
+		c.Abort()
+		return
+	}
+
+	submissionID := generateSubmissionID()
+	testCases := getPlaceholderTestCases(req.ProblemID)
+
+	job, err := h.acquirer.Enqueue(c.Request.Context(), k8s.ExecutionJobParams{
+		SubmissionID: submissionID,
+		ProblemID:    req.ProblemID,
+		Code:         req.Code,
+		TestCases:    testCases,
+		Language:     req.Language,
+	}, []string{req.Language})
+	if err != nil {
+		ticket.Release()
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to queue submission",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	releaseCtx, cancel := context.WithTimeout(context.Background(), maxReleaseWait)
+	go func() {
+		defer cancel()
+		h.releaseOnCompletion(releaseCtx, job.SubmissionID, ticket)
+	}()
+
+	c.JSON(http.StatusAccepted, SubmitResponse{
+		SubmissionID:  job.SubmissionID,
+		Status:        job.Status,
+		QueuePosition: ticket.Position,
+		EstimatedWait: ticket.EstimatedWait,
+	})
+}
+
+// releasePollInterval is how often releaseOnCompletion checks a submitted
+// job's status to find out when its scheduler slot can be freed.
+const releasePollInterval = 500 * time.Millisecond
+
+// releaseOnCompletion polls the acquirer for submissionID's status and
+// releases ticket's scheduler slot once the job reaches a terminal state,
+// so the slot stays held for as long as the submission is actually
+// running rather than just enqueued. It releases (and returns) early if
+// ctx is done, which maxReleaseWait guarantees happens eventually even if
+// the job's status never resolves.
+func (h *ExecuteHandler) releaseOnCompletion(ctx context.Context, submissionID string, ticket *submissions.Ticket) {
+	defer ticket.Release()
+
+	ticker := time.NewTicker(releasePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job, err := h.acquirer.GetJobStatus(ctx, submissionID)
+			if err != nil {
+				continue
+			}
+			if job.Status == models.ProvisionerJobCompleted || job.Status == models.ProvisionerJobFailed {
+				return
+			}
+		}
+	}
+}
+
+// GetSubmissionStatus handles GET /api/submissions/:id, for clients polling
+// a submission queued via Submit instead of streaming it.
+func (h *ExecuteHandler) GetSubmissionStatus(c *gin.Context) {
+	if h.acquirer == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "async submission is not configured"})
+		return
+	}
+
+	job, err := h.acquirer.GetJobStatus(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to look up submission",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"submission_id": job.SubmissionID,
+		"status":        job.Status,
+	}
+	if job.Status == models.ProvisionerJobCompleted {
+		var result k8s.ExecutionResult
+		if err := json.Unmarshal(job.Result, &result); err == nil {
+			response["result"] = result
+		}
+	}
+	if job.Status == models.ProvisionerJobFailed {
+		response["error"] = job.Error
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // generateSubmissionID creates a unique submission ID
 // TODO: Replace with UUID from database
 func generateSubmissionID() string {
@@ -1,26 +1,86 @@
 package handlers
 
 import (
+	"context"
 	"crypto/subtle"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/server/middleware"
 	"github.com/nahtao97/scribble/internal/services"
 )
 
+// leaderboardCacheTTL is how long a cached leaderboard page or user rank
+// entry is served before falling back to the underlying query. It's tuned
+// to roughly the compute cadence (leaderboards are recomputed by a
+// periodic external ComputeLeaderboards call, not on every write), so a
+// cached page is rarely more than one compute cycle stale even without the
+// explicit Invalidate calls below.
+const leaderboardCacheTTL = 5 * time.Minute
+
 // LeaderboardHandler handles HTTP requests for leaderboard endpoints
 type LeaderboardHandler struct {
 	leaderboardService *services.LeaderboardService
+	cache              services.LeaderboardCache
 }
 
 // NewLeaderboardHandler creates a new leaderboard handler instance
-func NewLeaderboardHandler(leaderboardService *services.LeaderboardService) *LeaderboardHandler {
+func NewLeaderboardHandler(leaderboardService *services.LeaderboardService, cache services.LeaderboardCache) *LeaderboardHandler {
 	return &LeaderboardHandler{
 		leaderboardService: leaderboardService,
+		cache:              cache,
+	}
+}
+
+// cachedJSON serves the JSON encoding of load's result from h.cache under
+// key if present, otherwise calls load, caches its JSON encoding for
+// leaderboardCacheTTL, and returns that. Sets the X-Cache response header
+// to HIT or MISS either way, so callers can write the returned bytes
+// straight to the response with c.Data. If h.cache is nil (no backend
+// configured), it always calls load and leaves the header unset.
+func (h *LeaderboardHandler) cachedJSON(c *gin.Context, key string, load func() (interface{}, error)) ([]byte, error) {
+	if h.cache == nil {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	}
+
+	ctx := c.Request.Context()
+	if raw, hit, err := h.cache.Get(ctx, key); err == nil && hit {
+		c.Header("X-Cache", "HIT")
+		return raw, nil
+	}
+
+	c.Header("X-Cache", "MISS")
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cache value for key %s: %w", key, err)
 	}
+	_ = h.cache.Set(ctx, key, raw, leaderboardCacheTTL)
+	return raw, nil
+}
+
+// invalidateCache drops every cached entry under prefix, logging nothing on
+// failure beyond the error it returns - a stale cache entry here just means
+// a later reader sees slightly-out-of-date rankings for up to
+// leaderboardCacheTTL, not incorrect state.
+func (h *LeaderboardHandler) invalidateCache(ctx context.Context, prefix string) {
+	if h.cache == nil {
+		return
+	}
+	_ = h.cache.Invalidate(ctx, prefix)
 }
 
 // ComputeLeaderboards handles POST /internal/leaderboards/compute
@@ -38,6 +98,7 @@ func (h *LeaderboardHandler) ComputeLeaderboards(c *gin.Context) {
 	providedSecret := c.GetHeader("X-Admin-Secret")
 	// Use constant-time comparison to prevent timing attacks
 	if subtle.ConstantTimeCompare([]byte(providedSecret), []byte(adminSecret)) != 1 {
+		middleware.MarkSuspicious(c, middleware.ReasonAdminSecretMismatch)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "invalid admin credentials",
 		})
@@ -67,7 +128,12 @@ func (h *LeaderboardHandler) ComputeLeaderboards(c *gin.Context) {
 			return
 		}
 
-		result, err := h.leaderboardService.ComputeLeaderboard(metricType)
+		scope := models.LeaderboardScope(c.Query("scope"))
+		if scope == "" {
+			scope = models.ScopeGlobal
+		}
+
+		result, err := h.leaderboardService.ComputeLeaderboardForScope(metricType, scope)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": err.Error(),
@@ -75,6 +141,11 @@ func (h *LeaderboardHandler) ComputeLeaderboards(c *gin.Context) {
 			return
 		}
 
+		// Drop cached pages for this metric (every scope and page) and user
+		// ranks, now that fresh rankings have been written.
+		h.invalidateCache(c.Request.Context(), fmt.Sprintf("lb:%s:", metricType))
+		h.invalidateCache(c.Request.Context(), "lb:user:")
+
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
 			"results": []models.ComputeResult{*result},
@@ -91,6 +162,10 @@ func (h *LeaderboardHandler) ComputeLeaderboards(c *gin.Context) {
 		return
 	}
 
+	// Every metric changed, so drop the whole leaderboard cache rather than
+	// invalidating each metric's prefix individually.
+	h.invalidateCache(c.Request.Context(), "lb:")
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"results": results,
@@ -135,7 +210,15 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 		}
 	}
 
-	result, err := h.leaderboardService.GetLeaderboard(metricType, page, pageSize)
+	scope := models.LeaderboardScope(c.Query("scope"))
+	if scope == "" {
+		scope = models.ScopeGlobal
+	}
+
+	key := fmt.Sprintf("lb:%s:%s:%d:%d", metricType, scope, page, pageSize)
+	raw, err := h.cachedJSON(c, key, func() (interface{}, error) {
+		return h.leaderboardService.GetLeaderboardForScope(metricType, scope, page, pageSize)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -143,7 +226,7 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.Data(http.StatusOK, "application/json", raw)
 }
 
 // GetUserRanks handles GET /internal/leaderboards/user/:user_id
@@ -158,7 +241,117 @@ func (h *LeaderboardHandler) GetUserRanks(c *gin.Context) {
 		return
 	}
 
-	ranks, err := h.leaderboardService.GetUserAllRanks(uint(userID))
+	key := fmt.Sprintf("lb:user:%d", userID)
+	raw, err := h.cachedJSON(c, key, func() (interface{}, error) {
+		return h.leaderboardService.GetUserAllRanks(uint(userID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": userID,
+		"ranks":   json.RawMessage(raw),
+	})
+}
+
+// OpenSeason handles POST /internal/leaderboards/seasons
+// Admin-only endpoint to open a new season at a given name/start time.
+func (h *LeaderboardHandler) OpenSeason(c *gin.Context) {
+	adminSecret := os.Getenv("ADMIN_SECRET")
+	if adminSecret == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "admin secret not configured",
+		})
+		return
+	}
+	providedSecret := c.GetHeader("X-Admin-Secret")
+	if subtle.ConstantTimeCompare([]byte(providedSecret), []byte(adminSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid admin credentials",
+		})
+		return
+	}
+
+	var req struct {
+		Name     string    `json:"name" binding:"required"`
+		StartsAt time.Time `json:"starts_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.StartsAt.IsZero() {
+		req.StartsAt = time.Now()
+	}
+
+	season, err := h.leaderboardService.OpenSeason(req.Name, req.StartsAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.invalidateCache(c.Request.Context(), "lb:")
+
+	c.JSON(http.StatusCreated, season)
+}
+
+// CloseSeason handles POST /internal/leaderboards/seasons/:season_id/close
+// Admin-only endpoint to close a season, freezing its final rankings into
+// leaderboard_archive.
+func (h *LeaderboardHandler) CloseSeason(c *gin.Context) {
+	adminSecret := os.Getenv("ADMIN_SECRET")
+	if adminSecret == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "admin secret not configured",
+		})
+		return
+	}
+	providedSecret := c.GetHeader("X-Admin-Secret")
+	if subtle.ConstantTimeCompare([]byte(providedSecret), []byte(adminSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid admin credentials",
+		})
+		return
+	}
+
+	seasonIDParam := c.Param("season_id")
+	seasonID, err := strconv.ParseUint(seasonIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid season ID"})
+		return
+	}
+
+	result, err := h.leaderboardService.CloseSeason(uint(seasonID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.invalidateCache(c.Request.Context(), "lb:")
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
+
+// GetUserHistoricalRanks handles GET /internal/leaderboards/user/:user_id/history
+// Returns a user's rank trajectory across past closed seasons.
+func (h *LeaderboardHandler) GetUserHistoricalRanks(c *gin.Context) {
+	userIDParam := c.Param("user_id")
+	userID, err := strconv.ParseUint(userIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	history, err := h.leaderboardService.GetUserHistoricalRanks(uint(userID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -168,7 +361,7 @@ func (h *LeaderboardHandler) GetUserRanks(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"user_id": userID,
-		"ranks":   ranks,
+		"history": history,
 	})
 }
 
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// MetricsHandler handles HTTP requests for materialized user metrics
+type MetricsHandler struct {
+	metricsService *services.MetricsService
+}
+
+// NewMetricsHandler creates a new metrics handler instance
+func NewMetricsHandler(metricsService *services.MetricsService) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: metricsService,
+	}
+}
+
+// RefreshMetrics handles POST /internal/metrics/refresh
+// Admin-only endpoint to recompute user_metrics. With a user_id query param
+// it refreshes just that user; otherwise it refreshes every user with at
+// least one submission.
+func (h *MetricsHandler) RefreshMetrics(c *gin.Context) {
+	adminSecret := os.Getenv("ADMIN_SECRET")
+	if adminSecret == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "admin secret not configured",
+		})
+		return
+	}
+
+	providedSecret := c.GetHeader("X-Admin-Secret")
+	// Use constant-time comparison to prevent timing attacks
+	if subtle.ConstantTimeCompare([]byte(providedSecret), []byte(adminSecret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid admin credentials",
+		})
+		return
+	}
+
+	if userIDParam := c.Query("user_id"); userIDParam != "" {
+		userID, err := strconv.ParseUint(userIDParam, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid user ID",
+			})
+			return
+		}
+
+		metrics, err := h.metricsService.RefreshUserMetrics(uint(userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"metrics": metrics,
+		})
+		return
+	}
+
+	result, err := h.metricsService.RefreshAllMetrics()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"result":  result,
+	})
+}
@@ -6,18 +6,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/cache"
+	"github.com/nahtao97/scribble/internal/problems"
 	"github.com/nahtao97/scribble/internal/services"
 )
 
 // ProblemHandler handles HTTP requests for problem-related endpoints
 type ProblemHandler struct {
 	problemService *services.ProblemService
+	cache          *cache.Cache
 }
 
 // NewProblemHandler creates a new problem handler instance
-func NewProblemHandler(problemService *services.ProblemService) *ProblemHandler {
+func NewProblemHandler(problemService *services.ProblemService, c *cache.Cache) *ProblemHandler {
 	return &ProblemHandler{
 		problemService: problemService,
+		cache:          c,
 	}
 }
 
@@ -98,9 +102,12 @@ func (h *ProblemHandler) GetDailyChallengeByDate(c *gin.Context) {
 	var challenge interface{}
 	var err error
 
-	// Handle "today" as a special case
+	// Handle "today" as a special case, cached until the next UTC
+	// midnight since "today" names the same challenge for its whole day.
 	if dateParam == "today" {
-		challenge, err = h.problemService.GetTodaysDailyChallenge()
+		challenge, err = h.cache.GetOrLoad("daily_challenge_today", "daily_challenge:today", untilNextUTCMidnight(), func() (interface{}, error) {
+			return h.problemService.GetTodaysDailyChallenge()
+		})
 	} else {
 		// Parse date in YYYY-MM-DD format
 		date, parseErr := time.Parse("2006-01-02", dateParam)
@@ -126,3 +133,47 @@ func (h *ProblemHandler) GetDailyChallengeByDate(c *gin.Context) {
 		"daily_challenge": challenge,
 	})
 }
+
+// ImportProblems handles POST /internal/problems/import
+// Accepts a gzipped tarball of problem directories (problem.yaml plus a
+// tests/ folder), validates each one, and upserts it by slug. Partial
+// success is reported rather than treated as a failure: problems that
+// parse and validate are imported even if others in the same tarball
+// don't.
+func (h *ProblemHandler) ImportProblems(c *gin.Context) {
+	defs, loadErr := problems.LoadTarball(c.Request.Body)
+	if loadErr != nil && len(defs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": loadErr.Error(),
+		})
+		return
+	}
+
+	imported := make([]string, 0, len(defs))
+	failures := make([]string, 0)
+	for _, def := range defs {
+		if _, err := h.problemService.ImportProblem(def); err != nil {
+			failures = append(failures, def.Slug+": "+err.Error())
+			continue
+		}
+		imported = append(imported, def.Slug)
+	}
+
+	if loadErr != nil {
+		failures = append(failures, loadErr.Error())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported": imported,
+		"failures": failures,
+	})
+}
+
+// untilNextUTCMidnight returns how long remains until the next UTC day
+// boundary, so a cached "today" value expires exactly when it stops
+// meaning today.
+func untilNextUTCMidnight() time.Duration {
+	now := time.Now().UTC()
+	nextMidnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return nextMidnight.Sub(now)
+}
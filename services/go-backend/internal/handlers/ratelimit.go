@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/server/middleware"
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// writeRateLimited sets the Retry-After header to retryAfter (rounded up
+// to the nearest whole second, per RFC 9110) and responds 429 Too Many
+// Requests. Shared by handlers wired up with a *services.RateLimiter.
+func writeRateLimited(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": "rate limit exceeded",
+	})
+}
+
+// RateLimitMiddleware returns a gin.HandlerFunc that enforces antiCheatService's
+// TierAuthenticated rate limit (a GCRALimiter, once wired in via
+// NewAntiCheatServiceWithRateLimiters, gives this a sliding window with burst
+// and cooldown instead of an abrupt fixed window) ahead of routes that aren't
+// already gated by CheckSubmissionForTier, such as the code executor polling
+// an in-progress run's status. The caller's user ID is read from the
+// X-User-Id header, set upstream by the Node.js proxy's auth layer; a
+// request with no such header is let through unrated, since there's no key
+// to rate limit it by.
+func RateLimitMiddleware(antiCheatService *services.AntiCheatService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDParam := c.GetHeader("X-User-Id")
+		if userIDParam == "" {
+			c.Next()
+			return
+		}
+
+		userID, err := strconv.ParseUint(userIDParam, 10, 32)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter, err := antiCheatService.CheckRateLimit(uint(userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			middleware.MarkSuspicious(c, middleware.ReasonRateLimited)
+			c.Header("X-RateLimit-Remaining", "0")
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(retryAfter).Unix(), 10))
+			writeRateLimited(c, retryAfter)
+			c.Abort()
+			return
+		}
+
+		c.Header("X-RateLimit-Remaining", "1")
+		c.Next()
+	}
+}
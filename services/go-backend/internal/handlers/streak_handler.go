@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nahtao97/scribble/internal/services"
@@ -11,12 +12,14 @@ import (
 // StreakHandler handles streak-related HTTP requests
 type StreakHandler struct {
 	streakService *services.StreakService
+	rateLimiter   *services.RateLimiter
 }
 
 // NewStreakHandler creates a new streak handler
-func NewStreakHandler(streakService *services.StreakService) *StreakHandler {
+func NewStreakHandler(streakService *services.StreakService, rateLimiter *services.RateLimiter) *StreakHandler {
 	return &StreakHandler{
 		streakService: streakService,
+		rateLimiter:   rateLimiter,
 	}
 }
 
@@ -37,6 +40,11 @@ func (h *StreakHandler) UpdateStreak(c *gin.Context) {
 		return
 	}
 
+	if ok, retryAfter := h.rateLimiter.Allow(userID, "streaks.update"); !ok {
+		writeRateLimited(c, retryAfter)
+		return
+	}
+
 	var req UpdateStreakRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -45,7 +53,7 @@ func (h *StreakHandler) UpdateStreak(c *gin.Context) {
 		return
 	}
 
-	streak, err := h.streakService.UpdateStreak(userID, req.ProblemID, req.SubmissionID)
+	streak, err := h.streakService.UpdateStreak(c.Request.Context(), userID, req.ProblemID, req.SubmissionID)
 	if err != nil {
 		if errors.Is(err, services.ErrNotDailyChallenge) {
 			// Not an error - submission is not for daily challenge
@@ -97,6 +105,109 @@ func (h *StreakHandler) GetStreak(c *gin.Context) {
 	c.JSON(http.StatusOK, streak)
 }
 
+// SetDayCutoffHourRequest is the request body for setting a user's streak
+// day cutoff hour
+type SetDayCutoffHourRequest struct {
+	DayCutoffHour int `json:"day_cutoff_hour"`
+}
+
+// SetDayCutoffHour handles PUT /internal/streaks/:user_id/cutoff-hour
+func (h *StreakHandler) SetDayCutoffHour(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "user_id is required",
+		})
+		return
+	}
+
+	var req SetDayCutoffHourRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	streak, err := h.streakService.SetDayCutoffHour(userID, req.DayCutoffHour)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCutoffHour) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set day cutoff hour",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, streak)
+}
+
+// GetActivityCalendar handles GET /internal/streaks/:user_id/calendar?from=2026-01-01&to=2026-01-31&tz=America/New_York
+func (h *StreakHandler) GetActivityCalendar(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "user_id is required",
+		})
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must be a date in YYYY-MM-DD format",
+		})
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "to must be a date in YYYY-MM-DD format",
+		})
+		return
+	}
+
+	tz := c.DefaultQuery("tz", "UTC")
+	calendar, err := h.streakService.GetActivityCalendar(userID, from, to, tz)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get activity calendar",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"calendar": calendar,
+	})
+}
+
+// GetStreakSegments handles GET /internal/streaks/:user_id/segments
+func (h *StreakHandler) GetStreakSegments(c *gin.Context) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "user_id is required",
+		})
+		return
+	}
+
+	segments, err := h.streakService.GetStreakSegments(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get streak segments",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"segments": segments,
+	})
+}
+
 // GetLeaderboard handles GET /internal/streaks/leaderboard
 func (h *StreakHandler) GetLeaderboard(c *gin.Context) {
 	limit := 10 // Default limit
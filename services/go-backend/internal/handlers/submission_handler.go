@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -11,12 +13,14 @@ import (
 // SubmissionHandler handles HTTP requests for submission-related endpoints
 type SubmissionHandler struct {
 	submissionService *services.SubmissionService
+	rateLimiter       *services.RateLimiter
 }
 
 // NewSubmissionHandler creates a new submission handler instance
-func NewSubmissionHandler(submissionService *services.SubmissionService) *SubmissionHandler {
+func NewSubmissionHandler(submissionService *services.SubmissionService, rateLimiter *services.RateLimiter) *SubmissionHandler {
 	return &SubmissionHandler{
 		submissionService: submissionService,
+		rateLimiter:       rateLimiter,
 	}
 }
 
@@ -45,6 +49,45 @@ func (h *SubmissionHandler) GetSubmissionByID(c *gin.Context) {
 	})
 }
 
+// StreamSubmissionEvents handles GET /api/submissions/:id/events over
+// server-sent events, pushing every status change SubmissionService's
+// PublishStatus fans out for this submission instead of making the client
+// poll GetSubmissionByID. Unlike ExecuteHandler.Stream (which drives and
+// streams one in-flight execution), this just subscribes - it works for
+// any submission, including one another scribble replica is executing.
+func (h *SubmissionHandler) StreamSubmissionEvents(c *gin.Context) {
+	submissionID := c.Param("id")
+
+	events, unsubscribe, err := h.submissionService.Subscribe(c.Request.Context(), submissionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to subscribe to submission events",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-events
+		if !ok {
+			return false
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return false
+		}
+
+		c.SSEvent("status", string(payload))
+		return true
+	})
+}
+
 // GetPercentileMetrics handles GET /internal/submissions/:id/percentile
 // Returns percentile comparison metrics for a submission
 // e.g., "Faster than 78% of submissions", "Uses less memory than 65% of submissions"
@@ -58,6 +101,11 @@ func (h *SubmissionHandler) GetPercentileMetrics(c *gin.Context) {
 		return
 	}
 
+	if ok, retryAfter := h.rateLimiter.Allow(c.ClientIP(), "submissions.percentile"); !ok {
+		writeRateLimited(c, retryAfter)
+		return
+	}
+
 	metrics, err := h.submissionService.CalculatePercentileMetrics(uint(id))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -83,6 +131,11 @@ func (h *SubmissionHandler) GetProblemStats(c *gin.Context) {
 		return
 	}
 
+	if ok, retryAfter := h.rateLimiter.Allow(c.ClientIP(), "submissions.problem_stats"); !ok {
+		writeRateLimited(c, retryAfter)
+		return
+	}
+
 	stats, err := h.submissionService.GetProblemSubmissionStats(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -95,3 +148,50 @@ func (h *SubmissionHandler) GetProblemStats(c *gin.Context) {
 		"stats": stats,
 	})
 }
+
+// GetProblemDistribution handles GET /internal/problems/:id/distribution
+// Returns percentile bands and histogram buckets for a problem's runtime or
+// memory distribution in a given language, e.g. ?language=go&metric=runtime
+func (h *SubmissionHandler) GetProblemDistribution(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid problem ID",
+		})
+		return
+	}
+
+	if ok, retryAfter := h.rateLimiter.Allow(c.ClientIP(), "submissions.distribution"); !ok {
+		writeRateLimited(c, retryAfter)
+		return
+	}
+
+	language := c.Query("language")
+	if language == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "language query parameter is required",
+		})
+		return
+	}
+
+	metric := services.DistributionMetric(c.DefaultQuery("metric", string(services.MetricRuntime)))
+	if metric != services.MetricRuntime && metric != services.MetricMemory {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "metric must be 'runtime' or 'memory'",
+		})
+		return
+	}
+
+	distribution, err := h.submissionService.GetProblemDistribution(uint(id), language, metric)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"distribution": distribution,
+	})
+}
@@ -1,22 +1,27 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nahtao97/scribble/internal/cache"
 	"github.com/nahtao97/scribble/internal/services"
 )
 
 // UserHandler handles HTTP requests for user-related endpoints
 type UserHandler struct {
 	userService *services.UserService
+	cache       *cache.Cache
 }
 
 // NewUserHandler creates a new user handler instance
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService *services.UserService, c *cache.Cache) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		cache:       c,
 	}
 }
 
@@ -32,7 +37,10 @@ func (h *UserHandler) GetUserMetrics(c *gin.Context) {
 		return
 	}
 
-	metrics, err := h.userService.GetUserMetrics(uint(userID))
+	key := fmt.Sprintf("user_metrics:%d", userID)
+	metrics, err := h.cache.GetOrLoad("user_metrics", key, 10*time.Second, func() (interface{}, error) {
+		return h.userService.GetUserMetrics(uint(userID))
+	})
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": err.Error(),
@@ -84,14 +92,7 @@ func (h *UserHandler) GetTopUsers(c *gin.Context) {
 		limit = 100
 	}
 
-	var users interface{}
-
-	switch by {
-	case "problems":
-		users, err = h.userService.GetTopUsersByProblems(limit)
-	case "streak":
-		users, err = h.userService.GetTopUsersByStreak(limit)
-	default:
+	if by != "problems" && by != "streak" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":         "invalid 'by' parameter",
 			"valid_options": []string{"problems", "streak"},
@@ -99,6 +100,13 @@ func (h *UserHandler) GetTopUsers(c *gin.Context) {
 		return
 	}
 
+	key := fmt.Sprintf("top_users:%s:limit=%d", by, limit)
+	users, err := h.cache.GetOrLoad("top_users", key, 30*time.Second, func() (interface{}, error) {
+		if by == "problems" {
+			return h.userService.GetTopUsersByProblems(limit)
+		}
+		return h.userService.GetTopUsersByStreak(limit)
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
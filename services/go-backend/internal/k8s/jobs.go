@@ -1,39 +1,115 @@
 package k8s
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
+	"sync"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// managedByLabel is the Job label (and annotation) recording which
+// controller owns a submission's execution Job, mirroring
+// models.Submission.ManagedBy.
+const managedByLabel = "scribble.io/managed-by"
+
+// resultFilePath is where the executor image is expected to write its
+// ExecutionResult JSON. collectJobResult execs into the pod to read it
+// directly instead of parsing it out of pod logs, which truncates once a
+// submission's own stdout (e.g. a large test output) grows past a few KB.
+const resultFilePath = "/tmp/result.json"
+
+// tracer emits one span per stage of a job's lifecycle (created,
+// pod-scheduled, logs-collected, cleaned-up), so a slow or stuck
+// execution can be diagnosed end-to-end in a trace backend instead of
+// from logs alone.
+var tracer = otel.Tracer("github.com/nahtao97/scribble/internal/k8s")
+
+// nameRand generates the random suffix Job and PodPool pod names use.
+// Seeding a fresh source on every call (as this package used to, via
+// rand.Seed(time.Now().UnixNano())) reseeds the global generator from the
+// same nanosecond-resolution clock on every call - concurrent callers in
+// the same tick produce the same "random" suffix - and mutating
+// math/rand's global state from multiple goroutines at once is itself a
+// race. One seeded source, guarded by a mutex, avoids both.
+var (
+	nameRandMu sync.Mutex
+	nameRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
 )
 
+// randomSuffix returns a 6-digit random string for disambiguating
+// resource names (e.g. "exec-<submission-id>-<suffix>").
+func randomSuffix() string {
+	nameRandMu.Lock()
+	n := nameRand.Intn(1000000)
+	nameRandMu.Unlock()
+	return fmt.Sprintf("%06d", n)
+}
+
 // ExecutionJobParams contains parameters for creating an execution job
 type ExecutionJobParams struct {
 	SubmissionID string      // Unique submission ID
 	ProblemID    string      // Problem identifier
 	Code         string      // User's code to execute
 	TestCases    interface{} // Test cases to run against
+	// Language selects this execution's runtime and resource budget: the
+	// Kubernetes backend looks it up in its RuntimeRegistry (image,
+	// commands, CPU/memory, timeouts), while sandbox.LocalRuntime resolves
+	// it through internal/limits (wall and CPU time, memory, process count,
+	// output size).
+	Language string
+	// ManagedBy mirrors models.Submission.ManagedBy. Empty or
+	// models.ManagedByExecutor means CreateExecutionJob's own JobManager
+	// runs and polls the Job as usual; any other value suspends the Job
+	// (spec.suspend=true) and hands admission/dispatch to that external
+	// controller instead.
+	ManagedBy string
 }
 
 // JobManager handles Kubernetes Job operations
 type JobManager struct {
-	clientset *kubernetes.Clientset
+	// clientset is kubernetes.Interface rather than the concrete
+	// *kubernetes.Clientset NewJobManager actually builds, so tests can
+	// substitute k8s.io/client-go/kubernetes/fake's Clientset for
+	// WatchJob/jobEventForPod without a real apiserver.
+	clientset kubernetes.Interface
+	// config is kept alongside clientset (rather than just building the
+	// clientset and discarding it) because ExecInPod's SPDY executor needs
+	// to negotiate its own upgraded connection from the raw rest.Config -
+	// it can't be built from a *kubernetes.Clientset alone.
+	config    *rest.Config
 	namespace string
+	runtimes  *RuntimeRegistry
 }
 
 // NewJobManager creates a new K8s Job manager
-// Automatically detects in-cluster config or falls back to kubeconfig
+// Automatically detects in-cluster config or falls back to kubeconfig.
+// The runtime registry is loaded from RUNTIME_REGISTRY_CONFIG if set
+// (see LoadRuntimeRegistry), otherwise it falls back to defaultRuntimes.
 func NewJobManager() (*JobManager, error) {
 	var config *rest.Config
 	var err error
@@ -62,21 +138,49 @@ func NewJobManager() (*JobManager, error) {
 		namespace = "default"
 	}
 
+	runtimes := NewRuntimeRegistry()
+	if configPath := os.Getenv("RUNTIME_REGISTRY_CONFIG"); configPath != "" {
+		runtimes, err = LoadRuntimeRegistry(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load runtime registry: %w", err)
+		}
+	}
+
 	return &JobManager{
 		clientset: clientset,
+		config:    config,
 		namespace: namespace,
+		runtimes:  runtimes,
 	}, nil
 }
 
 // CreateExecutionJob creates a Kubernetes Job to execute user code
 // Job name format: exec-<submission-id>-<random>
-// Resource limits: 512Mi memory, 500m CPU
-// ActiveDeadlineSeconds: 10
+// The executor image, compile/run commands, per-language CPU/memory
+// limits, and compile/execute timeouts all come from jm.runtimes, keyed by
+// params.Language (see RuntimeRegistry). Returns an error if params.Language
+// isn't registered.
+// When params.ManagedBy names an external controller (anything other than
+// "" or models.ManagedByExecutor), the Job is created with spec.suspend=true
+// and left for that controller to admit and dispatch - JobManager only
+// polls/waits for Jobs it manages itself.
 func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJobParams) (string, error) {
+	ctx, span := tracer.Start(ctx, "job_created")
+	defer span.End()
+
+	runtime, ok := jm.runtimes.Get(params.Language)
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %s", params.Language)
+	}
+
+	managedBy := params.ManagedBy
+	if managedBy == "" {
+		managedBy = models.ManagedByExecutor
+	}
+	suspend := managedBy != models.ManagedByExecutor
+
 	// Generate random suffix for job name uniqueness
-	rand.Seed(time.Now().UnixNano())
-	randomSuffix := fmt.Sprintf("%06d", rand.Intn(1000000))
-	jobName := fmt.Sprintf("exec-%s-%s", params.SubmissionID, randomSuffix)
+	jobName := fmt.Sprintf("exec-%s-%s", params.SubmissionID, randomSuffix())
 
 	// Encode code to base64 for safe env var transmission
 	codeB64 := base64.StdEncoding.EncodeToString([]byte(params.Code))
@@ -87,18 +191,24 @@ func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJo
 		return "", fmt.Errorf("failed to marshal test cases: %w", err)
 	}
 
-	// Get executor image from environment or use default
-	executorImage := os.Getenv("PYTHON_EXECUTOR_IMAGE")
-	if executorImage == "" {
-		executorImage = "scribble-python-executor:latest"
-	}
+	executorImage := runtime.Image
 
-	// Define resource limits
-	cpuLimit := resource.MustParse("500m")
-	memoryLimit := resource.MustParse("512Mi")
+	// CPU/memory limits come from the registry rather than internal/limits
+	// now that each language names its own container resources directly;
+	// internal/limits.Resolve still backs sandbox.LocalRuntime's
+	// PIDs/OutputBytes enforcement and its cpu.max cap, which aren't
+	// expressible as Kubernetes container resources at all (LocalRuntime
+	// does not enforce Limits.OpenFiles or Limits.StackBytes).
+	cpuLimit := resource.MustParse(runtime.CPU)
+	memoryLimit := *resource.NewQuantity(runtime.MemoryBytes, resource.BinarySI)
 
-	// ActiveDeadlineSeconds: kill job after 10 seconds
-	var activeDeadlineSeconds int64 = 10
+	// ActiveDeadlineSeconds: kill the job once its compile + execute time
+	// budget elapses, mirroring the CompilationTimeMs/ExecutionTimeMs split
+	// already tracked per submission (see models.Submission).
+	activeDeadlineSeconds := int64((runtime.CompileTimeout + runtime.RunTimeout).Seconds())
+	if activeDeadlineSeconds < 1 {
+		activeDeadlineSeconds = 1
+	}
 
 	// Get RuntimeClass from environment (gVisor for sandboxed execution)
 	runtimeClassName := os.Getenv("K8S_RUNTIME_CLASS")
@@ -124,9 +234,14 @@ func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJo
 				"app":           "scribble-executor",
 				"submission-id": params.SubmissionID,
 				"problem-id":    params.ProblemID,
+				managedByLabel:  managedBy,
+			},
+			Annotations: map[string]string{
+				managedByLabel: managedBy,
 			},
 		},
 		Spec: batchv1.JobSpec{
+			Suspend:               &suspend,
 			ActiveDeadlineSeconds: &activeDeadlineSeconds,
 			// Don't retry failed executions automatically
 			BackoffLimit: func() *int32 { i := int32(0); return &i }(),
@@ -158,7 +273,7 @@ func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJo
 					},
 					Containers: []corev1.Container{
 						{
-							Name:  "executor",
+							Name:  executorContainerName,
 							Image: executorImage,
 							Env: []corev1.EnvVar{
 								{
@@ -173,6 +288,34 @@ func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJo
 									Name:  "PROBLEM_ID",
 									Value: params.ProblemID,
 								},
+								{
+									Name:  "LANGUAGE",
+									Value: params.Language,
+								},
+								{
+									Name:  "COMPILE_CMD",
+									Value: runtime.CompileCmd,
+								},
+								{
+									Name:  "RUN_CMD",
+									Value: runtime.RunCmd,
+								},
+								{
+									Name:  "FILE_EXTENSION",
+									Value: runtime.FileExtension,
+								},
+								{
+									Name:  "COMPILE_TIMEOUT_SECONDS",
+									Value: fmt.Sprintf("%d", int64(runtime.CompileTimeout.Seconds())),
+								},
+								{
+									Name:  "RUN_TIMEOUT_SECONDS",
+									Value: fmt.Sprintf("%d", int64(runtime.RunTimeout.Seconds())),
+								},
+								{
+									Name:  "RESULT_FILE",
+									Value: resultFilePath,
+								},
 							},
 							Resources: corev1.ResourceRequirements{
 								Limits: corev1.ResourceList{
@@ -225,57 +368,257 @@ func (jm *JobManager) CreateExecutionJob(ctx context.Context, params ExecutionJo
 		},
 	}
 
-	// Create the job in Kubernetes
-	createdJob, err := jm.clientset.BatchV1().Jobs(jm.namespace).Create(ctx, job, metav1.CreateOptions{})
+	// Create the job in Kubernetes, retrying transient apiserver errors
+	// (5xx, etcd leader elections) rather than failing the submission
+	// outright.
+	var createdJob *batchv1.Job
+	err = retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		var createErr error
+		createdJob, createErr = jm.clientset.BatchV1().Jobs(jm.namespace).Create(ctx, job, metav1.CreateOptions{})
+		return createErr
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create K8s job: %w", err)
 	}
 
+	ownerRef := metav1.OwnerReference{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       createdJob.Name,
+		UID:        createdJob.UID,
+	}
+
+	if err := jm.createDenyAllNetworkPolicy(ctx, createdJob.Name, params.SubmissionID, ownerRef); err != nil {
+		return "", fmt.Errorf("failed to create network policy for job %s: %w", createdJob.Name, err)
+	}
+
+	if err := jm.createPodDisruptionBudget(ctx, createdJob.Name, params.SubmissionID, ownerRef); err != nil {
+		return "", fmt.Errorf("failed to create pod disruption budget for job %s: %w", createdJob.Name, err)
+	}
+
 	return createdJob.Name, nil
 }
 
-// GetJobLogs retrieves logs from a completed job
-func (jm *JobManager) GetJobLogs(ctx context.Context, jobName string) (string, error) {
+// networkPolicyName and podDisruptionBudgetName derive a deterministic
+// name from jobName, so DeleteJob can find and remove them without having
+// to list by label.
+func networkPolicyName(jobName string) string {
+	return "netpol-" + jobName
+}
+
+func podDisruptionBudgetName(jobName string) string {
+	return "pdb-" + jobName
+}
+
+// createDenyAllNetworkPolicy creates a NetworkPolicy selecting jobName's
+// pod by its submission-id label and denying all ingress and egress -
+// DNSPolicy/DNSConfig above already strip DNS resolution, but nothing
+// previously stopped the pod from reaching cluster IPs directly by
+// address. ownerRef ties its lifetime to the Job so Kubernetes garbage
+// collects it if the Job is ever deleted outside of DeleteJob.
+func (jm *JobManager) createDenyAllNetworkPolicy(ctx context.Context, jobName, submissionID string, ownerRef metav1.OwnerReference) error {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            networkPolicyName(jobName),
+			Namespace:       jm.namespace,
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"submission-id": submissionID},
+			},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+			// No Ingress/Egress rules means "deny all" for the selected pods.
+		},
+	}
+
+	return retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		_, err := jm.clientset.NetworkingV1().NetworkPolicies(jm.namespace).Create(ctx, policy, metav1.CreateOptions{})
+		return err
+	})
+}
+
+// createPodDisruptionBudget protects jobName's pod from voluntary
+// eviction (node drains, cluster autoscaler scale-down) for as long as
+// it's running a submission, the same PDB-per-workload pattern used to
+// shield long-running verifier pods elsewhere. MinAvailable: 1 against a
+// single-pod selector means that pod can never be voluntarily evicted.
+func (jm *JobManager) createPodDisruptionBudget(ctx context.Context, jobName, submissionID string, ownerRef metav1.OwnerReference) error {
+	minAvailable := intstr.FromInt(1)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            podDisruptionBudgetName(jobName),
+			Namespace:       jm.namespace,
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"submission-id": submissionID},
+			},
+		},
+	}
+
+	return retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		_, err := jm.clientset.PolicyV1().PodDisruptionBudgets(jm.namespace).Create(ctx, pdb, metav1.CreateOptions{})
+		return err
+	})
+}
+
+// maxLogLineBytes bounds how long a single log line GetJobLogs streams can
+// be, so a runaway executor writing one enormous unbroken line can't grow
+// the scanner's buffer without limit.
+const maxLogLineBytes = 1 << 20
+
+// LogLine is one line read from a pod's log stream, in the order it was
+// written. Err is set (with Text empty) on the final LogLine if streaming
+// ended because of an error rather than the stream's natural EOF.
+type LogLine struct {
+	Text string
+	Err  error
+}
+
+// GetJobLogs streams jobName's pod logs line by line instead of a single
+// fixed-size Read, which silently truncated anything past the first 4KB.
+// The returned channel is closed once the pod's log stream ends (normally
+// when its container exits) or ctx is cancelled.
+func (jm *JobManager) GetJobLogs(ctx context.Context, jobName string) (<-chan LogLine, error) {
 	// Get pods for this job
 	pods, err := jm.clientset.CoreV1().Pods(jm.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to list pods for job: %w", err)
+		return nil, fmt.Errorf("failed to list pods for job: %w", err)
 	}
 
 	if len(pods.Items) == 0 {
-		return "", fmt.Errorf("no pods found for job %s", jobName)
+		return nil, fmt.Errorf("no pods found for job %s", jobName)
 	}
 
 	// Get logs from the first pod (jobs should only have one pod)
 	podName := pods.Items[0].Name
-	req := jm.clientset.CoreV1().Pods(jm.namespace).GetLogs(podName, &corev1.PodLogOptions{})
-	logs, err := req.Stream(ctx)
+	req := jm.clientset.CoreV1().Pods(jm.namespace).GetLogs(podName, &corev1.PodLogOptions{Follow: true})
+	stream, err := req.Stream(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get logs: %w", err)
+		return nil, fmt.Errorf("failed to open log stream: %w", err)
 	}
-	defer logs.Close()
 
-	// Read logs
-	buf := make([]byte, 4096)
-	n, err := logs.Read(buf)
-	if err != nil && err.Error() != "EOF" {
-		return "", fmt.Errorf("failed to read logs: %w", err)
+	lines := make(chan LogLine)
+	go func() {
+		defer close(lines)
+		defer stream.Close()
+
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineBytes)
+		for scanner.Scan() {
+			select {
+			case lines <- LogLine{Text: scanner.Text()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			select {
+			case lines <- LogLine{Err: fmt.Errorf("failed to read logs: %w", err)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return lines, nil
+}
+
+// ExecInPod runs cmd inside jobName's pod (in the "executor" container)
+// over the same SPDY remotecommand protocol gitlab-runner's kubernetes
+// executor uses to run commands in an already-scheduled pod, and returns
+// its captured stdout/stderr. Used by collectJobResult to read
+// resultFilePath directly rather than parsing it out of pod logs.
+func (jm *JobManager) ExecInPod(ctx context.Context, jobName string, cmd []string) (stdout, stderr string, err error) {
+	pods, err := jm.clientset.CoreV1().Pods(jm.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list pods for job: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return "", "", fmt.Errorf("no pods found for job %s", jobName)
 	}
 
-	return string(buf[:n]), nil
+	return execInPodContainer(ctx, jm.clientset, jm.config, jm.namespace, pods.Items[0].Name, executorContainerName, cmd, nil)
 }
 
-// DeleteJob removes a job and its associated pods
+// executorContainerName is the name every executor Pod's primary
+// container is given, whether it was created for a single Job (jobs.go)
+// or as a warm PodPool member (pod_pool.go).
+const executorContainerName = "executor"
+
+// execInPodContainer runs cmd inside podName's container over the SPDY
+// remotecommand protocol, optionally piping stdin to it, and returns its
+// captured stdout/stderr. Shared by JobManager.ExecInPod and PodPool.exec
+// so both paths negotiate the exec stream the same way.
+func execInPodContainer(ctx context.Context, clientset kubernetes.Interface, config *rest.Config, namespace, podName, container string, cmd []string, stdin io.Reader) (stdout, stderr string, err error) {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create exec executor: %w", err)
+	}
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	streamErr := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdoutBuf,
+		Stderr: &stderrBuf,
+	})
+	if streamErr != nil {
+		return stdoutBuf.String(), stderrBuf.String(), fmt.Errorf("failed to exec %v in pod %s: %w", cmd, podName, streamErr)
+	}
+
+	return stdoutBuf.String(), stderrBuf.String(), nil
+}
+
+// DeleteJob removes a job and its associated pods, along with the
+// NetworkPolicy and PodDisruptionBudget CreateExecutionJob created
+// alongside it. Their ownerReferences would eventually get them garbage
+// collected anyway, but deleting them here makes cleanup immediate rather
+// than waiting on the garbage collector.
 func (jm *JobManager) DeleteJob(ctx context.Context, jobName string) error {
 	// Delete with PropagationPolicy=Background to clean up pods automatically
 	deletePolicy := metav1.DeletePropagationBackground
-	err := jm.clientset.BatchV1().Jobs(jm.namespace).Delete(ctx, jobName, metav1.DeleteOptions{
-		PropagationPolicy: &deletePolicy,
+	err := retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		return jm.clientset.BatchV1().Jobs(jm.namespace).Delete(ctx, jobName, metav1.DeleteOptions{
+			PropagationPolicy: &deletePolicy,
+		})
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete job: %w", err)
 	}
+
+	if err := retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		return jm.clientset.NetworkingV1().NetworkPolicies(jm.namespace).Delete(ctx, networkPolicyName(jobName), metav1.DeleteOptions{})
+	}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete network policy for job %s: %w", jobName, err)
+	}
+
+	if err := retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		return jm.clientset.PolicyV1().PodDisruptionBudgets(jm.namespace).Delete(ctx, podDisruptionBudgetName(jobName), metav1.DeleteOptions{})
+	}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod disruption budget for job %s: %w", jobName, err)
+	}
+
 	return nil
 }
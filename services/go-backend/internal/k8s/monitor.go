@@ -6,23 +6,53 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/nahtao97/scribble/internal/logging"
+	"github.com/nahtao97/scribble/internal/models"
 )
 
 // ExecutionResult represents the result of code execution
 type ExecutionResult struct {
-	Status          string       `json:"status"`           // accepted, wrong_answer, runtime_error, time_limit, memory_limit, compilation_error
+	Status          string       `json:"status"`           // accepted, wrong_answer, runtime_error, time_limit, memory_limit, output_limit, compilation_error
 	ErrorMessage    string       `json:"error_message"`    // Error details if any
 	ExecutionTimeMs int64        `json:"execution_time_ms"`
 	MemoryUsedKB    int64        `json:"memory_used_kb"`
 	TestsPassed     int          `json:"tests_passed"`
 	TestsTotal      int          `json:"tests_total"`
 	TestResults     []TestResult `json:"test_results,omitempty"`
+	// Verdict is the short code for a resource-limit violation (TLE, MLE,
+	// OLE, or RE:signal=<name> for a signal-terminated process), set by
+	// VerdictForStatus. Empty for any other Status.
+	Verdict string `json:"verdict,omitempty"`
+}
+
+// VerdictForStatus translates Status/errorMessage into the short verdict
+// code ExecuteHandler surfaces to clients: TLE/MLE/OLE for the three
+// resource limits a sandbox enforces, errorMessage verbatim when it's
+// already a "RE:signal=..." form (LocalRuntime sets it that way for a
+// signal-terminated process), and "" for every other status (a verdict
+// beyond pass/fail isn't meaningful there).
+func VerdictForStatus(status, errorMessage string) string {
+	switch status {
+	case "time_limit":
+		return "TLE"
+	case "memory_limit":
+		return "MLE"
+	case "output_limit":
+		return "OLE"
+	case "runtime_error":
+		if strings.HasPrefix(errorMessage, "RE:signal=") {
+			return errorMessage
+		}
+	}
+	return ""
 }
 
 // TestResult represents the result of a single test case
@@ -76,6 +106,8 @@ func (jm *JobManager) WaitForJobCompletion(ctx context.Context, jobName string,
 	}
 	defer watcher.Stop()
 
+	podScheduled := false
+
 	// Watch for job status changes
 	for {
 		select {
@@ -84,6 +116,7 @@ func (jm *JobManager) WaitForJobCompletion(ctx context.Context, jobName string,
 				return &ExecutionResult{
 					Status:       "time_limit",
 					ErrorMessage: "Execution exceeded time limit",
+					Verdict:      "TLE",
 				}, ErrJobTimeout
 			}
 			return nil, ctx.Err()
@@ -103,6 +136,11 @@ func (jm *JobManager) WaitForJobCompletion(ctx context.Context, jobName string,
 				continue
 			}
 
+			if !podScheduled && job.Status.Active > 0 {
+				podScheduled = true
+				jm.emitStageSpan(ctx, "pod_scheduled")
+			}
+
 			// Check if job completed
 			result, done, err := jm.checkJobStatus(ctx, job)
 			if done {
@@ -112,11 +150,21 @@ func (jm *JobManager) WaitForJobCompletion(ctx context.Context, jobName string,
 	}
 }
 
+// emitStageSpan records an instant span marking jobName's arrival at a
+// lifecycle stage (e.g. pod_scheduled), for stages that don't have a
+// well-defined start/end the way job creation or cleanup do.
+func (jm *JobManager) emitStageSpan(ctx context.Context, stage string) {
+	_, span := tracer.Start(ctx, stage)
+	span.End()
+}
+
 // pollForJobCompletion polls job status at regular intervals
 func (jm *JobManager) pollForJobCompletion(ctx context.Context, jobName string, config MonitorConfig) (*ExecutionResult, error) {
 	ticker := time.NewTicker(config.PollInterval)
 	defer ticker.Stop()
 
+	podScheduled := false
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -124,6 +172,7 @@ func (jm *JobManager) pollForJobCompletion(ctx context.Context, jobName string,
 				return &ExecutionResult{
 					Status:       "time_limit",
 					ErrorMessage: "Execution exceeded time limit",
+					Verdict:      "TLE",
 				}, ErrJobTimeout
 			}
 			return nil, ctx.Err()
@@ -134,6 +183,11 @@ func (jm *JobManager) pollForJobCompletion(ctx context.Context, jobName string,
 				continue
 			}
 
+			if !podScheduled && job.Status.Active > 0 {
+				podScheduled = true
+				jm.emitStageSpan(ctx, "pod_scheduled")
+			}
+
 			result, done, err := jm.checkJobStatus(ctx, job)
 			if done {
 				return result, err
@@ -170,6 +224,7 @@ func (jm *JobManager) checkJobStatus(ctx context.Context, job *batchv1.Job) (*Ex
 			return &ExecutionResult{
 				Status:       "time_limit",
 				ErrorMessage: "Execution exceeded time limit",
+				Verdict:      "TLE",
 			}, true, ErrJobTimeout
 		}
 	}
@@ -180,6 +235,9 @@ func (jm *JobManager) checkJobStatus(ctx context.Context, job *batchv1.Job) (*Ex
 
 // collectJobResult reads the execution result from pod logs
 func (jm *JobManager) collectJobResult(ctx context.Context, jobName string) (*ExecutionResult, error) {
+	ctx, span := tracer.Start(ctx, "logs_collected")
+	defer span.End()
+
 	// Find the pod for this job
 	pods, err := jm.clientset.CoreV1().Pods(jm.namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
@@ -203,13 +261,22 @@ func (jm *JobManager) collectJobResult(ctx context.Context, jobName string) (*Ex
 					return &ExecutionResult{
 						Status:       "memory_limit",
 						ErrorMessage: "Execution exceeded memory limit",
+						Verdict:      "MLE",
 					}, nil
 				}
 			}
 		}
 	}
 
-	// Get pod logs
+	// Prefer reading resultFilePath directly out of the pod over pod logs:
+	// a submission that writes a lot to stdout would otherwise truncate or
+	// interleave with the result JSON the executor appends after it.
+	if result, err := jm.collectResultFromFile(ctx, jobName); err == nil {
+		return result, nil
+	}
+
+	// Fall back to the log-based result for executor images that don't
+	// yet write resultFilePath.
 	logs, err := jm.getPodLogs(ctx, pod.Name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod logs: %w", err)
@@ -232,6 +299,24 @@ func (jm *JobManager) collectJobResult(ctx context.Context, jobName string) (*Ex
 	return &result, nil
 }
 
+// collectResultFromFile execs into jobName's pod to read resultFilePath
+// and parse it as an ExecutionResult.
+func (jm *JobManager) collectResultFromFile(ctx context.Context, jobName string) (*ExecutionResult, error) {
+	stdout, _, err := jm.ExecInPod(ctx, jobName, []string{"cat", resultFilePath})
+	if err != nil {
+		return nil, err
+	}
+	if stdout == "" {
+		return nil, fmt.Errorf("%s is empty", resultFilePath)
+	}
+
+	var result ExecutionResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", resultFilePath, err)
+	}
+	return &result, nil
+}
+
 // getPodLogs retrieves complete logs from a pod
 func (jm *JobManager) getPodLogs(ctx context.Context, podName string) (string, error) {
 	req := jm.clientset.CoreV1().Pods(jm.namespace).GetLogs(podName, &corev1.PodLogOptions{})
@@ -257,12 +342,14 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-// CleanupJob deletes a completed job and its pods
+// CleanupJob deletes a completed job and its pods, plus the NetworkPolicy
+// and PodDisruptionBudget CreateExecutionJob created alongside it - see
+// DeleteJob, which this delegates to.
 func (jm *JobManager) CleanupJob(ctx context.Context, jobName string) error {
-	propagation := metav1.DeletePropagationBackground
-	return jm.clientset.BatchV1().Jobs(jm.namespace).Delete(ctx, jobName, metav1.DeleteOptions{
-		PropagationPolicy: &propagation,
-	})
+	ctx, span := tracer.Start(ctx, "cleaned_up")
+	defer span.End()
+
+	return jm.DeleteJob(ctx, jobName)
 }
 
 // ExecuteAndWait is a convenience method that creates a job, waits for completion,
@@ -276,11 +363,20 @@ func (jm *JobManager) ExecuteAndWait(ctx context.Context, params ExecutionJobPar
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
+	// A non-default ManagedBy hands the suspended Job off to an external
+	// controller (e.g. Kueue) to admit, dispatch, and report status for -
+	// JobManager only polls/cleans up Jobs it owns itself.
+	if params.ManagedBy != "" && params.ManagedBy != models.ManagedByExecutor {
+		return &ExecutionResult{Status: models.StatusPending}, nil
+	}
+
 	// Always cleanup the job when done
 	defer func() {
 		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		_ = jm.CleanupJob(cleanupCtx, jobName)
+		if err := jm.CleanupJob(cleanupCtx, jobName); err != nil {
+			logging.WithStacktrace(ctx, fmt.Errorf("failed to clean up job %s: %w", jobName, err))
+		}
 	}()
 
 	// Wait for completion and collect result
@@ -0,0 +1,328 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// poolLeaseLabel records whether a PodPool pod is currently leased out to
+// a submission, so `kubectl get pods -l scribble.io/leased=true` shows
+// exactly what's in flight without needing to query PodPool's in-process
+// state.
+const poolLeaseLabel = "scribble.io/leased"
+
+// poolLanguageLabel selects a PodPool pod's language, the same way
+// RuntimeRegistry keys LanguageRuntime.
+const poolLanguageLabel = "scribble.io/pool-language"
+
+// ErrPoolExhausted is returned by Acquire when no idle pod is available
+// for a language - callers should fall back to
+// JobManager.CreateExecutionJob rather than block waiting for one to free
+// up, since a fresh Job is strictly slower, not unavailable.
+var ErrPoolExhausted = errors.New("pod pool exhausted for language")
+
+// PodPoolConfig configures PodPool's warm pool size and recycling policy.
+type PodPoolConfig struct {
+	// PerLanguage is how many idle pods PodPool keeps warm per language.
+	PerLanguage int
+	// MaxExecutions is how many submissions a single pod handles before
+	// Release recycles it (deletes it and warms a replacement), bounding
+	// how much state (leaked processes, /tmp litter) one sandbox can
+	// accumulate across submissions.
+	MaxExecutions int
+}
+
+// DefaultPodPoolConfig returns a starting point for PodPool: 2 warm pods
+// per language, recycled every 50 executions.
+func DefaultPodPoolConfig() PodPoolConfig {
+	return PodPoolConfig{PerLanguage: 2, MaxExecutions: 50}
+}
+
+// pooledPod is one warm executor pod and its lease state.
+type pooledPod struct {
+	name       string
+	language   string
+	executions int
+}
+
+// PodPool maintains a warm pool of pre-started executor Pods per
+// language, gated by readiness, so a submission can dispatch straight to
+// an idle pod over the exec API instead of paying a fresh Job + gVisor
+// sandbox's multi-second cold start on every submission - the same
+// problem batch-runner workloads hit when every task pays a fresh Pod's
+// startup overhead. JobManager.CreateExecutionJob remains the fallback
+// path once a language's pool is exhausted (see ErrPoolExhausted).
+type PodPool struct {
+	clientset *kubernetes.Clientset
+	config    *rest.Config
+	namespace string
+	runtimes  *RuntimeRegistry
+	poolCfg   PodPoolConfig
+
+	mu   sync.Mutex
+	idle map[string][]*pooledPod // language -> idle pods, most-recently-warmed last
+}
+
+// NewPodPool creates a PodPool. clientset/config/namespace should be the
+// same values JobManager was built with - PodPool is a sibling subsystem,
+// not a replacement.
+func NewPodPool(clientset *kubernetes.Clientset, config *rest.Config, namespace string, runtimes *RuntimeRegistry, poolCfg PodPoolConfig) *PodPool {
+	return &PodPool{
+		clientset: clientset,
+		config:    config,
+		namespace: namespace,
+		runtimes:  runtimes,
+		poolCfg:   poolCfg,
+		idle:      make(map[string][]*pooledPod),
+	}
+}
+
+// Warm tops language's idle pool up to poolCfg.PerLanguage, creating and
+// waiting for readiness on any pods currently missing. Intended to be
+// called once at startup for every language the runtime registry
+// supports, and again after Release recycles a pod.
+func (p *PodPool) Warm(ctx context.Context, language string) error {
+	runtime, ok := p.runtimes.Get(language)
+	if !ok {
+		return fmt.Errorf("unsupported language: %s", language)
+	}
+
+	p.mu.Lock()
+	have := len(p.idle[language])
+	p.mu.Unlock()
+
+	for i := have; i < p.poolCfg.PerLanguage; i++ {
+		pod, err := p.createWarmPod(ctx, language, runtime)
+		if err != nil {
+			return fmt.Errorf("failed to warm pod for %s: %w", language, err)
+		}
+		p.mu.Lock()
+		p.idle[language] = append(p.idle[language], pod)
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// createWarmPod schedules one idle executor pod for language and blocks
+// until its readiness probe passes.
+func (p *PodPool) createWarmPod(ctx context.Context, language string, runtime LanguageRuntime) (*pooledPod, error) {
+	name := fmt.Sprintf("pool-%s-%s", language, randomSuffix())
+	runAsNonRoot := true
+	runAsUser := int64(1000)
+	allowPrivilegeEscalation := false
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: p.namespace,
+			Labels: map[string]string{
+				"app":             "scribble-executor-pool",
+				poolLanguageLabel: language,
+				poolLeaseLabel:    "false",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:  executorContainerName,
+					Image: runtime.Image,
+					// Idle until Execute execs CODE/TEST_CASES in and runs
+					// CompileCmd/RunCmd itself - there's no per-submission
+					// env var to pick up at startup the way a fresh Job has.
+					Command: []string{"sleep", "infinity"},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"true"}},
+						},
+						PeriodSeconds: 5,
+					},
+					SecurityContext: &corev1.SecurityContext{
+						RunAsNonRoot:             &runAsNonRoot,
+						RunAsUser:                &runAsUser,
+						AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+					},
+				},
+			},
+		},
+	}
+
+	created, err := p.clientset.CoreV1().Pods(p.namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.waitForReady(ctx, created.Name); err != nil {
+		return nil, err
+	}
+
+	return &pooledPod{name: created.Name, language: language}, nil
+}
+
+// waitForReady polls podName until its PodReady condition is true or 30
+// seconds elapse.
+func (p *PodPool) waitForReady(ctx context.Context, podName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pod %s did not become ready: %w", podName, ctx.Err())
+		case <-ticker.C:
+			pod, err := p.clientset.CoreV1().Pods(p.namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// Acquire leases an idle pod for language, marking it busy via
+// poolLeaseLabel. Returns ErrPoolExhausted if none are idle - the caller
+// should fall back to JobManager.CreateExecutionJob rather than wait.
+func (p *PodPool) Acquire(ctx context.Context, language string) (*pooledPod, error) {
+	p.mu.Lock()
+	idle := p.idle[language]
+	if len(idle) == 0 {
+		p.mu.Unlock()
+		return nil, ErrPoolExhausted
+	}
+	pod := idle[len(idle)-1]
+	p.idle[language] = idle[:len(idle)-1]
+	p.mu.Unlock()
+
+	if err := p.setLeased(ctx, pod.name, true); err != nil {
+		return nil, fmt.Errorf("failed to lease pod %s: %w", pod.name, err)
+	}
+	return pod, nil
+}
+
+// setLeased patches podName's poolLeaseLabel.
+func (p *PodPool) setLeased(ctx context.Context, podName string, leased bool) error {
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, poolLeaseLabel, fmt.Sprintf("%t", leased))
+	_, err := p.clientset.CoreV1().Pods(p.namespace).Patch(ctx, podName, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// Execute dispatches params to pod over the exec API: it writes Code and
+// TestCases into the pod's filesystem, runs the language's CompileCmd (if
+// any) followed by RunCmd, and reads resultFilePath back - the exec-based
+// equivalent of a fresh Job's env vars and result collection, without a
+// new Pod's scheduling and sandbox startup cost.
+func (p *PodPool) Execute(ctx context.Context, pod *pooledPod, params ExecutionJobParams) (*ExecutionResult, error) {
+	runtime, ok := p.runtimes.Get(pod.language)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s", pod.language)
+	}
+
+	testCasesJSON, err := json.Marshal(params.TestCases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal test cases: %w", err)
+	}
+
+	sourcePath := fmt.Sprintf("/submission/main.%s", runtime.FileExtension)
+	if err := p.writeFile(ctx, pod.name, sourcePath, strings.NewReader(params.Code)); err != nil {
+		return nil, fmt.Errorf("failed to write submission source: %w", err)
+	}
+	if err := p.writeFile(ctx, pod.name, "/submission/test_cases.json", bytes.NewReader(testCasesJSON)); err != nil {
+		return nil, fmt.Errorf("failed to write test cases: %w", err)
+	}
+
+	cmd := runtime.RunCmd
+	if runtime.CompileCmd != "" {
+		cmd = runtime.CompileCmd + " && " + runtime.RunCmd
+	}
+	// A non-zero exit here usually means the submission itself failed
+	// (compile error, non-zero run exit), not that the exec call failed -
+	// resultFilePath is written either way, so the error is intentionally
+	// ignored in favor of reading whatever ended up there.
+	_, _, _ = p.exec(ctx, pod.name, []string{"sh", "-c", cmd + " > " + resultFilePath + " 2>&1"}, nil)
+
+	stdout, _, err := p.exec(ctx, pod.name, []string{"cat", resultFilePath}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution result: %w", err)
+	}
+	pod.executions++
+
+	var result ExecutionResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		return &ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: fmt.Sprintf("failed to parse execution result: %s", truncateString(stdout, 200)),
+		}, nil
+	}
+	return &result, nil
+}
+
+// writeFile execs `cat > path` in pod, piping content as stdin.
+func (p *PodPool) writeFile(ctx context.Context, podName, path string, content io.Reader) error {
+	_, _, err := p.exec(ctx, podName, []string{"sh", "-c", "cat > " + path}, content)
+	return err
+}
+
+func (p *PodPool) exec(ctx context.Context, podName string, cmd []string, stdin io.Reader) (stdout, stderr string, err error) {
+	return execInPodContainer(ctx, p.clientset, p.config, p.namespace, podName, executorContainerName, cmd, stdin)
+}
+
+// Release returns pod to service after a submission finishes. oomed marks
+// that the submission's process was OOM-killed, which - like having
+// reached poolCfg.MaxExecutions - recycles the pod (deletes it and warms
+// a replacement) instead of reusing a sandbox that just proved it can run
+// out of memory for the next, unrelated submission.
+func (p *PodPool) Release(ctx context.Context, pod *pooledPod, oomed bool) error {
+	if shouldRecycle(pod.executions, p.poolCfg.MaxExecutions, oomed) {
+		return p.recycle(ctx, pod)
+	}
+
+	if err := p.setLeased(ctx, pod.name, false); err != nil {
+		return fmt.Errorf("failed to release pod %s: %w", pod.name, err)
+	}
+	p.mu.Lock()
+	p.idle[pod.language] = append(p.idle[pod.language], pod)
+	p.mu.Unlock()
+	return nil
+}
+
+// shouldRecycle decides whether a pod that just finished a submission
+// should be torn down and replaced instead of returned to the idle pool.
+func shouldRecycle(executions, maxExecutions int, oomed bool) bool {
+	if oomed {
+		return true
+	}
+	return maxExecutions > 0 && executions >= maxExecutions
+}
+
+// recycle deletes pod and, best-effort, warms a replacement so the pool's
+// PerLanguage count doesn't quietly shrink. A failure to warm the
+// replacement is returned to the caller to log - pod itself is still
+// deleted either way.
+func (p *PodPool) recycle(ctx context.Context, pod *pooledPod) error {
+	propagation := metav1.DeletePropagationBackground
+	if err := p.clientset.CoreV1().Pods(p.namespace).Delete(ctx, pod.name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	}); err != nil {
+		return fmt.Errorf("failed to delete recycled pod %s: %w", pod.name, err)
+	}
+	return p.Warm(ctx, pod.language)
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import "testing"
+
+func TestShouldRecycle_OOMAlwaysRecycles(t *testing.T) {
+	if !shouldRecycle(1, 50, true) {
+		t.Error("expected an OOM-killed pod to be recycled regardless of execution count")
+	}
+}
+
+func TestShouldRecycle_BelowMaxExecutionsKeepsPod(t *testing.T) {
+	if shouldRecycle(49, 50, false) {
+		t.Error("expected a pod below MaxExecutions to be kept")
+	}
+}
+
+func TestShouldRecycle_AtMaxExecutionsRecycles(t *testing.T) {
+	if !shouldRecycle(50, 50, false) {
+		t.Error("expected a pod at MaxExecutions to be recycled")
+	}
+}
+
+func TestShouldRecycle_ZeroMaxExecutionsDisablesRecycling(t *testing.T) {
+	if shouldRecycle(1000, 0, false) {
+		t.Error("expected MaxExecutions=0 to mean \"never recycle on count\"")
+	}
+}
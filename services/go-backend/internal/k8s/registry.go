@@ -0,0 +1,267 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LanguageRuntime describes everything CreateExecutionJob needs to run one
+// submission in a given language: which executor image to schedule, how to
+// compile and run the submitted code inside it, how long each of those
+// steps is allowed to take, and the file extension the executor should
+// write the submission to.
+type LanguageRuntime struct {
+	// Image is the executor container image, e.g.
+	// "scribble-cpp-executor:latest".
+	Image string `yaml:"image" json:"image"`
+	// CompileCmd is the shell command the executor runs to compile the
+	// submission before executing it. Empty for interpreted languages.
+	CompileCmd string `yaml:"compile_cmd" json:"compile_cmd,omitempty"`
+	// RunCmd is the shell command the executor runs to execute the
+	// (compiled, if applicable) submission.
+	RunCmd string `yaml:"run_cmd" json:"run_cmd"`
+	// FileExtension is the extension the executor should give the
+	// submitted source file, e.g. "cpp", "go", "rs" - without the dot.
+	FileExtension string `yaml:"file_extension" json:"file_extension"`
+	// CompileTimeout bounds CompileCmd, separately from RunTimeout,
+	// mirroring the CompilationTimeMs/ExecutionTimeMs split already
+	// tracked per submission (see models.Submission).
+	CompileTimeout time.Duration `yaml:"compile_timeout" json:"compile_timeout"`
+	// RunTimeout bounds RunCmd.
+	RunTimeout time.Duration `yaml:"run_timeout" json:"run_timeout"`
+	// CPU is this language's container CPU limit, in Kubernetes resource
+	// quantity form (e.g. "500m", "1").
+	CPU string `yaml:"cpu" json:"cpu"`
+	// MemoryBytes is this language's container memory limit.
+	MemoryBytes int64 `yaml:"memory_bytes" json:"memory_bytes"`
+}
+
+// defaultRuntimes is the registry CreateExecutionJob falls back to when no
+// config file is loaded via LoadRuntimeRegistry - enough to run the
+// languages scribble ships support for out of the box. Operators add a
+// language (or override one of these) by pointing RUNTIME_REGISTRY_CONFIG
+// at their own YAML/JSON file instead of recompiling.
+var defaultRuntimes = map[string]LanguageRuntime{
+	"python": {
+		Image:          "scribble-python-executor:latest",
+		RunCmd:         "python3 /submission/main.py",
+		FileExtension:  "py",
+		CompileTimeout: 0,
+		RunTimeout:     8 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    256 << 20,
+	},
+	"node": {
+		Image:          "scribble-node-executor:latest",
+		RunCmd:         "node /submission/main.js",
+		FileExtension:  "js",
+		CompileTimeout: 0,
+		RunTimeout:     8 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    256 << 20,
+	},
+	"cpp": {
+		Image:          "scribble-cpp-executor:latest",
+		CompileCmd:     "g++ -O2 -o /submission/main /submission/main.cpp",
+		RunCmd:         "/submission/main",
+		FileExtension:  "cpp",
+		CompileTimeout: 10 * time.Second,
+		RunTimeout:     6 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    256 << 20,
+	},
+	"go": {
+		Image:          "scribble-go-executor:latest",
+		CompileCmd:     "go build -o /submission/main /submission/main.go",
+		RunCmd:         "/submission/main",
+		FileExtension:  "go",
+		CompileTimeout: 15 * time.Second,
+		RunTimeout:     8 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    384 << 20,
+	},
+	"java": {
+		Image:          "scribble-java-executor:latest",
+		CompileCmd:     "javac /submission/Main.java -d /submission",
+		RunCmd:         "java -cp /submission Main",
+		FileExtension:  "java",
+		CompileTimeout: 20 * time.Second,
+		RunTimeout:     20 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    768 << 20,
+	},
+	"rust": {
+		Image:          "scribble-rust-executor:latest",
+		CompileCmd:     "rustc -O -o /submission/main /submission/main.rs",
+		RunCmd:         "/submission/main",
+		FileExtension:  "rs",
+		CompileTimeout: 15 * time.Second,
+		RunTimeout:     6 * time.Second,
+		CPU:            "500m",
+		MemoryBytes:    256 << 20,
+	},
+}
+
+// RuntimeRegistry maps a language ID (python, cpp, go, java, rust, node, ...)
+// to the LanguageRuntime CreateExecutionJob builds a Job spec from. It's
+// safe for concurrent use so a reload can swap the registry's contents
+// while requests are being served.
+type RuntimeRegistry struct {
+	mu        sync.RWMutex
+	languages map[string]LanguageRuntime
+}
+
+// NewRuntimeRegistry creates a RuntimeRegistry seeded with defaultRuntimes.
+func NewRuntimeRegistry() *RuntimeRegistry {
+	languages := make(map[string]LanguageRuntime, len(defaultRuntimes))
+	for lang, rt := range defaultRuntimes {
+		languages[lang] = rt
+	}
+	return &RuntimeRegistry{languages: languages}
+}
+
+// Get returns the LanguageRuntime registered for language, and whether one
+// was found.
+func (r *RuntimeRegistry) Get(language string) (LanguageRuntime, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.languages[language]
+	return rt, ok
+}
+
+// Register adds or overrides language's LanguageRuntime.
+func (r *RuntimeRegistry) Register(language string, rt LanguageRuntime) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.languages[language] = rt
+}
+
+// Languages returns every registered language ID, in no particular order.
+func (r *RuntimeRegistry) Languages() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	langs := make([]string, 0, len(r.languages))
+	for lang := range r.languages {
+		langs = append(langs, lang)
+	}
+	return langs
+}
+
+// configDuration lets a runtime registry config file write timeouts as Go
+// duration strings ("5s") instead of raw nanosecond integers, for both of
+// LoadRuntimeRegistry's supported formats - encoding/json only calls
+// UnmarshalJSON, yaml.v3 only calls UnmarshalYAML, so both are implemented
+// here rather than relying on one format to fall back on the other.
+type configDuration time.Duration
+
+// UnmarshalJSON accepts either a Go duration string ("5s") or a
+// nanosecond integer.
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		*d = configDuration(parsed)
+		return nil
+	}
+
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return fmt.Errorf("duration must be a string or integer nanoseconds: %w", err)
+	}
+	*d = configDuration(ns)
+	return nil
+}
+
+// UnmarshalYAML accepts either a Go duration string ("5s") or a
+// nanosecond integer.
+func (d *configDuration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		*d = configDuration(parsed)
+		return nil
+	}
+
+	var ns int64
+	if err := value.Decode(&ns); err != nil {
+		return fmt.Errorf("duration must be a string or integer nanoseconds: %w", err)
+	}
+	*d = configDuration(ns)
+	return nil
+}
+
+// languageRuntimeConfig is LanguageRuntime's on-disk shape: identical
+// except its timeouts go through configDuration so a config file can write
+// "5s" instead of 5000000000.
+type languageRuntimeConfig struct {
+	Image          string         `yaml:"image" json:"image"`
+	CompileCmd     string         `yaml:"compile_cmd" json:"compile_cmd,omitempty"`
+	RunCmd         string         `yaml:"run_cmd" json:"run_cmd"`
+	FileExtension  string         `yaml:"file_extension" json:"file_extension"`
+	CompileTimeout configDuration `yaml:"compile_timeout" json:"compile_timeout"`
+	RunTimeout     configDuration `yaml:"run_timeout" json:"run_timeout"`
+	CPU            string         `yaml:"cpu" json:"cpu"`
+	MemoryBytes    int64          `yaml:"memory_bytes" json:"memory_bytes"`
+}
+
+func (c languageRuntimeConfig) toLanguageRuntime() LanguageRuntime {
+	return LanguageRuntime{
+		Image:          c.Image,
+		CompileCmd:     c.CompileCmd,
+		RunCmd:         c.RunCmd,
+		FileExtension:  c.FileExtension,
+		CompileTimeout: time.Duration(c.CompileTimeout),
+		RunTimeout:     time.Duration(c.RunTimeout),
+		CPU:            c.CPU,
+		MemoryBytes:    c.MemoryBytes,
+	}
+}
+
+// runtimeConfigFile is the on-disk shape LoadRuntimeRegistry parses: a flat
+// map of language ID to its LanguageRuntime, so an operator's config file
+// only needs to list the languages it's adding or overriding rather than
+// the whole registry.
+type runtimeConfigFile struct {
+	Languages map[string]languageRuntimeConfig `yaml:"languages" json:"languages"`
+}
+
+// LoadRuntimeRegistry reads a YAML or JSON file (selected by its
+// extension - .json for JSON, anything else for YAML) of language
+// overrides and merges them over defaultRuntimes, so an operator's config
+// only needs to name the languages it's adding or changing.
+func LoadRuntimeRegistry(path string) (*RuntimeRegistry, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runtime registry config %s: %w", path, err)
+	}
+
+	var cfg runtimeConfigFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse runtime registry config %s: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse runtime registry config %s: %w", path, err)
+		}
+	}
+
+	registry := NewRuntimeRegistry()
+	for lang, rt := range cfg.Languages {
+		registry.Register(lang, rt.toLanguageRuntime())
+	}
+	return registry, nil
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRuntimeRegistry_DefaultsCoverTicketLanguages(t *testing.T) {
+	registry := NewRuntimeRegistry()
+
+	for _, lang := range []string{"python", "cpp", "go", "java", "rust", "node"} {
+		if _, ok := registry.Get(lang); !ok {
+			t.Errorf("expected default registry to have an entry for %q", lang)
+		}
+	}
+}
+
+func TestRuntimeRegistry_GetUnknownLanguage(t *testing.T) {
+	registry := NewRuntimeRegistry()
+
+	if _, ok := registry.Get("cobol"); ok {
+		t.Error("expected Get(cobol) to report not found")
+	}
+}
+
+func TestRuntimeRegistry_RegisterOverridesDefault(t *testing.T) {
+	registry := NewRuntimeRegistry()
+
+	custom := LanguageRuntime{
+		Image:      "my-python-executor:v2",
+		RunCmd:     "python3.12 /submission/main.py",
+		RunTimeout: 12 * time.Second,
+	}
+	registry.Register("python", custom)
+
+	got, ok := registry.Get("python")
+	if !ok {
+		t.Fatal("expected Get(python) to find the overridden entry")
+	}
+	if got != custom {
+		t.Errorf("Get(python) = %+v, want %+v", got, custom)
+	}
+}
+
+func TestLoadRuntimeRegistry_YAMLOverridesMergeOverDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runtimes.yaml")
+	contents := `
+languages:
+  python:
+    image: custom-python-executor:latest
+    run_cmd: python3 /submission/main.py
+    file_extension: py
+    run_timeout: 5s
+    cpu: "250m"
+    memory_bytes: 134217728
+  zig:
+    image: scribble-zig-executor:latest
+    compile_cmd: zig build-exe /submission/main.zig
+    run_cmd: /submission/main
+    file_extension: zig
+    compile_timeout: 10s
+    run_timeout: 6s
+    cpu: "500m"
+    memory_bytes: 268435456
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	registry, err := LoadRuntimeRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRuntimeRegistry failed: %v", err)
+	}
+
+	python, ok := registry.Get("python")
+	if !ok {
+		t.Fatal("expected python to still be registered")
+	}
+	if python.Image != "custom-python-executor:latest" {
+		t.Errorf("python.Image = %q, want overridden value", python.Image)
+	}
+	if python.RunTimeout != 5*time.Second {
+		t.Errorf("python.RunTimeout = %v, want 5s", python.RunTimeout)
+	}
+
+	zig, ok := registry.Get("zig")
+	if !ok {
+		t.Fatal("expected zig to be added by the config file")
+	}
+	if zig.FileExtension != "zig" {
+		t.Errorf("zig.FileExtension = %q, want zig", zig.FileExtension)
+	}
+
+	// Languages the config file doesn't mention keep their built-in defaults.
+	if _, ok := registry.Get("java"); !ok {
+		t.Error("expected java to still be registered from defaults")
+	}
+}
+
+func TestLoadRuntimeRegistry_JSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runtimes.json")
+	contents := `{
+		"languages": {
+			"python": {
+				"image": "json-python-executor:latest",
+				"run_cmd": "python3 /submission/main.py",
+				"file_extension": "py",
+				"run_timeout": 5000000000,
+				"cpu": "250m",
+				"memory_bytes": 134217728
+			}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	registry, err := LoadRuntimeRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRuntimeRegistry failed: %v", err)
+	}
+
+	python, ok := registry.Get("python")
+	if !ok {
+		t.Fatal("expected python to be registered")
+	}
+	if python.Image != "json-python-executor:latest" {
+		t.Errorf("python.Image = %q, want overridden value", python.Image)
+	}
+}
+
+func TestLoadRuntimeRegistry_MissingFile(t *testing.T) {
+	if _, err := LoadRuntimeRegistry("/nonexistent/runtimes.yaml"); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
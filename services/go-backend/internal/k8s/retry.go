@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// RetryConfig bounds retryWithBackoff's attempts, mirroring
+// internal/runner.ClientConfig's BaseBackoff/MaxBackoff split for the same
+// reason: a fixed interval either hammers a struggling apiserver or makes
+// healthy requests wait needlessly long.
+type RetryConfig struct {
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryConfig is used for every apiserver call retryWithBackoff
+// wraps unless a caller needs something slower/faster.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:  3,
+		BaseBackoff: 200 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+	}
+}
+
+// retryWithBackoff calls fn, retrying with exponential backoff on errors
+// isRetryableAPIError considers transient (apiserver 5xx, etcd leader
+// changes surfaced as timeouts/conflicts). Any other error, or the final
+// failed attempt, is returned as-is.
+func retryWithBackoff(ctx context.Context, cfg RetryConfig, fn func() error) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := cfg.BaseBackoff * time.Duration(uint(1)<<uint(attempt-1))
+			if backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !isRetryableAPIError(err) {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("exhausted retries: %w", lastErr)
+}
+
+// isRetryableAPIError reports whether err looks like a transient apiserver
+// condition (overloaded, mid-leader-election, briefly unreachable) rather
+// than a request that will never succeed (not found, invalid, forbidden).
+func isRetryableAPIError(err error) bool {
+	return apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsConflict(err)
+}
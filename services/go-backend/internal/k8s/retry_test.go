@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRetryWithBackoff_SucceedsWithoutRetryingOnFirstAttempt(t *testing.T) {
+	calls := 0
+	err := retryWithBackoff(context.Background(), DefaultRetryConfig(), func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryWithBackoff() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRetryWithBackoff_RetriesTransientErrorsThenSucceeds(t *testing.T) {
+	calls := 0
+	cfg := RetryConfig{MaxRetries: 3, BaseBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	err := retryWithBackoff(context.Background(), cfg, func() error {
+		calls++
+		if calls < 3 {
+			return apierrors.NewServiceUnavailable("apiserver overloaded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryWithBackoff() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryWithBackoff_NonRetryableErrorReturnsImmediately(t *testing.T) {
+	calls := 0
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "job-1")
+
+	err := retryWithBackoff(context.Background(), DefaultRetryConfig(), func() error {
+		calls++
+		return notFound
+	})
+	if !errors.Is(err, notFound) && err != notFound {
+		t.Errorf("retryWithBackoff() error = %v, want the original NotFound error", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (non-retryable error shouldn't retry)", calls)
+	}
+}
+
+func TestRetryWithBackoff_ExhaustsRetriesAndReturnsWrappedError(t *testing.T) {
+	calls := 0
+	cfg := RetryConfig{MaxRetries: 2, BaseBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	err := retryWithBackoff(context.Background(), cfg, func() error {
+		calls++
+		return apierrors.NewTimeoutError("apiserver timeout", 0)
+	})
+	if err == nil {
+		t.Fatal("retryWithBackoff() error = nil, want exhausted-retries error")
+	}
+	if calls != cfg.MaxRetries+1 {
+		t.Errorf("calls = %d, want %d (1 initial + MaxRetries retries)", calls, cfg.MaxRetries+1)
+	}
+}
+
+func TestRetryWithBackoff_ContextCancelledDuringBackoffReturnsCtxErr(t *testing.T) {
+	cfg := RetryConfig{MaxRetries: 5, BaseBackoff: 50 * time.Millisecond, MaxBackoff: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	err := retryWithBackoff(ctx, cfg, func() error {
+		calls++
+		return apierrors.NewServiceUnavailable("still overloaded")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("retryWithBackoff() error = %v, want context.Canceled", err)
+	}
+}
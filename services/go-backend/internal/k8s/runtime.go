@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/logging"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// Runtime abstracts "run this submission somewhere and report back" behind
+// an interface ExecuteHandler depends on instead of the concrete
+// Kubernetes-backed JobManager, so a sandbox backend that doesn't need a
+// cluster (see internal/sandbox.LocalRuntime) can stand in for local
+// development or low-latency execution. Every implementation must honor
+// the same ExecutionResult schema so downstream code (streak service,
+// submission storage) doesn't need to know which backend ran a submission.
+type Runtime interface {
+	// CreateExecution starts executing params and returns an opaque
+	// execution ID that Wait/Logs/Cleanup use to refer back to it.
+	CreateExecution(ctx context.Context, params ExecutionJobParams) (executionID string, err error)
+	// Wait blocks until executionID finishes or config.MaxWaitTime elapses.
+	Wait(ctx context.Context, executionID string, config MonitorConfig) (*ExecutionResult, error)
+	// Logs returns whatever output executionID has produced so far.
+	Logs(ctx context.Context, executionID string) (string, error)
+	// Cleanup releases any resources (pods, processes, cgroups, scratch
+	// files) still held for executionID.
+	Cleanup(ctx context.Context, executionID string) error
+}
+
+// CreateExecution implements Runtime by delegating to CreateExecutionJob.
+func (jm *JobManager) CreateExecution(ctx context.Context, params ExecutionJobParams) (string, error) {
+	return jm.CreateExecutionJob(ctx, params)
+}
+
+// Wait implements Runtime by delegating to WaitForJobCompletion.
+func (jm *JobManager) Wait(ctx context.Context, executionID string, config MonitorConfig) (*ExecutionResult, error) {
+	return jm.WaitForJobCompletion(ctx, executionID, config)
+}
+
+// Logs implements Runtime by draining GetJobLogs' streamed lines into a
+// single string, so callers that just want "everything logged so far"
+// don't need to know about LogLine.
+func (jm *JobManager) Logs(ctx context.Context, executionID string) (string, error) {
+	lines, err := jm.GetJobLogs(ctx, executionID)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for line := range lines {
+		if line.Err != nil {
+			return out.String(), line.Err
+		}
+		out.WriteString(line.Text)
+		out.WriteByte('\n')
+	}
+	return out.String(), nil
+}
+
+// Cleanup implements Runtime by delegating to CleanupJob.
+func (jm *JobManager) Cleanup(ctx context.Context, executionID string) error {
+	return jm.CleanupJob(ctx, executionID)
+}
+
+// EventStreamer is the optional capability a Runtime can implement to
+// support ExecuteHandler.Stream's live per-test-case progress; *JobManager
+// implements it via StreamJobEvents. A Runtime that doesn't implement it
+// (e.g. LocalRuntime) still works with Stream, just without intermediate
+// events - only a final completed/error event is emitted.
+type EventStreamer interface {
+	StreamJobEvents(ctx context.Context, executionID string) (<-chan ExecutionEvent, error)
+}
+
+// ExecuteAndWaitRuntime is the Runtime-interface equivalent of
+// JobManager.ExecuteAndWait: it creates the execution, waits for it with
+// DefaultMonitorConfig, and always cleans up afterward, regardless of
+// which Runtime backs it.
+func ExecuteAndWaitRuntime(ctx context.Context, rt Runtime, params ExecutionJobParams) (*ExecutionResult, error) {
+	executionID, err := rt.CreateExecution(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// A non-default ManagedBy hands this execution off to an external
+	// controller to dispatch and report status for - nothing here should
+	// poll or clean it up.
+	if params.ManagedBy != "" && params.ManagedBy != models.ManagedByExecutor {
+		return &ExecutionResult{Status: models.StatusPending}, nil
+	}
+
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := rt.Cleanup(cleanupCtx, executionID); err != nil {
+			logging.WithStacktrace(ctx, fmt.Errorf("failed to clean up execution %s: %w", executionID, err))
+		}
+	}()
+
+	result, err := rt.Wait(ctx, executionID, DefaultMonitorConfig())
+	if err != nil && result == nil {
+		return &ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: err.Error(),
+		}, err
+	}
+
+	return result, nil
+}
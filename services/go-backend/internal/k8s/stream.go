@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ExecutionEventType names the kind of update StreamJobEvents emits.
+type ExecutionEventType string
+
+const (
+	EventJobCreated     ExecutionEventType = "job_created"
+	EventPodScheduled   ExecutionEventType = "pod_scheduled"
+	EventTestCaseResult ExecutionEventType = "test_case_result"
+	EventStdoutChunk    ExecutionEventType = "stdout_chunk"
+	EventCompleted      ExecutionEventType = "completed"
+	EventError          ExecutionEventType = "error"
+)
+
+// ExecutionEvent is one update in a job's execution lifecycle, as emitted by
+// StreamJobEvents. Only the field(s) relevant to Type are populated - e.g. a
+// stdout_chunk event only sets Chunk, a test_case_result event only sets
+// TestResult.
+type ExecutionEvent struct {
+	Type       ExecutionEventType `json:"type"`
+	Chunk      string             `json:"chunk,omitempty"`
+	TestResult *TestResult        `json:"test_result,omitempty"`
+	Result     *ExecutionResult   `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// StreamJobEvents tails jobName's lifecycle and pod logs, emitting a typed
+// ExecutionEvent for each job/pod status change and each line of log
+// output, so callers can show live progress instead of blocking on
+// ExecuteAndWait. The returned channel is closed once the job reaches a
+// terminal state (completed or error) or ctx is cancelled.
+func (jm *JobManager) StreamJobEvents(ctx context.Context, jobName string) (<-chan ExecutionEvent, error) {
+	watcher, err := jm.clientset.BatchV1().Jobs(jm.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("metadata.name=%s", jobName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch job %s: %w", jobName, err)
+	}
+
+	events := make(chan ExecutionEvent, 16)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+
+		events <- ExecutionEvent{Type: EventJobCreated}
+
+		podStreamStarted := false
+
+		for {
+			select {
+			case <-ctx.Done():
+				events <- ExecutionEvent{Type: EventError, Error: ctx.Err().Error()}
+				return
+
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				if event.Type == watch.Error {
+					continue
+				}
+
+				job, ok := event.Object.(*batchv1.Job)
+				if !ok {
+					continue
+				}
+
+				if !podStreamStarted && (job.Status.Active > 0 || job.Status.Succeeded > 0 || job.Status.Failed > 0) {
+					podStreamStarted = true
+					events <- ExecutionEvent{Type: EventPodScheduled}
+					go jm.streamPodLogs(ctx, jobName, events)
+				}
+
+				if job.Status.Succeeded > 0 || job.Status.Failed > 0 {
+					result, err := jm.collectJobResult(ctx, job.Name)
+					if err != nil {
+						events <- ExecutionEvent{Type: EventError, Error: err.Error()}
+						return
+					}
+					events <- ExecutionEvent{Type: EventCompleted, Result: result}
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// streamPodLogs tails jobName's pod logs line by line, emitting a
+// stdout_chunk event per line and a test_case_result event for any line
+// that parses as a TestResult. It gives up silently on error - the caller's
+// watch loop still emits a final completed/error event once the job itself
+// finishes, so a logging hiccup here doesn't lose the eventual result.
+func (jm *JobManager) streamPodLogs(ctx context.Context, jobName string, events chan<- ExecutionEvent) {
+	pods, err := jm.clientset.CoreV1().Pods(jm.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+	})
+	if err != nil || len(pods.Items) == 0 {
+		return
+	}
+
+	req := jm.clientset.CoreV1().Pods(jm.namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{
+		Follow: true,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var testResult TestResult
+		if err := json.Unmarshal([]byte(line), &testResult); err == nil {
+			events <- ExecutionEvent{Type: EventTestCaseResult, TestResult: &testResult}
+			continue
+		}
+
+		events <- ExecutionEvent{Type: EventStdoutChunk, Chunk: line}
+	}
+}
@@ -0,0 +1,28 @@
+package k8s
+
+import "testing"
+
+func TestVerdictForStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		errorMessage string
+		want         string
+	}{
+		{"time limit", "time_limit", "Execution exceeded time limit", "TLE"},
+		{"memory limit", "memory_limit", "Execution exceeded memory limit", "MLE"},
+		{"output limit", "output_limit", "Execution exceeded output limit", "OLE"},
+		{"signaled runtime error", "runtime_error", "RE:signal=killed", "RE:signal=killed"},
+		{"non-signal runtime error", "runtime_error", "executor exited: exit status 1", ""},
+		{"accepted", "accepted", "", ""},
+		{"wrong answer", "wrong_answer", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerdictForStatus(tt.status, tt.errorMessage); got != tt.want {
+				t.Errorf("VerdictForStatus(%q, %q) = %q, want %q", tt.status, tt.errorMessage, got, tt.want)
+			}
+		})
+	}
+}
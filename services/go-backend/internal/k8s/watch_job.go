@@ -0,0 +1,168 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// JobEventType names a coarse lifecycle state WatchJob derives from a
+// job's pod, distinct from ExecutionEventType's finer-grained log/test
+// progress - a SubmissionRunner only needs to know which
+// models.Submission.Status to move to, not every line of output.
+type JobEventType string
+
+const (
+	JobEventPending          JobEventType = "pending"
+	JobEventRunning          JobEventType = "running"
+	JobEventSucceeded        JobEventType = "succeeded"
+	JobEventFailed           JobEventType = "failed"
+	JobEventDeadlineExceeded JobEventType = "deadline_exceeded"
+	JobEventOOMKilled        JobEventType = "oom_killed"
+	JobEventImagePullBackOff JobEventType = "image_pull_back_off"
+)
+
+// JobEvent is one lifecycle update WatchJob emits for a job's pod.
+type JobEvent struct {
+	Type JobEventType
+	// Reason carries the underlying container/pod condition reason for
+	// Failed and ImagePullBackOff events (e.g. "Error", "ErrImagePull"),
+	// for logging - callers that only care about the Submission status
+	// transition can ignore it.
+	Reason string
+}
+
+// jobEventTerminal reports whether a JobEventType ends a job's lifecycle -
+// WatchJob closes its channel after emitting one of these.
+func jobEventTerminal(t JobEventType) bool {
+	switch t {
+	case JobEventSucceeded, JobEventFailed, JobEventDeadlineExceeded, JobEventOOMKilled:
+		return true
+	default:
+		return false
+	}
+}
+
+// WatchJob watches jobName's pod and emits a JobEvent for each lifecycle
+// transition (Pending, Running, Succeeded, Failed, DeadlineExceeded,
+// OOMKilled, ImagePullBackOff), so a SubmissionRunner can keep
+// models.Submission.Status in sync without polling. The returned channel
+// is closed once a terminal event is emitted or ctx is cancelled.
+// Establishing the watch is retried with exponential backoff to survive a
+// transient apiserver hiccup rather than failing the whole submission.
+func (jm *JobManager) WatchJob(ctx context.Context, jobName string) (<-chan JobEvent, error) {
+	var watcher watch.Interface
+	err := retryWithBackoff(ctx, DefaultRetryConfig(), func() error {
+		w, err := jm.clientset.CoreV1().Pods(jm.namespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("job-name=%s", jobName),
+		})
+		if err != nil {
+			return err
+		}
+		watcher = w
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch pods for job %s: %w", jobName, err)
+	}
+
+	events := make(chan JobEvent, 8)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+
+		events <- JobEvent{Type: JobEventPending}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				if event.Type == watch.Error {
+					continue
+				}
+
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+
+				jobEvent := jm.jobEventForPod(ctx, jobName, pod)
+				if jobEvent == nil {
+					continue
+				}
+				events <- *jobEvent
+				if jobEventTerminal(jobEvent.Type) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// jobEventForPod derives a JobEvent from pod's current status, or nil if
+// nothing worth reporting has changed (e.g. still Pending with no
+// container waiting reason yet).
+func (jm *JobManager) jobEventForPod(ctx context.Context, jobName string, pod *corev1.Pod) *JobEvent {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil {
+			switch cs.State.Waiting.Reason {
+			case "ImagePullBackOff", "ErrImagePull":
+				return &JobEvent{Type: JobEventImagePullBackOff, Reason: cs.State.Waiting.Reason}
+			}
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		return &JobEvent{Type: JobEventPending}
+
+	case corev1.PodRunning:
+		return &JobEvent{Type: JobEventRunning}
+
+	case corev1.PodSucceeded:
+		return &JobEvent{Type: JobEventSucceeded}
+
+	case corev1.PodFailed:
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled" {
+				return &JobEvent{Type: JobEventOOMKilled, Reason: "OOMKilled"}
+			}
+		}
+
+		if jm.jobDeadlineExceeded(ctx, jobName) {
+			return &JobEvent{Type: JobEventDeadlineExceeded, Reason: "DeadlineExceeded"}
+		}
+
+		return &JobEvent{Type: JobEventFailed, Reason: "Failed"}
+	}
+
+	return nil
+}
+
+// jobDeadlineExceeded checks jobName's own status for the
+// ActiveDeadlineSeconds condition - that's recorded on the Job, not the
+// pod, so a failed pod alone can't distinguish "ran out of time" from any
+// other failure.
+func (jm *JobManager) jobDeadlineExceeded(ctx context.Context, jobName string) bool {
+	job, err := jm.clientset.BatchV1().Jobs(jm.namespace).Get(ctx, jobName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Reason == "DeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
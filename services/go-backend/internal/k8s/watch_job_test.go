@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJobEventForPod_WaitingImagePullBackOff(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			},
+		},
+	}
+
+	event := jm.jobEventForPod(context.Background(), "job-1", pod)
+	if event == nil || event.Type != JobEventImagePullBackOff {
+		t.Fatalf("jobEventForPod() = %+v, want JobEventImagePullBackOff", event)
+	}
+}
+
+func TestJobEventForPod_PhasePendingAndRunning(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+
+	pending := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodPending}})
+	if pending == nil || pending.Type != JobEventPending {
+		t.Fatalf("jobEventForPod(Pending) = %+v, want JobEventPending", pending)
+	}
+
+	running := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}})
+	if running == nil || running.Type != JobEventRunning {
+		t.Fatalf("jobEventForPod(Running) = %+v, want JobEventRunning", running)
+	}
+}
+
+func TestJobEventForPod_Succeeded(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+
+	event := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodSucceeded}})
+	if event == nil || event.Type != JobEventSucceeded {
+		t.Fatalf("jobEventForPod(Succeeded) = %+v, want JobEventSucceeded", event)
+	}
+}
+
+func TestJobEventForPod_FailedWithOOMKilledContainer(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}}},
+			},
+		},
+	}
+
+	event := jm.jobEventForPod(context.Background(), "job-1", pod)
+	if event == nil || event.Type != JobEventOOMKilled {
+		t.Fatalf("jobEventForPod() = %+v, want JobEventOOMKilled", event)
+	}
+}
+
+func TestJobEventForPod_FailedWithDeadlineExceededJob(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-1", Namespace: "default"},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Reason: "DeadlineExceeded"},
+			},
+		},
+	}
+	jm := &JobManager{clientset: fake.NewSimpleClientset(job), namespace: "default"}
+
+	event := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}})
+	if event == nil || event.Type != JobEventDeadlineExceeded {
+		t.Fatalf("jobEventForPod() = %+v, want JobEventDeadlineExceeded", event)
+	}
+}
+
+func TestJobEventForPod_FailedWithoutOOMOrDeadlineFallsBackToFailed(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+
+	event := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed}})
+	if event == nil || event.Type != JobEventFailed {
+		t.Fatalf("jobEventForPod() = %+v, want JobEventFailed", event)
+	}
+}
+
+func TestJobEventForPod_UnknownPhaseReturnsNil(t *testing.T) {
+	jm := &JobManager{clientset: fake.NewSimpleClientset(), namespace: "default"}
+
+	if event := jm.jobEventForPod(context.Background(), "job-1", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodUnknown}}); event != nil {
+		t.Fatalf("jobEventForPod(Unknown) = %+v, want nil", event)
+	}
+}
+
+func TestWatchJob_EmitsPendingThenTerminalAndCloses(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	jm := &JobManager{clientset: clientset, namespace: "default"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := jm.WatchJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("WatchJob() error = %v", err)
+	}
+
+	first := <-events
+	if first.Type != JobEventPending {
+		t.Fatalf("first event = %+v, want JobEventPending", first)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-1-abc123", Labels: map[string]string{"job-name": "job-1"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	if _, err := clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+
+	select {
+	case second, ok := <-events:
+		if !ok {
+			t.Fatal("events channel closed before the Succeeded event was emitted")
+		}
+		if second.Type != JobEventSucceeded {
+			t.Fatalf("second event = %+v, want JobEventSucceeded", second)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the Succeeded event")
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected events channel to be closed after a terminal event")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the events channel to close")
+	}
+}
+
+func TestWatchJob_ContextCancelClosesChannel(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	jm := &JobManager{clientset: clientset, namespace: "default"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := jm.WatchJob(ctx, "job-1")
+	if err != nil {
+		t.Fatalf("WatchJob() error = %v", err)
+	}
+
+	<-events // Pending
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected events channel to be closed after ctx is cancelled")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for the events channel to close after cancellation")
+	}
+}
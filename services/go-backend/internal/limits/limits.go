@@ -0,0 +1,76 @@
+// Package limits resolves the sandbox resource budget a submission runs
+// under - wall-clock and CPU time, memory, process count, open files,
+// stdout size and stack size - from the language being executed and,
+// when the problem defines its own, the problem's per-language time/
+// memory budget (problems.Limit). Languages whose runtime has very
+// different startup and steady-state costs (JVM warmup vs. a native Rust
+// binary) get their own defaults instead of sharing one generic budget.
+package limits
+
+import "time"
+
+// Limits bounds a single execution's resource usage. sandbox.LocalRuntime
+// enforces WallTime via its context deadline, RSSBytes and PIDs via
+// cgroups v2 (a positive CPUTime only turns on a one-core cpu.max cap; the
+// CPU time used is not capped), and OutputBytes via a capped writer.
+// OpenFiles and StackBytes are not enforced. k8s.JobManager does
+// not use Limits: its CPU/memory limits and deadline come from its
+// RuntimeRegistry instead.
+type Limits struct {
+	WallTime    time.Duration
+	CPUTime     time.Duration
+	RSSBytes    int64
+	PIDs        int64
+	OpenFiles   int64
+	OutputBytes int64
+	StackBytes  int64
+}
+
+// genericDefaults is used for any language without its own entry in
+// languageDefaults.
+var genericDefaults = Limits{
+	WallTime:    10 * time.Second,
+	CPUTime:     10 * time.Second,
+	RSSBytes:    512 << 20,
+	PIDs:        32,
+	OpenFiles:   64,
+	OutputBytes: 8 << 20,
+	StackBytes:  8 << 20,
+}
+
+// languageDefaults overrides genericDefaults for the languages scribble
+// actually supports (models.ValidLanguages), since a JVM's warmup cost or
+// a compiled binary's steady-state memory footprint differ by an order of
+// magnitude from a CPython interpreter's.
+var languageDefaults = map[string]Limits{
+	"python":     {WallTime: 8 * time.Second, CPUTime: 8 * time.Second, RSSBytes: 256 << 20, PIDs: 16, OpenFiles: 64, OutputBytes: 8 << 20, StackBytes: 8 << 20},
+	"javascript": {WallTime: 8 * time.Second, CPUTime: 8 * time.Second, RSSBytes: 256 << 20, PIDs: 16, OpenFiles: 64, OutputBytes: 8 << 20, StackBytes: 8 << 20},
+	"go":         {WallTime: 8 * time.Second, CPUTime: 8 * time.Second, RSSBytes: 384 << 20, PIDs: 32, OpenFiles: 64, OutputBytes: 8 << 20, StackBytes: 8 << 20},
+	"java":       {WallTime: 20 * time.Second, CPUTime: 15 * time.Second, RSSBytes: 768 << 20, PIDs: 64, OpenFiles: 128, OutputBytes: 8 << 20, StackBytes: 16 << 20},
+	"cpp":        {WallTime: 6 * time.Second, CPUTime: 6 * time.Second, RSSBytes: 256 << 20, PIDs: 8, OpenFiles: 32, OutputBytes: 8 << 20, StackBytes: 8 << 20},
+	"rust":       {WallTime: 6 * time.Second, CPUTime: 6 * time.Second, RSSBytes: 256 << 20, PIDs: 8, OpenFiles: 32, OutputBytes: 8 << 20, StackBytes: 8 << 20},
+}
+
+// Resolve returns language's sandbox limits, overriding them with the
+// problem's own budget (problemTimeMs/problemMemoryKB, taken straight from
+// a problems.Limit) whenever it's positive: problemTimeMs sets both
+// WallTime and CPUTime, problemMemoryKB sets RSSBytes. Every other field
+// (PIDs, OpenFiles, OutputBytes, StackBytes) always comes from the
+// language's (or, failing that, the generic) defaults, since problem.yaml
+// doesn't author those.
+func Resolve(language string, problemTimeMs, problemMemoryKB int64) Limits {
+	l, ok := languageDefaults[language]
+	if !ok {
+		l = genericDefaults
+	}
+
+	if problemTimeMs > 0 {
+		l.WallTime = time.Duration(problemTimeMs) * time.Millisecond
+		l.CPUTime = l.WallTime
+	}
+	if problemMemoryKB > 0 {
+		l.RSSBytes = problemMemoryKB * 1024
+	}
+
+	return l
+}
@@ -0,0 +1,41 @@
+package limits
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveUsesLanguageDefaults(t *testing.T) {
+	l := Resolve("java", 0, 0)
+	want := languageDefaults["java"]
+	if l != want {
+		t.Errorf("Resolve(java, 0, 0) = %+v, want %+v", l, want)
+	}
+}
+
+func TestResolveUnknownLanguageFallsBackToGeneric(t *testing.T) {
+	l := Resolve("cobol", 0, 0)
+	if l != genericDefaults {
+		t.Errorf("Resolve(cobol, 0, 0) = %+v, want genericDefaults %+v", l, genericDefaults)
+	}
+}
+
+func TestResolveProblemOverridesWallTimeAndMemory(t *testing.T) {
+	l := Resolve("python", 3000, 131072)
+
+	if l.WallTime != 3*time.Second {
+		t.Errorf("WallTime = %v, want 3s", l.WallTime)
+	}
+	if l.CPUTime != 3*time.Second {
+		t.Errorf("CPUTime = %v, want 3s", l.CPUTime)
+	}
+	if l.RSSBytes != 131072*1024 {
+		t.Errorf("RSSBytes = %d, want %d", l.RSSBytes, 131072*1024)
+	}
+
+	// Fields problem.yaml doesn't author still come from the language
+	// defaults.
+	if l.PIDs != languageDefaults["python"].PIDs {
+		t.Errorf("PIDs = %d, want language default %d", l.PIDs, languageDefaults["python"].PIDs)
+	}
+}
@@ -0,0 +1,180 @@
+// Package load implements a config-driven HTTP load testing harness: a
+// JSON file describes one or more named scenarios (method, URL template,
+// headers/body, volume, and pass/fail thresholds), and Runner executes
+// them and reports aggregated latency/error stats. A scenario runs
+// closed-loop (a worker pool pulling requests as fast as it can) or
+// open-loop (requests dispatched at a fixed rate, independent of server
+// throughput). This lets ops reuse the same scenarios against staging or
+// prod without writing new Go code for every fan-out shape.
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config is the top-level JSON load test configuration: a named list of
+// scenarios to run, plus whether they run one after another or all at once.
+type Config struct {
+	// Parallel runs all scenarios concurrently instead of one at a time.
+	Parallel  bool       `json:"parallel"`
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+// Scenario describes a single load test: what request to send, how hard
+// to send it, and what counts as passing.
+type Scenario struct {
+	Name string `json:"name"`
+
+	// Method and URL are text/template strings rendered once per request
+	// against a Vars value produced by VarSource (see template.go).
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Headers and Body are also rendered as templates, so e.g. a body can
+	// embed "{{.UserID}}" the same way the URL can.
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+
+	// VarSource supplies the per-request template variables (e.g. a CSV of
+	// user IDs, or a numeric range). Optional - a scenario with no
+	// VarSource renders its templates with no variables.
+	VarSource *VarSource `json:"var_source,omitempty"`
+
+	// Mode selects the load generation strategy. ModeClosed (the zero
+	// value) runs Concurrency workers that each pull the next request as
+	// soon as their previous one completes, so measured latency is
+	// coupled to how fast the server responds ("coordinated omission").
+	// ModeOpen instead dispatches requests at RPS independent of how long
+	// prior requests take, which is what capacity-planning numbers need.
+	Mode string `json:"mode,omitempty"`
+	// Concurrency is the number of workers issuing requests in parallel.
+	// Used by ModeClosed; ignored by ModeOpen.
+	Concurrency int `json:"concurrency"`
+	// RPS is the target request rate for ModeOpen. Required when Mode is
+	// ModeOpen; ignored otherwise.
+	RPS float64 `json:"rps,omitempty"`
+	// MaxInFlight caps the number of concurrently in-flight requests for
+	// ModeOpen, so a server that falls behind RPS can't grow goroutines
+	// without bound while the scheduler keeps dispatching. Required when
+	// Mode is ModeOpen; ignored otherwise.
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+	// Distribution selects how ModeOpen spaces successive scheduled start
+	// times: DistributionUniform (the default) uses a fixed interval of
+	// 1/RPS, DistributionPoisson draws exponentially-distributed
+	// inter-arrival times with the same mean. Ignored by ModeClosed.
+	Distribution string `json:"distribution,omitempty"`
+
+	// TotalRequests caps the scenario by request count. If zero, Duration
+	// is used instead.
+	TotalRequests int `json:"total_requests,omitempty"`
+	// Duration caps the scenario by wall-clock time. Ignored if
+	// TotalRequests is set.
+	Duration Duration `json:"duration,omitempty"`
+	// RampUp spreads worker startup evenly over this duration instead of
+	// launching all workers at once. Ignored by ModeOpen.
+	RampUp Duration `json:"ramp_up,omitempty"`
+
+	// Thresholds define what makes the scenario a pass or a fail.
+	Thresholds Thresholds `json:"thresholds,omitempty"`
+}
+
+// Mode values for Scenario.Mode. The zero value is ModeClosed, so existing
+// configs that don't set mode keep today's worker-pool behavior.
+const (
+	ModeClosed = "closed"
+	ModeOpen   = "open"
+)
+
+// Distribution values for Scenario.Distribution. The zero value is
+// DistributionUniform.
+const (
+	DistributionUniform = "uniform"
+	DistributionPoisson = "poisson"
+)
+
+// Thresholds are the pass/fail budget for a scenario's aggregated Result.
+type Thresholds struct {
+	// MaxErrorRate is the maximum acceptable fraction (0-1) of failed
+	// requests (non-2xx responses or transport errors).
+	MaxErrorRate float64 `json:"max_error_rate,omitempty"`
+	// P95LatencyMs and P99LatencyMs are maximum acceptable latencies in
+	// milliseconds. Zero means no budget is enforced for that percentile.
+	P95LatencyMs int64 `json:"p95_latency_ms,omitempty"`
+	P99LatencyMs int64 `json:"p99_latency_ms,omitempty"`
+}
+
+// Duration lets config files write durations as Go duration strings
+// (e.g. "30s") instead of raw nanosecond integers.
+type Duration time.Duration
+
+// UnmarshalJSON accepts either a Go duration string ("30s") or a
+// nanosecond integer.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return fmt.Errorf("duration must be a string or integer nanoseconds: %w", err)
+	}
+	*d = Duration(ns)
+	return nil
+}
+
+func (d Duration) value() time.Duration {
+	return time.Duration(d)
+}
+
+// LoadConfig reads and parses a Config from a JSON file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read load test config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse load test config %s: %w", path, err)
+	}
+
+	if len(cfg.Scenarios) == 0 {
+		return nil, fmt.Errorf("load test config %s defines no scenarios", path)
+	}
+	for i, s := range cfg.Scenarios {
+		if s.Name == "" {
+			return nil, fmt.Errorf("scenario %d is missing a name", i)
+		}
+		switch s.Mode {
+		case "", ModeClosed:
+			if s.Concurrency <= 0 {
+				return nil, fmt.Errorf("scenario %q: concurrency must be positive", s.Name)
+			}
+		case ModeOpen:
+			if s.RPS <= 0 {
+				return nil, fmt.Errorf("scenario %q: rps must be positive for open-loop mode", s.Name)
+			}
+			if s.MaxInFlight <= 0 {
+				return nil, fmt.Errorf("scenario %q: max_in_flight must be positive for open-loop mode", s.Name)
+			}
+			if s.Distribution != "" && s.Distribution != DistributionUniform && s.Distribution != DistributionPoisson {
+				return nil, fmt.Errorf("scenario %q: unknown distribution %q", s.Name, s.Distribution)
+			}
+		default:
+			return nil, fmt.Errorf("scenario %q: unknown mode %q", s.Name, s.Mode)
+		}
+		if s.TotalRequests <= 0 && s.Duration.value() <= 0 {
+			return nil, fmt.Errorf("scenario %q: one of total_requests or duration is required", s.Name)
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,161 @@
+package load
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram is a log-linear bucketed latency histogram in the style of
+// HdrHistogram: latency_ns is classified by its binary decade
+// (floor(log2(latency_ns))) and a linear sub-bucket within that decade,
+// giving a fixed-size bucket array regardless of how many samples are
+// recorded. Record is lock-free (plain atomic increments), so it adds no
+// contention to the request path the way a mutex-protected raw sample
+// slice does, and memory stays bounded under a long-running stress test
+// instead of growing with the request count.
+type Histogram struct {
+	counts [numBuckets]int64
+
+	count int64
+	sum   int64 // nanoseconds
+	min   int64 // nanoseconds
+	max   int64 // nanoseconds
+}
+
+const (
+	// subBuckets is how many linear sub-buckets each binary decade is
+	// split into - higher values trade bucket-array size for percentile
+	// precision.
+	subBuckets = 32
+	// maxDecades covers latencies up to 2^40ns (~18 minutes), far beyond
+	// any realistic HTTP request latency.
+	maxDecades = 40
+	numBuckets = maxDecades * subBuckets
+)
+
+// NewHistogram returns an empty Histogram ready to Record into.
+func NewHistogram() *Histogram {
+	h := &Histogram{}
+	atomic.StoreInt64(&h.min, math.MaxInt64)
+	return h
+}
+
+// Record adds one latency sample. Safe for concurrent use by many
+// goroutines with no lock.
+func (h *Histogram) Record(d time.Duration) {
+	ns := int64(d)
+	if ns < 1 {
+		ns = 1
+	}
+
+	atomic.AddInt64(&h.counts[bucketIndex(ns)], 1)
+	atomic.AddInt64(&h.sum, ns)
+	atomic.AddInt64(&h.count, 1)
+
+	for {
+		cur := atomic.LoadInt64(&h.min)
+		if ns >= cur || atomic.CompareAndSwapInt64(&h.min, cur, ns) {
+			break
+		}
+	}
+	for {
+		cur := atomic.LoadInt64(&h.max)
+		if ns <= cur || atomic.CompareAndSwapInt64(&h.max, cur, ns) {
+			break
+		}
+	}
+}
+
+// Count returns the number of samples recorded so far.
+func (h *Histogram) Count() int64 {
+	return atomic.LoadInt64(&h.count)
+}
+
+// Min returns the smallest recorded latency, or 0 if nothing was recorded.
+func (h *Histogram) Min() time.Duration {
+	v := atomic.LoadInt64(&h.min)
+	if v == math.MaxInt64 {
+		return 0
+	}
+	return time.Duration(v)
+}
+
+// Max returns the largest recorded latency.
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(atomic.LoadInt64(&h.max))
+}
+
+// Mean returns the arithmetic mean of every recorded latency.
+func (h *Histogram) Mean() time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&h.sum) / count)
+}
+
+// Percentile estimates the p-th percentile (0-100) latency by scanning
+// bucket counts in ascending latency order until the target rank is
+// reached. This is O(numBuckets), not O(samples), and the returned value
+// is the recorded sample itself when it falls in the lowest decade(s);
+// above that it's the midpoint of whichever sub-bucket contains the
+// target rank.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	total := h.Count()
+	if total == 0 {
+		return 0
+	}
+
+	target := int64(math.Ceil(p / 100 * float64(total)))
+	if target < 1 {
+		target = 1
+	}
+
+	var cumulative int64
+	for idx := 0; idx < numBuckets; idx++ {
+		cumulative += atomic.LoadInt64(&h.counts[idx])
+		if cumulative >= target {
+			return time.Duration(bucketMidpoint(idx))
+		}
+	}
+	return h.Max()
+}
+
+// bucketIndex maps a latency in nanoseconds to its bucket: the decade
+// (floor(log2(ns))) times subBuckets, plus a linear fraction within that
+// decade's range [2^decade, 2^(decade+1)).
+func bucketIndex(ns int64) int {
+	decade := bits.Len64(uint64(ns)) - 1
+	if decade < 0 {
+		decade = 0
+	}
+	if decade >= maxDecades {
+		decade = maxDecades - 1
+	}
+
+	low := int64(1) << uint(decade)
+	high := low << 1
+	frac := int((ns - low) * subBuckets / (high - low))
+	if frac >= subBuckets {
+		frac = subBuckets - 1
+	}
+
+	return decade*subBuckets + frac
+}
+
+// bucketMidpoint is the inverse of bucketIndex: the representative
+// nanosecond value for everything that falls in bucket idx.
+func bucketMidpoint(idx int) int64 {
+	decade := idx / subBuckets
+	frac := idx % subBuckets
+	low := int64(1) << uint(decade)
+	high := low << 1
+	if high-low <= subBuckets {
+		// Each value in this decade has a bucket of its own.
+		return low + int64(frac)*(high-low)/subBuckets
+	}
+	width := (high - low) / subBuckets
+	return low + int64(frac)*width + width/2
+}
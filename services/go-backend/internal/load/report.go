@@ -0,0 +1,69 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Report is the machine-readable output of a load test run: every
+// scenario's Result plus a top-level Passed that's false if any scenario
+// failed its thresholds.
+type Report struct {
+	Passed  bool     `json:"passed"`
+	Results []Result `json:"results"`
+}
+
+// NewReport builds a Report from a Run's results, setting Passed to
+// whether every scenario passed its own thresholds.
+func NewReport(results []Result) Report {
+	report := Report{Passed: true, Results: results}
+	for _, result := range results {
+		if !result.Passed {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON, suitable for CI artifact
+// upload or diffing between runs.
+func (rep Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// WriteSummary writes a human-readable summary table, one line per
+// scenario, suitable for a terminal.
+func (rep Report) WriteSummary(w io.Writer) error {
+	for _, result := range rep.Results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %s: %d requests, %.1f%% errors, %.1f req/s, p50=%dms p95=%dms p99=%dms\n",
+			status, result.ScenarioName, result.TotalRequests, result.ErrorRate*100,
+			result.RequestsPerSec, result.P50LatencyMs, result.P95LatencyMs, result.P99LatencyMs); err != nil {
+			return err
+		}
+		if result.P99SchedulingDelayMs > 0 || result.P95SchedulingDelayMs > 0 || result.P50SchedulingDelayMs > 0 {
+			if _, err := fmt.Fprintf(w, "       scheduling delay: p50=%dms p95=%dms p99=%dms\n",
+				result.P50SchedulingDelayMs, result.P95SchedulingDelayMs, result.P99SchedulingDelayMs); err != nil {
+				return err
+			}
+		}
+		for _, reason := range result.FailedReasons {
+			if _, err := fmt.Fprintf(w, "       - %s\n", reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	overall := "PASSED"
+	if !rep.Passed {
+		overall = "FAILED"
+	}
+	_, err := fmt.Fprintf(w, "\nLoad test %s\n", overall)
+	return err
+}
@@ -0,0 +1,439 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Runner executes a Config's scenarios and collects their results.
+type Runner struct {
+	client *http.Client
+}
+
+// NewRunner creates a Runner using a client with no timeout of its own -
+// each request is bounded by ctx instead, so a scenario's Duration (if
+// set) can cut requests off cleanly.
+func NewRunner() *Runner {
+	return &Runner{client: &http.Client{}}
+}
+
+// Result is the aggregated outcome of running one Scenario.
+type Result struct {
+	ScenarioName   string        `json:"scenario_name"`
+	TotalRequests  int           `json:"total_requests"`
+	SuccessCount   int           `json:"success_count"`
+	ErrorCount     int           `json:"error_count"`
+	ErrorRate      float64       `json:"error_rate"`
+	Duration       time.Duration `json:"duration_ns"`
+	RequestsPerSec float64       `json:"requests_per_sec"`
+	MinLatencyMs   int64         `json:"min_latency_ms"`
+	MaxLatencyMs   int64         `json:"max_latency_ms"`
+	AvgLatencyMs   int64         `json:"avg_latency_ms"`
+	P50LatencyMs   int64         `json:"p50_latency_ms"`
+	P95LatencyMs   int64         `json:"p95_latency_ms"`
+	P99LatencyMs   int64         `json:"p99_latency_ms"`
+
+	// P50SchedulingDelayMs, P95SchedulingDelayMs, and P99SchedulingDelayMs
+	// report how far a request's actual start time drifted from its
+	// scheduled one in a ModeOpen scenario - the coordinated-omission gap
+	// a closed-loop worker pool can't measure, because it only ever
+	// starts the next request once a worker frees up. Zero for
+	// ModeClosed scenarios.
+	P50SchedulingDelayMs int64 `json:"p50_scheduling_delay_ms,omitempty"`
+	P95SchedulingDelayMs int64 `json:"p95_scheduling_delay_ms,omitempty"`
+	P99SchedulingDelayMs int64 `json:"p99_scheduling_delay_ms,omitempty"`
+
+	// Passed reflects whether the scenario stayed within its Thresholds.
+	// Always true for a scenario with no thresholds configured.
+	Passed        bool     `json:"passed"`
+	FailedReasons []string `json:"failed_reasons,omitempty"`
+
+	// RawSamplesMs holds every recorded latency in milliseconds, in the
+	// order requests completed. Only populated when the
+	// SCRIBBLE_LOADTEST_RAW_SAMPLES env var is set, since keeping it
+	// around for every request is exactly the unbounded-memory behavior
+	// the histogram above avoids.
+	RawSamplesMs []int64 `json:"raw_samples_ms,omitempty"`
+}
+
+// recorder accumulates one scenario's outcomes without a mutex: Record is
+// a handful of atomic ops and (optionally) one mutex-guarded append for
+// raw sample mode, so it stays cheap under high concurrency and doesn't
+// itself bias the latencies being measured.
+type recorder struct {
+	histogram *Histogram
+	success   int64
+	errors    int64
+
+	// schedHistogram accumulates scheduling-delay samples for ModeOpen
+	// scenarios. Left empty (Count() == 0) for ModeClosed scenarios,
+	// which have no notion of a scheduled start time to drift from.
+	schedHistogram *Histogram
+
+	rawMu sync.Mutex
+	rawMs []int64
+	rawOn bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		histogram:      NewHistogram(),
+		schedHistogram: NewHistogram(),
+		rawOn:          os.Getenv("SCRIBBLE_LOADTEST_RAW_SAMPLES") != "",
+	}
+}
+
+func (r *recorder) record(latency time.Duration, success bool) {
+	r.histogram.Record(latency)
+	if success {
+		atomic.AddInt64(&r.success, 1)
+	} else {
+		atomic.AddInt64(&r.errors, 1)
+	}
+	if r.rawOn {
+		r.rawMu.Lock()
+		r.rawMs = append(r.rawMs, latency.Milliseconds())
+		r.rawMu.Unlock()
+	}
+}
+
+// recordSchedulingDelay records how late (or, in principle, early) a
+// request's actual start time was against its scheduled one.
+func (r *recorder) recordSchedulingDelay(delay time.Duration) {
+	if delay < 0 {
+		delay = 0
+	}
+	r.schedHistogram.Record(delay)
+}
+
+// Run executes every scenario in cfg and returns one Result per scenario,
+// in the same order as cfg.Scenarios. Scenarios run serially unless
+// cfg.Parallel is set.
+func (r *Runner) Run(ctx context.Context, cfg *Config) ([]Result, error) {
+	results := make([]Result, len(cfg.Scenarios))
+
+	if !cfg.Parallel {
+		for i, scenario := range cfg.Scenarios {
+			result, err := r.runScenario(ctx, scenario)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(cfg.Scenarios))
+	for i, scenario := range cfg.Scenarios {
+		wg.Add(1)
+		go func(i int, scenario Scenario) {
+			defer wg.Done()
+			result, err := r.runScenario(ctx, scenario)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = result
+		}(i, scenario)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// runScenario drives scenario until either TotalRequests is reached or
+// Duration elapses, using whichever worker model Mode selects.
+func (r *Runner) runScenario(ctx context.Context, scenario Scenario) (Result, error) {
+	if scenario.Mode == ModeOpen {
+		return r.runScenarioOpenLoop(ctx, scenario)
+	}
+	return r.runScenarioClosedLoop(ctx, scenario)
+}
+
+// runScenarioClosedLoop drives Concurrency workers against scenario, each
+// rendering its own template variables from a shared varFeed, until
+// either TotalRequests is reached or Duration elapses. Every worker pulls
+// its next request as soon as its previous one completes, so measured
+// latency is coupled to how fast the server responds.
+func (r *Runner) runScenarioClosedLoop(ctx context.Context, scenario Scenario) (Result, error) {
+	feed, err := newVarFeed(scenario.VarSource)
+	if err != nil {
+		return Result{}, err
+	}
+	var feedMu sync.Mutex
+
+	scenarioCtx := ctx
+	var cancel context.CancelFunc
+	if scenario.TotalRequests <= 0 && scenario.Duration.value() > 0 {
+		scenarioCtx, cancel = context.WithTimeout(ctx, scenario.Duration.value())
+		defer cancel()
+	}
+
+	rec := newRecorder()
+	var sent int
+	var sentMu sync.Mutex
+	canSend := func() bool {
+		if scenario.TotalRequests <= 0 {
+			return true
+		}
+		sentMu.Lock()
+		defer sentMu.Unlock()
+		if sent >= scenario.TotalRequests {
+			return false
+		}
+		sent++
+		return true
+	}
+
+	var workersDone sync.WaitGroup
+	rampStep := time.Duration(0)
+	if scenario.RampUp.value() > 0 && scenario.Concurrency > 0 {
+		rampStep = scenario.RampUp.value() / time.Duration(scenario.Concurrency)
+	}
+
+	start := time.Now()
+	for w := 0; w < scenario.Concurrency; w++ {
+		workersDone.Add(1)
+		delay := rampStep * time.Duration(w)
+		go func(delay time.Duration) {
+			defer workersDone.Done()
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-scenarioCtx.Done():
+					return
+				}
+			}
+			for {
+				select {
+				case <-scenarioCtx.Done():
+					return
+				default:
+				}
+				if !canSend() {
+					return
+				}
+
+				feedMu.Lock()
+				vars := feed.next()
+				feedMu.Unlock()
+
+				r.doRequest(scenarioCtx, scenario, vars, rec)
+			}
+		}(delay)
+	}
+
+	workersDone.Wait()
+	elapsed := time.Since(start)
+
+	return summarize(scenario, rec, elapsed), nil
+}
+
+// runScenarioOpenLoop schedules request start times at RPS - uniformly
+// spaced or, for DistributionPoisson, drawn from a Poisson arrival
+// process - and launches each as its own goroutine, up to MaxInFlight
+// concurrently. Unlike the closed-loop worker pool, the schedule doesn't
+// slip when the server is slow: a late request just shows up as
+// scheduling delay in the Result instead of silently throttling the
+// send rate, which is what makes this mode useful for validating
+// queueing behavior like the bounded-concurrency middleware's.
+func (r *Runner) runScenarioOpenLoop(ctx context.Context, scenario Scenario) (Result, error) {
+	feed, err := newVarFeed(scenario.VarSource)
+	if err != nil {
+		return Result{}, err
+	}
+	var feedMu sync.Mutex
+
+	scenarioCtx := ctx
+	var cancel context.CancelFunc
+	if scenario.TotalRequests <= 0 && scenario.Duration.value() > 0 {
+		scenarioCtx, cancel = context.WithTimeout(ctx, scenario.Duration.value())
+		defer cancel()
+	}
+
+	rec := newRecorder()
+	var sent int
+	var sentMu sync.Mutex
+	canSend := func() bool {
+		if scenario.TotalRequests <= 0 {
+			return true
+		}
+		sentMu.Lock()
+		defer sentMu.Unlock()
+		if sent >= scenario.TotalRequests {
+			return false
+		}
+		sent++
+		return true
+	}
+
+	limiter := rate.NewLimiter(rate.Limit(scenario.RPS), 1)
+	sem := make(chan struct{}, scenario.MaxInFlight)
+	var inFlight sync.WaitGroup
+
+	start := time.Now()
+	interval := time.Duration(float64(time.Second) / scenario.RPS)
+	scheduled := start
+
+	for canSend() {
+		if scenario.Distribution == DistributionPoisson {
+			gap := time.Duration(rand.ExpFloat64() / scenario.RPS * float64(time.Second))
+			scheduled = scheduled.Add(gap)
+			if err := sleepUntil(scenarioCtx, scheduled); err != nil {
+				break
+			}
+		} else {
+			scheduled = scheduled.Add(interval)
+			if err := limiter.Wait(scenarioCtx); err != nil {
+				break
+			}
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-scenarioCtx.Done():
+		}
+		if scenarioCtx.Err() != nil {
+			break
+		}
+
+		feedMu.Lock()
+		vars := feed.next()
+		feedMu.Unlock()
+
+		dispatchedAt := scheduled
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			defer func() { <-sem }()
+			rec.recordSchedulingDelay(time.Since(dispatchedAt))
+			r.doRequest(scenarioCtx, scenario, vars, rec)
+		}()
+	}
+
+	inFlight.Wait()
+	elapsed := time.Since(start)
+
+	return summarize(scenario, rec, elapsed), nil
+}
+
+// sleepUntil blocks until t or ctx is cancelled, whichever comes first.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) doRequest(ctx context.Context, scenario Scenario, vars map[string]string, rec *recorder) {
+	url, err := renderTemplate(scenario.Name+"-url", scenario.URL, vars)
+	if err != nil {
+		return
+	}
+	body, err := renderTemplate(scenario.Name+"-body", scenario.Body, vars)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, scenario.Method, url, bytes.NewBufferString(body))
+	if err != nil {
+		return
+	}
+	for key, value := range scenario.Headers {
+		headerValue, err := renderTemplate(scenario.Name+"-header-"+key, value, vars)
+		if err != nil {
+			return
+		}
+		req.Header.Set(key, headerValue)
+	}
+
+	reqStart := time.Now()
+	resp, err := r.client.Do(req)
+	latency := time.Since(reqStart)
+	if err != nil {
+		rec.record(latency, false)
+		return
+	}
+	resp.Body.Close()
+
+	rec.record(latency, resp.StatusCode >= 200 && resp.StatusCode < 300)
+}
+
+func summarize(scenario Scenario, rec *recorder, elapsed time.Duration) Result {
+	success := atomic.LoadInt64(&rec.success)
+	errorCount := atomic.LoadInt64(&rec.errors)
+	total := success + errorCount
+
+	result := Result{
+		ScenarioName:  scenario.Name,
+		TotalRequests: int(total),
+		SuccessCount:  int(success),
+		ErrorCount:    int(errorCount),
+		Duration:      elapsed,
+		Passed:        true,
+	}
+	if rec.rawOn {
+		result.RawSamplesMs = rec.rawMs
+	}
+	if total == 0 {
+		return result
+	}
+
+	result.ErrorRate = float64(errorCount) / float64(total)
+	if elapsed > 0 {
+		result.RequestsPerSec = float64(total) / elapsed.Seconds()
+	}
+	result.MinLatencyMs = rec.histogram.Min().Milliseconds()
+	result.MaxLatencyMs = rec.histogram.Max().Milliseconds()
+	result.AvgLatencyMs = rec.histogram.Mean().Milliseconds()
+	result.P50LatencyMs = rec.histogram.Percentile(50).Milliseconds()
+	result.P95LatencyMs = rec.histogram.Percentile(95).Milliseconds()
+	result.P99LatencyMs = rec.histogram.Percentile(99).Milliseconds()
+
+	if rec.schedHistogram.Count() > 0 {
+		result.P50SchedulingDelayMs = rec.schedHistogram.Percentile(50).Milliseconds()
+		result.P95SchedulingDelayMs = rec.schedHistogram.Percentile(95).Milliseconds()
+		result.P99SchedulingDelayMs = rec.schedHistogram.Percentile(99).Milliseconds()
+	}
+
+	checkThresholds(&result, scenario.Thresholds)
+
+	return result
+}
+
+func checkThresholds(result *Result, thresholds Thresholds) {
+	if thresholds.MaxErrorRate > 0 && result.ErrorRate > thresholds.MaxErrorRate {
+		result.Passed = false
+		result.FailedReasons = append(result.FailedReasons, "error rate exceeded threshold")
+	}
+	if thresholds.P95LatencyMs > 0 && result.P95LatencyMs > thresholds.P95LatencyMs {
+		result.Passed = false
+		result.FailedReasons = append(result.FailedReasons, "p95 latency exceeded threshold")
+	}
+	if thresholds.P99LatencyMs > 0 && result.P99LatencyMs > thresholds.P99LatencyMs {
+		result.Passed = false
+		result.FailedReasons = append(result.FailedReasons, "p99 latency exceeded threshold")
+	}
+}
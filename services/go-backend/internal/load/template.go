@@ -0,0 +1,135 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// VarSource describes where a scenario's per-request template variables
+// come from: either a CSV file (one row of named columns per request) or
+// a numeric range (a single "ID" field stepping from Start to End).
+type VarSource struct {
+	// CSVFile, if set, is read once and its rows are cycled through as
+	// requests are issued, so a 100-row CSV backing a 10,000-request
+	// scenario just repeats.
+	CSVFile string `json:"csv_file,omitempty"`
+
+	// Range, if set (and CSVFile is not), generates {{.ID}} values
+	// Start, Start+1, ..., End, wrapping back to Start past the end.
+	Range *VarRange `json:"range,omitempty"`
+}
+
+// VarRange is an inclusive integer range used to synthesize a single "ID"
+// template variable, e.g. for fanning requests out across user IDs
+// 1..10000 without needing a CSV.
+type VarRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// varFeed yields the next template variable set on every call to next(),
+// cycling once it runs out.
+type varFeed struct {
+	rows []map[string]string
+	pos  int
+}
+
+func newVarFeed(src *VarSource) (*varFeed, error) {
+	if src == nil {
+		return &varFeed{rows: []map[string]string{{}}}, nil
+	}
+
+	if src.CSVFile != "" {
+		rows, err := readCSVRows(src.CSVFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("var source CSV %s has no data rows", src.CSVFile)
+		}
+		return &varFeed{rows: rows}, nil
+	}
+
+	if src.Range != nil {
+		if src.Range.End < src.Range.Start {
+			return nil, fmt.Errorf("var source range end (%d) is before start (%d)", src.Range.End, src.Range.Start)
+		}
+		var rows []map[string]string
+		for i := src.Range.Start; i <= src.Range.End; i++ {
+			rows = append(rows, map[string]string{"ID": strconv.Itoa(i)})
+		}
+		return &varFeed{rows: rows}, nil
+	}
+
+	return nil, fmt.Errorf("var source must set either csv_file or range")
+}
+
+// next returns the next variable set, cycling back to the first row once
+// the feed is exhausted.
+func (f *varFeed) next() map[string]string {
+	row := f.rows[f.pos%len(f.rows)]
+	f.pos++
+	return row
+}
+
+func readCSVRows(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open var source CSV %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var header []string
+	var rows []map[string]string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if header == nil {
+			header = fields
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(fields) {
+				row[strings.TrimSpace(name)] = strings.TrimSpace(fields[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read var source CSV %s: %w", path, err)
+	}
+
+	return rows, nil
+}
+
+// renderTemplate renders text as a text/template against vars. Scenario
+// templates are small (a URL, a header value, a body), so compiling them
+// fresh on every call keeps the call site simple rather than threading a
+// cache through Runner.
+func renderTemplate(name, text string, vars map[string]string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
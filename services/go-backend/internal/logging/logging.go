@@ -0,0 +1,102 @@
+// Package logging provides a context-aware structured logger, replacing
+// the ad-hoc fmt.Printf/fmt.Fprintf calls scattered across the server and
+// service layers. A logger carrying request-scoped fields (trace_id,
+// submission_id, user_id, problem_id, job_name, ...) is attached to a
+// context.Context via WithFields and retrieved with FromContext, so a
+// call deep in JobManager or StreakService logs with the same fields an
+// HTTP handler attached at the top of the request without having to pass
+// them down as extra parameters.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// base is the root logger every context-less or unattached logger falls
+// back to.
+var base = zerolog.New(os.Stdout).With().Timestamp().Logger()
+
+type ctxKey struct{}
+type traceIDKey struct{}
+
+// FromContext returns the logger attached to ctx by WithFields or
+// Middleware, or the unadorned base logger if none was attached.
+func FromContext(ctx context.Context) zerolog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(zerolog.Logger); ok {
+		return logger
+	}
+	return base
+}
+
+// RequestID returns the trace ID Middleware attached to ctx, or "" if ctx
+// wasn't derived from a request Middleware handled (e.g. a background job
+// context). Unlike FromContext, which hands back a logger that already has
+// trace_id baked in as a field, this is for call sites that need the raw ID
+// itself - e.g. to record it alongside other request-scoped data.
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// WithFields returns a context carrying a logger that includes every
+// key/value in keyvals (an alternating key, value, key, value, ... list)
+// in addition to whatever fields ctx's current logger already carries.
+func WithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	logCtx := FromContext(ctx).With()
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		logCtx = logCtx.Interface(key, keyvals[i+1])
+	}
+	return context.WithValue(ctx, ctxKey{}, logCtx.Logger())
+}
+
+// WithStacktrace logs err at error level via ctx's logger, attaching the
+// caller's current stack trace, for error sites that would otherwise
+// swallow err (e.g. a deferred cleanup call whose failure isn't worth
+// failing the request over, but is worth recording).
+func WithStacktrace(ctx context.Context, err error) {
+	buf := make([]byte, 8192)
+	n := runtime.Stack(buf, false)
+	logger := FromContext(ctx)
+	logger.Error().Err(err).Str("stacktrace", string(buf[:n])).Msg("swallowed error")
+}
+
+// Middleware injects a request-scoped logger carrying a trace ID into the
+// request's context, so every log line emitted while handling a request
+// can be correlated back to it. It reuses an incoming X-Request-Id header
+// if present (e.g. set by an upstream proxy) instead of minting a new one.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader("X-Request-Id")
+		if traceID == "" {
+			traceID = newTraceID()
+		}
+		c.Writer.Header().Set("X-Request-Id", traceID)
+
+		ctx := WithFields(c.Request.Context(), "trace_id", traceID)
+		ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func newTraceID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
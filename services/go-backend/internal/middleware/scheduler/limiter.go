@@ -0,0 +1,104 @@
+// Package scheduler provides a bounded-concurrency queueing middleware for
+// Gin routes. Unlike a naive semaphore, a request's concurrency slot is
+// held for the full lifetime of c.Next() - including writing the
+// response body - so a client slowly reading a large JSON payload counts
+// against the route's concurrency budget instead of appearing to free it
+// up early.
+package scheduler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config bounds one route's admission behavior.
+type Config struct {
+	// MaxConcurrency is how many requests may run the handler at once.
+	MaxConcurrency int
+	// MaxQueueSize is how many additional requests may wait for a
+	// concurrency slot before new requests are rejected with 503.
+	MaxQueueSize int
+	// Timeout is how long a queued request waits for a concurrency slot
+	// before it's rejected with 504.
+	Timeout time.Duration
+}
+
+// Limiter enforces a Config for one named route. Construct one per route
+// (via New) and share it across requests - it is safe for concurrent use.
+type Limiter struct {
+	route string
+	cfg   Config
+
+	// slots is the concurrency semaphore: a request holds one entry for
+	// as long as it's running the handler, including writing the response.
+	slots chan struct{}
+	// waiting is the bounded queue: a request holds one entry from the
+	// moment it starts waiting for a slot until it either gets one or
+	// gives up.
+	waiting chan struct{}
+}
+
+// New creates a Limiter for route, registering its Prometheus metrics
+// under that route's label.
+func New(route string, cfg Config) *Limiter {
+	return &Limiter{
+		route:   route,
+		cfg:     cfg,
+		slots:   make(chan struct{}, cfg.MaxConcurrency),
+		waiting: make(chan struct{}, cfg.MaxQueueSize),
+	}
+}
+
+// Middleware returns a gin.HandlerFunc that admits requests according to
+// l's Config: it runs the handler immediately if a slot is free, waits in
+// the bounded queue if not, and responds 503 (queue full) or 504 (queue
+// timeout) instead of running the handler if admission fails.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		select {
+		case l.waiting <- struct{}{}:
+		default:
+			Rejections.WithLabelValues(l.route, "queue_full").Inc()
+			c.AbortWithStatusJSON(503, gin.H{"error": "server is overloaded, try again shortly"})
+			return
+		}
+		QueueDepth.WithLabelValues(l.route).Inc()
+		leaveQueue := func() {
+			<-l.waiting
+			QueueDepth.WithLabelValues(l.route).Dec()
+		}
+
+		waitStart := time.Now()
+		timer := time.NewTimer(l.cfg.Timeout)
+		defer timer.Stop()
+
+		select {
+		case l.slots <- struct{}{}:
+			leaveQueue()
+			WaitDuration.WithLabelValues(l.route).Observe(time.Since(waitStart).Seconds())
+		case <-timer.C:
+			leaveQueue()
+			Rejections.WithLabelValues(l.route, "timeout").Inc()
+			c.AbortWithStatusJSON(504, gin.H{"error": "timed out waiting for an available worker"})
+			return
+		case <-c.Request.Context().Done():
+			// Client disconnected while queued; nothing to respond with.
+			leaveQueue()
+			c.Abort()
+			return
+		}
+
+		ConcurrentRequests.WithLabelValues(l.route).Inc()
+		defer func() {
+			<-l.slots
+			ConcurrentRequests.WithLabelValues(l.route).Dec()
+		}()
+
+		// c.Next() runs the handler and, for a normal (non-streaming)
+		// response, writes the full body before returning - so the slot
+		// above is held until the body is flushed, not just until the
+		// handler function returns control.
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// ConcurrentRequests reports how many requests are currently executing
+// (holding a concurrency slot, including response writing) per route.
+var ConcurrentRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "scribble",
+	Subsystem: "http_queue",
+	Name:      "concurrent_requests",
+	Help:      "Number of requests currently holding a concurrency slot, per route.",
+}, []string{"route"})
+
+// QueueDepth reports how many requests are currently waiting for a
+// concurrency slot per route.
+var QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "scribble",
+	Subsystem: "http_queue",
+	Name:      "queue_depth",
+	Help:      "Number of requests currently waiting for a concurrency slot, per route.",
+}, []string{"route"})
+
+// WaitDuration measures how long an admitted request waited in the queue
+// before acquiring a concurrency slot.
+var WaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "scribble",
+	Subsystem: "http_queue",
+	Name:      "wait_duration_seconds",
+	Help:      "Time an admitted request spent waiting for a concurrency slot, per route.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"route"})
+
+// Rejections counts requests turned away without running the handler,
+// labeled by reason ("queue_full" or "timeout").
+var Rejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "http_queue",
+	Name:      "rejections_total",
+	Help:      "Number of requests rejected by the bounded-concurrency queue, per route and reason.",
+}, []string{"route", "reason"})
+
+func init() {
+	prometheus.MustRegister(ConcurrentRequests, QueueDepth, WaitDuration, Rejections)
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ActivityEvent is one row in the cross-subsystem activity stream: a
+// generic (event, elementType, elementID) record that lets a general audit
+// view join together changes from otherwise-unrelated subsystems (flag
+// reviews, streak updates, problem edits, ...) without each one inventing
+// its own log table.
+type ActivityEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Event       string    `gorm:"not null;index" json:"event"`
+	ElementType string    `gorm:"not null;index" json:"elementType"`
+	ElementID   uint      `gorm:"not null;index" json:"elementID"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name
+func (ActivityEvent) TableName() string {
+	return "activity_events"
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ContestScoringMode selects how ContestService.GetContestLeaderboard turns
+// a contest's submissions into a per-user score.
+type ContestScoringMode string
+
+const (
+	// ScoringICPC ranks by problems solved (descending), tie-broken by
+	// cumulative time-to-solve plus a penalty per wrong attempt before
+	// each accepted submission - the classic ICPC rules.
+	ScoringICPC ContestScoringMode = "icpc"
+	// ScoringIOI ranks by the sum of each problem's best partial score -
+	// the classic IOI rules, for contests that award partial credit
+	// instead of a binary accept/reject.
+	ScoringIOI ContestScoringMode = "ioi"
+)
+
+// Contest represents a timed competition over a fixed set of Problems.
+// Submissions made while the contest is running (see
+// SubmissionService.CreateSubmission) are stamped with its ID so
+// ContestService can score them without re-deriving the window per query.
+type Contest struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `json:"description,omitempty"`
+	StartAt     time.Time `gorm:"not null" json:"start_at"`
+	EndAt       time.Time `gorm:"not null" json:"end_at"`
+	// ScoringMode selects GetContestLeaderboard's scoring rules.
+	ScoringMode ContestScoringMode `gorm:"not null;default:'icpc'" json:"scoring_mode"`
+	// FreezeMinutes hides leaderboard movement from the last FreezeMinutes
+	// of the contest, the way a real onsite scoreboard freezes near the
+	// end - GetContestLeaderboard computes ranks as of EndAt.Add(-FreezeMinutes)
+	// once the contest is inside its freeze window.
+	FreezeMinutes int `gorm:"not null;default:0" json:"freeze_minutes"`
+
+	Problems []Problem `gorm:"many2many:contest_problems;" json:"problems,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for the Contest model
+func (Contest) TableName() string {
+	return "contests"
+}
+
+// InProgress reports whether t falls inside c's [StartAt, EndAt] window.
+func (c Contest) InProgress(t time.Time) bool {
+	return !t.Before(c.StartAt) && !t.After(c.EndAt)
+}
+
+// FreezeAt is the instant GetContestLeaderboard stops reflecting new
+// submissions once the contest enters its freeze window - EndAt itself
+// when FreezeMinutes is 0.
+func (c Contest) FreezeAt() time.Time {
+	return c.EndAt.Add(-time.Duration(c.FreezeMinutes) * time.Minute)
+}
+
+// ContestParticipant registers a user for a Contest. Registration is only
+// used to scope GetContestLeaderboard/GetUserContestHistory to people who
+// opted in - ContestService doesn't reject submissions from an
+// unregistered user.
+type ContestParticipant struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ContestID    uint      `gorm:"not null;uniqueIndex:idx_contest_user" json:"contest_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_contest_user" json:"user_id"`
+	RegisteredAt time.Time `gorm:"autoCreateTime" json:"registered_at"`
+}
+
+// TableName specifies the table name for the ContestParticipant model
+func (ContestParticipant) TableName() string {
+	return "contest_participants"
+}
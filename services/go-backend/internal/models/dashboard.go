@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// DashboardRole is a user's permission level on a Dashboard they don't own.
+type DashboardRole string
+
+const (
+	// DashboardRoleAdmin can rename, reconfigure, share, or delete a
+	// dashboard, same as its owner.
+	DashboardRoleAdmin DashboardRole = "admin"
+	// DashboardRoleViewer can only render a dashboard.
+	DashboardRoleViewer DashboardRole = "viewer"
+)
+
+// DashboardWidgetType identifies which data source a DashboardWidget pulls from.
+type DashboardWidgetType string
+
+const (
+	WidgetTypeLeaderboard    DashboardWidgetType = "leaderboard"
+	WidgetTypeStreak         DashboardWidgetType = "streak"
+	WidgetTypeStreakHistory  DashboardWidgetType = "streak_history"
+	WidgetTypeDailyChallenge DashboardWidgetType = "daily_challenge"
+)
+
+// DashboardWidget configures a single panel of a Dashboard. MetricType and
+// Scope only apply to WidgetTypeLeaderboard; the other widget types ignore
+// them.
+type DashboardWidget struct {
+	ID              uint                `gorm:"primaryKey" json:"id"`
+	DashboardID     uint                `gorm:"not null;index" json:"dashboard_id"`
+	Type            DashboardWidgetType `gorm:"not null" json:"type"`
+	MetricType      MetricType          `json:"metric_type,omitempty"`
+	Scope           LeaderboardScope    `json:"scope,omitempty"`
+	Position        int                 `gorm:"not null;default:0" json:"position"`
+	CacheTTLSeconds int                 `gorm:"not null;default:60" json:"cache_ttl_seconds"`
+}
+
+// TableName specifies the table name for the DashboardWidget model
+func (DashboardWidget) TableName() string {
+	return "dashboard_widgets"
+}
+
+// Dashboard is a user-owned, customizable collection of widgets that
+// RenderDashboard resolves into one batched response.
+type Dashboard struct {
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	OwnerID   string            `gorm:"not null;index" json:"owner_id"`
+	Name      string            `gorm:"not null" json:"name"`
+	Widgets   []DashboardWidget `gorm:"foreignKey:DashboardID" json:"widgets,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Dashboard model
+func (Dashboard) TableName() string {
+	return "dashboards"
+}
+
+// DashboardAccess grants userID a role on a dashboard it doesn't own. The
+// owner implicitly has DashboardRoleAdmin and never needs a row here.
+type DashboardAccess struct {
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	DashboardID uint          `gorm:"not null;uniqueIndex:idx_dashboard_access_user" json:"dashboard_id"`
+	UserID      string        `gorm:"not null;uniqueIndex:idx_dashboard_access_user" json:"user_id"`
+	Role        DashboardRole `gorm:"not null" json:"role"`
+}
+
+// TableName specifies the table name for the DashboardAccess model
+func (DashboardAccess) TableName() string {
+	return "dashboard_access"
+}
+
+// RenderedWidget is one widget's resolved data within a RenderedDashboard.
+// Only the field matching the widget's Type is populated; Error is set
+// instead if that widget's underlying query failed, so one broken widget
+// doesn't fail the whole render.
+type RenderedWidget struct {
+	Widget         DashboardWidget   `json:"widget"`
+	Leaderboard    *LeaderboardPage  `json:"leaderboard,omitempty"`
+	Rank           *LeaderboardEntry `json:"rank,omitempty"`
+	Streak         *UserStreak       `json:"streak,omitempty"`
+	StreakHistory  []StreakHistory   `json:"streak_history,omitempty"`
+	DailyChallenge *DailyChallenge   `json:"daily_challenge,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	CachedAt       time.Time         `json:"cached_at"`
+}
+
+// RenderedDashboard is the full batched response RenderDashboard returns.
+type RenderedDashboard struct {
+	Dashboard  Dashboard        `json:"dashboard"`
+	Widgets    []RenderedWidget `json:"widgets"`
+	RenderedAt time.Time        `json:"rendered_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// FlagReviewEvent is one immutable record of a FlaggedSubmission's status
+// changing hands, written alongside every AntiCheatService.ReviewFlag call
+// so review history survives the row itself being updated in place.
+type FlagReviewEvent struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	FlagID      uint       `gorm:"not null;index" json:"flag_id"`
+	ActorUserID uint       `gorm:"not null" json:"actor_user_id"`
+	PrevStatus  FlagStatus `gorm:"not null" json:"prev_status"`
+	NewStatus   FlagStatus `gorm:"not null" json:"new_status"`
+	Notes       string     `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (FlagReviewEvent) TableName() string {
+	return "flag_review_events"
+}
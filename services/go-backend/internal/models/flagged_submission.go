@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 )
 
@@ -8,11 +9,13 @@ import (
 type FlagReason string
 
 const (
-	FlagReasonSuspiciousTime   FlagReason = "suspicious_time"   // Execution time too fast for problem difficulty
-	FlagReasonZeroMemory       FlagReason = "zero_memory"       // 0 KB memory usage (impossible)
-	FlagReasonRateLimitAbuse   FlagReason = "rate_limit_abuse"  // Too many submissions in short period
-	FlagReasonIdenticalCode    FlagReason = "identical_code"    // Same code submitted by multiple users
-	FlagReasonPatternMatch     FlagReason = "pattern_match"     // Known cheating pattern detected
+	FlagReasonSuspiciousTime     FlagReason = "suspicious_time"     // Execution time too fast for problem difficulty
+	FlagReasonZeroMemory         FlagReason = "zero_memory"         // 0 KB memory usage (impossible)
+	FlagReasonRateLimitAbuse     FlagReason = "rate_limit_abuse"    // Too many submissions in short period
+	FlagReasonIdenticalCode      FlagReason = "identical_code"      // Same code submitted by multiple users
+	FlagReasonPatternMatch       FlagReason = "pattern_match"       // Known cheating pattern detected
+	FlagReasonCodeSimilarity     FlagReason = "code_similarity"     // Winnowing fingerprint match above the Jaccard threshold, see AntiCheatService.AnalyzeSimilarity
+	FlagReasonStatisticalAnomaly FlagReason = "statistical_anomaly" // Execution time/memory far below the problem's observed population, see StatisticalAnticheatDetector
 )
 
 // FlagStatus represents the review status of a flagged submission
@@ -34,7 +37,7 @@ type FlaggedSubmission struct {
 	Reason       FlagReason `gorm:"not null" json:"reason"`
 	Details      string     `gorm:"type:text" json:"details,omitempty"` // JSON details about the flag
 	Status       FlagStatus `gorm:"default:pending" json:"status"`
-	ReviewedBy   *uint      `json:"reviewed_by,omitempty"`   // Admin user ID who reviewed
+	ReviewedBy   *uint      `json:"reviewed_by,omitempty"` // Admin user ID who reviewed
 	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
 	ReviewNotes  *string    `gorm:"type:text" json:"review_notes,omitempty"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
@@ -45,13 +48,40 @@ func (FlaggedSubmission) TableName() string {
 	return "flagged_submissions"
 }
 
+// flagSeverities ranks each FlagReason from least (1) to most (3) severe,
+// for admin tooling like the flag stream's ?min_severity filter. Identical
+// code and known cheating patterns outrank a merely-suspicious timing, since
+// they're much less likely to be false positives.
+var flagSeverities = map[FlagReason]int{
+	FlagReasonSuspiciousTime:     1,
+	FlagReasonZeroMemory:         2,
+	FlagReasonRateLimitAbuse:     2,
+	FlagReasonIdenticalCode:      3,
+	FlagReasonPatternMatch:       3,
+	FlagReasonCodeSimilarity:     3,
+	FlagReasonStatisticalAnomaly: 2,
+}
+
+// Severity returns reason's severity rank, defaulting to 1 for any reason
+// not listed in flagSeverities.
+func (r FlagReason) Severity() int {
+	if severity, ok := flagSeverities[r]; ok {
+		return severity
+	}
+	return 1
+}
+
 // RateLimitEntry tracks submission rate for a user
 type RateLimitEntry struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	UserID      uint      `gorm:"not null;uniqueIndex" json:"user_id"`
-	Submissions int       `gorm:"default:0" json:"submissions"`   // Count in current window
-	WindowStart time.Time `gorm:"not null" json:"window_start"`   // Start of rate limit window
-	LastSubmit  time.Time `gorm:"not null" json:"last_submit"`    // Last submission time
+	Submissions int       `gorm:"default:0" json:"submissions"` // Count in current window
+	WindowStart time.Time `gorm:"not null" json:"window_start"` // Start of rate limit window
+	LastSubmit  time.Time `gorm:"not null" json:"last_submit"`  // Last submission time
+	// TAT is the theoretical arrival time GCRALimiter tracks per user -
+	// unused by SQLFixedWindowLimiter, which only reads Submissions/
+	// WindowStart above.
+	TAT time.Time `gorm:"column:tat" json:"tat"`
 }
 
 // TableName specifies the table name
@@ -59,12 +89,56 @@ func (RateLimitEntry) TableName() string {
 	return "rate_limit_entries"
 }
 
+// ProblemStats tracks the running population statistics StatisticalAnticheatDetector
+// uses to spot submissions whose execution time or memory usage is an
+// outlier relative to every other accepted submission for this problem, as
+// opposed to SuspiciousTimeThresholds' fixed per-difficulty floor below. N,
+// ExecMean/ExecM2, and MemMean/MemM2 are updated in place via Welford's
+// online algorithm on every accepted submission, so the mean and variance
+// are exact without ever storing the individual samples.
+type ProblemStats struct {
+	ProblemID uint      `gorm:"primaryKey" json:"problem_id"`
+	N         int       `gorm:"not null;default:0" json:"n"`
+	ExecMean  float64   `json:"exec_mean"`
+	ExecM2    float64   `json:"exec_m2"`
+	MemMean   float64   `json:"mem_mean"`
+	MemM2     float64   `json:"mem_m2"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ProblemStats) TableName() string {
+	return "problem_stats"
+}
+
+// Variance returns the population variance of n samples given a running m2
+// (the sum of squared differences from the mean) - 0 when there are fewer
+// than 2 samples, since variance is undefined for n < 2.
+func variance(n int, m2 float64) float64 {
+	if n < 2 {
+		return 0
+	}
+	return m2 / float64(n)
+}
+
+// ExecStdDev returns the population standard deviation of observed
+// execution times.
+func (p ProblemStats) ExecStdDev() float64 {
+	return math.Sqrt(variance(p.N, p.ExecM2))
+}
+
+// MemStdDev returns the population standard deviation of observed memory
+// usage.
+func (p ProblemStats) MemStdDev() float64 {
+	return math.Sqrt(variance(p.N, p.MemM2))
+}
+
 // SuspiciousTimeThresholds defines minimum expected execution times by difficulty
 // Submissions faster than these are flagged as suspicious
 var SuspiciousTimeThresholds = map[string]int{
-	"easy":   5,   // 5ms minimum for easy problems
-	"medium": 10,  // 10ms minimum for medium problems
-	"hard":   20,  // 20ms minimum for hard problems
+	"easy":   5,  // 5ms minimum for easy problems
+	"medium": 10, // 10ms minimum for medium problems
+	"hard":   20, // 20ms minimum for hard problems
 }
 
 // RateLimitConfig defines rate limiting parameters
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,18 @@ const (
 	MetricLowestMemoryAvg MetricType = "lowest_memory_avg"
 	MetricProblemsSolved  MetricType = "problems_solved"
 	MetricLongestStreak   MetricType = "longest_streak"
+	MetricMedianRuntime   MetricType = "median_runtime"
+	MetricP95Runtime      MetricType = "p95_runtime"
+	MetricWeightedScore   MetricType = "weighted_score"
+	// MetricFastestPercentile and MetricLowestMemoryPercentile rank users by
+	// the difficulty-weighted mean percentile of their best accepted
+	// submission to each problem they've solved, against that problem's own
+	// runtime/memory distribution - unlike MetricFastestAvg/
+	// MetricLowestMemoryAvg, this isn't biased toward whoever only attempted
+	// easy problems with small inputs, since a time is judged relative to
+	// other submissions to the same problem rather than in absolute ms.
+	MetricFastestPercentile      MetricType = "fastest_percentile"
+	MetricLowestMemoryPercentile MetricType = "lowest_memory_percentile"
 )
 
 // AllMetricTypes returns all available metric types
@@ -21,18 +35,116 @@ func AllMetricTypes() []MetricType {
 		MetricLowestMemoryAvg,
 		MetricProblemsSolved,
 		MetricLongestStreak,
+		MetricMedianRuntime,
+		MetricP95Runtime,
+		MetricWeightedScore,
+		MetricFastestPercentile,
+		MetricLowestMemoryPercentile,
 	}
 }
 
+// difficultyWeights maps a problem's difficulty to the multiplier
+// MetricWeightedScore applies to a user's fraction of accepted test case
+// weight for that problem.
+var difficultyWeights = map[string]float64{
+	"easy":   1,
+	"medium": 3,
+	"hard":   7,
+}
+
+// DifficultyWeight returns the MetricWeightedScore multiplier for
+// difficulty, defaulting to the "medium" weight for an unrecognized value.
+func DifficultyWeight(difficulty string) float64 {
+	if weight, ok := difficultyWeights[difficulty]; ok {
+		return weight
+	}
+	return difficultyWeights["medium"]
+}
+
+// LeaderboardScope narrows a leaderboard to a time window or a dimension of
+// the underlying data, instead of ranking every user globally.
+type LeaderboardScope string
+
+const (
+	// ScopeGlobal ranks all users with no time window or filter - the
+	// original, and still default, leaderboard scope.
+	ScopeGlobal LeaderboardScope = "global"
+	// ScopeWeekly ranks only submissions since the most recent Monday
+	// 00:00 UTC.
+	ScopeWeekly LeaderboardScope = "weekly"
+	// ScopeDaily ranks only submissions since midnight UTC today.
+	ScopeDaily LeaderboardScope = "daily"
+	// ScopeMonthly ranks only submissions since the first of the current
+	// month, 00:00 UTC.
+	ScopeMonthly LeaderboardScope = "monthly"
+)
+
+const (
+	languageScopePrefix   = "language:"
+	difficultyScopePrefix = "difficulty:"
+	seasonScopePrefix     = "season:"
+)
+
+// SeasonScope builds the scope that ranks users within a single Season's
+// start/end bounds, e.g. SeasonScope(3) == "season:3". Unlike
+// ScopeWeekly/ScopeDaily/ScopeMonthly, a season's window is looked up from
+// the seasons table rather than derived from the current time, so it can
+// be closed (and frozen into leaderboard_archive) independently of the
+// calendar.
+func SeasonScope(seasonID uint) LeaderboardScope {
+	return LeaderboardScope(seasonScopePrefix + strconv.FormatUint(uint64(seasonID), 10))
+}
+
+// Season returns the season ID this scope ranks within, and whether it is
+// a season-scoped leaderboard at all.
+func (s LeaderboardScope) Season() (uint, bool) {
+	raw, ok := strings.CutPrefix(string(s), seasonScopePrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// LanguageScope builds the scope that ranks users by submissions in a
+// single language, e.g. LanguageScope("python") == "language:python".
+func LanguageScope(language string) LeaderboardScope {
+	return LeaderboardScope(languageScopePrefix + language)
+}
+
+// DifficultyScope builds the scope that ranks users by submissions to
+// problems of a single difficulty, e.g. DifficultyScope("hard") ==
+// "difficulty:hard".
+func DifficultyScope(difficulty string) LeaderboardScope {
+	return LeaderboardScope(difficultyScopePrefix + difficulty)
+}
+
+// Language returns the language this scope filters to, and whether it is a
+// language-scoped leaderboard at all.
+func (s LeaderboardScope) Language() (string, bool) {
+	return strings.CutPrefix(string(s), languageScopePrefix)
+}
+
+// Difficulty returns the difficulty this scope filters to, and whether it
+// is a difficulty-scoped leaderboard at all.
+func (s LeaderboardScope) Difficulty() (string, bool) {
+	return strings.CutPrefix(string(s), difficultyScopePrefix)
+}
+
 // LeaderboardEntry represents a cached leaderboard entry
 // Maps to leaderboard_cache table in schema.sql
 type LeaderboardEntry struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	UserID      uint       `gorm:"not null;uniqueIndex:idx_user_metric" json:"user_id"`
-	MetricType  MetricType `gorm:"not null;uniqueIndex:idx_user_metric" json:"metric_type"`
-	MetricValue float64    `gorm:"type:decimal(12,2);not null" json:"metric_value"`
-	Rank        int        `gorm:"not null" json:"rank"`
-	ComputedAt  time.Time  `gorm:"autoCreateTime" json:"computed_at"`
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	UserID      uint             `gorm:"not null;uniqueIndex:idx_user_metric_scope" json:"user_id"`
+	MetricType  MetricType       `gorm:"not null;uniqueIndex:idx_user_metric_scope" json:"metric_type"`
+	Scope       LeaderboardScope `gorm:"not null;default:'global';uniqueIndex:idx_user_metric_scope" json:"scope"`
+	ScopePeriod *time.Time       `json:"scope_period,omitempty"`
+	MetricValue float64          `gorm:"type:decimal(12,2);not null" json:"metric_value"`
+	Rank        int              `gorm:"not null" json:"rank"`
+	ComputedAt  time.Time        `gorm:"autoCreateTime" json:"computed_at"`
 }
 
 // TableName specifies the table name for LeaderboardEntry
@@ -49,15 +161,17 @@ type LeaderboardWithUser struct {
 
 // ComputeResult contains statistics about a leaderboard computation
 type ComputeResult struct {
-	MetricType     MetricType `json:"metric_type"`
-	EntriesUpdated int        `json:"entries_updated"`
-	ComputedAt     time.Time  `json:"computed_at"`
+	MetricType     MetricType       `json:"metric_type"`
+	Scope          LeaderboardScope `json:"scope"`
+	EntriesUpdated int              `json:"entries_updated"`
+	ComputedAt     time.Time        `json:"computed_at"`
 }
 
 // LeaderboardPage represents a paginated leaderboard response
 type LeaderboardPage struct {
 	Entries    []LeaderboardWithUser `json:"entries"`
 	MetricType MetricType            `json:"metric_type"`
+	Scope      LeaderboardScope      `json:"scope"`
 	Page       int                   `json:"page"`
 	PageSize   int                   `json:"page_size"`
 	TotalPages int                   `json:"total_pages"`
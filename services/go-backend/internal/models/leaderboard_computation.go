@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// LeaderboardComputation records one run of LeaderboardService's scheduled
+// recomputation for a single metric type, for observability into a
+// subsystem that otherwise runs unattended on a cron schedule.
+type LeaderboardComputation struct {
+	ID             uint             `gorm:"primaryKey" json:"id"`
+	MetricType     MetricType       `gorm:"not null;index" json:"metric_type"`
+	Scope          LeaderboardScope `gorm:"not null;default:'global';index" json:"scope"`
+	StartedAt      time.Time        `gorm:"not null" json:"started_at"`
+	DurationMs     int64            `json:"duration_ms"`
+	EntriesUpdated int              `json:"entries_updated"`
+	Error          string           `gorm:"type:text" json:"error,omitempty"`
+}
+
+// TableName specifies the table name
+func (LeaderboardComputation) TableName() string {
+	return "leaderboard_computations"
+}
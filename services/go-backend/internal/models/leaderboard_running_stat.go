@@ -0,0 +1,18 @@
+package models
+
+// LeaderboardRunningStat is a per-user running sum/count for an
+// average-based leaderboard metric (MetricFastestAvg,
+// MetricLowestMemoryAvg), letting LeaderboardService fold in a single new
+// submission without re-scanning every submission for that user.
+type LeaderboardRunningStat struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_user_stat_metric" json:"user_id"`
+	MetricType MetricType `gorm:"not null;uniqueIndex:idx_user_stat_metric" json:"metric_type"`
+	Sum        float64    `gorm:"not null;default:0" json:"sum"`
+	Count      int64      `gorm:"not null;default:0" json:"count"`
+}
+
+// TableName specifies the table name
+func (LeaderboardRunningStat) TableName() string {
+	return "leaderboard_running_stats"
+}
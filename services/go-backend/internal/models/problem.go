@@ -18,8 +18,18 @@ type Problem struct {
 	Hints       []string       `gorm:"type:text[]" json:"hints,omitempty"`
 	Category    string         `json:"category,omitempty"`
 	Tags        []string       `gorm:"type:text[]" json:"tags,omitempty"`
-	CreatedAt   time.Time      `json:"created_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Judge, FloatTolerance and SpecialJudgeBinary mirror
+	// problems.ProblemDef's judge config; see that package for what each
+	// judge type does. LanguageLimits maps a language to its time_ms/
+	// memory_kb budget for this problem (problems.Limit, JSON-encoded).
+	Judge              string      `gorm:"not null;default:exact" json:"judge"`
+	FloatTolerance     float64     `json:"float_tolerance,omitempty"`
+	SpecialJudgeBinary string      `json:"special_judge_binary,omitempty"`
+	LanguageLimits     interface{} `gorm:"type:jsonb;not null" json:"language_limits"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName specifies the table name for the Problem model
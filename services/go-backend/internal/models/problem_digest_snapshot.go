@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ProblemDigestSnapshotRow is a serialized t-digest snapshot for one
+// (problem, language, metric), persisted periodically and on shutdown so a
+// restart can warm straight from it instead of replaying every accepted
+// submission.
+type ProblemDigestSnapshotRow struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProblemID string    `gorm:"not null;uniqueIndex:idx_problem_digest" json:"problem_id"`
+	Language  string    `gorm:"not null;uniqueIndex:idx_problem_digest" json:"language"`
+	Metric    string    `gorm:"not null;uniqueIndex:idx_problem_digest" json:"metric"`
+	Snapshot  []byte    `gorm:"type:bytea;not null" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ProblemDigestSnapshotRow model
+func (ProblemDigestSnapshotRow) TableName() string {
+	return "problem_digest_snapshots"
+}
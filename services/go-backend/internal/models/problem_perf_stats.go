@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ProblemPerfStats is a persisted t-digest summary of one (problem,
+// language, metric)'s accepted-submission distribution, kept up to date by
+// LeaderboardService.RecomputeProblemStats so MetricFastestPercentile/
+// MetricLowestMemoryPercentile can look up a submission's percentile in
+// O(log k) instead of rescanning every accepted submission to the problem.
+type ProblemPerfStats struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProblemID uint      `gorm:"not null;uniqueIndex:idx_problem_perf_stats" json:"problem_id"`
+	Language  string    `gorm:"not null;uniqueIndex:idx_problem_perf_stats" json:"language"`
+	Metric    string    `gorm:"not null;uniqueIndex:idx_problem_perf_stats" json:"metric"`
+	Count     int64     `gorm:"not null" json:"count"`
+	Digest    []byte    `gorm:"type:bytea;not null" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ProblemPerfStats model
+func (ProblemPerfStats) TableName() string {
+	return "problem_perf_stats"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ProblemSubmissionStatsRow is one flushed window of per-problem submission
+// counts, written by internal/counters.Counters.Tick roughly every 15
+// minutes so GetProblemSubmissionStats can read recent history instead of
+// scanning the submissions table on every call.
+type ProblemSubmissionStatsRow struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ProblemID   uint      `gorm:"not null;index" json:"problem_id"`
+	WindowStart time.Time `gorm:"not null;index" json:"window_start"`
+	Submissions int64     `json:"submissions"`
+	Accepted    int64     `json:"accepted"`
+	SumTime     int64     `json:"sum_time"`
+	SumMem      int64     `json:"sum_mem"`
+}
+
+// TableName specifies the table name for the ProblemSubmissionStatsRow model
+func (ProblemSubmissionStatsRow) TableName() string {
+	return "problem_submission_stats"
+}
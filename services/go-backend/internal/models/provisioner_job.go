@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Provisioner job statuses
+const (
+	ProvisionerJobQueued    = "queued"
+	ProvisionerJobRunning   = "running"
+	ProvisionerJobCompleted = "completed"
+	ProvisionerJobFailed    = "failed"
+)
+
+// ProvisionerJob is a durable record of one execution request, picked up by
+// an internal/acquirer worker instead of being run synchronously in the API
+// process. Persisting it means a submission survives an API pod restart,
+// and lets executor workers scale independently of the API.
+type ProvisionerJob struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	SubmissionID string     `gorm:"not null;uniqueIndex" json:"submission_id"`
+	Tags         string     `gorm:"not null;default:''" json:"tags"`
+	Payload      []byte     `gorm:"type:bytea;not null" json:"-"`
+	Status       string     `gorm:"not null;default:'queued';index" json:"status"`
+	WorkerID     string     `json:"worker_id,omitempty"`
+	LockedAt     *time.Time `json:"locked_at,omitempty"`
+	HeartbeatAt  *time.Time `json:"heartbeat_at,omitempty"`
+	Result       []byte     `gorm:"type:bytea" json:"-"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the ProvisionerJob model
+func (ProvisionerJob) TableName() string {
+	return "provisioner_jobs"
+}
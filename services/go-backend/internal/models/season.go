@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SeasonStatus is whether a Season is still accepting activity into its
+// SeasonScope leaderboard or has been closed and frozen into
+// leaderboard_archive.
+type SeasonStatus string
+
+const (
+	SeasonOpen   SeasonStatus = "open"
+	SeasonClosed SeasonStatus = "closed"
+)
+
+// Season bounds a SeasonScope leaderboard window. Opening one starts a new
+// `season:<id>` scope at StartsAt; closing it sets EndsAt, stops the
+// window from growing, and triggers LeaderboardService.CloseSeason to
+// snapshot its final rankings into leaderboard_archive.
+type Season struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	Name      string       `gorm:"not null" json:"name"`
+	Status    SeasonStatus `gorm:"not null;default:'open'" json:"status"`
+	StartsAt  time.Time    `gorm:"not null" json:"starts_at"`
+	EndsAt    *time.Time   `json:"ends_at,omitempty"`
+	CreatedAt time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for Season
+func (Season) TableName() string {
+	return "seasons"
+}
+
+// LeaderboardArchiveEntry is an immutable snapshot of one user's final
+// rank for one metric in a closed season, written once by
+// LeaderboardService.CloseSeason.
+type LeaderboardArchiveEntry struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	SeasonID    uint       `gorm:"not null;uniqueIndex:idx_archive_season_user_metric" json:"season_id"`
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_archive_season_user_metric" json:"user_id"`
+	MetricType  MetricType `gorm:"not null;uniqueIndex:idx_archive_season_user_metric" json:"metric_type"`
+	MetricValue float64    `gorm:"type:decimal(12,2);not null" json:"metric_value"`
+	Rank        int        `gorm:"not null" json:"rank"`
+	ArchivedAt  time.Time  `gorm:"autoCreateTime" json:"archived_at"`
+}
+
+// TableName specifies the table name for LeaderboardArchiveEntry
+func (LeaderboardArchiveEntry) TableName() string {
+	return "leaderboard_archive"
+}
@@ -8,6 +8,7 @@ import (
 
 // Submission status constants
 const (
+	StatusQueued           = "queued"
 	StatusPending          = "pending"
 	StatusRunning          = "running"
 	StatusAccepted         = "accepted"
@@ -18,18 +19,44 @@ const (
 	StatusCompilationError = "compilation_error"
 )
 
+// ManagedByExecutor is the reserved Submission.ManagedBy value (and its
+// zero-value default) meaning scribble's own in-process JobManager creates
+// and polls the execution Job, same as before ManagedBy existed. Any other
+// value names an external controller - e.g. "kueue.x-k8s.io/multikueue" -
+// that owns admission and dispatch instead, following the pattern of the
+// Kubernetes training-operator's managedBy field.
+const ManagedByExecutor = "scribble.io/executor"
+
 // Submission represents a user's code submission
 type Submission struct {
-	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID    string         `json:"user_id" gorm:"not null;index"`
-	ProblemID string         `json:"problem_id" gorm:"not null;index"`
-	Language  string         `json:"language" gorm:"not null"`
-	Code      string         `json:"code" gorm:"type:text;not null"`
-	Status    string         `json:"status" gorm:"not null;default:'pending'"`
+	ID        string `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string `json:"user_id" gorm:"not null;index"`
+	ProblemID string `json:"problem_id" gorm:"not null;index"`
+	Language  string `json:"language" gorm:"not null"`
+	Code      string `json:"code" gorm:"type:text;not null"`
+	Status    string `json:"status" gorm:"not null;default:'pending'"`
+
+	// ManagedBy says which controller runs this submission's execution Job:
+	// ManagedByExecutor (the default) for scribble's own JobManager, or an
+	// external controller ID (e.g. "kueue.x-k8s.io/multikueue") that
+	// JobManager creates a suspended Job for and otherwise stays out of.
+	// Immutable after first write - see SubmissionService.SetManagedBy.
+	ManagedBy string `json:"managed_by" gorm:"not null;default:'scribble.io/executor'"`
+
+	// BatchID groups submissions created together by
+	// BatchSubmissionService.CreateBatch. Nil for a submission created
+	// outside a batch.
+	BatchID *string `json:"batch_id,omitempty" gorm:"index"`
+
+	// ContestID is Contest.ID, stringified the same way ProblemID
+	// references models.Problem.ID, for a submission made while that
+	// contest was running (see Contest.InProgress). Nil for a submission
+	// made outside any contest.
+	ContestID *string `json:"contest_id,omitempty" gorm:"index"`
 
 	// Time breakdown fields
-	CompilationTimeMs   int `json:"compilation_time_ms" gorm:"default:0"`
-	ExecutionTimeMs     int `json:"execution_time_ms" gorm:"default:0"`      // Average per test
+	CompilationTimeMs    int `json:"compilation_time_ms" gorm:"default:0"`
+	ExecutionTimeMs      int `json:"execution_time_ms" gorm:"default:0"`       // Average per test
 	TotalExecutionTimeMs int `json:"total_execution_time_ms" gorm:"default:0"` // Sum of all tests
 
 	// Memory usage
@@ -43,6 +70,12 @@ type Submission struct {
 	ErrorMessage string `json:"error_message,omitempty" gorm:"type:text"`
 	ErrorType    string `json:"error_type,omitempty"`
 
+	// Attempts is how many times JudgeQueue has dispatched this submission
+	// to the executor, incremented before each try. A submission that
+	// exhausts JudgeQueueConfig.MaxAttempts is parked in
+	// submission_dead_letters instead of retried further.
+	Attempts int `json:"attempts" gorm:"not null;default:0"`
+
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -85,6 +118,27 @@ type SubmissionWithDetails struct {
 	TestResults []TestResult `json:"test_results,omitempty" gorm:"foreignKey:SubmissionID"`
 }
 
+// PercentileMetrics reports how a submission's runtime and memory usage
+// compare to other accepted submissions for the same problem and language.
+// ExecutionTime*/Memory* fields are nil when the distribution's sample size
+// is below services.MinDistributionSample - a percentile computed from a
+// handful of submissions isn't meaningful.
+type PercentileMetrics struct {
+	SubmissionID string `json:"submission_id"`
+	ProblemID    string `json:"problem_id"`
+	Language     string `json:"language"`
+
+	SampleSize int `json:"sample_size"`
+
+	ExecutionTimePercentile *float64 `json:"execution_time_percentile,omitempty"`
+	ExecutionTimeRank       *int     `json:"execution_time_rank,omitempty"`
+	ExecutionTimeMessage    string   `json:"execution_time_message,omitempty"`
+
+	MemoryPercentile *float64 `json:"memory_percentile,omitempty"`
+	MemoryRank       *int     `json:"memory_rank,omitempty"`
+	MemoryMessage    string   `json:"memory_message,omitempty"`
+}
+
 // PassRate calculates the percentage of tests passed
 func (s *Submission) PassRate() float64 {
 	if s.TestsTotal == 0 {
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SubmissionDeadLetter records a submission whose execution JudgeQueue gave
+// up retrying - the executor returned an error on every attempt up to
+// JudgeQueueConfig.MaxAttempts. The original dispatch payload is kept
+// alongside the last error so an admin can inspect why it failed and, once
+// the underlying cause (a flaky node, an executor outage) has cleared,
+// retry it via JudgeQueue.ReplayDeadLetter.
+type SubmissionDeadLetter struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SubmissionID string    `gorm:"not null;index;type:uuid" json:"submission_id"`
+	Payload      string    `gorm:"type:text;not null" json:"payload"`
+	LastError    string    `gorm:"type:text;not null" json:"last_error"`
+	Attempts     int       `gorm:"not null" json:"attempts"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the SubmissionDeadLetter model
+func (SubmissionDeadLetter) TableName() string {
+	return "submission_dead_letters"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// SubmissionFingerprint is one Winnowing fingerprint extracted from a
+// submission's source code, used by SimilarityService to find near-duplicate
+// submissions to the same problem from different users.
+type SubmissionFingerprint struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ProblemID   uint   `gorm:"not null;index:idx_problem_fingerprint" json:"problem_id"`
+	Fingerprint uint64 `gorm:"not null;index:idx_problem_fingerprint" json:"fingerprint"`
+	// Position is this fingerprint's index into its source's k-gram hash
+	// stream (see code_similarity.go's codeFingerprint), kept around so two
+	// submissions' shared fingerprints can be clustered back into matched
+	// source regions instead of just counted.
+	Position     int       `gorm:"not null;default:0" json:"position"`
+	SubmissionID uint      `gorm:"not null;index" json:"submission_id"`
+	UserID       uint      `gorm:"not null" json:"user_id"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the SubmissionFingerprint model
+func (SubmissionFingerprint) TableName() string {
+	return "submission_fingerprints"
+}
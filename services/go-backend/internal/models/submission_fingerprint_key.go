@@ -0,0 +1,21 @@
+package models
+
+// SubmissionFingerprintKey assigns a stable uint surrogate to a submission's
+// real UUID primary key, so it can be stored in
+// SubmissionFingerprint.SubmissionID - a uint column that predates any
+// submission in this codebase having a UUID id (see code_similarity.go's own
+// submissionID uint parameters, which were never backed by a real
+// Submission row to begin with). PlagiarismService is the only caller that
+// fingerprints real Submission rows, and resolves this mapping via
+// PlagiarismService.submissionFingerprintKey, creating it on first use. The
+// uniqueIndex on SubmissionID is what makes the mapping collision-free,
+// unlike hashing the UUID down to a fixed-width int.
+type SubmissionFingerprintKey struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	SubmissionID string `gorm:"not null;uniqueIndex;type:uuid" json:"submission_id"`
+}
+
+// TableName specifies the table name for the SubmissionFingerprintKey model
+func (SubmissionFingerprintKey) TableName() string {
+	return "submission_fingerprint_keys"
+}
@@ -46,3 +46,30 @@ func (m *UserAggregateMetrics) ToSummary() UserMetricsSummary {
 		CurrentStreak:  m.CurrentStreak,
 	}
 }
+
+// UserMetrics is the materialized row MetricsService.RefreshUserMetrics
+// writes per user, computed from the submissions table rather than read
+// live from a database view. LeaderboardService's problems_solved and
+// longest_streak metric types read from this table instead of
+// re-aggregating raw submissions on every compute.
+type UserMetrics struct {
+	UserID           uint       `gorm:"primaryKey" json:"user_id"`
+	ProblemsSolved   int        `gorm:"not null;default:0" json:"problems_solved"`
+	AcceptanceRate   float64    `gorm:"not null;default:0" json:"acceptance_rate"`
+	CurrentStreak    int        `gorm:"not null;default:0" json:"current_streak"`
+	LongestStreak    int        `gorm:"not null;default:0" json:"longest_streak"`
+	LastSolvedDate   *time.Time `gorm:"type:date" json:"last_solved_date,omitempty"`
+	FavoriteLanguage *string    `json:"favorite_language,omitempty"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (UserMetrics) TableName() string {
+	return "user_metrics"
+}
+
+// MetricsRefreshResult summarizes a MetricsService refresh call.
+type MetricsRefreshResult struct {
+	UsersUpdated int       `json:"users_updated"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
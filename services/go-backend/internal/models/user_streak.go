@@ -8,15 +8,24 @@ import (
 
 // UserStreak tracks a user's daily challenge streak
 type UserStreak struct {
-	ID              uint           `json:"id" gorm:"primaryKey"`
-	UserID          string         `json:"user_id" gorm:"uniqueIndex;not null"`
-	CurrentStreak   int            `json:"current_streak" gorm:"not null;default:0"`
-	LongestStreak   int            `json:"longest_streak" gorm:"not null;default:0"`
-	LastSolvedDate  *time.Time     `json:"last_solved_date" gorm:"type:date"`
-	TotalDaysSolved int            `json:"total_days_solved" gorm:"not null;default:0"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          string     `json:"user_id" gorm:"uniqueIndex;not null"`
+	CurrentStreak   int        `json:"current_streak" gorm:"not null;default:0"`
+	LongestStreak   int        `json:"longest_streak" gorm:"not null;default:0"`
+	LastSolvedDate  *time.Time `json:"last_solved_date" gorm:"type:date"`
+	TotalDaysSolved int        `json:"total_days_solved" gorm:"not null;default:0"`
+	// StreakFreezes is how many missed-day passes the user has banked, via
+	// StreakService.GrantFreeze or automatic accrual in UpdateStreak. A
+	// freeze is spent by ConsumeFreeze to cover exactly one missed day
+	// instead of resetting CurrentStreak.
+	StreakFreezes int `json:"streak_freezes" gorm:"not null;default:0"`
+	// DayCutoffHour is the local hour (0-23) at which the user's streak day
+	// rolls over, e.g. 4 for a night owl who doesn't want a 2am solve to
+	// start a new streak day. Defaults to 0 (midnight, i.e. no shift).
+	DayCutoffHour int            `json:"day_cutoff_hour" gorm:"not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // TableName specifies the table name for the UserStreak model
@@ -39,3 +48,22 @@ type StreakHistory struct {
 func (StreakHistory) TableName() string {
 	return "streak_history"
 }
+
+// SolveEvent records a single daily-challenge solve, one row per user per
+// streak day. Unlike StreakHistory (which stores the streak-day *number* at
+// the time of solving), SolveEvent stores the actual calendar day
+// (StreakDay, already adjusted for the user's DayCutoffHour) so it can be
+// walked date-by-date to build activity calendars and streak segments.
+type SolveEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"not null;index"`
+	ProblemID uint      `json:"problem_id" gorm:"not null"`
+	SolvedAt  time.Time `json:"solved_at" gorm:"not null"`
+	StreakDay time.Time `json:"streak_day" gorm:"type:date;not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the SolveEvent model
+func (SolveEvent) TableName() string {
+	return "solve_events"
+}
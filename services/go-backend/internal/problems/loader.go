@@ -0,0 +1,157 @@
+package problems
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// problemFile is the filename Loader expects at the root of each
+// problem's directory.
+const problemFile = "problem.yaml"
+
+// testsDir is the subdirectory Loader reads input/expected pairs from,
+// named caseNNN.in / caseNNN.out (sorted lexically), directly under
+// tests/ for ordinary cases and under tests/samples/ for the cases marked
+// TestCaseDef.IsSample.
+const testsDir = "tests"
+
+// Loader reads a directory of problem subdirectories (each holding a
+// problem.yaml and a tests/ subdirectory) into validated ProblemDefs.
+type Loader struct {
+	dir string
+}
+
+// NewLoader creates a Loader reading problem subdirectories of dir.
+func NewLoader(dir string) *Loader {
+	return &Loader{dir: dir}
+}
+
+// Load reads every problem subdirectory of l.dir, parsing and validating
+// each one. It returns every successfully parsed ProblemDef alongside an
+// aggregated error describing every subdirectory that failed (nil if
+// none did) - a single bad problem.yaml doesn't stop the rest of the set
+// from loading.
+func (l *Loader) Load() ([]*ProblemDef, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read problems directory %s: %w", l.dir, err)
+	}
+
+	var defs []*ProblemDef
+	var loadErrs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(l.dir, entry.Name())
+		def, err := loadOne(dir)
+		if err != nil {
+			loadErrs = append(loadErrs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		defs = append(defs, def)
+	}
+
+	if len(loadErrs) > 0 {
+		return defs, fmt.Errorf("failed to load %d problem(s): %w", len(loadErrs), joinErrors(loadErrs))
+	}
+	return defs, nil
+}
+
+// loadOne parses and validates the single problem rooted at dir.
+func loadOne(dir string) (*ProblemDef, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, problemFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", problemFile, err)
+	}
+
+	var def ProblemDef
+	if err := yaml.Unmarshal(raw, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", problemFile, err)
+	}
+
+	testCases, err := loadTestCases(filepath.Join(dir, testsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", testsDir, err)
+	}
+	def.TestCases = testCases
+
+	if errs := def.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid problem definition: %w", joinErrors(errs))
+	}
+
+	return &def, nil
+}
+
+// loadTestCases reads dir's caseNNN.in/caseNNN.out pairs (sorted by
+// filename so case order is stable) plus any under a samples/
+// subdirectory, which are marked IsSample.
+func loadTestCases(dir string) ([]TestCaseDef, error) {
+	var cases []TestCaseDef
+
+	plain, err := readCasePairs(dir, false)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	cases = append(cases, plain...)
+
+	samples, err := readCasePairs(filepath.Join(dir, "samples"), true)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	cases = append(cases, samples...)
+
+	return cases, nil
+}
+
+// readCasePairs reads dir's *.in/*.out pairs, in lexical filename order.
+func readCasePairs(dir string, isSample bool) ([]TestCaseDef, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".in" {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".in"))
+		}
+	}
+	sort.Strings(names)
+
+	var cases []TestCaseDef
+	for _, name := range names {
+		input, err := os.ReadFile(filepath.Join(dir, name+".in"))
+		if err != nil {
+			return nil, fmt.Errorf("%s.in: %w", name, err)
+		}
+		expected, err := os.ReadFile(filepath.Join(dir, name+".out"))
+		if err != nil {
+			return nil, fmt.Errorf("%s.out: %w", name, err)
+		}
+		cases = append(cases, TestCaseDef{
+			Input:          string(input),
+			ExpectedOutput: string(expected),
+			IsSample:       isSample,
+		})
+	}
+	return cases, nil
+}
+
+// joinErrors folds errs into a single error whose message lists each of
+// them, since fmt.Errorf's %w only wraps one error at a time.
+func joinErrors(errs []error) error {
+	msg := ""
+	for i, err := range errs {
+		if i > 0 {
+			msg += "; "
+		}
+		msg += err.Error()
+	}
+	return fmt.Errorf("%s", msg)
+}
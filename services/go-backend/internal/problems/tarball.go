@@ -0,0 +1,82 @@
+package problems
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadTarball extracts a gzipped tar of the same layout Loader reads from
+// disk (one directory per problem, each with a problem.yaml and tests/
+// subdirectory) into a temporary directory and loads it, for the
+// /internal/problems/import endpoint. The temporary directory is removed
+// before LoadTarball returns, win or lose.
+func LoadTarball(r io.Reader) ([]*ProblemDef, error) {
+	dir, err := os.MkdirTemp("", "scribble-problems-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractTarball(r, dir); err != nil {
+		return nil, fmt.Errorf("failed to extract tarball: %w", err)
+	}
+
+	return NewLoader(dir).Load()
+}
+
+// extractTarball writes r's gzipped tar contents under dir, refusing any
+// entry whose path would escape dir (a zip-slip guard) since dir's
+// contents are about to be read back as trusted problem definitions.
+func extractTarball(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("not a gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, header.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q escapes the extraction directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeFile copies src into a newly created file at path.
+func writeFile(path string, src io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, src)
+	return err
+}
@@ -0,0 +1,136 @@
+// Package problems reads author-facing problem definitions - one
+// directory per problem, a problem.yaml plus a tests/ subdirectory of
+// input/expected pairs - and turns them into validated, JSON-serializable
+// ProblemDef values. YAML is the format authors write; JSON (via each
+// type's json tags) is the canonical form the rest of the backend,
+// Loader.Load's callers, and the /internal/problems/import endpoint all
+// consume.
+package problems
+
+import "fmt"
+
+// JudgeType names how a submission's output is compared against a test
+// case's expected output.
+type JudgeType string
+
+const (
+	// JudgeExact requires a byte-for-byte match.
+	JudgeExact JudgeType = "exact"
+	// JudgeWhitespaceInsensitive ignores leading/trailing whitespace and
+	// collapses runs of internal whitespace before comparing.
+	JudgeWhitespaceInsensitive JudgeType = "whitespace_insensitive"
+	// JudgeFloatTolerance parses both sides as whitespace-separated
+	// floats and requires each pair to be within ProblemDef.FloatTolerance.
+	JudgeFloatTolerance JudgeType = "float_tolerance"
+	// JudgeSpecialJudge runs ProblemDef.SpecialJudgeBinary with the
+	// input, expected output, and actual output as arguments, and trusts
+	// its exit code (0 = accepted).
+	JudgeSpecialJudge JudgeType = "special_judge"
+)
+
+// validJudgeTypes is JudgeType's enum, used by Validate.
+var validJudgeTypes = map[JudgeType]bool{
+	JudgeExact:                 true,
+	JudgeWhitespaceInsensitive: true,
+	JudgeFloatTolerance:        true,
+	JudgeSpecialJudge:          true,
+}
+
+// validDifficulties is Difficulty's enum, mirroring models.Problem's
+// 'easy'/'medium'/'hard' convention.
+var validDifficulties = map[string]bool{
+	"easy":   true,
+	"medium": true,
+	"hard":   true,
+}
+
+// Limit bounds one language's resource budget for a problem.
+type Limit struct {
+	TimeMs   int64 `yaml:"time_ms" json:"time_ms"`
+	MemoryKb int64 `yaml:"memory_kb" json:"memory_kb"`
+}
+
+// ProblemDef is the parsed, validated form of one problem.yaml.
+type ProblemDef struct {
+	Slug        string  `yaml:"slug" json:"slug"`
+	Title       string  `yaml:"title" json:"title"`
+	Difficulty  string  `yaml:"difficulty" json:"difficulty"`
+	Description string  `yaml:"description" json:"description"`
+	Constraints string  `yaml:"constraints" json:"constraints,omitempty"`
+	Category    string   `yaml:"category" json:"category,omitempty"`
+	Tags        []string `yaml:"tags" json:"tags,omitempty"`
+	Hints       []string `yaml:"hints" json:"hints,omitempty"`
+
+	// Limits maps a supported language (e.g. "python", "cpp") to its
+	// time/memory budget for this problem. A language missing here isn't
+	// offered for this problem.
+	Limits map[string]Limit `yaml:"limits" json:"limits"`
+
+	Judge JudgeType `yaml:"judge" json:"judge"`
+	// FloatTolerance is only read when Judge is JudgeFloatTolerance.
+	FloatTolerance float64 `yaml:"float_tolerance,omitempty" json:"float_tolerance,omitempty"`
+	// SpecialJudgeBinary is only read when Judge is JudgeSpecialJudge; it's
+	// a path relative to the problem's own directory.
+	SpecialJudgeBinary string `yaml:"special_judge_binary,omitempty" json:"special_judge_binary,omitempty"`
+
+	// TestCases is populated by Loader from the problem's tests/
+	// subdirectory, not read directly from problem.yaml.
+	TestCases []TestCaseDef `yaml:"-" json:"test_cases"`
+}
+
+// TestCaseDef is one input/expected pair from a problem's tests/
+// subdirectory, converted to the same jsonb-friendly shape
+// models.TestCase stores.
+type TestCaseDef struct {
+	Input          interface{} `json:"input"`
+	ExpectedOutput interface{} `json:"expected_output"`
+	IsSample       bool        `json:"is_sample"`
+}
+
+// Validate checks that d satisfies the problems schema: required fields
+// are set, Difficulty and Judge are recognized enum values, Limits is
+// non-empty with positive budgets, FloatTolerance/SpecialJudgeBinary are
+// set when their Judge requires them, and at least one test case exists.
+// It returns every violation found, not just the first, so an author
+// fixing a problem.yaml doesn't have to re-run validation once per error.
+func (d *ProblemDef) Validate() []error {
+	var errs []error
+
+	if d.Slug == "" {
+		errs = append(errs, fmt.Errorf("slug is required"))
+	}
+	if d.Title == "" {
+		errs = append(errs, fmt.Errorf("title is required"))
+	}
+	if d.Description == "" {
+		errs = append(errs, fmt.Errorf("description is required"))
+	}
+	if !validDifficulties[d.Difficulty] {
+		errs = append(errs, fmt.Errorf("difficulty %q is not one of easy/medium/hard", d.Difficulty))
+	}
+	if !validJudgeTypes[d.Judge] {
+		errs = append(errs, fmt.Errorf("judge %q is not a recognized judge type", d.Judge))
+	}
+	if d.Judge == JudgeFloatTolerance && d.FloatTolerance <= 0 {
+		errs = append(errs, fmt.Errorf("judge float_tolerance requires a positive float_tolerance"))
+	}
+	if d.Judge == JudgeSpecialJudge && d.SpecialJudgeBinary == "" {
+		errs = append(errs, fmt.Errorf("judge special_judge requires special_judge_binary"))
+	}
+	if len(d.Limits) == 0 {
+		errs = append(errs, fmt.Errorf("at least one language limit is required"))
+	}
+	for lang, limit := range d.Limits {
+		if limit.TimeMs <= 0 {
+			errs = append(errs, fmt.Errorf("limits.%s.time_ms must be positive", lang))
+		}
+		if limit.MemoryKb <= 0 {
+			errs = append(errs, fmt.Errorf("limits.%s.memory_kb must be positive", lang))
+		}
+	}
+	if len(d.TestCases) == 0 {
+		errs = append(errs, fmt.Errorf("at least one test case is required (tests/ subdirectory is empty or missing)"))
+	}
+
+	return errs
+}
@@ -0,0 +1,84 @@
+package problems
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceWindow coalesces the burst of fs events a single `git checkout`
+// or editor save produces into one reload, instead of reloading once per
+// touched file.
+const debounceWindow = 250 * time.Millisecond
+
+// Watch watches l.dir and every directory under it that exists when Watch
+// is called (each problem's subdirectory, its tests/ and tests/samples/)
+// for changes and calls onReload
+// with a freshly loaded set of ProblemDefs - debounced so a burst of
+// writes triggers one reload, not one per file - until ctx is cancelled.
+// onReload's error argument is non-nil if any problem in the set failed
+// to load; defs still holds whatever did load successfully.
+func (l *Loader) Watch(ctx context.Context, onReload func(defs []*ProblemDef, err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := addRecursive(watcher, l.dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var debounce *time.Timer
+		reload := func() {
+			defs, err := l.Load()
+			onReload(defs, err)
+			// A newly created problem subdirectory (or tests/ dir) needs
+			// its own watch registered, so re-scan after every reload.
+			_ = addRecursive(watcher, l.dir)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				if debounce != nil {
+					debounce.Stop()
+				}
+				return
+
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(debounceWindow, reload)
+
+			case <-watcher.Errors:
+				// A watch error doesn't invalidate what's already loaded;
+				// the next successful event still triggers a reload.
+			}
+		}
+	}()
+
+	return nil
+}
+
+// addRecursive adds dir and every directory under it (problem
+// subdirectories and their tests/ subdirectories) to watcher, skipping
+// any it's already watching.
+func addRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,272 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+)
+
+// ClientConfig configures a RunnerClient's HTTP client and retry/backoff
+// behavior.
+type ClientConfig struct {
+	// MaxRetries is how many additional attempts to make after the first
+	// one fails with a connection-level (not execution-level) error.
+	MaxRetries int
+	// BaseBackoff/MaxBackoff bound the exponential backoff between
+	// retries.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// HTTPClient is used to talk to the runner. Defaults to a fresh
+	// http.Client with a generous timeout if nil.
+	HTTPClient *http.Client
+}
+
+// DefaultClientConfig returns sensible defaults for a RunnerClient.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		MaxRetries:  3,
+		BaseBackoff: 200 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c ClientConfig) withDefaults() ClientConfig {
+	defaults := DefaultClientConfig()
+	if c.HTTPClient == nil {
+		c.HTTPClient = defaults.HTTPClient
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = defaults.MaxRetries
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = defaults.BaseBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaults.MaxBackoff
+	}
+	return c
+}
+
+// RunnerClient talks to a single standalone runner (cmd/runner) over
+// HTTP. It implements services.JobExecutor's ExecuteAndWait so a
+// Dispatcher can treat a remote runner the same as a local *k8s.JobManager.
+type RunnerClient struct {
+	baseURL string
+	config  ClientConfig
+}
+
+// NewRunnerClient creates a RunnerClient against the runner listening at
+// baseURL (e.g. "http://runner-0:9090").
+func NewRunnerClient(baseURL string, config ClientConfig) *RunnerClient {
+	return &RunnerClient{baseURL: baseURL, config: config.withDefaults()}
+}
+
+// Healthy reports whether the runner's /healthz endpoint is reachable.
+func (c *RunnerClient) Healthy(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+// ExecuteAndWait submits params to the runner and blocks until the
+// execution's terminal event arrives, retrying connection-level failures
+// with exponential backoff. Execution-level failures (the job ran but
+// didn't pass, or the runner reported an error) are not retried.
+func (c *RunnerClient) ExecuteAndWait(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := c.config.BaseBackoff * time.Duration(uint(1)<<uint(attempt-1))
+			if backoff > c.config.MaxBackoff {
+				backoff = c.config.MaxBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		result, err := c.execute(ctx, params)
+		if err == nil {
+			return result, nil
+		}
+		if !isRetryable(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("runner %s: exhausted retries: %w", c.baseURL, lastErr)
+}
+
+// execute makes a single attempt against the runner, reading its stream
+// of ExecutionEvents to completion and returning the terminal one.
+func (c *RunnerClient) execute(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal execution params: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/execute", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("runner %s: unexpected status %d", c.baseURL, resp.StatusCode)
+	}
+
+	var last ExecutionEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var event ExecutionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		last = event
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &retryableError{err}
+	}
+
+	switch last.Type {
+	case EventCompleted:
+		return last.Result, nil
+	case EventFailed:
+		return nil, fmt.Errorf("runner %s: execution failed: %s", c.baseURL, last.Error)
+	default:
+		return nil, &retryableError{fmt.Errorf("runner %s: stream ended without a terminal event", c.baseURL)}
+	}
+}
+
+// retryableError marks a connection-level failure (dropped connection,
+// truncated stream) as safe to retry. Execution-level failures - the
+// runner ran the job and reported an error - are plain errors instead.
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func isRetryable(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re)
+}
+
+// Pool load-balances execution requests across multiple runners with
+// round-robin selection, skipping any runner its background health check
+// has marked unreachable. It implements the same ExecuteAndWait surface
+// as a single RunnerClient (and *k8s.JobManager), so a WorkerPool can be
+// backed by a fleet of runners instead of an in-process JobManager
+// without any change to Dispatcher or WorkerPool.Submit.
+type Pool struct {
+	clients []*RunnerClient
+
+	mu      sync.RWMutex
+	healthy []bool
+
+	next uint64
+}
+
+// NewPool creates a Pool over the given runner base URLs. Call
+// StartHealthChecks to begin marking runners unhealthy when they stop
+// responding; until then every runner is assumed healthy.
+func NewPool(baseURLs []string, config ClientConfig) *Pool {
+	clients := make([]*RunnerClient, len(baseURLs))
+	healthy := make([]bool, len(baseURLs))
+	for i, url := range baseURLs {
+		clients[i] = NewRunnerClient(url, config)
+		healthy[i] = true
+	}
+
+	return &Pool{clients: clients, healthy: healthy}
+}
+
+// StartHealthChecks polls every runner's Healthy at the given interval
+// until ctx is cancelled, updating which runners ExecuteAndWait will
+// route to. Intended to be called once, right after NewPool.
+func (p *Pool) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+func (p *Pool) checkHealth(ctx context.Context) {
+	for i, client := range p.clients {
+		healthy := client.Healthy(ctx)
+		p.mu.Lock()
+		p.healthy[i] = healthy
+		p.mu.Unlock()
+	}
+}
+
+// ExecuteAndWait routes params to the next healthy runner in round-robin
+// order.
+func (p *Pool) ExecuteAndWait(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	client, err := p.pick()
+	if err != nil {
+		return nil, err
+	}
+	return client.ExecuteAndWait(ctx, params)
+}
+
+// pick returns the next healthy runner in round-robin order. If every
+// runner is currently marked unhealthy, it falls back to round-robin over
+// all of them anyway - a runner can recover between health checks, and
+// refusing to even try is worse than one failed attempt.
+func (p *Pool) pick() (*RunnerClient, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	n := len(p.clients)
+	if n == 0 {
+		return nil, errors.New("runner pool has no configured runners")
+	}
+
+	start := int(atomic.AddUint64(&p.next, 1)-1) % n
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		if p.healthy[idx] {
+			return p.clients[idx], nil
+		}
+	}
+
+	return p.clients[start], nil
+}
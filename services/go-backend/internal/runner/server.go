@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/k8s"
+)
+
+// jobRecordTTL is how long a completed job's record is kept around so a
+// client's Status call can still observe the final result after Execute's
+// stream has ended.
+const jobRecordTTL = 5 * time.Minute
+
+// jobRecord tracks an in-flight or completed job so Status/Cancel can
+// look it up by ID after Execute's stream has moved on.
+type jobRecord struct {
+	status EventType
+	result *k8s.ExecutionResult
+	err    string
+	cancel context.CancelFunc
+}
+
+// Server exposes k8s.JobManager's execution path over HTTP so it can run
+// as a standalone runner process (cmd/runner), separate from the API
+// server. The API server talks to one or more Servers through a
+// RunnerClient/Pool instead of calling JobManager directly, which lets
+// execution capacity scale - and be redeployed - independently of the API.
+type Server struct {
+	jobManager *k8s.JobManager
+
+	mu   sync.Mutex
+	jobs map[string]*jobRecord
+}
+
+// NewServer creates a Server backed by jobManager.
+func NewServer(jobManager *k8s.JobManager) *Server {
+	return &Server{
+		jobManager: jobManager,
+		jobs:       make(map[string]*jobRecord),
+	}
+}
+
+// RegisterRoutes wires the runner's endpoints onto router.
+func (s *Server) RegisterRoutes(router *gin.Engine) {
+	router.GET("/healthz", s.Healthz)
+
+	v1 := router.Group("/v1")
+	{
+		v1.POST("/execute", s.Execute)
+		v1.POST("/cancel/:jobID", s.Cancel)
+		v1.GET("/status/:jobID", s.Status)
+	}
+}
+
+// Execute runs a job and streams its lifecycle as newline-delimited JSON
+// ExecutionEvents, flushing after each one so a RunnerClient can observe
+// progress instead of blocking on the full response body.
+func (s *Server) Execute(c *gin.Context) {
+	var params k8s.ExecutionJobParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid execution params",
+		})
+		return
+	}
+
+	jobID := uuid.NewString()
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	s.mu.Lock()
+	s.jobs[jobID] = &jobRecord{status: EventQueued, cancel: cancel}
+	s.mu.Unlock()
+
+	c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event ExecutionEvent) {
+		event.JobID = jobID
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		payload = append(payload, '\n')
+		c.Writer.Write(payload)
+		c.Writer.Flush()
+	}
+
+	writeEvent(ExecutionEvent{Type: EventQueued})
+
+	s.updateJob(jobID, EventRunning, nil, "")
+	writeEvent(ExecutionEvent{Type: EventRunning})
+
+	result, err := s.jobManager.ExecuteAndWait(ctx, params)
+	if err != nil && result == nil {
+		s.updateJob(jobID, EventFailed, nil, err.Error())
+		writeEvent(ExecutionEvent{Type: EventFailed, Error: err.Error()})
+		s.scheduleEviction(jobID)
+		return
+	}
+
+	s.updateJob(jobID, EventCompleted, result, "")
+	writeEvent(ExecutionEvent{Type: EventCompleted, Result: result})
+	s.scheduleEviction(jobID)
+}
+
+// Status handles GET /v1/status/:jobID
+func (s *Server) Status(c *gin.Context) {
+	jobID := c.Param("jobID")
+
+	s.mu.Lock()
+	record, ok := s.jobs[jobID]
+	s.mu.Unlock()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "job not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, StatusResponse{
+		JobID:  jobID,
+		Status: record.status,
+		Result: record.result,
+		Error:  record.err,
+	})
+}
+
+// Cancel handles POST /v1/cancel/:jobID, stopping the job's execution
+// context if it's still running.
+func (s *Server) Cancel(c *gin.Context) {
+	jobID := c.Param("jobID")
+
+	s.mu.Lock()
+	record, ok := s.jobs[jobID]
+	s.mu.Unlock()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "job not found",
+		})
+		return
+	}
+
+	record.cancel()
+	c.JSON(http.StatusOK, gin.H{
+		"cancelled": true,
+	})
+}
+
+// Healthz handles GET /healthz, used by Pool's health checks.
+func (s *Server) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+func (s *Server) updateJob(jobID string, status EventType, result *k8s.ExecutionResult, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.jobs[jobID]
+	if !ok {
+		return
+	}
+	record.status = status
+	record.result = result
+	record.err = errMsg
+}
+
+// scheduleEviction removes a terminal job's record after jobRecordTTL so
+// a long-running runner doesn't accumulate one entry per job forever.
+func (s *Server) scheduleEviction(jobID string) {
+	time.AfterFunc(jobRecordTTL, func() {
+		s.mu.Lock()
+		delete(s.jobs, jobID)
+		s.mu.Unlock()
+	})
+}
@@ -0,0 +1,33 @@
+package runner
+
+import "github.com/nahtao97/scribble/internal/k8s"
+
+// EventType enumerates the lifecycle stages a runner reports while it
+// executes a job.
+type EventType string
+
+const (
+	EventQueued    EventType = "queued"
+	EventRunning   EventType = "running"
+	EventCompleted EventType = "completed"
+	EventFailed    EventType = "failed"
+)
+
+// ExecutionEvent is one entry in the newline-delimited JSON stream
+// returned by Server.Execute. A stream always ends with exactly one
+// EventCompleted event carrying the job's final Result or one EventFailed
+// event carrying its Error.
+type ExecutionEvent struct {
+	Type   EventType            `json:"type"`
+	JobID  string               `json:"job_id"`
+	Result *k8s.ExecutionResult `json:"result,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// StatusResponse is the payload returned by GET /v1/status/:jobID.
+type StatusResponse struct {
+	JobID  string               `json:"job_id"`
+	Status EventType            `json:"status"`
+	Result *k8s.ExecutionResult `json:"result,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
@@ -0,0 +1,88 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setupCgroup creates a cgroup v2 directory at path and writes l's
+// resource budget into it: memory.max (so the kernel OOM-kills anything
+// that exceeds it), pids.max (so a fork bomb can't escape the process
+// count it was given) and cpu.max (so a CPU-bound process gets at most one
+// core's worth of CPU per period; a positive CPUTime only switches this
+// cap on, its value does not set the quota, and total CPU time is not
+// capped) - the local-runtime equivalent of the Kubernetes backend's
+// container resource limits. A zero field in l leaves that control unset
+// (unenforced).
+func setupCgroup(path string, l Limits) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	if l.RSSBytes > 0 {
+		if err := writeCgroupFile(path, "memory.max", strconv.FormatInt(l.RSSBytes, 10)); err != nil {
+			return err
+		}
+	}
+	if l.PIDs > 0 {
+		if err := writeCgroupFile(path, "pids.max", strconv.FormatInt(l.PIDs, 10)); err != nil {
+			return err
+		}
+	}
+	if l.CPUTime > 0 {
+		// cpu.max is "$MAX $PERIOD" microseconds: allow one full period's
+		// worth of CPU time per period, i.e. a single core's worth - the
+		// ceiling a busy loop can't go over, not a budget that runs out.
+		period := 100 * time.Millisecond
+		if err := writeCgroupFile(path, "cpu.max", fmt.Sprintf("%d %d", period.Microseconds(), period.Microseconds())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCgroupFile writes value to the cgroup control file name under dir.
+func writeCgroupFile(dir, name, value string) error {
+	return os.WriteFile(filepath.Join(dir, name), []byte(value), 0644)
+}
+
+// addProcessToCgroup moves pid into the cgroup at path.
+func addProcessToCgroup(path string, pid int) error {
+	return os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// cgroupHitOOM reports whether the cgroup at path has ever OOM-killed a
+// process, by reading its oom_kill counter from memory.events - the local
+// equivalent of checking a pod's container status for OOMKilled.
+func cgroupHitOOM(path string) bool {
+	return cgroupEventNonZero(filepath.Join(path, "memory.events"), "oom_kill")
+}
+
+// cgroupHitPidsLimit reports whether the cgroup at path ever refused to
+// fork a new process because it was at its pids.max, by reading pids.max
+// from pids.events.
+func cgroupHitPidsLimit(path string) bool {
+	return cgroupEventNonZero(filepath.Join(path, "pids.events"), "max")
+}
+
+// cgroupEventNonZero reads file (a cgroup *.events file, one "key value"
+// pair per line) and reports whether key's counter is present and > 0.
+func cgroupEventNonZero(file, key string) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == key {
+			count, err := strconv.Atoi(fields[1])
+			return err == nil && count > 0
+		}
+	}
+
+	return false
+}
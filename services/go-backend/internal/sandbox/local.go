@@ -0,0 +1,366 @@
+// Package sandbox provides a local, non-Kubernetes implementation of
+// k8s.Runtime so contributors can exercise the execute pipeline without a
+// cluster, and so execution can run with lower latency than scheduling a
+// Kubernetes Job. LocalRuntime expects the same contract the Kubernetes
+// backend's executor image implements: a program that reads CODE
+// (base64-encoded), TEST_CASES (JSON) and PROBLEM_ID from its environment
+// and prints a k8s.ExecutionResult as JSON on stdout - so the same
+// executor binary/image can run under either backend.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/limits"
+)
+
+// Limits is internal/limits.Limits, re-exported so callers working
+// entirely within sandbox don't also need to import internal/limits.
+type Limits = limits.Limits
+
+// Config configures a LocalRuntime.
+type Config struct {
+	// ExecutorCommand is the command (and args) to run for each
+	// execution, split on whitespace from SCRIBBLE_LOCAL_EXECUTOR_CMD by
+	// DefaultConfig.
+	ExecutorCommand []string
+	// CgroupRoot is the cgroup v2 directory LocalRuntime creates a
+	// per-execution subdirectory under to enforce each execution's
+	// resolved Limits and detect OOM/pids-limit kills. Empty disables
+	// cgroup enforcement entirely (e.g. on a dev machine without cgroup v2
+	// or without permission to write to it) - executions still run, just
+	// without enforced memory/process-count/CPU limits.
+	CgroupRoot string
+}
+
+// DefaultConfig reads ExecutorCommand from SCRIBBLE_LOCAL_EXECUTOR_CMD,
+// falling back to "scribble-executor".
+func DefaultConfig() Config {
+	command := os.Getenv("SCRIBBLE_LOCAL_EXECUTOR_CMD")
+	if command == "" {
+		command = "scribble-executor"
+	}
+
+	return Config{
+		ExecutorCommand: strings.Fields(command),
+		CgroupRoot:      "/sys/fs/cgroup/scribble-executor",
+	}
+}
+
+// execution tracks one in-flight or completed run started by CreateExecution.
+type execution struct {
+	done       chan struct{}
+	stdout     *cappedWriter
+	result     *k8s.ExecutionResult
+	cgroupPath string
+}
+
+// LocalRuntime implements k8s.Runtime by running each execution as a local
+// OS process instead of a Kubernetes Job.
+type LocalRuntime struct {
+	cfg Config
+
+	mu         sync.Mutex
+	executions map[string]*execution
+}
+
+// NewLocalRuntime creates a LocalRuntime using cfg.
+func NewLocalRuntime(cfg Config) *LocalRuntime {
+	return &LocalRuntime{
+		cfg:        cfg,
+		executions: make(map[string]*execution),
+	}
+}
+
+// CreateExecution starts params running in the background and returns an
+// execution ID for Wait/Logs/Cleanup to refer back to it. The execution's
+// resource budget comes from limits.Resolve(params.Language, ...); ctx's
+// deadline (typically set by the HTTP handler from that same budget's
+// WallTime) bounds how long the subprocess is allowed to run, so a caller
+// with a shorter deadline of its own still cuts the execution off early.
+func (lr *LocalRuntime) CreateExecution(ctx context.Context, params k8s.ExecutionJobParams) (string, error) {
+	testCasesJSON, err := json.Marshal(params.TestCases)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal test cases: %w", err)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	executionID := fmt.Sprintf("local-%s-%06d", params.SubmissionID, rand.Intn(1000000))
+
+	execLimits := limits.Resolve(params.Language, 0, 0)
+	ex := &execution{
+		done:   make(chan struct{}),
+		stdout: newCappedWriter(execLimits.OutputBytes),
+	}
+
+	lr.mu.Lock()
+	lr.executions[executionID] = ex
+	lr.mu.Unlock()
+
+	go lr.run(ctx, executionID, ex, params, testCasesJSON, execLimits)
+
+	return executionID, nil
+}
+
+// run executes params in a subprocess, enforcing execLimits.WallTime via
+// ctx and (if lr.cfg.CgroupRoot is set) execLimits.RSSBytes/PIDs via
+// cgroups, plus a one-core cpu.max cap when execLimits.CPUTime is
+// positive, execLimits.OutputBytes via ex.stdout's cap, and parses the
+// resulting ExecutionResult from the process's stdout.
+func (lr *LocalRuntime) run(ctx context.Context, executionID string, ex *execution, params k8s.ExecutionJobParams, testCasesJSON []byte, execLimits limits.Limits) {
+	defer close(ex.done)
+
+	runCtx, cancel := context.WithTimeout(ctx, execLimits.WallTime)
+	defer cancel()
+
+	if len(lr.cfg.ExecutorCommand) == 0 {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: "no executor command configured",
+		}
+		return
+	}
+
+	cmd := exec.CommandContext(runCtx, lr.cfg.ExecutorCommand[0], lr.cfg.ExecutorCommand[1:]...)
+	cmd.Env = append(os.Environ(),
+		"CODE="+base64.StdEncoding.EncodeToString([]byte(params.Code)),
+		"TEST_CASES="+string(testCasesJSON),
+		"PROBLEM_ID="+params.ProblemID,
+	)
+	cmd.Stdout = ex.stdout
+	cmd.Stderr = ex.stdout
+
+	if lr.cfg.CgroupRoot != "" {
+		cgroupPath := filepath.Join(lr.cfg.CgroupRoot, executionID)
+		if err := setupCgroup(cgroupPath, execLimits); err == nil {
+			ex.cgroupPath = cgroupPath
+		}
+		// Best effort: if the cgroup can't be created (no cgroup v2, no
+		// permission), the execution still runs, just without enforced
+		// memory/process-count/CPU limits, rather than failing the
+		// submission.
+	}
+
+	if err := cmd.Start(); err != nil {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: fmt.Sprintf("failed to start executor: %v", err),
+		}
+		return
+	}
+
+	if ex.cgroupPath != "" {
+		_ = addProcessToCgroup(ex.cgroupPath, cmd.Process.Pid)
+	}
+
+	waitErr := cmd.Wait()
+
+	if ex.stdout.exceeded() {
+		_ = cmd.Process.Kill()
+		ex.result = &k8s.ExecutionResult{
+			Status:       "output_limit",
+			ErrorMessage: "Execution exceeded output limit",
+			Verdict:      "OLE",
+		}
+		return
+	}
+
+	if ex.cgroupPath != "" && cgroupHitOOM(ex.cgroupPath) {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "memory_limit",
+			ErrorMessage: "Execution exceeded memory limit",
+			Verdict:      "MLE",
+		}
+		return
+	}
+
+	if ex.cgroupPath != "" && cgroupHitPidsLimit(ex.cgroupPath) {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: "Execution exceeded process limit",
+		}
+		return
+	}
+
+	if runCtx.Err() == context.DeadlineExceeded {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "time_limit",
+			ErrorMessage: "Execution exceeded time limit",
+			Verdict:      "TLE",
+		}
+		return
+	}
+
+	if sig, ok := terminatingSignal(waitErr); ok {
+		errMsg := fmt.Sprintf("RE:signal=%s", sig)
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: errMsg,
+			Verdict:      k8s.VerdictForStatus("runtime_error", errMsg),
+		}
+		return
+	}
+
+	if waitErr != nil {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: fmt.Sprintf("executor exited: %v", waitErr),
+		}
+		return
+	}
+
+	output := ex.stdout.String()
+
+	var result k8s.ExecutionResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		ex.result = &k8s.ExecutionResult{
+			Status:       "runtime_error",
+			ErrorMessage: fmt.Sprintf("failed to parse execution result: %s", truncate(output, 200)),
+		}
+		return
+	}
+
+	ex.result = &result
+}
+
+// terminatingSignal reports the name of the signal that killed the
+// process behind waitErr, if any - used to surface a CPU-time kill
+// (cgroups v2's cpu.max throttles rather than kills, so a process that
+// keeps burning CPU past its budget is instead caught by ctx's deadline;
+// this path exists for an executor image that enforces its own RLIMIT_CPU
+// and gets SIGXCPU'd by the kernel for it).
+func terminatingSignal(waitErr error) (string, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(waitErr, &exitErr) {
+		return "", false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return "", false
+	}
+	return status.Signal().String(), true
+}
+
+// Wait blocks until executionID finishes, ctx is cancelled, or
+// config.MaxWaitTime elapses.
+func (lr *LocalRuntime) Wait(ctx context.Context, executionID string, config k8s.MonitorConfig) (*k8s.ExecutionResult, error) {
+	ex, err := lr.get(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ex.done:
+		return ex.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(config.MaxWaitTime):
+		return &k8s.ExecutionResult{
+			Status:       "time_limit",
+			ErrorMessage: "Execution exceeded time limit",
+			Verdict:      "TLE",
+		}, k8s.ErrJobTimeout
+	}
+}
+
+// Logs returns executionID's stdout/stderr captured so far.
+func (lr *LocalRuntime) Logs(ctx context.Context, executionID string) (string, error) {
+	ex, err := lr.get(executionID)
+	if err != nil {
+		return "", err
+	}
+
+	return ex.stdout.String(), nil
+}
+
+// Cleanup forgets executionID and removes its cgroup, if one was created.
+func (lr *LocalRuntime) Cleanup(ctx context.Context, executionID string) error {
+	lr.mu.Lock()
+	ex, ok := lr.executions[executionID]
+	delete(lr.executions, executionID)
+	lr.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if ex.cgroupPath != "" {
+		_ = os.Remove(ex.cgroupPath)
+	}
+	return nil
+}
+
+func (lr *LocalRuntime) get(executionID string) (*execution, error) {
+	lr.mu.Lock()
+	ex, ok := lr.executions[executionID]
+	lr.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown execution %s", executionID)
+	}
+	return ex, nil
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
+
+// cappedWriter is an io.Writer that stops accepting bytes once it has
+// buffered limitBytes, so a submission that writes gigabytes to stdout
+// can be killed (and reported as an output-limit violation) instead of
+// exhausting this process's own memory. A zero limitBytes disables the
+// cap.
+type cappedWriter struct {
+	mu         sync.Mutex
+	buf        bytes.Buffer
+	limitBytes int64
+	over       bool
+}
+
+func newCappedWriter(limitBytes int64) *cappedWriter {
+	return &cappedWriter{limitBytes: limitBytes}
+}
+
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.limitBytes > 0 && int64(w.buf.Len())+int64(len(p)) > w.limitBytes {
+		w.over = true
+		// Report every byte as written so the caller (cmd.Wait) doesn't
+		// see a write error; run() checks exceeded() afterward instead.
+		return len(p), nil
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *cappedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func (w *cappedWriter) exceeded() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.over
+}
+
+var _ io.Writer = (*cappedWriter)(nil)
@@ -0,0 +1,37 @@
+package sandbox
+
+import "testing"
+
+func TestCappedWriterUnderLimit(t *testing.T) {
+	w := newCappedWriter(16)
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if w.exceeded() {
+		t.Error("exceeded() = true for a write under the cap")
+	}
+	if got := w.String(); got != "hello" {
+		t.Errorf("String() = %q, want %q", got, "hello")
+	}
+}
+
+func TestCappedWriterOverLimit(t *testing.T) {
+	w := newCappedWriter(8)
+	if _, err := w.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !w.exceeded() {
+		t.Error("exceeded() = false for a write over the cap")
+	}
+}
+
+func TestCappedWriterZeroLimitDisablesCap(t *testing.T) {
+	w := newCappedWriter(0)
+	big := make([]byte, 1<<20)
+	if _, err := w.Write(big); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if w.exceeded() {
+		t.Error("exceeded() = true with limitBytes == 0")
+	}
+}
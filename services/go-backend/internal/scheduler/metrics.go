@@ -0,0 +1,28 @@
+package scheduler
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// QueueDepth reports how many submissions from each user are currently
+// queued (admitted but not yet running are excluded - they're counted as
+// running instead), so an operator can spot a single user backing up the
+// queue.
+var QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "scribble",
+	Subsystem: "scheduler",
+	Name:      "queue_depth",
+	Help:      "Number of queued (not yet admitted) submissions per user.",
+}, []string{"user_id"})
+
+// Preemptions counts how many times a running job has been cancelled to
+// make room for a more fair-share-deprived user's submission, labeled by
+// the user whose job was preempted.
+var Preemptions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "scheduler",
+	Name:      "preemptions_total",
+	Help:      "Number of running jobs preempted to admit a higher-priority submission.",
+}, []string{"evicted_user_id"})
+
+func init() {
+	prometheus.MustRegister(QueueDepth, Preemptions)
+}
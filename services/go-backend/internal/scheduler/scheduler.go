@@ -0,0 +1,356 @@
+// Package scheduler sits between ExecuteHandler and JobManager, queueing
+// incoming submissions instead of firing them straight at Kubernetes. It
+// enforces a global concurrency cap on outstanding Jobs and a per-user fair
+// share within that cap, so one user flooding the queue can't starve
+// everyone else's submissions.
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/services"
+)
+
+// DefaultCapacity is the default cap on outstanding Kubernetes Jobs across
+// every user.
+const DefaultCapacity = 20
+
+// ErrSchedulerClosed is returned by Submit once Shutdown has been called.
+var ErrSchedulerClosed = errors.New("scheduler is shut down")
+
+// Config tunes a Scheduler's admission policy.
+type Config struct {
+	// Capacity bounds how many Jobs the scheduler will keep outstanding at
+	// once, across every user combined.
+	Capacity int
+
+	// ProtectedFractionOfFairShare guards a user's running jobs from
+	// preemption as long as their current allocation stays at or below
+	// this fraction of their fair share. Only users over the threshold are
+	// eligible to be preempted to admit a more fair-share-starved user's
+	// submission when the scheduler is at Capacity. Defaults to 1.0.
+	ProtectedFractionOfFairShare float64
+}
+
+// DefaultConfig returns sensible defaults: DefaultCapacity outstanding
+// jobs, and a user is only preemptible once they're using more than their
+// full fair share.
+func DefaultConfig() Config {
+	return Config{
+		Capacity:                     DefaultCapacity,
+		ProtectedFractionOfFairShare: 1.0,
+	}
+}
+
+// Ticket is a handle to a queued or running submission, returned by Submit.
+// Wait blocks until the underlying job completes (or ctx is cancelled),
+// the same way JobManager.WaitForJobCompletion does for a single job.
+type Ticket struct {
+	ID          string
+	UserID      string
+	SubmittedAt time.Time
+
+	done   chan struct{}
+	result *k8s.ExecutionResult
+	err    error
+}
+
+// Wait blocks until the ticket's job completes, returning its result, or
+// returns ctx.Err() if ctx is cancelled first (the job itself keeps running
+// to completion either way).
+func (t *Ticket) Wait(ctx context.Context) (*k8s.ExecutionResult, error) {
+	select {
+	case <-t.done:
+		return t.result, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// queueItem is one pending submission sitting in the priority heap.
+type queueItem struct {
+	ticket      *Ticket
+	userID      string
+	params      k8s.ExecutionJobParams
+	submittedAt time.Time
+	index       int
+}
+
+// runningJob tracks one admitted submission so it can be found and
+// preempted (cancelled) if a higher-priority user needs its slot.
+type runningJob struct {
+	userID string
+	cancel context.CancelFunc
+}
+
+// Scheduler admits queued submissions onto a JobExecutor under a global
+// concurrency cap, choosing admission order by each user's fair-share
+// deficit: the user furthest under their fair share of Capacity goes next,
+// ties broken by submission time.
+type Scheduler struct {
+	executor services.JobExecutor
+	cfg      Config
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    priorityQueue
+	queued   map[string]int // userID -> count of this user's queued (not yet admitted) items
+	inFlight map[string]int // userID -> count of this user's currently running jobs
+	running  map[string]*runningJob
+	admitted int
+	closed   bool
+}
+
+// NewScheduler creates a Scheduler that admits jobs onto executor according
+// to cfg, and starts its dispatch loop. Zero-valued Config fields fall back
+// to DefaultConfig's.
+func NewScheduler(executor services.JobExecutor, cfg Config) *Scheduler {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCapacity
+	}
+	if cfg.ProtectedFractionOfFairShare <= 0 {
+		cfg.ProtectedFractionOfFairShare = 1.0
+	}
+
+	s := &Scheduler{
+		executor: executor,
+		cfg:      cfg,
+		queued:   make(map[string]int),
+		inFlight: make(map[string]int),
+		running:  make(map[string]*runningJob),
+	}
+	s.queue.sched = s
+	s.cond = sync.NewCond(&s.mu)
+
+	go s.dispatchLoop()
+
+	return s
+}
+
+// Submit queues params under userID and returns a Ticket the caller can
+// Wait on. It never blocks on cluster capacity itself - admission happens
+// asynchronously in the dispatch loop according to fair-share order.
+func (s *Scheduler) Submit(ctx context.Context, userID string, params k8s.ExecutionJobParams) (*Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrSchedulerClosed
+	}
+
+	ticket := &Ticket{
+		ID:          params.SubmissionID,
+		UserID:      userID,
+		SubmittedAt: time.Now(),
+		done:        make(chan struct{}),
+	}
+	item := &queueItem{
+		ticket:      ticket,
+		userID:      userID,
+		params:      params,
+		submittedAt: ticket.SubmittedAt,
+	}
+
+	heap.Push(&s.queue, item)
+	s.queued[userID]++
+	QueueDepth.WithLabelValues(userID).Set(float64(s.queued[userID]))
+	s.cond.Signal()
+
+	return ticket, nil
+}
+
+// Shutdown stops admitting new work from the queue. Submissions already
+// admitted keep running to completion; Submit starts returning
+// ErrSchedulerClosed.
+func (s *Scheduler) Shutdown() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// activeUserCount returns the number of distinct users with either a
+// queued or a running submission right now. Callers must hold s.mu.
+func (s *Scheduler) activeUserCount() int {
+	active := make(map[string]struct{}, len(s.queued)+len(s.inFlight))
+	for userID, n := range s.queued {
+		if n > 0 {
+			active[userID] = struct{}{}
+		}
+	}
+	for userID, n := range s.inFlight {
+		if n > 0 {
+			active[userID] = struct{}{}
+		}
+	}
+	if len(active) == 0 {
+		return 1
+	}
+	return len(active)
+}
+
+// fairShare returns userID's equal share of Capacity, split evenly across
+// every currently active user. Callers must hold s.mu.
+func (s *Scheduler) fairShare() float64 {
+	return float64(s.cfg.Capacity) / float64(s.activeUserCount())
+}
+
+// deficit returns how far under (< 1) or over (> 1) their fair share
+// userID's current running allocation is. A lower deficit means userID has
+// used less of their fair share and so should be admitted first. Callers
+// must hold s.mu.
+func (s *Scheduler) deficit(userID string) float64 {
+	share := s.fairShare()
+	if share <= 0 {
+		return float64(s.inFlight[userID])
+	}
+	return float64(s.inFlight[userID]) / share
+}
+
+// dispatchLoop is the scheduler's single admission goroutine: it pops the
+// highest-priority queued item and admits it whenever a slot is free, and
+// otherwise looks for a running job it can preempt to make room for a
+// sufficiently higher-priority submission.
+func (s *Scheduler) dispatchLoop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		for s.queue.Len() == 0 {
+			if s.closed {
+				return
+			}
+			s.cond.Wait()
+		}
+		if s.closed {
+			return
+		}
+
+		if s.admitted < s.cfg.Capacity {
+			item := heap.Pop(&s.queue).(*queueItem)
+			s.queued[item.userID]--
+			QueueDepth.WithLabelValues(item.userID).Set(float64(s.queued[item.userID]))
+			s.admit(item)
+			continue
+		}
+
+		head := s.queue.items[0]
+		if victim := s.findPreemptionVictim(head.userID); victim != nil {
+			Preemptions.WithLabelValues(victim.userID).Inc()
+			victim.cancel()
+		}
+
+		// Either nothing was preemptible or a preemption is in flight; either
+		// way, wait for the next signal (a completed job freeing a slot, or
+		// state changing enough to revisit this decision).
+		s.cond.Wait()
+	}
+}
+
+// findPreemptionVictim looks for a running job belonging to a user whose
+// current allocation exceeds ProtectedFractionOfFairShare of their fair
+// share and who is less fair-share-deprived than candidateUserID, returning
+// the worst offender if more than one qualifies. Callers must hold s.mu.
+func (s *Scheduler) findPreemptionVictim(candidateUserID string) *runningJob {
+	candidateDeficit := s.deficit(candidateUserID)
+	share := s.fairShare()
+
+	var victim *runningJob
+	var worstRatio float64
+	for _, rj := range s.running {
+		if share <= 0 {
+			continue
+		}
+		ratio := float64(s.inFlight[rj.userID]) / share
+		if ratio <= s.cfg.ProtectedFractionOfFairShare {
+			continue
+		}
+		if s.deficit(rj.userID) <= candidateDeficit {
+			continue
+		}
+		if victim == nil || ratio > worstRatio {
+			victim = rj
+			worstRatio = ratio
+		}
+	}
+	return victim
+}
+
+// admit starts item running against the executor. Callers must hold s.mu.
+func (s *Scheduler) admit(item *queueItem) {
+	s.inFlight[item.userID]++
+	s.admitted++
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.running[item.ticket.ID] = &runningJob{userID: item.userID, cancel: cancel}
+
+	go s.run(item, runCtx)
+}
+
+// run executes item against the executor and delivers its result to the
+// ticket, then frees item's admission slot and wakes the dispatch loop so
+// the next item (if any) can be admitted.
+func (s *Scheduler) run(item *queueItem, ctx context.Context) {
+	result, err := s.executor.ExecuteAndWait(ctx, item.params)
+
+	item.ticket.result = result
+	item.ticket.err = err
+	close(item.ticket.done)
+
+	s.mu.Lock()
+	delete(s.running, item.ticket.ID)
+	s.inFlight[item.userID]--
+	if s.inFlight[item.userID] <= 0 {
+		delete(s.inFlight, item.userID)
+	}
+	s.admitted--
+	s.cond.Broadcast()
+	s.mu.Unlock()
+}
+
+// priorityQueue is a container/heap of queueItems ordered by fair-share
+// deficit, then submission time. It holds a back-reference to the owning
+// Scheduler so Less can compare against each user's live allocation rather
+// than a priority snapshotted at enqueue time.
+type priorityQueue struct {
+	items []*queueItem
+	sched *Scheduler
+}
+
+func (pq *priorityQueue) Len() int { return len(pq.items) }
+
+func (pq *priorityQueue) Less(i, j int) bool {
+	a, b := pq.items[i], pq.items[j]
+	da, db := pq.sched.deficit(a.userID), pq.sched.deficit(b.userID)
+	if da != db {
+		return da < db
+	}
+	return a.submittedAt.Before(b.submittedAt)
+}
+
+func (pq *priorityQueue) Swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+func (pq *priorityQueue) Push(x interface{}) {
+	item := x.(*queueItem)
+	item.index = len(pq.items)
+	pq.items = append(pq.items, item)
+}
+
+func (pq *priorityQueue) Pop() interface{} {
+	old := pq.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	pq.items = old[:n-1]
+	return item
+}
@@ -0,0 +1,43 @@
+// Package middleware provides Gin middleware for the HTTP server: a
+// structured per-request access log (RequestLogger) and suspicious-request
+// tracking for Prometheus scraping (MarkSuspicious, SuspiciousMetricsHandler).
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nahtao97/scribble/internal/logging"
+)
+
+// RequestLogger emits exactly one structured log line per request (method,
+// path, status, latency, user ID, request ID) after the handler chain
+// returns, so concurrent requests' lines are never interleaved the way
+// logging a line at the start and another at the end of the request would
+// be. The user ID is read from the X-User-Id header set by the Node.js
+// proxy's auth layer, matching RateLimitMiddleware's convention - there's
+// no in-process auth to read it from otherwise. The line is logged at WARN
+// instead of INFO if MarkSuspicious flagged the request.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		logger := logging.FromContext(c.Request.Context())
+		event := logger.Info()
+		if suspicious, ok := c.Get(suspiciousContextKey); ok && suspicious == true {
+			event = logger.Warn()
+		}
+
+		event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Int("status", c.Writer.Status()).
+			Dur("latency", time.Since(start)).
+			Str("user_id", c.GetHeader("X-User-Id")).
+			Str("request_id", logging.RequestID(c.Request.Context())).
+			Msg("request handled")
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Reasons a request can be marked suspicious via MarkSuspicious, matching
+// the "reason" label on SuspiciousRequestsTotal.
+const (
+	ReasonRateLimited         = "rate_limited"
+	ReasonFlaggedEndpoint     = "flagged_endpoint"
+	ReasonAdminSecretMismatch = "admin_secret_mismatch"
+)
+
+// SuspiciousRequestsTotal counts requests MarkSuspicious flagged, labeled by
+// reason.
+var SuspiciousRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "http",
+	Name:      "suspicious_requests_total",
+	Help:      "Number of requests flagged as suspicious (rate limited, hit a flagged endpoint, or failed admin auth), by reason.",
+}, []string{"reason"})
+
+// suspiciousRegistry is scoped to just SuspiciousRequestsTotal rather than
+// prometheus.DefaultRegisterer, so /internal/metrics/suspicious doesn't dump
+// unrelated scheduler/cache/submissions counters alongside it - there's no
+// shared /metrics endpoint registered anywhere else in this service yet.
+var suspiciousRegistry = prometheus.NewRegistry()
+
+func init() {
+	suspiciousRegistry.MustRegister(SuspiciousRequestsTotal)
+}
+
+const suspiciousContextKey = "middleware.suspicious"
+
+// MarkSuspicious flags the in-flight request as suspicious for reason,
+// bumping SuspiciousRequestsTotal and causing RequestLogger to log the
+// request at WARN instead of INFO. Call it while c is still in scope, e.g.
+// from RateLimitMiddleware's denial branch or an admin-secret comparison
+// failure.
+func MarkSuspicious(c *gin.Context, reason string) {
+	c.Set(suspiciousContextKey, true)
+	SuspiciousRequestsTotal.WithLabelValues(reason).Inc()
+}
+
+// SuspiciousMetricsHandler serves GET /internal/metrics/suspicious in the
+// standard Prometheus exposition format, scoped to SuspiciousRequestsTotal.
+func SuspiciousMetricsHandler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(suspiciousRegistry, promhttp.HandlerOpts{})
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
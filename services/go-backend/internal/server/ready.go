@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrNotReady is returned by ReadyStorage.Get before Set has been called -
+// e.g. while the startup goroutine is still applying migrations - so
+// callers can respond with 503 + Retry-After instead of racing a nil
+// *gorm.DB or surfacing an opaque 500.
+var ErrNotReady = errors.New("storage not ready")
+
+// ReadyStorage gates access to the database connection behind a readiness
+// flag, so a handler or service constructed before the DB finishes
+// connecting and migrating fails fast with ErrNotReady instead of panicking
+// on a nil *gorm.DB. Set is called once, by the startup goroutine that runs
+// migrations, after they succeed.
+type ReadyStorage struct {
+	mu              sync.RWMutex
+	db              *gorm.DB
+	ready           bool
+	createdAt       time.Time
+	startTimeMargin time.Duration
+}
+
+// NewReadyStorage creates a ReadyStorage that isn't ready yet. startTimeMargin
+// is how long after creation RetryAfter should keep telling callers to wait
+// for the normal startup window to pass, before falling back to a flat
+// 1-second retry for whatever's taking longer than expected.
+func NewReadyStorage(startTimeMargin time.Duration) *ReadyStorage {
+	return &ReadyStorage{
+		createdAt:       time.Now(),
+		startTimeMargin: startTimeMargin,
+	}
+}
+
+// Set marks storage ready, making every subsequent Get return db. Intended
+// to be called exactly once, after startup migrations finish.
+func (s *ReadyStorage) Set(db *gorm.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.db = db
+	s.ready = true
+}
+
+// Get returns the underlying *gorm.DB, or ErrNotReady if Set hasn't been
+// called yet.
+func (s *ReadyStorage) Get() (*gorm.DB, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.ready {
+		return nil, ErrNotReady
+	}
+	return s.db, nil
+}
+
+// Ready reports whether Set has been called yet.
+func (s *ReadyStorage) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ready
+}
+
+// RetryAfter is how long a caller that got ErrNotReady should wait before
+// retrying: whatever's left of startTimeMargin since creation, or 1 second
+// once that window has elapsed and startup is taking longer than expected.
+func (s *ReadyStorage) RetryAfter() time.Duration {
+	remaining := s.startTimeMargin - time.Since(s.createdAt)
+	if remaining < time.Second {
+		return time.Second
+	}
+	return remaining
+}
+
+// ReadyHandler serves GET /ready: 200 once Set has been called (storage and
+// migrations are up), 503 + Retry-After otherwise.
+func (s *ReadyStorage) ReadyHandler(c *gin.Context) {
+	if !s.Ready() {
+		retryAfter := s.RetryAfter()
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not ready",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ready",
+	})
+}
+
+// RequireReady returns a gin.HandlerFunc that short-circuits with 503 +
+// Retry-After when storage isn't ready yet, instead of letting the wrapped
+// handler run into a nil or not-yet-migrated database. Use it to gate
+// routes that can't tolerate a half-started server, e.g.
+// POST /internal/leaderboards/compute.
+func (s *ReadyStorage) RequireReady() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.Ready() {
+			retryAfter := s.RetryAfter()
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "storage not ready",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestReadyStorage_GetBeforeSet(t *testing.T) {
+	storage := NewReadyStorage(10 * time.Second)
+
+	if storage.Ready() {
+		t.Error("expected storage not to be ready before Set")
+	}
+
+	if _, err := storage.Get(); err != ErrNotReady {
+		t.Errorf("expected ErrNotReady, got %v", err)
+	}
+}
+
+func TestReadyStorage_GetAfterSet(t *testing.T) {
+	storage := NewReadyStorage(10 * time.Second)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	storage.Set(db)
+
+	if !storage.Ready() {
+		t.Error("expected storage to be ready after Set")
+	}
+
+	got, err := storage.Get()
+	if err != nil {
+		t.Fatalf("Get failed after Set: %v", err)
+	}
+	if got != db {
+		t.Error("expected Get to return the db passed to Set")
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := NewReadyStorage(10 * time.Second)
+
+	router := gin.New()
+	router.GET("/ready", storage.ReadyHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before Set, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header before Set")
+	}
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	storage.Set(db)
+
+	req = httptest.NewRequest(http.MethodGet, "/ready", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 after Set, got %d", w.Code)
+	}
+}
+
+func TestRequireReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := NewReadyStorage(10 * time.Second)
+
+	router := gin.New()
+	router.POST("/internal/leaderboards/compute", storage.RequireReady(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/internal/leaderboards/compute", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before Set, got %d", w.Code)
+	}
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	storage.Set(db)
+
+	req = httptest.NewRequest(http.MethodPost, "/internal/leaderboards/compute", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected handler to run after Set, got %d", w.Code)
+	}
+}
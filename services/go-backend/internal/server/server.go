@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/nahtao97/scribble/internal/logging"
+	"github.com/nahtao97/scribble/internal/server/middleware"
 )
 
 // Server represents the HTTP server instance
@@ -27,8 +30,12 @@ func NewServer(config *Config) *Server {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	// Create router with default middleware
-	router := gin.Default()
+	// Create router with panic recovery and our structured request logger
+	// in place of gin.Default()'s plain-text access log. logging.Middleware
+	// must run first so middleware.RequestLogger can read the trace ID it
+	// attaches to the request context.
+	router := gin.New()
+	router.Use(gin.Recovery(), logging.Middleware(), middleware.RequestLogger())
 
 	return &Server{
 		router: router,
@@ -44,6 +51,10 @@ func (s *Server) RegisterRoutes() {
 	// API version endpoint
 	s.router.GET("/api/version", s.versionHandler)
 
+	// Suspicious-request counters for Prometheus scraping (see
+	// middleware.MarkSuspicious)
+	s.router.GET("/internal/metrics/suspicious", middleware.SuspiciousMetricsHandler())
+
 	// TODO: Register problem endpoints
 	// TODO: Register submission endpoints
 	// TODO: Register leaderboard endpoints
@@ -95,18 +106,20 @@ func (s *Server) Start() error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
+	logger := logging.FromContext(context.Background())
+
 	// Start server in goroutine so we can listen for shutdown signals
 	go func() {
-		fmt.Printf("Starting Go backend server on port %s (env: %s)\n", s.config.Port, s.config.Env)
+		logger.Info().Str("port", s.config.Port).Str("env", s.config.Env).Msg("starting server")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			logger.Error().Err(err).Msg("server error")
 			os.Exit(1)
 		}
 	}()
 
 	// Block until we receive a shutdown signal
 	<-quit
-	fmt.Println("\nReceived shutdown signal, initiating graceful shutdown...")
+	logger.Info().Msg("received shutdown signal, initiating graceful shutdown")
 
 	// Create context with 15-second timeout for graceful shutdown
 	// This allows in-flight requests to complete before forceful termination
@@ -119,7 +132,7 @@ func (s *Server) Start() error {
 		return fmt.Errorf("error during graceful shutdown: %w", err)
 	}
 
-	fmt.Println("Server shutdown complete. All connections closed gracefully.")
+	logger.Info().Msg("server shutdown complete, all connections closed gracefully")
 	return nil
 }
 
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// RecordActivity appends one row to the general activity stream. Pass a
+// transaction handle to keep the activity row atomic with whatever
+// business-logic change it documents (see AntiCheatService.ReviewFlag).
+func RecordActivity(db *gorm.DB, event, elementType string, elementID uint) error {
+	row := models.ActivityEvent{Event: event, ElementType: elementType, ElementID: elementID}
+	if err := db.Create(&row).Error; err != nil {
+		return fmt.Errorf("failed to record activity event: %w", err)
+	}
+	return nil
+}
@@ -1,41 +1,93 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/nahtao97/scribble/internal/logging"
 	"github.com/nahtao97/scribble/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrFlagStatusConflict is returned by ReviewFlag when the caller's
+// expectedStatus doesn't match the flag's current status - another admin
+// reviewed it first.
+var ErrFlagStatusConflict = errors.New("flag status conflict")
+
+// ErrSuperAdminRequired is returned by ReviewFlag when a flag is already
+// FlagStatusBanned and the caller isn't a superadmin - a regular admin
+// can't silently overturn a ban.
+var ErrSuperAdminRequired = errors.New("only a superadmin may move a flag out of banned status")
+
+// ErrNoReviewHistory is returned by RevertFlag when a flag has no prior
+// review event to revert to.
+var ErrNoReviewHistory = errors.New("flag has no review history to revert")
+
 // AntiCheatService handles cheating detection and prevention
 type AntiCheatService struct {
 	db              *gorm.DB
 	rateLimitConfig models.RateLimitConfig
+	backends        map[RateLimitTier]RateLimitBackend
+	statDetector    *StatisticalAnticheatDetector
+	broker          *FlagBroker
+	similarityQueue chan SimilarityJob
 }
 
-// NewAntiCheatService creates a new anti-cheat service instance
+// NewAntiCheatService creates a new anti-cheat service instance, with each
+// RateLimitTier backed by its own in-process TokenBucketLimiter per
+// DefaultRateLimitPolicies. Use NewAntiCheatServiceWithRateLimiters instead
+// to plug in SQLFixedWindowLimiter or RedisSlidingWindowLimiter backends,
+// e.g. when rate limits must be enforced across multiple replicas.
 func NewAntiCheatService(db *gorm.DB) *AntiCheatService {
+	backends := make(map[RateLimitTier]RateLimitBackend, len(DefaultRateLimitPolicies()))
+	for tier, policy := range DefaultRateLimitPolicies() {
+		backends[tier] = NewTokenBucketLimiter(policy, 0)
+	}
+
+	return NewAntiCheatServiceWithRateLimiters(db, backends)
+}
+
+// NewAntiCheatServiceWithRateLimiters creates a new anti-cheat service
+// instance using the given RateLimitBackend per tier. A tier absent from
+// backends falls back to TierAuthenticated's backend.
+func NewAntiCheatServiceWithRateLimiters(db *gorm.DB, backends map[RateLimitTier]RateLimitBackend) *AntiCheatService {
 	return &AntiCheatService{
 		db:              db,
 		rateLimitConfig: models.DefaultRateLimitConfig(),
+		backends:        backends,
+		statDetector:    NewStatisticalAnticheatDetector(db),
+		similarityQueue: make(chan SimilarityJob, codeSimQueueSize),
 	}
 }
 
 // SubmissionCheckResult contains the result of checking a submission
 type SubmissionCheckResult struct {
-	Allowed      bool              `json:"allowed"`
-	Flagged      bool              `json:"flagged"`
-	FlagReasons  []models.FlagReason `json:"flag_reasons,omitempty"`
-	RateLimited  bool              `json:"rate_limited"`
-	Message      string            `json:"message,omitempty"`
-	RetryAfter   *time.Duration    `json:"retry_after,omitempty"`
+	Allowed     bool                `json:"allowed"`
+	Flagged     bool                `json:"flagged"`
+	FlagReasons []models.FlagReason `json:"flag_reasons,omitempty"`
+	RateLimited bool                `json:"rate_limited"`
+	Message     string              `json:"message,omitempty"`
+	RetryAfter  *time.Duration      `json:"retry_after,omitempty"`
+	// Remaining is how many more submissions the user can make right now
+	// without waiting. Only meaningful when RateLimited is false.
+	Remaining int `json:"remaining"`
 }
 
-// CheckSubmission performs anti-cheat checks on a submission
-// Called before or after code execution
+// CheckSubmission performs anti-cheat checks on a submission for a
+// TierAuthenticated caller. Called before or after code execution.
 func (s *AntiCheatService) CheckSubmission(userID, problemID uint, executionTimeMs, memoryUsedKb int, difficulty string) (*SubmissionCheckResult, error) {
+	return s.CheckSubmissionForTier(TierAuthenticated, userID, problemID, executionTimeMs, memoryUsedKb, difficulty)
+}
+
+// CheckSubmissionForTier is CheckSubmission with the caller's RateLimitTier
+// made explicit, so e.g. anonymous callers can be held to a tighter policy
+// than authenticated ones.
+func (s *AntiCheatService) CheckSubmissionForTier(tier RateLimitTier, userID, problemID uint, executionTimeMs, memoryUsedKb int, difficulty string) (*SubmissionCheckResult, error) {
 	result := &SubmissionCheckResult{
 		Allowed:     true,
 		Flagged:     false,
@@ -43,7 +95,7 @@ func (s *AntiCheatService) CheckSubmission(userID, problemID uint, executionTime
 	}
 
 	// Check rate limit first
-	rateLimited, retryAfter, err := s.checkRateLimit(userID)
+	rateLimited, retryAfter, remaining, err := s.checkRateLimit(tier, userID)
 	if err != nil {
 		return nil, fmt.Errorf("rate limit check failed: %w", err)
 	}
@@ -56,6 +108,8 @@ func (s *AntiCheatService) CheckSubmission(userID, problemID uint, executionTime
 		return result, nil
 	}
 
+	result.Remaining = remaining
+
 	// Check for suspicious execution time
 	if s.isSuspiciousTime(executionTimeMs, difficulty) {
 		result.Flagged = true
@@ -68,9 +122,28 @@ func (s *AntiCheatService) CheckSubmission(userID, problemID uint, executionTime
 		result.FlagReasons = append(result.FlagReasons, models.FlagReasonZeroMemory)
 	}
 
+	// Check against the problem's observed population, once it has enough
+	// samples to trust - see StatisticalAnticheatDetector for the fixed
+	// SuspiciousTimeThresholds check above it degrades to until then.
+	isOutlier, err := s.statDetector.CheckAndUpdate(problemID, executionTimeMs, memoryUsedKb)
+	if err != nil {
+		return nil, err
+	}
+	if isOutlier {
+		result.Flagged = true
+		result.FlagReasons = append(result.FlagReasons, models.FlagReasonStatisticalAnomaly)
+	}
+
 	return result, nil
 }
 
+// GetProblemStats returns problemID's running execution time/memory
+// statistics, as tracked by the StatisticalAnticheatDetector every
+// CheckSubmissionForTier call feeds (admin inspection / debugging).
+func (s *AntiCheatService) GetProblemStats(problemID uint) (*models.ProblemStats, error) {
+	return s.statDetector.GetProblemStats(problemID)
+}
+
 // isSuspiciousTime checks if execution time is too fast for problem difficulty
 func (s *AntiCheatService) isSuspiciousTime(executionTimeMs int, difficulty string) bool {
 	threshold, ok := models.SuspiciousTimeThresholds[difficulty]
@@ -81,98 +154,102 @@ func (s *AntiCheatService) isSuspiciousTime(executionTimeMs int, difficulty stri
 	return executionTimeMs < threshold
 }
 
-// checkRateLimit checks if user has exceeded submission rate limit
-// Uses atomic updates to prevent race conditions
-func (s *AntiCheatService) checkRateLimit(userID uint) (bool, *time.Duration, error) {
-	now := time.Now()
-	windowStart := now.Add(-s.rateLimitConfig.WindowDuration)
-
-	// Try atomic increment for existing entry within window
-	// This prevents race conditions by using UPDATE ... WHERE in a single query
-	result := s.db.Exec(`
-		UPDATE rate_limit_entries
-		SET submissions = submissions + 1, last_submit = ?
-		WHERE user_id = ?
-		  AND window_start > ?
-		  AND submissions < ?
-	`, now, userID, windowStart, s.rateLimitConfig.MaxSubmissions)
+// checkRateLimit checks whether userID has exceeded tier's submission rate
+// limit using the RateLimitBackend registered for tier (falling back to
+// TierAuthenticated's backend if tier has none registered). The
+// rate_limit_entries table is no longer read on every submission - it's
+// only touched on a denial, as an audit trail other instances (or an
+// admin) can inspect, since not every backend persists its own state there.
+func (s *AntiCheatService) checkRateLimit(tier RateLimitTier, userID uint) (rateLimited bool, retryAfter *time.Duration, remaining int, err error) {
+	backend := s.backendFor(tier)
 
-	if result.Error != nil {
-		return false, nil, fmt.Errorf("failed to update rate limit: %w", result.Error)
+	allowed, wait, err := backend.Allow(context.Background(), strconv.FormatUint(uint64(userID), 10))
+	if err != nil {
+		return false, nil, 0, err
+	}
+	if allowed {
+		return false, nil, 0, nil
 	}
 
-	// If we updated a row, the submission is allowed
-	if result.RowsAffected > 0 {
-		return false, nil, nil
+	if err := s.recordRateLimitDenial(userID); err != nil {
+		return false, nil, 0, fmt.Errorf("failed to record rate limit denial: %w", err)
 	}
 
-	// No row updated - either entry doesn't exist, window expired, or limit reached
-	// Need to check which case and handle accordingly
-	var entry models.RateLimitEntry
-	err := s.db.Where("user_id = ?", userID).First(&entry).Error
-
-	if err == gorm.ErrRecordNotFound {
-		// First submission - create entry atomically using INSERT ... ON CONFLICT
-		// Use ON CONFLICT to handle race between concurrent first submissions
-		createResult := s.db.Exec(`
-			INSERT INTO rate_limit_entries (user_id, submissions, window_start, last_submit)
-			VALUES (?, 1, ?, ?)
-			ON CONFLICT (user_id) DO UPDATE SET
-				submissions = CASE
-					WHEN rate_limit_entries.window_start <= ? THEN 1
-					WHEN rate_limit_entries.submissions < ? THEN rate_limit_entries.submissions + 1
-					ELSE rate_limit_entries.submissions
-				END,
-				window_start = CASE
-					WHEN rate_limit_entries.window_start <= ? THEN ?
-					ELSE rate_limit_entries.window_start
-				END,
-				last_submit = ?
-		`, userID, now, now, windowStart, s.rateLimitConfig.MaxSubmissions, windowStart, now, now)
-
-		if createResult.Error != nil {
-			return false, nil, fmt.Errorf("failed to create rate limit entry: %w", createResult.Error)
-		}
-		return false, nil, nil
-	} else if err != nil {
-		return false, nil, fmt.Errorf("failed to query rate limit: %w", err)
+	return true, &wait, 0, nil
+}
+
+// backendFor returns the RateLimitBackend registered for tier, falling back
+// to TierAuthenticated's when tier has no backend of its own.
+func (s *AntiCheatService) backendFor(tier RateLimitTier) RateLimitBackend {
+	if backend, ok := s.backends[tier]; ok {
+		return backend
 	}
+	return s.backends[TierAuthenticated]
+}
 
-	// Entry exists - check if window expired
-	windowEnd := entry.WindowStart.Add(s.rateLimitConfig.WindowDuration)
-	if now.After(windowEnd) {
-		// Window expired - reset atomically
-		s.db.Exec(`
-			UPDATE rate_limit_entries
-			SET submissions = 1, window_start = ?, last_submit = ?
-			WHERE user_id = ? AND window_start = ?
-		`, now, now, userID, entry.WindowStart)
-		return false, nil, nil
+// ResetRateLimit clears userID's tracked rate-limit state for tier, for
+// admin use when a legitimate user gets caught by a false positive.
+func (s *AntiCheatService) ResetRateLimit(tier RateLimitTier, userID uint) error {
+	return s.backendFor(tier).Reset(context.Background(), strconv.FormatUint(uint64(userID), 10))
+}
+
+// CheckRateLimit is a standalone rate-limit check for TierAuthenticated
+// callers, for use by RateLimitMiddleware ahead of a handler that doesn't
+// go through CheckSubmissionForTier (e.g. to set X-RateLimit-* headers
+// before the request body is even read).
+func (s *AntiCheatService) CheckRateLimit(userID uint) (allowed bool, retryAfter time.Duration, err error) {
+	rateLimited, wait, _, err := s.checkRateLimit(TierAuthenticated, userID)
+	if err != nil {
+		return false, 0, err
 	}
+	if rateLimited {
+		return false, *wait, nil
+	}
+	return true, 0, nil
+}
 
-	// Limit reached - check cooldown
-	if entry.Submissions >= s.rateLimitConfig.MaxSubmissions {
-		cooldownEnd := entry.LastSubmit.Add(s.rateLimitConfig.CooldownDuration)
-		if now.Before(cooldownEnd) {
-			remaining := cooldownEnd.Sub(now)
-			return true, &remaining, nil
-		}
-		// Cooldown expired - reset atomically
-		s.db.Exec(`
-			UPDATE rate_limit_entries
-			SET submissions = 1, window_start = ?, last_submit = ?
-			WHERE user_id = ? AND submissions >= ?
-		`, now, now, userID, s.rateLimitConfig.MaxSubmissions)
-		return false, nil, nil
+// recordRateLimitDenial upserts a RateLimitEntry row for userID purely as an
+// audit record of the denial - it is never read back to make the allow/deny
+// decision, which now lives entirely in the registered RateLimitBackend.
+func (s *AntiCheatService) recordRateLimitDenial(userID uint) error {
+	now := time.Now()
+
+	result := s.db.Exec(`
+		INSERT INTO rate_limit_entries (user_id, submissions, window_start, last_submit)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			submissions = rate_limit_entries.submissions + 1,
+			last_submit = ?
+	`, userID, now, now, now)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to upsert rate limit audit entry: %w", result.Error)
 	}
 
-	// Edge case: concurrent request incremented just before us
-	return false, nil, nil
+	return nil
+}
+
+// AttachBroker wires a FlagBroker into the service so FlagSubmission fans
+// newly created flags out to admin flag stream subscribers. Optional - when
+// never called, FlagSubmission behaves exactly as before.
+func (s *AntiCheatService) AttachBroker(broker *FlagBroker) {
+	s.broker = broker
 }
 
-// FlagSubmission creates a flag record for a suspicious submission
-func (s *AntiCheatService) FlagSubmission(submissionID, userID, problemID uint, reason models.FlagReason, details map[string]interface{}) error {
-	detailsJSON, err := json.Marshal(details)
+// FlagSubmission creates a flag record for a suspicious submission. When ctx
+// carries a request ID (see logging.RequestID), it's merged into details
+// under "request_id" so an admin reviewing the flag via ReviewFlag can
+// correlate it back to the HTTP call that triggered it.
+func (s *AntiCheatService) FlagSubmission(ctx context.Context, submissionID, userID, problemID uint, reason models.FlagReason, details map[string]interface{}) error {
+	merged := make(map[string]interface{}, len(details)+1)
+	for k, v := range details {
+		merged[k] = v
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		merged["request_id"] = requestID
+	}
+
+	detailsJSON, err := json.Marshal(merged)
 	if err != nil {
 		detailsJSON = []byte("{}")
 	}
@@ -191,6 +268,10 @@ func (s *AntiCheatService) FlagSubmission(submissionID, userID, problemID uint,
 		return fmt.Errorf("failed to create flag: %w", result.Error)
 	}
 
+	if s.broker != nil {
+		s.broker.Publish(flag)
+	}
+
 	return nil
 }
 
@@ -234,28 +315,131 @@ func (s *AntiCheatService) GetFlagsByUser(userID uint) ([]models.FlaggedSubmissi
 	return flags, nil
 }
 
-// ReviewFlag updates the status of a flagged submission (admin action)
-func (s *AntiCheatService) ReviewFlag(flagID, adminUserID uint, status models.FlagStatus, notes string) error {
-	now := time.Now()
+// ReviewFlag updates the status of a flagged submission (admin action),
+// recording the transition as an immutable FlagReviewEvent and a general
+// activity stream row in the same transaction as the status update itself.
+// The flag row is locked with SELECT ... FOR UPDATE for the duration of the
+// transaction, so two admins reviewing the same flag at once serialize
+// instead of racing. When expectedStatus is non-empty, the second of the two
+// still fails with ErrFlagStatusConflict once it acquires the lock and sees
+// a status that no longer matches. Moving a flag out of FlagStatusBanned
+// requires isSuperAdmin - a regular admin can't silently overturn a ban -
+// and fails with ErrSuperAdminRequired otherwise.
+func (s *AntiCheatService) ReviewFlag(flagID, adminUserID uint, status models.FlagStatus, notes string, expectedStatus models.FlagStatus, isSuperAdmin bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var flag models.FlaggedSubmission
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flag, flagID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("flag with ID %d not found", flagID)
+			}
+			return fmt.Errorf("failed to load flag: %w", err)
+		}
 
-	result := s.db.Model(&models.FlaggedSubmission{}).
-		Where("id = ?", flagID).
-		Updates(map[string]interface{}{
-			"status":       status,
-			"reviewed_by":  adminUserID,
-			"reviewed_at":  now,
-			"review_notes": notes,
-		})
+		if expectedStatus != "" && flag.Status != expectedStatus {
+			return ErrFlagStatusConflict
+		}
 
-	if result.Error != nil {
-		return fmt.Errorf("failed to review flag: %w", result.Error)
-	}
+		if flag.Status == models.FlagStatusBanned && !isSuperAdmin {
+			return ErrSuperAdminRequired
+		}
+
+		prevStatus := flag.Status
+		now := time.Now()
+
+		result := tx.Model(&models.FlaggedSubmission{}).
+			Where("id = ?", flagID).
+			Updates(map[string]interface{}{
+				"status":       status,
+				"reviewed_by":  adminUserID,
+				"reviewed_at":  now,
+				"review_notes": notes,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to review flag: %w", result.Error)
+		}
+
+		event := models.FlagReviewEvent{
+			FlagID:      flagID,
+			ActorUserID: adminUserID,
+			PrevStatus:  prevStatus,
+			NewStatus:   status,
+			Notes:       notes,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to record flag review event: %w", err)
+		}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("flag with ID %d not found", flagID)
+		if err := RecordActivity(tx, "flag_review", "flag", flagID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetFlagReviewHistory returns flagID's review events in chronological
+// order, for the admin history endpoint.
+func (s *AntiCheatService) GetFlagReviewHistory(flagID uint) ([]models.FlagReviewEvent, error) {
+	var events []models.FlagReviewEvent
+	if err := s.db.Where("flag_id = ?", flagID).Order("created_at ASC").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get flag review history: %w", err)
 	}
+	return events, nil
+}
 
-	return nil
+// RevertFlag undoes a flag's most recent review, restoring the status it
+// held immediately before that review (event.PrevStatus) and appending
+// another FlagReviewEvent recording the revert - history is append-only, so
+// reverting never deletes or rewrites the event being undone. Locks the
+// flag row the same way ReviewFlag does. Returns ErrNoReviewHistory if the
+// flag has never been reviewed.
+func (s *AntiCheatService) RevertFlag(flagID, reviewerID uint, note string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var flag models.FlaggedSubmission
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&flag, flagID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("flag with ID %d not found", flagID)
+			}
+			return fmt.Errorf("failed to load flag: %w", err)
+		}
+
+		var lastEvent models.FlagReviewEvent
+		err := tx.Where("flag_id = ?", flagID).Order("created_at DESC").First(&lastEvent).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoReviewHistory
+			}
+			return fmt.Errorf("failed to load flag review history: %w", err)
+		}
+
+		restoredStatus := lastEvent.PrevStatus
+		now := time.Now()
+
+		result := tx.Model(&models.FlaggedSubmission{}).
+			Where("id = ?", flagID).
+			Updates(map[string]interface{}{
+				"status":       restoredStatus,
+				"reviewed_by":  reviewerID,
+				"reviewed_at":  now,
+				"review_notes": note,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to revert flag: %w", result.Error)
+		}
+
+		event := models.FlagReviewEvent{
+			FlagID:      flagID,
+			ActorUserID: reviewerID,
+			PrevStatus:  flag.Status,
+			NewStatus:   restoredStatus,
+			Notes:       note,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to record flag revert event: %w", err)
+		}
+
+		return RecordActivity(tx, "flag_revert", "flag", flagID)
+	})
 }
 
 // GetFlagStats returns statistics about flagged submissions
@@ -291,27 +475,25 @@ func (s *AntiCheatService) GetFlagStats() (*FlagStats, error) {
 
 // FlagStats contains aggregated flag statistics
 type FlagStats struct {
-	Total    int64                        `json:"total"`
-	Pending  int64                        `json:"pending"`
-	Reviewed int64                        `json:"reviewed"`
-	Cleared  int64                        `json:"cleared"`
-	Banned   int64                        `json:"banned"`
-	ByReason map[models.FlagReason]int64  `json:"by_reason"`
+	Total    int64                       `json:"total"`
+	Pending  int64                       `json:"pending"`
+	Reviewed int64                       `json:"reviewed"`
+	Cleared  int64                       `json:"cleared"`
+	Banned   int64                       `json:"banned"`
+	ByReason map[models.FlagReason]int64 `json:"by_reason"`
 }
 
-// CleanupOldRateLimitEntries removes stale rate limit entries (called periodically)
+// CleanupOldRateLimitEntries is a no-op kept for backward compatibility with
+// its cron caller. Rate limiting itself is enforced by the RateLimitBackend
+// registered per tier now - TokenBucketLimiter's fixed-size LRU is its own
+// eviction policy, and SQLFixedWindowLimiter/RedisSlidingWindowLimiter each
+// expire their own state - so there's nothing left here that needs a
+// periodic sweep.
 func (s *AntiCheatService) CleanupOldRateLimitEntries() (int64, error) {
-	cutoff := time.Now().Add(-24 * time.Hour) // Remove entries older than 24 hours
-
-	result := s.db.Where("last_submit < ?", cutoff).Delete(&models.RateLimitEntry{})
-	if result.Error != nil {
-		return 0, fmt.Errorf("failed to cleanup rate limit entries: %w", result.Error)
-	}
-
-	return result.RowsAffected, nil
+	return 0, nil
 }
 
 // EnsureTables creates the required tables if they don't exist
 func (s *AntiCheatService) EnsureTables() error {
-	return s.db.AutoMigrate(&models.FlaggedSubmission{}, &models.RateLimitEntry{})
+	return s.db.AutoMigrate(&models.FlaggedSubmission{}, &models.RateLimitEntry{}, &models.FlagReviewEvent{}, &models.ActivityEvent{}, &models.SubmissionFingerprint{})
 }
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -20,7 +22,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate test tables
-	err = db.AutoMigrate(&models.FlaggedSubmission{}, &models.RateLimitEntry{})
+	err = db.AutoMigrate(&models.FlaggedSubmission{}, &models.RateLimitEntry{}, &models.FlagReviewEvent{}, &models.ActivityEvent{}, &models.SubmissionFingerprint{}, &models.ProblemStats{})
 	if err != nil {
 		t.Fatalf("failed to migrate test tables: %v", err)
 	}
@@ -201,7 +203,7 @@ func TestFlagSubmission(t *testing.T) {
 		"expected_min":      20,
 	}
 
-	err := service.FlagSubmission(1, 1, 1, models.FlagReasonSuspiciousTime, details)
+	err := service.FlagSubmission(context.Background(), 1, 1, 1, models.FlagReasonSuspiciousTime, details)
 	if err != nil {
 		t.Fatalf("FlagSubmission failed: %v", err)
 	}
@@ -303,7 +305,7 @@ func TestReviewFlag(t *testing.T) {
 	db.Create(&flag)
 
 	// Review the flag
-	err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "False positive - legitimate solution")
+	err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "False positive - legitimate solution", "", false)
 	if err != nil {
 		t.Fatalf("ReviewFlag failed: %v", err)
 	}
@@ -329,12 +331,159 @@ func TestReviewFlag_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	service := NewAntiCheatService(db)
 
-	err := service.ReviewFlag(999, 100, models.FlagStatusCleared, "test")
+	err := service.ReviewFlag(999, 100, models.FlagStatusCleared, "test", "", false)
 	if err == nil {
 		t.Error("expected error for non-existent flag")
 	}
 }
 
+func TestReviewFlag_StatusConflict(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	flag := models.FlaggedSubmission{
+		SubmissionID: 1,
+		UserID:       1,
+		ProblemID:    1,
+		Reason:       models.FlagReasonSuspiciousTime,
+		Status:       models.FlagStatusPending,
+	}
+	db.Create(&flag)
+
+	err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "expected mismatch", models.FlagStatusReviewed, false)
+	if !errors.Is(err, ErrFlagStatusConflict) {
+		t.Fatalf("expected ErrFlagStatusConflict, got %v", err)
+	}
+
+	var unchanged models.FlaggedSubmission
+	db.First(&unchanged, flag.ID)
+	if unchanged.Status != models.FlagStatusPending {
+		t.Errorf("expected status to stay %s after conflict, got %s", models.FlagStatusPending, unchanged.Status)
+	}
+}
+
+func TestReviewFlag_RecordsHistory(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	flag := models.FlaggedSubmission{
+		SubmissionID: 1,
+		UserID:       1,
+		ProblemID:    1,
+		Reason:       models.FlagReasonSuspiciousTime,
+		Status:       models.FlagStatusPending,
+	}
+	db.Create(&flag)
+
+	if err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "looks fine", models.FlagStatusPending, false); err != nil {
+		t.Fatalf("ReviewFlag failed: %v", err)
+	}
+
+	events, err := service.GetFlagReviewHistory(flag.ID)
+	if err != nil {
+		t.Fatalf("GetFlagReviewHistory failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 review event, got %d", len(events))
+	}
+	if events[0].PrevStatus != models.FlagStatusPending || events[0].NewStatus != models.FlagStatusCleared {
+		t.Errorf("unexpected event transition: %+v", events[0])
+	}
+
+	var activityCount int64
+	db.Model(&models.ActivityEvent{}).Where("event = ? AND element_type = ? AND element_id = ?", "flag_review", "flag", flag.ID).Count(&activityCount)
+	if activityCount != 1 {
+		t.Errorf("expected 1 activity event, got %d", activityCount)
+	}
+}
+
+func TestReviewFlag_BannedRequiresSuperAdmin(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	flag := models.FlaggedSubmission{
+		SubmissionID: 1,
+		UserID:       1,
+		ProblemID:    1,
+		Reason:       models.FlagReasonSuspiciousTime,
+		Status:       models.FlagStatusBanned,
+	}
+	db.Create(&flag)
+
+	err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "overturning ban", "", false)
+	if !errors.Is(err, ErrSuperAdminRequired) {
+		t.Fatalf("expected ErrSuperAdminRequired, got %v", err)
+	}
+
+	if err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "overturning ban", "", true); err != nil {
+		t.Fatalf("ReviewFlag as superadmin failed: %v", err)
+	}
+
+	var updated models.FlaggedSubmission
+	db.First(&updated, flag.ID)
+	if updated.Status != models.FlagStatusCleared {
+		t.Errorf("expected status %s, got %s", models.FlagStatusCleared, updated.Status)
+	}
+}
+
+func TestRevertFlag(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	flag := models.FlaggedSubmission{
+		SubmissionID: 1,
+		UserID:       1,
+		ProblemID:    1,
+		Reason:       models.FlagReasonSuspiciousTime,
+		Status:       models.FlagStatusPending,
+	}
+	db.Create(&flag)
+
+	if err := service.ReviewFlag(flag.ID, 100, models.FlagStatusCleared, "looks fine", "", false); err != nil {
+		t.Fatalf("ReviewFlag failed: %v", err)
+	}
+
+	if err := service.RevertFlag(flag.ID, 101, "actually suspicious, reverting"); err != nil {
+		t.Fatalf("RevertFlag failed: %v", err)
+	}
+
+	var reverted models.FlaggedSubmission
+	db.First(&reverted, flag.ID)
+	if reverted.Status != models.FlagStatusPending {
+		t.Errorf("expected status reverted to %s, got %s", models.FlagStatusPending, reverted.Status)
+	}
+
+	events, err := service.GetFlagReviewHistory(flag.ID)
+	if err != nil {
+		t.Fatalf("GetFlagReviewHistory failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 review events (review + revert), got %d", len(events))
+	}
+	if events[1].PrevStatus != models.FlagStatusCleared || events[1].NewStatus != models.FlagStatusPending {
+		t.Errorf("unexpected revert event transition: %+v", events[1])
+	}
+}
+
+func TestRevertFlag_NoHistory(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	flag := models.FlaggedSubmission{
+		SubmissionID: 1,
+		UserID:       1,
+		ProblemID:    1,
+		Reason:       models.FlagReasonSuspiciousTime,
+		Status:       models.FlagStatusPending,
+	}
+	db.Create(&flag)
+
+	err := service.RevertFlag(flag.ID, 100, "nothing to revert")
+	if !errors.Is(err, ErrNoReviewHistory) {
+		t.Fatalf("expected ErrNoReviewHistory, got %v", err)
+	}
+}
+
 func TestGetFlagStats(t *testing.T) {
 	db := setupTestDB(t)
 	service := NewAntiCheatService(db)
@@ -384,40 +533,27 @@ func TestCleanupOldRateLimitEntries(t *testing.T) {
 	db := setupTestDB(t)
 	service := NewAntiCheatService(db)
 
-	// Create rate limit entries with different ages
-	now := time.Now()
-	oldTime := now.Add(-48 * time.Hour) // 2 days old
-	recentTime := now.Add(-1 * time.Hour) // 1 hour old
-
-	entries := []models.RateLimitEntry{
-		{UserID: 1, Submissions: 5, WindowStart: oldTime, LastSubmit: oldTime},
-		{UserID: 2, Submissions: 3, WindowStart: recentTime, LastSubmit: recentTime},
-		{UserID: 3, Submissions: 2, WindowStart: oldTime, LastSubmit: oldTime},
-	}
-	for _, e := range entries {
-		db.Create(&e)
-	}
+	// Rate limiting now lives entirely in the registered RateLimitBackend
+	// per tier (a TokenBucketLimiter by default), whose LRU is its own
+	// eviction policy, so cleanup no longer touches rate_limit_entries at
+	// all.
+	oldTime := time.Now().Add(-48 * time.Hour)
+	db.Create(&models.RateLimitEntry{UserID: 1, Submissions: 5, WindowStart: oldTime, LastSubmit: oldTime})
 
-	// Cleanup
 	deleted, err := service.CleanupOldRateLimitEntries()
 	if err != nil {
 		t.Fatalf("CleanupOldRateLimitEntries failed: %v", err)
 	}
 
-	if deleted != 2 {
-		t.Errorf("expected 2 entries deleted, got %d", deleted)
+	if deleted != 0 {
+		t.Errorf("expected cleanup to be a no-op, got %d deleted", deleted)
 	}
 
-	// Verify only recent entry remains
 	var remaining []models.RateLimitEntry
 	db.Find(&remaining)
 
 	if len(remaining) != 1 {
-		t.Errorf("expected 1 remaining entry, got %d", len(remaining))
-	}
-
-	if remaining[0].UserID != 2 {
-		t.Errorf("expected UserID 2 to remain, got %d", remaining[0].UserID)
+		t.Errorf("expected the entry to remain untouched, got %d remaining", len(remaining))
 	}
 }
 
@@ -436,3 +572,199 @@ func TestDefaultRateLimitConfig(t *testing.T) {
 		t.Errorf("expected CooldownDuration 10 minutes, got %v", config.CooldownDuration)
 	}
 }
+
+func TestFingerprintSource_Deterministic(t *testing.T) {
+	code := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+
+	a := fingerprintSource("python", code)
+	b := fingerprintSource("python", code)
+
+	if len(a) == 0 {
+		t.Fatal("expected at least one fingerprint")
+	}
+	if len(a) != len(b) {
+		t.Fatalf("expected deterministic fingerprint count, got %d and %d", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("fingerprint %d differs between runs: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
+
+func TestFingerprintSource_RenamedIdentifiersMatch(t *testing.T) {
+	original := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+	renamed := `def solve(count):
+    result = 0
+    for idx in range(count):
+        result += idx
+    return result`
+
+	a := fingerprintSource("python", original)
+	b := fingerprintSource("python", renamed)
+
+	hashesA := make(map[uint64]struct{}, len(a))
+	for _, fp := range a {
+		hashesA[fp.hash] = struct{}{}
+	}
+
+	shared := 0
+	for _, fp := range b {
+		if _, ok := hashesA[fp.hash]; ok {
+			shared++
+		}
+	}
+	if shared == 0 {
+		t.Fatal("expected renamed-identifier source to still share fingerprints with the original")
+	}
+}
+
+func TestAnalyzeSimilarity_FlagsNearDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	original := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+	renamed := `def solve(count):
+    result = 0
+    for idx in range(count):
+        result += idx
+    return result`
+
+	if err := service.AnalyzeSimilarity(context.Background(), 1, 100, 7, "python", original); err != nil {
+		t.Fatalf("AnalyzeSimilarity (first submission) failed: %v", err)
+	}
+	if err := service.AnalyzeSimilarity(context.Background(), 2, 200, 7, "python", renamed); err != nil {
+		t.Fatalf("AnalyzeSimilarity (second submission) failed: %v", err)
+	}
+
+	flags, err := service.GetFlagsByUser(200)
+	if err != nil {
+		t.Fatalf("GetFlagsByUser failed: %v", err)
+	}
+	if len(flags) != 1 {
+		t.Fatalf("expected 1 flag for the near-duplicate submission, got %d", len(flags))
+	}
+	if flags[0].Reason != models.FlagReasonCodeSimilarity {
+		t.Errorf("expected FlagReasonCodeSimilarity, got %s", flags[0].Reason)
+	}
+}
+
+func TestAnalyzeSimilarity_NoFlagForDifferentCode(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	solve := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+	fibonacci := `def fibonacci(n):
+    if n <= 1:
+        return n
+    a, b = 0, 1
+    for _ in range(n - 1):
+        a, b = b, a + b
+    return b`
+
+	if err := service.AnalyzeSimilarity(context.Background(), 1, 100, 7, "python", solve); err != nil {
+		t.Fatalf("AnalyzeSimilarity (first submission) failed: %v", err)
+	}
+	if err := service.AnalyzeSimilarity(context.Background(), 2, 200, 7, "python", fibonacci); err != nil {
+		t.Fatalf("AnalyzeSimilarity (second submission) failed: %v", err)
+	}
+
+	flags, err := service.GetFlagsByUser(200)
+	if err != nil {
+		t.Fatalf("GetFlagsByUser failed: %v", err)
+	}
+	if len(flags) != 0 {
+		t.Fatalf("expected no flags for dissimilar code, got %d", len(flags))
+	}
+}
+
+func TestFindSimilarSubmissions_NearDuplicateAboveThreshold(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	original := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+	renamed := `def solve(count):
+    result = 0
+    for idx in range(count):
+        result += idx
+    return result`
+
+	if err := service.AnalyzeSimilarity(context.Background(), 1, 100, 7, "python", original); err != nil {
+		t.Fatalf("AnalyzeSimilarity (first submission) failed: %v", err)
+	}
+	if err := service.AnalyzeSimilarity(context.Background(), 2, 200, 7, "python", renamed); err != nil {
+		t.Fatalf("AnalyzeSimilarity (second submission) failed: %v", err)
+	}
+
+	matches, err := service.FindSimilarSubmissions(1, 0.6, 0)
+	if err != nil {
+		t.Fatalf("FindSimilarSubmissions failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 similar submission, got %d", len(matches))
+	}
+	if matches[0].SubmissionID != 2 {
+		t.Errorf("expected match on submission 2, got %d", matches[0].SubmissionID)
+	}
+	if matches[0].Jaccard < 0.8 {
+		t.Errorf("expected Jaccard >= 0.8 for renamed-identifier duplicate, got %f", matches[0].Jaccard)
+	}
+	if len(matches[0].MatchedRegions) == 0 {
+		t.Error("expected at least one matched region for a near-duplicate")
+	}
+}
+
+func TestFindSimilarSubmissions_BelowThresholdForDifferentCode(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAntiCheatService(db)
+
+	solve := `def solve(n):
+    total = 0
+    for i in range(n):
+        total += i
+    return total`
+	fibonacci := `def fibonacci(n):
+    if n <= 1:
+        return n
+    a, b = 0, 1
+    for _ in range(n - 1):
+        a, b = b, a + b
+    return b`
+
+	if err := service.AnalyzeSimilarity(context.Background(), 1, 100, 7, "python", solve); err != nil {
+		t.Fatalf("AnalyzeSimilarity (first submission) failed: %v", err)
+	}
+	if err := service.AnalyzeSimilarity(context.Background(), 2, 200, 7, "python", fibonacci); err != nil {
+		t.Fatalf("AnalyzeSimilarity (second submission) failed: %v", err)
+	}
+
+	matches, err := service.FindSimilarSubmissions(1, 0.01, 0)
+	if err != nil {
+		t.Fatalf("FindSimilarSubmissions failed: %v", err)
+	}
+	for _, m := range matches {
+		if m.Jaccard >= 0.2 {
+			t.Errorf("expected structurally different solutions to score < 0.2, got %f for submission %d", m.Jaccard, m.SubmissionID)
+		}
+	}
+}
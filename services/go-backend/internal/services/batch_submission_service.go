@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// SubmissionRequest is one item of a Judge0-style batch submission: a
+// single run against one stdin/expected_output pair, rather than the full
+// test-case suite a normal Submission runs against.
+type SubmissionRequest struct {
+	LanguageID     string `json:"language_id"`
+	SourceCode     string `json:"source_code"`
+	Stdin          string `json:"stdin"`
+	ExpectedOutput string `json:"expected_output"`
+	CPUTimeLimit   int    `json:"cpu_time_limit"`
+	MemoryLimit    int    `json:"memory_limit"`
+}
+
+// SubmissionResult mirrors Judge0's per-submission result shape, returned
+// by GetBatch for each token.
+type SubmissionResult struct {
+	Token         string `json:"token"`
+	Status        string `json:"status"`
+	Stdout        string `json:"stdout,omitempty"`
+	Stderr        string `json:"stderr,omitempty"`
+	CompileOutput string `json:"compile_output,omitempty"`
+	Time          int64  `json:"time_ms"`
+	Memory        int64  `json:"memory_kb"`
+}
+
+// BatchSubmissionService accepts a Judge0-style array of submissions in one
+// call, persists them as models.Submission rows sharing a BatchID, and
+// dispatches each to the executor pool concurrently - partial-batch
+// failures are isolated to the one submission that failed, since every
+// submission's status is tracked and updated independently. Library code
+// only: the ticket this shipped under asked for the Judge0-compatible
+// service methods, not a /batch HTTP surface, so CreateBatch/GetBatch have
+// no handler or route yet - that's a separate follow-up once the actual
+// request/response shape callers need is settled. It dispatches through
+// the same Dispatcher JudgeQueue does (see
+// TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions in
+// submission_pipeline_integration_test.go for that path proven end to
+// end), just fanned out over a batch instead of one submission at a time.
+type BatchSubmissionService struct {
+	db          *gorm.DB
+	submissions *SubmissionService
+	dispatcher  *Dispatcher
+	queueName   string
+}
+
+// NewBatchSubmissionService creates a BatchSubmissionService that dispatches
+// onto dispatcher's DefaultQueueName queue.
+func NewBatchSubmissionService(db *gorm.DB, submissions *SubmissionService, dispatcher *Dispatcher) *BatchSubmissionService {
+	return &BatchSubmissionService{db: db, submissions: submissions, dispatcher: dispatcher, queueName: DefaultQueueName}
+}
+
+// CreateBatch persists one models.Submission per request, all sharing a
+// freshly generated BatchID, then dispatches them to the executor pool
+// concurrently in the background. It returns as soon as the submissions are
+// persisted - callers poll GetBatch (or GetUserBatchHistory) for results,
+// same as Judge0's /submissions/batch.
+func (s *BatchSubmissionService) CreateBatch(userID, problemID string, requests []SubmissionRequest) (batchID string, tokens []string, err error) {
+	if len(requests) == 0 {
+		return "", nil, fmt.Errorf("batch must contain at least one submission")
+	}
+
+	batchID = uuid.NewString()
+
+	rows := make([]models.Submission, 0, len(requests))
+	for _, req := range requests {
+		rows = append(rows, models.Submission{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			ProblemID: problemID,
+			Language:  req.LanguageID,
+			Code:      req.SourceCode,
+			Status:    models.StatusPending,
+			BatchID:   &batchID,
+		})
+	}
+
+	if err := s.db.Create(&rows).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to persist batch submissions: %w", err)
+	}
+
+	tokens = make([]string, len(rows))
+	for i, row := range rows {
+		tokens[i] = row.ID
+	}
+
+	go s.dispatchBatch(rows, requests)
+
+	return batchID, tokens, nil
+}
+
+// dispatchBatch runs every submission in rows through the executor pool
+// concurrently. Each submission updates only its own row, so one failing
+// (a dispatcher error, a compile error, ...) never rolls back or blocks its
+// siblings.
+func (s *BatchSubmissionService) dispatchBatch(rows []models.Submission, requests []SubmissionRequest) {
+	var wg sync.WaitGroup
+	for i, row := range rows {
+		wg.Add(1)
+		go func(submission models.Submission, request SubmissionRequest) {
+			defer wg.Done()
+			s.dispatchOne(submission, request)
+		}(row, requests[i])
+	}
+	wg.Wait()
+}
+
+// dispatchOne executes a single batch submission and persists its outcome.
+func (s *BatchSubmissionService) dispatchOne(submission models.Submission, request SubmissionRequest) {
+	ctx := context.Background()
+
+	testCases := []map[string]interface{}{
+		{
+			"input":           request.Stdin,
+			"expected_output": request.ExpectedOutput,
+		},
+	}
+
+	result, err := s.dispatcher.Submit(ctx, s.queueName, k8s.ExecutionJobParams{
+		SubmissionID: submission.ID,
+		ProblemID:    submission.ProblemID,
+		Code:         submission.Code,
+		TestCases:    testCases,
+		Language:     submission.Language,
+	})
+
+	updates := map[string]interface{}{}
+	if err != nil {
+		updates["status"] = models.StatusRuntimeError
+		updates["error_message"] = err.Error()
+	} else {
+		updates["status"] = result.Status
+		updates["error_message"] = result.ErrorMessage
+		updates["execution_time_ms"] = result.ExecutionTimeMs
+		updates["memory_used_kb"] = result.MemoryUsedKB
+		updates["tests_passed"] = result.TestsPassed
+		updates["tests_total"] = result.TestsTotal
+	}
+
+	if dbErr := s.db.Model(&models.Submission{}).Where("id = ?", submission.ID).Updates(updates).Error; dbErr != nil {
+		return
+	}
+
+	status, _ := updates["status"].(string)
+	if status != "" {
+		_ = s.submissions.PublishStatus(submission.ID, status, toInt64(updates["execution_time_ms"]), toInt64(updates["memory_used_kb"]))
+	}
+}
+
+// toInt64 reads an int64 execution_time_ms/memory_used_kb value out of the
+// updates map dispatchOne builds, defaulting to 0 when the key is absent
+// (the error path never sets them).
+func toInt64(v interface{}) int64 {
+	n, _ := v.(int64)
+	return n
+}
+
+// GetBatch retrieves the current status and Judge0-style result fields for
+// every token in tokens, in the same order.
+func (s *BatchSubmissionService) GetBatch(tokens []string) ([]SubmissionResult, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	var rows []models.Submission
+	if err := s.db.Where("id IN ?", tokens).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve batch submissions: %w", err)
+	}
+
+	byID := make(map[string]models.Submission, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+
+	results := make([]SubmissionResult, 0, len(tokens))
+	for _, token := range tokens {
+		row, ok := byID[token]
+		if !ok {
+			results = append(results, SubmissionResult{Token: token, Status: "not_found"})
+			continue
+		}
+
+		result := SubmissionResult{
+			Token:  row.ID,
+			Status: row.Status,
+			Time:   int64(row.ExecutionTimeMs),
+			Memory: int64(row.MemoryUsedKb),
+		}
+		switch row.Status {
+		case models.StatusCompilationError:
+			result.CompileOutput = row.ErrorMessage
+		case models.StatusRuntimeError, models.StatusTimeLimit, models.StatusMemoryLimit:
+			result.Stderr = row.ErrorMessage
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// GetUserBatchHistory parallels GetUserSubmissionHistory, restricted to one
+// batch.
+func (s *BatchSubmissionService) GetUserBatchHistory(userID, batchID string, page, pageSize int) (*SubmissionHistoryPage, error) {
+	return s.submissions.GetUserSubmissionHistory(SubmissionHistoryParams{
+		UserID:   userID,
+		BatchID:  batchID,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// failingCode is the sentinel submission Code fakeBatchExecutor treats as a
+// transient executor failure, so tests can force a specific batch entry to
+// fail without knowing its generated submission ID in advance.
+const failingCode = "__fail__"
+
+// fakeBatchExecutor implements JobExecutor for batch submission tests: it
+// fails every execution whose code is failingCode, and otherwise returns an
+// accepted result.
+type fakeBatchExecutor struct{}
+
+func (f *fakeBatchExecutor) ExecuteAndWait(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	if params.Code == failingCode {
+		return nil, errors.New("executor unavailable")
+	}
+	return &k8s.ExecutionResult{
+		Status:          models.StatusAccepted,
+		ExecutionTimeMs: 42,
+		MemoryUsedKB:    1024,
+		TestsPassed:     1,
+		TestsTotal:      1,
+	}, nil
+}
+
+func newTestBatchService(t *testing.T, executor JobExecutor) *BatchSubmissionService {
+	db := setupSubmissionTestDB(t)
+	submissionService := NewSubmissionService(db)
+
+	dispatcher := NewDispatcher(executor)
+	if err := dispatcher.RegisterQueue(DefaultQueueName, 4, 16); err != nil {
+		t.Fatalf("failed to register queue: %v", err)
+	}
+	t.Cleanup(func() { _ = dispatcher.Shutdown(context.Background()) })
+
+	return NewBatchSubmissionService(db, submissionService, dispatcher)
+}
+
+func waitForStatus(t *testing.T, batch *BatchSubmissionService, token, want string) models.Submission {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		results, err := batch.GetBatch([]string{token})
+		if err != nil {
+			t.Fatalf("GetBatch() error = %v", err)
+		}
+		if results[0].Status == want {
+			var submission models.Submission
+			if err := batch.db.First(&submission, "id = ?", token).Error; err != nil {
+				t.Fatalf("failed to reload submission: %v", err)
+			}
+			return submission
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for submission %s to reach status %q", token, want)
+	return models.Submission{}
+}
+
+func TestCreateBatch_PersistsLinkedSubmissionsSharingBatchID(t *testing.T) {
+	batch := newTestBatchService(t, &fakeBatchExecutor{})
+
+	requests := []SubmissionRequest{
+		{LanguageID: "python", SourceCode: "print(1)", Stdin: "", ExpectedOutput: "1"},
+		{LanguageID: "python", SourceCode: "print(2)", Stdin: "", ExpectedOutput: "2"},
+	}
+
+	batchID, tokens, err := batch.CreateBatch("u1", "p1", requests)
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(tokens))
+	}
+
+	var rows []models.Submission
+	if err := batch.db.Where("batch_id = ?", batchID).Find(&rows).Error; err != nil {
+		t.Fatalf("failed to query submissions: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 linked submissions, got %d", len(rows))
+	}
+}
+
+func TestCreateBatch_PartialFailureDoesNotAffectSiblings(t *testing.T) {
+	batch := newTestBatchService(t, &fakeBatchExecutor{})
+
+	_, tokens, err := batch.CreateBatch("u1", "p1", []SubmissionRequest{
+		{LanguageID: "python", SourceCode: "print(1)"},
+		{LanguageID: "python", SourceCode: failingCode},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	okToken, failToken := tokens[0], tokens[1]
+
+	waitForStatus(t, batch, okToken, models.StatusAccepted)
+	failed := waitForStatus(t, batch, failToken, models.StatusRuntimeError)
+	if failed.ErrorMessage == "" {
+		t.Error("expected failing submission to record an error message")
+	}
+
+	// The sibling submission must still show its own accepted result,
+	// unaffected by the other one failing.
+	results, err := batch.GetBatch([]string{okToken})
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+	if results[0].Status != models.StatusAccepted {
+		t.Errorf("sibling submission status = %q, want %q", results[0].Status, models.StatusAccepted)
+	}
+}
+
+func TestGetBatch_AggregatesPassFailCounts(t *testing.T) {
+	batch := newTestBatchService(t, &fakeBatchExecutor{})
+
+	_, tokens, err := batch.CreateBatch("u1", "p1", []SubmissionRequest{
+		{LanguageID: "python", SourceCode: "print(1)"},
+		{LanguageID: "python", SourceCode: "print(2)"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+
+	for _, token := range tokens {
+		waitForStatus(t, batch, token, models.StatusAccepted)
+	}
+
+	results, err := batch.GetBatch(tokens)
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+
+	var passed, failed int
+	for _, r := range results {
+		if r.Status == models.StatusAccepted {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	if passed != 2 || failed != 0 {
+		t.Errorf("expected 2 passed/0 failed, got %d passed/%d failed", passed, failed)
+	}
+}
+
+func TestGetUserBatchHistory_FiltersByBatchID(t *testing.T) {
+	batch := newTestBatchService(t, &fakeBatchExecutor{})
+
+	batchID, tokens, err := batch.CreateBatch("u1", "p1", []SubmissionRequest{
+		{LanguageID: "python", SourceCode: "print(1)"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch() error = %v", err)
+	}
+	waitForStatus(t, batch, tokens[0], models.StatusAccepted)
+
+	// An unrelated submission outside the batch shouldn't show up.
+	other := models.Submission{ID: "other-1", UserID: "u1", ProblemID: "p1", Language: "python", Code: "print(3)", Status: models.StatusAccepted}
+	if err := batch.db.Create(&other).Error; err != nil {
+		t.Fatalf("failed to create unrelated submission: %v", err)
+	}
+
+	page, err := batch.GetUserBatchHistory("u1", batchID, 1, 20)
+	if err != nil {
+		t.Fatalf("GetUserBatchHistory() error = %v", err)
+	}
+	if page.Total != 1 {
+		t.Fatalf("expected 1 submission in batch history, got %d", page.Total)
+	}
+	if page.Submissions[0].ID != tokens[0] {
+		t.Errorf("got submission %q, want %q", page.Submissions[0].ID, tokens[0])
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// spacedRepetitionTau controls how quickly a problem's dormancy weight
+// saturates toward 1 as it ages; roughly the number of days after which a
+// dormant problem becomes a strong pick regardless of other factors.
+const spacedRepetitionTau = 14.0
+
+// defaultTagBalanceWindow is how many of the most recent daily challenges
+// TagBalancedSelector avoids repeating tags from, when not overridden by
+// NewTagBalancedSelector.
+const defaultTagBalanceWindow = 5
+
+// ChallengeCandidate is a problem eligible to become the next daily
+// challenge, along with the history a ChallengeSelector needs to weigh it.
+type ChallengeCandidate struct {
+	Problem models.Problem
+	// LastUsed is nil if the problem has never been a daily challenge.
+	LastUsed *time.Time
+	// SolveRate is the problem's global acceptance rate (0-100) across all
+	// accepted and non-accepted submissions. Only meaningful when
+	// HasSubmissions is true.
+	SolveRate      float64
+	HasSubmissions bool
+}
+
+// ChallengeSelector picks the next daily challenge from a pool of
+// candidates. Implementations are pure functions of (candidates, recent
+// history) so DailyChallengeService.PreviewSelections can run them
+// repeatedly against a shrinking pool without touching the database.
+type ChallengeSelector interface {
+	// Name identifies the strategy, e.g. for the preview endpoint's
+	// ?strategy= query parameter.
+	Name() string
+	// Select picks one candidate. recent is the most-recently-used daily
+	// challenges, most recent first, for strategies that need more context
+	// than a single LastUsed timestamp (e.g. tag-balanced).
+	Select(candidates []ChallengeCandidate, recent []models.DailyChallenge) (ChallengeCandidate, error)
+}
+
+// SelectorByName resolves a strategy name (as taken from a query parameter
+// or config value) to a ChallengeSelector. An empty name defaults to
+// round-robin, preserving the previous behavior.
+func SelectorByName(name string) (ChallengeSelector, error) {
+	switch name {
+	case "", "round_robin":
+		return NewRoundRobinSelector(), nil
+	case "weighted_random":
+		return NewWeightedRandomSelector(), nil
+	case "spaced_repetition":
+		return NewSpacedRepetitionSelector(), nil
+	case "tag_balanced":
+		return NewTagBalancedSelector(defaultTagBalanceWindow), nil
+	default:
+		return nil, fmt.Errorf("unknown challenge selection strategy %q", name)
+	}
+}
+
+// RoundRobinSelector picks the problem that's never been a daily challenge
+// (lowest ID first), falling back to whichever problem was used longest
+// ago. This is the original SelectNextChallenge behavior.
+type RoundRobinSelector struct{}
+
+// NewRoundRobinSelector creates a RoundRobinSelector.
+func NewRoundRobinSelector() *RoundRobinSelector { return &RoundRobinSelector{} }
+
+func (s *RoundRobinSelector) Name() string { return "round_robin" }
+
+func (s *RoundRobinSelector) Select(candidates []ChallengeCandidate, _ []models.DailyChallenge) (ChallengeCandidate, error) {
+	if len(candidates) == 0 {
+		return ChallengeCandidate{}, ErrNoProblems
+	}
+
+	var neverUsed *ChallengeCandidate
+	for i := range candidates {
+		if candidates[i].LastUsed != nil {
+			continue
+		}
+		if neverUsed == nil || candidates[i].Problem.ID < neverUsed.Problem.ID {
+			neverUsed = &candidates[i]
+		}
+	}
+	if neverUsed != nil {
+		return *neverUsed, nil
+	}
+
+	oldest := candidates[0]
+	for _, c := range candidates[1:] {
+		switch {
+		case c.LastUsed.Before(*oldest.LastUsed):
+			oldest = c
+		case c.LastUsed.Equal(*oldest.LastUsed) && c.Problem.ID < oldest.Problem.ID:
+			oldest = c
+		}
+	}
+	return oldest, nil
+}
+
+// WeightedRandomSelector picks randomly, weighted toward harder problems
+// and problems fewer people solve - the idea being a daily challenge should
+// skew slightly toward problems worth the community's attention.
+type WeightedRandomSelector struct{}
+
+// NewWeightedRandomSelector creates a WeightedRandomSelector.
+func NewWeightedRandomSelector() *WeightedRandomSelector { return &WeightedRandomSelector{} }
+
+func (s *WeightedRandomSelector) Name() string { return "weighted_random" }
+
+var difficultyWeights = map[string]float64{
+	"easy":   1.0,
+	"medium": 1.5,
+	"hard":   2.0,
+}
+
+func (s *WeightedRandomSelector) Select(candidates []ChallengeCandidate, _ []models.DailyChallenge) (ChallengeCandidate, error) {
+	return weightedPick(candidates, func(c ChallengeCandidate) float64 {
+		difficulty := difficultyWeights[c.Problem.Difficulty]
+		if difficulty == 0 {
+			difficulty = 1.0
+		}
+
+		// Problems with no submission history yet are treated as average
+		// difficulty to solve, so they're neither over- nor under-favored.
+		solveRate := 50.0
+		if c.HasSubmissions {
+			solveRate = c.SolveRate
+		}
+
+		return difficulty * (100 - solveRate + 1) / 100
+	})
+}
+
+// SpacedRepetitionSelector biases toward problems that have been dormant
+// longest and problems whose global success rate has dropped, using an
+// exponential recency weight w = exp(-Δdays/τ) inverted (1-w) so the
+// longer a problem has been dormant the more it dominates the pick.
+type SpacedRepetitionSelector struct{}
+
+// NewSpacedRepetitionSelector creates a SpacedRepetitionSelector.
+func NewSpacedRepetitionSelector() *SpacedRepetitionSelector { return &SpacedRepetitionSelector{} }
+
+func (s *SpacedRepetitionSelector) Name() string { return "spaced_repetition" }
+
+func (s *SpacedRepetitionSelector) Select(candidates []ChallengeCandidate, _ []models.DailyChallenge) (ChallengeCandidate, error) {
+	return weightedPick(candidates, func(c ChallengeCandidate) float64 {
+		successRateWeight := 0.5
+		if c.HasSubmissions {
+			successRateWeight = (100 - c.SolveRate) / 100
+		}
+		return dormancyWeight(c) + successRateWeight
+	})
+}
+
+// dormancyWeight returns a problem's recency weight: 0 for one used today,
+// approaching 1 the longer it's gone unused, and 1 for one never used.
+func dormancyWeight(c ChallengeCandidate) float64 {
+	if c.LastUsed == nil {
+		return 1
+	}
+	deltaDays := time.Since(*c.LastUsed).Hours() / 24
+	if deltaDays < 0 {
+		deltaDays = 0
+	}
+	return 1 - math.Exp(-deltaDays/spacedRepetitionTau)
+}
+
+// TagBalancedSelector rotates through problem tags/categories so the same
+// topic doesn't repeat within a configurable window of recent challenges.
+type TagBalancedSelector struct {
+	// Window is how many of the most recent daily challenges to avoid
+	// repeating tags from.
+	Window int
+}
+
+// NewTagBalancedSelector creates a TagBalancedSelector that avoids repeating
+// tags used in the last window challenges. window <= 0 uses
+// defaultTagBalanceWindow.
+func NewTagBalancedSelector(window int) *TagBalancedSelector {
+	if window <= 0 {
+		window = defaultTagBalanceWindow
+	}
+	return &TagBalancedSelector{Window: window}
+}
+
+func (s *TagBalancedSelector) Name() string { return "tag_balanced" }
+
+func (s *TagBalancedSelector) Select(candidates []ChallengeCandidate, recent []models.DailyChallenge) (ChallengeCandidate, error) {
+	if len(candidates) == 0 {
+		return ChallengeCandidate{}, ErrNoProblems
+	}
+
+	window := s.Window
+	if window > len(recent) {
+		window = len(recent)
+	}
+
+	recentTags := make(map[string]bool)
+	for _, challenge := range recent[:window] {
+		for _, tag := range challenge.Problem.Tags {
+			recentTags[tag] = true
+		}
+	}
+
+	eligible := make([]ChallengeCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if !tagsOverlap(c.Problem.Tags, recentTags) {
+			eligible = append(eligible, c)
+		}
+	}
+	if len(eligible) == 0 {
+		// Every remaining problem shares a recently-used tag - fall back to
+		// the full pool rather than refusing to pick anything.
+		eligible = candidates
+	}
+
+	return weightedPick(eligible, dormancyWeight)
+}
+
+func tagsOverlap(tags []string, seen map[string]bool) bool {
+	for _, tag := range tags {
+		if seen[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedPick selects one candidate at random, weighted by weightFn.
+// Candidates with a non-positive total weight fall back to a uniform
+// random pick rather than erroring out.
+func weightedPick(candidates []ChallengeCandidate, weightFn func(ChallengeCandidate) float64) (ChallengeCandidate, error) {
+	if len(candidates) == 0 {
+		return ChallengeCandidate{}, ErrNoProblems
+	}
+
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, c := range candidates {
+		w := weightFn(c)
+		if w < 0 {
+			w = 0
+		}
+		weights[i] = w
+		total += w
+	}
+
+	if total <= 0 {
+		return candidates[rand.Intn(len(candidates))], nil
+	}
+
+	target := rand.Float64() * total
+	var cumulative float64
+	for i, w := range weights {
+		cumulative += w
+		if target <= cumulative {
+			return candidates[i], nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+const (
+	// codeSimKgramSize is how many tokens make up one k-gram before hashing.
+	codeSimKgramSize = 5
+	// codeSimWindowSize is how many consecutive k-gram hashes Winnowing
+	// selects the minimum from.
+	codeSimWindowSize = 4
+	// codeSimJaccardThreshold is the Jaccard similarity above which
+	// AnalyzeSimilarity flags a submission under FlagReasonCodeSimilarity.
+	codeSimJaccardThreshold = 0.8
+	// codeSimQueueSize bounds EnqueueSimilarityAnalysis's backlog - past
+	// this, background analysis is skipped rather than blocking the caller.
+	codeSimQueueSize = 100
+	// codeSimRollingBase is the multiplier used by rollingKgramHashes'
+	// polynomial rolling hash. It doesn't need to be prime: fingerprinting
+	// isn't cryptographic, it just needs collisions to be rare in practice.
+	codeSimRollingBase uint64 = 1000003003
+	// codeSimDefaultTopK bounds FindSimilarSubmissions' result count when
+	// the caller doesn't specify a limit.
+	codeSimDefaultTopK = 5
+	// codeSimRegionGap is the largest gap, in k-gram positions, between two
+	// matched fingerprints still considered part of the same matched
+	// region by clusterMatchedRegions - anything wider is a separate patch
+	// of shared code rather than a continuation of the same one.
+	codeSimRegionGap = 10
+)
+
+var (
+	codeSimBlockComment = regexp.MustCompile(`/\*[\s\S]*?\*/`)
+	codeSimLineComment  = regexp.MustCompile(`(//|#)[^\n]*`)
+	codeSimIdentifier   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	codeSimNumber       = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	codeSimString       = regexp.MustCompile("^(\".*\"|'.*'|`.*`)$")
+	codeSimTokenPattern = regexp.MustCompile(
+		"\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^'\\\\]|\\\\.)*'|`(?:[^`\\\\]|\\\\.)*`" +
+			`|[A-Za-z_][A-Za-z0-9_]*|[0-9]+(?:\.[0-9]+)?|[^\sA-Za-z0-9_]`,
+	)
+)
+
+// LanguageFrontend turns a submission's raw source into a normalized token
+// stream for fingerprinting: comments and whitespace stripped, identifiers
+// canonicalized to "ID" and literals to "LIT" so renamed variables and
+// changed constants don't change the fingerprint, while keywords and
+// punctuation are kept as-is so the token stream still reflects the code's
+// actual structure. Register a language-specific frontend with
+// RegisterLanguageFrontend; a language without one falls back to
+// genericFrontend's keyword-less canonicalization.
+type LanguageFrontend interface {
+	Tokenize(source string) []string
+}
+
+// keywordFrontend is a LanguageFrontend parameterized by a language's
+// reserved words - one tokenizer implementation serves every language
+// below, differing only in which identifier-shaped tokens it keeps literal.
+type keywordFrontend struct {
+	keywords map[string]struct{}
+}
+
+// Tokenize implements LanguageFrontend.
+func (f keywordFrontend) Tokenize(source string) []string {
+	source = codeSimBlockComment.ReplaceAllString(source, " ")
+	source = codeSimLineComment.ReplaceAllString(source, " ")
+
+	raw := codeSimTokenPattern.FindAllString(source, -1)
+	tokens := make([]string, 0, len(raw))
+	for _, t := range raw {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		tokens = append(tokens, f.canonicalize(t))
+	}
+	return tokens
+}
+
+// canonicalize reduces a single raw token to "LIT" for string/numeric
+// literals, "ID" for an identifier that isn't one of f's keywords, or the
+// token itself (lowercased for keywords) otherwise.
+func (f keywordFrontend) canonicalize(token string) string {
+	switch {
+	case codeSimString.MatchString(token), codeSimNumber.MatchString(token):
+		return "LIT"
+	case codeSimIdentifier.MatchString(token):
+		if _, isKeyword := f.keywords[strings.ToLower(token)]; isKeyword {
+			return strings.ToLower(token)
+		}
+		return "ID"
+	default:
+		return token
+	}
+}
+
+func keywordSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[strings.ToLower(w)] = struct{}{}
+	}
+	return set
+}
+
+var (
+	cLikeKeywords = keywordSet("if", "else", "for", "while", "do", "return", "break", "continue",
+		"switch", "case", "default", "class", "struct", "function", "public", "private", "protected",
+		"static", "void", "int", "float", "double", "string", "bool", "true", "false", "null", "new",
+		"import", "package", "var", "let", "const")
+
+	pythonKeywords = keywordSet("if", "elif", "else", "for", "while", "return", "break", "continue",
+		"def", "class", "import", "from", "as", "pass", "lambda", "with", "try", "except", "finally",
+		"raise", "yield", "and", "or", "not", "in", "is", "true", "false", "none", "self")
+
+	goKeywords = keywordSet("if", "else", "for", "range", "return", "break", "continue", "switch",
+		"case", "default", "func", "package", "import", "var", "const", "type", "struct", "interface",
+		"map", "chan", "go", "defer", "select", "nil", "true", "false")
+)
+
+// genericFrontend is the fallback LanguageFrontend for a language with no
+// registered keyword set: every identifier-shaped token canonicalizes to
+// "ID", which is a strictly rougher fingerprint but still shaped by the
+// language's literals and punctuation.
+var genericFrontend = keywordFrontend{}
+
+// languageFrontends maps a judge language name to its LanguageFrontend.
+var languageFrontends = map[string]LanguageFrontend{
+	"python":     keywordFrontend{keywords: pythonKeywords},
+	"javascript": keywordFrontend{keywords: cLikeKeywords},
+	"typescript": keywordFrontend{keywords: cLikeKeywords},
+	"java":       keywordFrontend{keywords: cLikeKeywords},
+	"c":          keywordFrontend{keywords: cLikeKeywords},
+	"cpp":        keywordFrontend{keywords: cLikeKeywords},
+	"go":         keywordFrontend{keywords: goKeywords},
+}
+
+// RegisterLanguageFrontend adds or replaces the LanguageFrontend used for
+// language by AnalyzeSimilarity's tokenization pass. Exposed so a language
+// added to the judge later isn't stuck with genericFrontend's keyword-less
+// canonicalization.
+func RegisterLanguageFrontend(language string, frontend LanguageFrontend) {
+	languageFrontends[strings.ToLower(language)] = frontend
+}
+
+// frontendFor returns language's registered LanguageFrontend, or
+// genericFrontend if none is registered.
+func frontendFor(language string) LanguageFrontend {
+	if frontend, ok := languageFrontends[strings.ToLower(language)]; ok {
+		return frontend
+	}
+	return genericFrontend
+}
+
+// tokenHash hashes a single canonicalized token with FNV-64a.
+func tokenHash(token string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(token))
+	return h.Sum64()
+}
+
+// rollingKgramHashes computes a polynomial rolling hash for every
+// overlapping window of k consecutive tokens in O(len(tokens)): each hash is
+// derived from the previous one by subtracting the outgoing token's
+// contribution and folding in the incoming token, rather than rehashing the
+// whole window from scratch.
+func rollingKgramHashes(tokens []string, k int) []uint64 {
+	if k <= 0 || len(tokens) < k {
+		return nil
+	}
+
+	tokenHashes := make([]uint64, len(tokens))
+	for i, t := range tokens {
+		tokenHashes[i] = tokenHash(t)
+	}
+
+	var highOrder uint64 = 1
+	for i := 0; i < k-1; i++ {
+		highOrder *= codeSimRollingBase
+	}
+
+	var h uint64
+	for i := 0; i < k; i++ {
+		h = h*codeSimRollingBase + tokenHashes[i]
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-k+1)
+	hashes = append(hashes, h)
+	for i := k; i < len(tokens); i++ {
+		h = (h-tokenHashes[i-k]*highOrder)*codeSimRollingBase + tokenHashes[i]
+		hashes = append(hashes, h)
+	}
+
+	return hashes
+}
+
+// codeFingerprint is one Winnowing-selected (hash, position) pair.
+type codeFingerprint struct {
+	hash     uint64
+	position int
+}
+
+// winnowFingerprints keeps the minimum of every sliding window of w
+// consecutive k-gram hashes, breaking ties by the rightmost position. That
+// tie-break, plus rollingKgramHashes being a pure function of the token
+// stream, is what makes the same source always winnow to the same
+// fingerprint set - the determinism AnalyzeSimilarity's Jaccard comparison
+// depends on.
+func winnowFingerprints(hashes []uint64, w int) []codeFingerprint {
+	if len(hashes) == 0 {
+		return nil
+	}
+	if w < 1 || w > len(hashes) {
+		w = len(hashes)
+	}
+
+	seen := make(map[uint64]struct{}, len(hashes))
+	fingerprints := make([]codeFingerprint, 0)
+	prevPos := -1
+
+	for start := 0; start+w <= len(hashes); start++ {
+		minPos := start
+		for i := start + 1; i < start+w; i++ {
+			if hashes[i] <= hashes[minPos] {
+				minPos = i
+			}
+		}
+
+		if minPos != prevPos {
+			if _, ok := seen[hashes[minPos]]; !ok {
+				seen[hashes[minPos]] = struct{}{}
+				fingerprints = append(fingerprints, codeFingerprint{hash: hashes[minPos], position: minPos})
+			}
+			prevPos = minPos
+		}
+	}
+
+	return fingerprints
+}
+
+// fingerprintSource runs language's registered LanguageFrontend over source
+// and reduces the result to a Winnowing fingerprint set.
+func fingerprintSource(language, source string) []codeFingerprint {
+	tokens := frontendFor(language).Tokenize(source)
+	hashes := rollingKgramHashes(tokens, codeSimKgramSize)
+	return winnowFingerprints(hashes, codeSimWindowSize)
+}
+
+// SimilarityJob is one submission queued for background analysis by
+// RunSimilarityWorker. AnalyzeSimilarity has no uint-keyed submissions table
+// of its own to look up from - FlaggedSubmission/SubmissionFingerprint key
+// off a plain uint submission_id supplied by the caller, same as the rest
+// of this package - so the job carries everything AnalyzeSimilarity needs
+// rather than just an ID.
+type SimilarityJob struct {
+	SubmissionID uint
+	UserID       uint
+	ProblemID    uint
+	Language     string
+	Code         string
+}
+
+// EnqueueSimilarityAnalysis submits job for background analysis by
+// RunSimilarityWorker, returning ErrQueueFull if the queue's buffer is
+// already full. Callers on the accepted-submission path should treat that
+// as "skip background analysis this time" rather than blocking on it.
+func (s *AntiCheatService) EnqueueSimilarityAnalysis(job SimilarityJob) error {
+	select {
+	case s.similarityQueue <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// RunSimilarityWorker pulls jobs off the similarity queue and runs
+// AnalyzeSimilarity for each until ctx is cancelled. Intended to run in its
+// own goroutine, started once alongside FlagBroker.Run.
+func (s *AntiCheatService) RunSimilarityWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.similarityQueue:
+			_ = s.AnalyzeSimilarity(ctx, job.SubmissionID, job.UserID, job.ProblemID, job.Language, job.Code)
+		}
+	}
+}
+
+// storeFingerprints fingerprints code with language's registered
+// LanguageFrontend, dedupes by hash, and persists one
+// models.SubmissionFingerprint row per distinct hash for submissionID. It
+// returns the deduped hashes so a caller can run its own comparison pass
+// (AnalyzeSimilarity's flagging, or RebuildIndex's plain re-indexing)
+// without re-deriving them.
+func (s *AntiCheatService) storeFingerprints(submissionID, userID, problemID uint, language, code string) ([]uint64, error) {
+	fingerprints := fingerprintSource(language, code)
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+
+	seenHashes := make(map[uint64]struct{}, len(fingerprints))
+	rows := make([]models.SubmissionFingerprint, 0, len(fingerprints))
+	for _, fp := range fingerprints {
+		if _, ok := seenHashes[fp.hash]; ok {
+			continue
+		}
+		seenHashes[fp.hash] = struct{}{}
+		rows = append(rows, models.SubmissionFingerprint{
+			ProblemID:    problemID,
+			Fingerprint:  fp.hash,
+			Position:     fp.position,
+			SubmissionID: submissionID,
+			UserID:       userID,
+		})
+	}
+	if err := s.db.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to store similarity fingerprints: %w", err)
+	}
+
+	hashes := make([]uint64, 0, len(seenHashes))
+	for hash := range seenHashes {
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// AnalyzeSimilarity fingerprints code with language's registered
+// LanguageFrontend, stores the fingerprints, then compares them against
+// every other user's fingerprints for the same problemID. Any match whose
+// Jaccard similarity exceeds codeSimJaccardThreshold flags submissionID
+// under FlagReasonCodeSimilarity, with the matched submission's ID and the
+// similarity score recorded in the flag's details. Safe to call
+// synchronously (e.g. from a handler) or from RunSimilarityWorker.
+func (s *AntiCheatService) AnalyzeSimilarity(ctx context.Context, submissionID, userID, problemID uint, language, code string) error {
+	hashes, err := s.storeFingerprints(submissionID, userID, problemID, language, code)
+	if err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	type matchRow struct {
+		SubmissionID uint
+		UserID       uint
+		Shared       int64
+	}
+	var matches []matchRow
+	if err := s.db.Model(&models.SubmissionFingerprint{}).
+		Select("submission_id, user_id, COUNT(DISTINCT fingerprint) as shared").
+		Where("problem_id = ? AND fingerprint IN ? AND submission_id != ? AND user_id != ?", problemID, hashes, submissionID, userID).
+		Group("submission_id, user_id").
+		Scan(&matches).Error; err != nil {
+		return fmt.Errorf("failed to find similarity matches: %w", err)
+	}
+
+	for _, match := range matches {
+		var otherSize int64
+		if err := s.db.Model(&models.SubmissionFingerprint{}).
+			Where("submission_id = ?", match.SubmissionID).
+			Distinct("fingerprint").
+			Count(&otherSize).Error; err != nil {
+			return fmt.Errorf("failed to count matched submission's fingerprints: %w", err)
+		}
+
+		union := int64(len(hashes)) + otherSize - match.Shared
+		if union == 0 {
+			continue
+		}
+		jaccard := float64(match.Shared) / float64(union)
+		if jaccard < codeSimJaccardThreshold {
+			continue
+		}
+
+		details := map[string]interface{}{
+			"matched_submission_id": match.SubmissionID,
+			"matched_user_id":       match.UserID,
+			"similarity":            jaccard,
+		}
+		if err := s.FlagSubmission(ctx, submissionID, userID, problemID, models.FlagReasonCodeSimilarity, details); err != nil {
+			return fmt.Errorf("failed to flag code similarity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MatchedRegion is one contiguous run of submissionID's k-gram positions
+// (see clusterMatchedRegions) whose fingerprints also appear in a candidate
+// submission - i.e. a patch of code the two submissions likely share.
+type MatchedRegion struct {
+	StartPosition int `json:"start_position"`
+	EndPosition   int `json:"end_position"`
+	SharedCount   int `json:"shared_count"`
+}
+
+// SimilarSubmission is one other submission to the same problem found by
+// FindSimilarSubmissions, with its Jaccard similarity against the submission
+// being queried and the regions of that submission's source the two share.
+type SimilarSubmission struct {
+	SubmissionID       uint            `json:"submission_id"`
+	UserID             uint            `json:"user_id"`
+	Jaccard            float64         `json:"jaccard_similarity"`
+	SharedFingerprints int             `json:"shared_fingerprints"`
+	MatchedRegions     []MatchedRegion `json:"matched_regions"`
+}
+
+// FindSimilarSubmissions returns the top-K (limit, or codeSimDefaultTopK if
+// <= 0) other submissions to submissionID's problem whose Jaccard similarity
+// against it is at least threshold, sorted by similarity descending. Unlike
+// AnalyzeSimilarity, this only reads already-stored fingerprints - it
+// neither fingerprints new code nor flags anything - so it's safe to call
+// from an unauthenticated read endpoint.
+func (s *AntiCheatService) FindSimilarSubmissions(submissionID uint, threshold float64, limit int) ([]SimilarSubmission, error) {
+	if limit <= 0 {
+		limit = codeSimDefaultTopK
+	}
+
+	var own []models.SubmissionFingerprint
+	if err := s.db.Where("submission_id = ?", submissionID).Find(&own).Error; err != nil {
+		return nil, fmt.Errorf("failed to load submission fingerprints: %w", err)
+	}
+	if len(own) == 0 {
+		return nil, nil
+	}
+	problemID := own[0].ProblemID
+
+	positionsByHash := make(map[uint64][]int, len(own))
+	hashes := make([]uint64, 0, len(own))
+	for _, fp := range own {
+		if _, ok := positionsByHash[fp.Fingerprint]; !ok {
+			hashes = append(hashes, fp.Fingerprint)
+		}
+		positionsByHash[fp.Fingerprint] = append(positionsByHash[fp.Fingerprint], fp.Position)
+	}
+	ownSize := len(hashes)
+
+	var others []models.SubmissionFingerprint
+	if err := s.db.Where("problem_id = ? AND fingerprint IN ? AND submission_id != ?", problemID, hashes, submissionID).
+		Find(&others).Error; err != nil {
+		return nil, fmt.Errorf("failed to find similarity matches: %w", err)
+	}
+
+	type candidate struct {
+		userID    uint
+		positions map[int]struct{}
+	}
+	candidates := make(map[uint]*candidate)
+	for _, fp := range others {
+		c, ok := candidates[fp.SubmissionID]
+		if !ok {
+			c = &candidate{userID: fp.UserID, positions: make(map[int]struct{})}
+			candidates[fp.SubmissionID] = c
+		}
+		for _, pos := range positionsByHash[fp.Fingerprint] {
+			c.positions[pos] = struct{}{}
+		}
+	}
+
+	results := make([]SimilarSubmission, 0, len(candidates))
+	for candidateID, c := range candidates {
+		var otherSize int64
+		if err := s.db.Model(&models.SubmissionFingerprint{}).
+			Where("submission_id = ?", candidateID).
+			Distinct("fingerprint").
+			Count(&otherSize).Error; err != nil {
+			return nil, fmt.Errorf("failed to count candidate fingerprints: %w", err)
+		}
+
+		shared := len(c.positions)
+		union := ownSize + int(otherSize) - shared
+		var jaccard float64
+		if union > 0 {
+			jaccard = float64(shared) / float64(union)
+		}
+		if jaccard < threshold {
+			continue
+		}
+
+		results = append(results, SimilarSubmission{
+			SubmissionID:       candidateID,
+			UserID:             c.userID,
+			Jaccard:            jaccard,
+			SharedFingerprints: shared,
+			MatchedRegions:     clusterMatchedRegions(c.positions),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Jaccard > results[j].Jaccard })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// clusterMatchedRegions sorts positions ascending and folds consecutive
+// positions no more than codeSimRegionGap apart into a single MatchedRegion,
+// turning a flat set of shared fingerprint positions into the patches of
+// source they actually correspond to.
+func clusterMatchedRegions(positions map[int]struct{}) []MatchedRegion {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	sorted := make([]int, 0, len(positions))
+	for pos := range positions {
+		sorted = append(sorted, pos)
+	}
+	sort.Ints(sorted)
+
+	regions := []MatchedRegion{{StartPosition: sorted[0], EndPosition: sorted[0], SharedCount: 1}}
+	for _, pos := range sorted[1:] {
+		last := &regions[len(regions)-1]
+		if pos-last.EndPosition <= codeSimRegionGap {
+			last.EndPosition = pos
+			last.SharedCount++
+			continue
+		}
+		regions = append(regions, MatchedRegion{StartPosition: pos, EndPosition: pos, SharedCount: 1})
+	}
+
+	return regions
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// icpcPenaltyMinutes is how many minutes ICPC scoring (scoreICPC) adds to
+// a user's cumulative time for each wrong attempt at a problem before they
+// solve it.
+const icpcPenaltyMinutes = 20
+
+// ContestService handles contest registration and contest-scoped
+// leaderboards/history on top of SubmissionService's submission records.
+// Library code only: the ticket this shipped under scoped the work to
+// these service methods, not contest-management or leaderboard routes, so
+// nothing under cmd/ constructs a ContestService yet - that's follow-up
+// work once the admin-facing contest CRUD flow is designed.
+// TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions
+// (submission_pipeline_integration_test.go) covers GetContestLeaderboard
+// reading real rows JudgeQueue wrote, so the schema the two agree on is
+// pinned down even without HTTP wiring.
+type ContestService struct {
+	db *gorm.DB
+}
+
+// NewContestService creates a new contest service instance.
+func NewContestService(db *gorm.DB) *ContestService {
+	return &ContestService{db: db}
+}
+
+// CreateContest persists contest.
+func (s *ContestService) CreateContest(contest *models.Contest) error {
+	if err := s.db.Create(contest).Error; err != nil {
+		return fmt.Errorf("failed to create contest: %w", err)
+	}
+	return nil
+}
+
+// AddProblem adds problemID to contestID's problem set.
+func (s *ContestService) AddProblem(contestID, problemID uint) error {
+	contest := models.Contest{ID: contestID}
+	problem := models.Problem{ID: problemID}
+	if err := s.db.Model(&contest).Association("Problems").Append(&problem); err != nil {
+		return fmt.Errorf("failed to add problem %d to contest %d: %w", problemID, contestID, err)
+	}
+	return nil
+}
+
+// RegisterParticipant registers userID for contestID. Registering twice is
+// a no-op rather than an error.
+func (s *ContestService) RegisterParticipant(contestID, userID uint) error {
+	participant := models.ContestParticipant{ContestID: contestID, UserID: userID}
+	err := s.db.Where("contest_id = ? AND user_id = ?", contestID, userID).FirstOrCreate(&participant).Error
+	if err != nil {
+		return fmt.Errorf("failed to register participant: %w", err)
+	}
+	return nil
+}
+
+// ResolveActiveContestID returns the ID (stringified, matching
+// models.Submission.ContestID) of the contest that's running at t and has
+// problemID in its problem set, or nil if there isn't one. Called when a
+// submission is created so it can be stamped with the contest it belongs
+// to. If more than one contest's window and problem set both match - an
+// unusual setup - the earliest-starting one wins.
+func (s *ContestService) ResolveActiveContestID(problemID uint, t time.Time) (*string, error) {
+	var contests []models.Contest
+	err := s.db.
+		Joins("JOIN contest_problems ON contest_problems.contest_id = contests.id").
+		Where("contest_problems.problem_id = ? AND contests.start_at <= ? AND contests.end_at >= ?", problemID, t, t).
+		Order("contests.start_at ASC").
+		Find(&contests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve active contest: %w", err)
+	}
+	if len(contests) == 0 {
+		return nil, nil
+	}
+
+	id := strconv.FormatUint(uint64(contests[0].ID), 10)
+	return &id, nil
+}
+
+// PaginationParams is a generic page/page-size request, clamped the same
+// way SubmissionHistoryParams.Page/PageSize are.
+type PaginationParams struct {
+	Page     int
+	PageSize int
+}
+
+func (p PaginationParams) normalize() (page, pageSize int) {
+	page = p.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize = p.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// ContestLeaderboardEntry is one user's ranked standing in a contest.
+// PenaltyMinutes and Score are mutually exclusive depending on
+// Contest.ScoringMode: ICPC populates PenaltyMinutes (lower is better),
+// IOI populates Score (higher is better).
+type ContestLeaderboardEntry struct {
+	Rank           int        `json:"rank"`
+	UserID         uint       `json:"user_id"`
+	ProblemsSolved int        `json:"problems_solved"`
+	PenaltyMinutes int64      `json:"penalty_minutes,omitempty"`
+	Score          float64    `json:"score,omitempty"`
+	LastAcceptedAt *time.Time `json:"last_accepted_at,omitempty"`
+}
+
+// ContestLeaderboardPage is a paginated, ranked ContestLeaderboardEntry
+// list, in the same Page/PageSize/Total/TotalPages shape as
+// models.LeaderboardPage.
+type ContestLeaderboardPage struct {
+	Entries    []ContestLeaderboardEntry `json:"entries"`
+	ContestID  uint                      `json:"contest_id"`
+	Page       int                       `json:"page"`
+	PageSize   int                       `json:"page_size"`
+	Total      int64                     `json:"total"`
+	TotalPages int                       `json:"total_pages"`
+}
+
+// contestSubmissionRow is what GetContestLeaderboard needs from each
+// submission to a contest's problems: just enough to score and tie-break
+// without loading Code/ErrorMessage/etc.
+type contestSubmissionRow struct {
+	UserID      string
+	ProblemID   string
+	Status      string
+	CreatedAt   time.Time
+	TestsPassed int
+	TestsTotal  int
+}
+
+// GetContestLeaderboard computes and paginates contestID's standings per
+// Contest.ScoringMode. While the contest is still running and within its
+// freeze window (see Contest.FreezeAt), only submissions made before the
+// freeze cutoff count, so the leaderboard doesn't reveal movement from the
+// last FreezeMinutes - the same blackout real onsite scoreboards use. Once
+// the contest has ended, the freeze no longer applies and every submission
+// counts.
+func (s *ContestService) GetContestLeaderboard(contestID uint, params PaginationParams) (*ContestLeaderboardPage, error) {
+	var contest models.Contest
+	if err := s.db.Preload("Problems").First(&contest, contestID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load contest %d: %w", contestID, err)
+	}
+
+	cutoff := contest.EndAt
+	if now := time.Now(); now.Before(contest.EndAt) {
+		freezeAt := contest.FreezeAt()
+		if now.After(freezeAt) {
+			cutoff = freezeAt
+		} else {
+			cutoff = now
+		}
+	}
+
+	contestIDStr := strconv.FormatUint(uint64(contestID), 10)
+	var rows []contestSubmissionRow
+	if err := s.db.Model(&models.Submission{}).
+		Select("user_id, problem_id, status, created_at, tests_passed, tests_total").
+		Where("contest_id = ? AND created_at <= ?", contestIDStr, cutoff).
+		Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load contest submissions: %w", err)
+	}
+
+	var entries []ContestLeaderboardEntry
+	switch contest.ScoringMode {
+	case models.ScoringIOI:
+		entries = scoreIOI(rows)
+	default:
+		entries = scoreICPC(rows, contest.StartAt)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return contestEntryLess(entries[j], entries[i], contest.ScoringMode)
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	page, pageSize := params.normalize()
+	total := int64(len(entries))
+	start := (page - 1) * pageSize
+	if start > len(entries) {
+		start = len(entries)
+	}
+	end := start + pageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	return &ContestLeaderboardPage{
+		Entries:    entries[start:end],
+		ContestID:  contestID,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// contestEntryLess reports whether a ranks below b: (ICPC) fewer problems
+// solved, then higher penalty, or (IOI) lower total score, then whoever
+// reached their final standing later.
+func contestEntryLess(a, b ContestLeaderboardEntry, mode models.ContestScoringMode) bool {
+	if mode == models.ScoringIOI {
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+	} else {
+		if a.ProblemsSolved != b.ProblemsSolved {
+			return a.ProblemsSolved < b.ProblemsSolved
+		}
+		if a.PenaltyMinutes != b.PenaltyMinutes {
+			return a.PenaltyMinutes > b.PenaltyMinutes
+		}
+	}
+	if a.LastAcceptedAt == nil || b.LastAcceptedAt == nil {
+		return a.LastAcceptedAt == nil && b.LastAcceptedAt != nil
+	}
+	return a.LastAcceptedAt.After(*b.LastAcceptedAt)
+}
+
+// scoreICPC groups rows by user and applies ICPC rules: a problem counts
+// as solved once the user has an accepted submission to it, contributing
+// its time-to-solve (AC time minus contestStart) plus icpcPenaltyMinutes
+// per wrong attempt at that problem before the AC.
+func scoreICPC(rows []contestSubmissionRow, contestStart time.Time) []ContestLeaderboardEntry {
+	type userState struct {
+		solved         map[string]bool
+		wrongBefore    map[string]int
+		penaltyMinutes int64
+		lastAccepted   *time.Time
+	}
+	states := make(map[string]*userState)
+	order := make([]string, 0)
+
+	for _, row := range rows {
+		state, ok := states[row.UserID]
+		if !ok {
+			state = &userState{solved: make(map[string]bool), wrongBefore: make(map[string]int)}
+			states[row.UserID] = state
+			order = append(order, row.UserID)
+		}
+		if state.solved[row.ProblemID] {
+			continue
+		}
+		if row.Status != models.StatusAccepted {
+			state.wrongBefore[row.ProblemID]++
+			continue
+		}
+		state.solved[row.ProblemID] = true
+		timeToSolve := row.CreatedAt.Sub(contestStart)
+		penalty := timeToSolve + time.Duration(state.wrongBefore[row.ProblemID])*icpcPenaltyMinutes*time.Minute
+		state.penaltyMinutes += int64(penalty / time.Minute)
+		createdAt := row.CreatedAt
+		state.lastAccepted = &createdAt
+	}
+
+	entries := make([]ContestLeaderboardEntry, 0, len(order))
+	for _, userID := range order {
+		state := states[userID]
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, ContestLeaderboardEntry{
+			UserID:         uint(id),
+			ProblemsSolved: len(state.solved),
+			PenaltyMinutes: state.penaltyMinutes,
+			LastAcceptedAt: state.lastAccepted,
+		})
+	}
+	return entries
+}
+
+// scoreIOI groups rows by user and applies IOI rules: each problem
+// contributes its best score (accepted test fraction, 0-100) across every
+// submission the user made to it.
+func scoreIOI(rows []contestSubmissionRow) []ContestLeaderboardEntry {
+	type userState struct {
+		bestByProb map[string]float64
+		solved     map[string]bool
+		lastScored *time.Time
+	}
+	states := make(map[string]*userState)
+	order := make([]string, 0)
+
+	for _, row := range rows {
+		state, ok := states[row.UserID]
+		if !ok {
+			state = &userState{bestByProb: make(map[string]float64), solved: make(map[string]bool)}
+			states[row.UserID] = state
+			order = append(order, row.UserID)
+		}
+
+		score := 0.0
+		switch {
+		case row.TestsTotal > 0:
+			score = float64(row.TestsPassed) / float64(row.TestsTotal) * 100
+		case row.Status == models.StatusAccepted:
+			score = 100
+		}
+
+		if score > state.bestByProb[row.ProblemID] {
+			state.bestByProb[row.ProblemID] = score
+			createdAt := row.CreatedAt
+			state.lastScored = &createdAt
+		}
+		if row.Status == models.StatusAccepted {
+			state.solved[row.ProblemID] = true
+		}
+	}
+
+	entries := make([]ContestLeaderboardEntry, 0, len(order))
+	for _, userID := range order {
+		state := states[userID]
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			continue
+		}
+		var total float64
+		for _, score := range state.bestByProb {
+			total += score
+		}
+		entries = append(entries, ContestLeaderboardEntry{
+			UserID:         uint(id),
+			ProblemsSolved: len(state.solved),
+			Score:          total,
+			LastAcceptedAt: state.lastScored,
+		})
+	}
+	return entries
+}
+
+// ContestHistoryEntry is one contest a user has submitted to, paired with
+// their standing in it.
+type ContestHistoryEntry struct {
+	Contest models.Contest          `json:"contest"`
+	Entry   ContestLeaderboardEntry `json:"entry"`
+}
+
+// ContestHistoryPage is a paginated, most-recently-started-first list of a
+// user's ContestHistoryEntry results.
+type ContestHistoryPage struct {
+	Entries    []ContestHistoryEntry `json:"entries"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	Total      int64                 `json:"total"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// GetUserContestHistory returns every contest userID has submitted to,
+// most recently started first, along with their final standing in each.
+func (s *ContestService) GetUserContestHistory(userID uint, params PaginationParams) (*ContestHistoryPage, error) {
+	userIDStr := strconv.FormatUint(uint64(userID), 10)
+
+	var contestIDStrs []string
+	if err := s.db.Model(&models.Submission{}).
+		Where("user_id = ? AND contest_id IS NOT NULL", userIDStr).
+		Distinct("contest_id").
+		Pluck("contest_id", &contestIDStrs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list contests for user: %w", err)
+	}
+
+	var contests []models.Contest
+	if len(contestIDStrs) > 0 {
+		if err := s.db.Where("id IN ?", contestIDStrs).Order("start_at DESC").Find(&contests).Error; err != nil {
+			return nil, fmt.Errorf("failed to load contests: %w", err)
+		}
+	}
+
+	page, pageSize := params.normalize()
+	total := int64(len(contests))
+	start := (page - 1) * pageSize
+	if start > len(contests) {
+		start = len(contests)
+	}
+	end := start + pageSize
+	if end > len(contests) {
+		end = len(contests)
+	}
+
+	entries := make([]ContestHistoryEntry, 0, end-start)
+	for _, contest := range contests[start:end] {
+		board, err := s.GetContestLeaderboard(contest.ID, PaginationParams{Page: 1, PageSize: 100000})
+		if err != nil {
+			return nil, err
+		}
+		var entry ContestLeaderboardEntry
+		for _, e := range board.Entries {
+			if e.UserID == userID {
+				entry = e
+				break
+			}
+		}
+		entries = append(entries, ContestHistoryEntry{Contest: contest, Entry: entry})
+	}
+
+	return &ContestHistoryPage{
+		Entries:    entries,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
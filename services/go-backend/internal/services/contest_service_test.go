@@ -0,0 +1,255 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupContestTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.Problem{}, &models.Submission{}, &models.Contest{}, &models.ContestParticipant{})
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+
+	return db
+}
+
+func createContestSubmission(t *testing.T, db *gorm.DB, userID uint, problemID uint, contestID uint, status string, testsPassed, testsTotal int, createdAt time.Time) {
+	t.Helper()
+	contestIDStr := strconv.FormatUint(uint64(contestID), 10)
+	submission := models.Submission{
+		ID:          uuid.NewString(),
+		UserID:      strconv.FormatUint(uint64(userID), 10),
+		ProblemID:   strconv.FormatUint(uint64(problemID), 10),
+		ContestID:   &contestIDStr,
+		Language:    "python",
+		Code:        "x",
+		Status:      status,
+		TestsPassed: testsPassed,
+		TestsTotal:  testsTotal,
+	}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create submission: %v", err)
+	}
+	if err := db.Model(&models.Submission{}).Where("id = ?", submission.ID).Update("created_at", createdAt).Error; err != nil {
+		t.Fatalf("failed to backdate submission: %v", err)
+	}
+}
+
+func TestGetContestLeaderboard_ICPCRanksBySolvedThenPenalty(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	start := time.Now().Add(-2 * time.Hour).UTC()
+	end := start.Add(3 * time.Hour)
+	contest := models.Contest{Name: "Cup", StartAt: start, EndAt: end, ScoringMode: models.ScoringICPC}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// User 1 solves problem 1 in 10 minutes, clean.
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusAccepted, 1, 1, start.Add(10*time.Minute))
+	// User 2 solves problem 1 in 5 minutes but with one wrong attempt first
+	// (costing a 20-minute penalty), netting a worse total than user 1.
+	createContestSubmission(t, db, 2, 1, contest.ID, models.StatusWrongAnswer, 0, 1, start.Add(2*time.Minute))
+	createContestSubmission(t, db, 2, 1, contest.ID, models.StatusAccepted, 1, 1, start.Add(5*time.Minute))
+
+	board, err := service.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(board.Entries))
+	}
+	if board.Entries[0].UserID != 1 {
+		t.Errorf("rank 1 = user %d, want user 1 (10 min beats 5 min + 20 min penalty)", board.Entries[0].UserID)
+	}
+	if board.Entries[0].Rank != 1 || board.Entries[1].Rank != 2 {
+		t.Errorf("ranks = %d, %d, want 1, 2", board.Entries[0].Rank, board.Entries[1].Rank)
+	}
+}
+
+func TestGetContestLeaderboard_ICPCTieBreaksByEarlierLastAccepted(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	start := time.Now().Add(-2 * time.Hour).UTC()
+	end := start.Add(3 * time.Hour)
+	contest := models.Contest{Name: "Cup", StartAt: start, EndAt: end, ScoringMode: models.ScoringICPC}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// Both users solve problem 1 clean (no wrong attempts), with
+	// time-to-solve rounding down to the same whole penalty minute (10),
+	// so ProblemsSolved and PenaltyMinutes tie exactly - only
+	// LastAcceptedAt differs, which is what this test exercises.
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusAccepted, 1, 1, start.Add(10*time.Minute))
+	createContestSubmission(t, db, 2, 1, contest.ID, models.StatusAccepted, 1, 1, start.Add(10*time.Minute+30*time.Second))
+
+	board, err := service.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(board.Entries))
+	}
+	if board.Entries[0].PenaltyMinutes != board.Entries[1].PenaltyMinutes {
+		t.Fatalf("expected a true tie on penalty minutes, got %d and %d", board.Entries[0].PenaltyMinutes, board.Entries[1].PenaltyMinutes)
+	}
+	if board.Entries[0].UserID != 1 {
+		t.Errorf("rank 1 = user %d, want user 1 (tied on penalty, reached final standing earlier)", board.Entries[0].UserID)
+	}
+	if board.Entries[0].Rank != 1 || board.Entries[1].Rank != 2 {
+		t.Errorf("ranks = %d, %d, want 1, 2", board.Entries[0].Rank, board.Entries[1].Rank)
+	}
+}
+
+func TestGetContestLeaderboard_IOISumsBestPerProblemScore(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	start := time.Now().Add(-2 * time.Hour).UTC()
+	end := start.Add(3 * time.Hour)
+	contest := models.Contest{Name: "IOI Cup", StartAt: start, EndAt: end, ScoringMode: models.ScoringIOI}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// User 1 gets partial credit twice on problem 1, best of the two counts.
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusWrongAnswer, 3, 10, start.Add(1*time.Minute))
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusWrongAnswer, 7, 10, start.Add(2*time.Minute))
+
+	board, err := service.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(board.Entries))
+	}
+	if board.Entries[0].Score != 70 {
+		t.Errorf("Score = %v, want 70 (best of 30/70)", board.Entries[0].Score)
+	}
+}
+
+func TestGetContestLeaderboard_ExcludesSubmissionsOutsideWindow(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	start := time.Now().Add(-2 * time.Hour).UTC()
+	end := start.Add(1 * time.Hour)
+	contest := models.Contest{Name: "Cup", StartAt: start, EndAt: end, ScoringMode: models.ScoringICPC}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// Inside the window - counts.
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusAccepted, 1, 1, start.Add(10*time.Minute))
+
+	// A submission after the contest ended still carries the contest's ID
+	// (e.g. stamped just before EndAt then judged late) but was created
+	// after the window closes, so GetContestLeaderboard's cutoff at EndAt
+	// must exclude it.
+	late := models.Submission{
+		ID:        uuid.NewString(),
+		UserID:    "2",
+		ProblemID: "1",
+		ContestID: func() *string { s := strconv.FormatUint(uint64(contest.ID), 10); return &s }(),
+		Language:  "python",
+		Code:      "x",
+		Status:    models.StatusAccepted,
+	}
+	if err := db.Create(&late).Error; err != nil {
+		t.Fatalf("failed to create late submission: %v", err)
+	}
+	if err := db.Model(&models.Submission{}).Where("id = ?", late.ID).Update("created_at", end.Add(time.Hour)).Error; err != nil {
+		t.Fatalf("failed to backdate late submission: %v", err)
+	}
+
+	board, err := service.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 1 {
+		t.Fatalf("expected only the in-window submission's user to appear, got %d entries", len(board.Entries))
+	}
+	if board.Entries[0].UserID != 1 {
+		t.Errorf("entry = user %d, want user 1", board.Entries[0].UserID)
+	}
+}
+
+func TestGetContestLeaderboard_FreezeHidesRecentSubmissions(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	// A contest still running, ending in 10 minutes, with a 15-minute
+	// freeze - so we're already inside the freeze window.
+	start := time.Now().Add(-50 * time.Minute).UTC()
+	end := time.Now().Add(10 * time.Minute).UTC()
+	contest := models.Contest{Name: "Cup", StartAt: start, EndAt: end, ScoringMode: models.ScoringICPC, FreezeMinutes: 15}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// Solved well before freeze - visible.
+	createContestSubmission(t, db, 1, 1, contest.ID, models.StatusAccepted, 1, 1, time.Now().Add(-30*time.Minute))
+	// Solved inside the freeze window - must not move the board yet.
+	createContestSubmission(t, db, 2, 1, contest.ID, models.StatusAccepted, 1, 1, time.Now().Add(-2*time.Minute))
+
+	board, err := service.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 1 {
+		t.Fatalf("expected the frozen submission to be hidden, got %d entries", len(board.Entries))
+	}
+	if board.Entries[0].UserID != 1 {
+		t.Errorf("entry = user %d, want user 1 (only the pre-freeze solve)", board.Entries[0].UserID)
+	}
+}
+
+func TestResolveActiveContestID_MatchesWindowAndProblem(t *testing.T) {
+	db := setupContestTestDB(t)
+	service := NewContestService(db)
+
+	start := time.Now().Add(-1 * time.Hour).UTC()
+	end := start.Add(2 * time.Hour)
+	contest := models.Contest{Name: "Cup", StartAt: start, EndAt: end}
+	if err := service.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+	if err := service.AddProblem(contest.ID, 1); err != nil {
+		t.Fatalf("AddProblem() error = %v", err)
+	}
+
+	contestID, err := service.ResolveActiveContestID(1, time.Now())
+	if err != nil {
+		t.Fatalf("ResolveActiveContestID() error = %v", err)
+	}
+	if contestID == nil || *contestID != strconv.FormatUint(uint64(contest.ID), 10) {
+		t.Errorf("ResolveActiveContestID() = %v, want %d", contestID, contest.ID)
+	}
+
+	// A problem not in the contest's set shouldn't resolve to it.
+	none, err := service.ResolveActiveContestID(999, time.Now())
+	if err != nil {
+		t.Fatalf("ResolveActiveContestID() error = %v", err)
+	}
+	if none != nil {
+		t.Errorf("ResolveActiveContestID() for unrelated problem = %v, want nil", none)
+	}
+}
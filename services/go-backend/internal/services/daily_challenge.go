@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/nahtao97/scribble/internal/models"
@@ -11,23 +12,46 @@ import (
 
 // Common errors for daily challenge service
 var (
-	ErrChallengeExists  = errors.New("daily challenge already exists for today")
-	ErrNoProblems       = errors.New("no problems available in database")
+	ErrChallengeExists = errors.New("daily challenge already exists for today")
+	ErrNoProblems      = errors.New("no problems available in database")
 )
 
+// recentChallengeWindow is how many of the most recent daily challenges are
+// loaded for selectors (like TagBalancedSelector) that need more context
+// than a single problem's LastUsed timestamp.
+const recentChallengeWindow = 10
+
+// DailyChallengeServiceConfig configures a DailyChallengeService's challenge
+// selection strategy.
+type DailyChallengeServiceConfig struct {
+	// Selector picks the next daily challenge from the candidate pool.
+	// Defaults to NewRoundRobinSelector(), preserving the original
+	// behavior, if nil.
+	Selector ChallengeSelector
+}
+
+// DefaultDailyChallengeServiceConfig returns the original round-robin
+// selection behavior.
+func DefaultDailyChallengeServiceConfig() DailyChallengeServiceConfig {
+	return DailyChallengeServiceConfig{Selector: NewRoundRobinSelector()}
+}
+
 // DailyChallengeService handles daily challenge selection and management
 type DailyChallengeService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	selector ChallengeSelector
 }
 
 // NewDailyChallengeService creates a new daily challenge service
-func NewDailyChallengeService(db *gorm.DB) *DailyChallengeService {
-	return &DailyChallengeService{db: db}
+func NewDailyChallengeService(db *gorm.DB, config DailyChallengeServiceConfig) *DailyChallengeService {
+	if config.Selector == nil {
+		config.Selector = NewRoundRobinSelector()
+	}
+	return &DailyChallengeService{db: db, selector: config.Selector}
 }
 
-// SelectNextChallenge selects the next problem for daily challenge
-// Uses round-robin: picks the problem that was least recently used as a daily challenge
-// If no problems have been used, picks the first problem by ID
+// SelectNextChallenge selects the next problem for the daily challenge
+// using the service's configured ChallengeSelector, and persists it.
 func (s *DailyChallengeService) SelectNextChallenge() (*models.DailyChallenge, error) {
 	today := time.Now().UTC().Truncate(24 * time.Hour)
 
@@ -41,40 +65,26 @@ func (s *DailyChallengeService) SelectNextChallenge() (*models.DailyChallenge, e
 		return nil, fmt.Errorf("failed to check existing challenge: %w", err)
 	}
 
-	// Get total problem count
-	var problemCount int64
-	if err := s.db.Model(&models.Problem{}).Count(&problemCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to count problems: %w", err)
+	candidates, err := s.buildCandidates()
+	if err != nil {
+		return nil, err
 	}
-	if problemCount == 0 {
+	if len(candidates) == 0 {
 		return nil, ErrNoProblems
 	}
 
-	// Find the next problem using round-robin
-	// Strategy: Select problem that hasn't been used recently or never used
-	var nextProblem models.Problem
-
-	// First, try to find a problem that has never been used
-	subQuery := s.db.Model(&models.DailyChallenge{}).Select("problem_id")
-	err = s.db.Where("id NOT IN (?)", subQuery).Order("id ASC").First(&nextProblem).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// All problems have been used at least once
-		// Pick the one used longest ago (round-robin)
-		err = s.db.
-			Joins("LEFT JOIN daily_challenges ON problems.id = daily_challenges.problem_id").
-			Group("problems.id").
-			Order("MAX(daily_challenges.challenge_date) ASC NULLS FIRST, problems.id ASC").
-			First(&nextProblem).Error
+	recent, err := s.GetRecentChallenges(recentChallengeWindow)
+	if err != nil {
+		return nil, err
 	}
 
+	chosen, err := s.selector.Select(candidates, recent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select next problem: %w", err)
 	}
 
-	// Create new daily challenge
 	challenge := &models.DailyChallenge{
-		ProblemID:     nextProblem.ID,
+		ProblemID:     chosen.Problem.ID,
 		ChallengeDate: today,
 	}
 
@@ -83,11 +93,123 @@ func (s *DailyChallengeService) SelectNextChallenge() (*models.DailyChallenge, e
 	}
 
 	// Load the problem relationship
-	challenge.Problem = nextProblem
+	challenge.Problem = chosen.Problem
 
 	return challenge, nil
 }
 
+// PreviewSelections runs strategy's selection n times against the current
+// candidate pool without persisting anything, removing each pick from the
+// pool before the next round so operators can see the sequence a strategy
+// would produce before switching to it in production.
+func (s *DailyChallengeService) PreviewSelections(strategy string, n int) ([]models.Problem, error) {
+	selector, err := SelectorByName(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.buildCandidates()
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoProblems
+	}
+
+	recent, err := s.GetRecentChallenges(recentChallengeWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	picks := make([]models.Problem, 0, n)
+	for i := 0; i < n && len(candidates) > 0; i++ {
+		chosen, err := selector.Select(candidates, recent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select preview pick %d: %w", i+1, err)
+		}
+		picks = append(picks, chosen.Problem)
+
+		remaining := candidates[:0]
+		for _, c := range candidates {
+			if c.Problem.ID != chosen.Problem.ID {
+				remaining = append(remaining, c)
+			}
+		}
+		candidates = remaining
+
+		simulated := models.DailyChallenge{ProblemID: chosen.Problem.ID, ChallengeDate: time.Now().UTC(), Problem: chosen.Problem}
+		recent = append([]models.DailyChallenge{simulated}, recent...)
+	}
+
+	return picks, nil
+}
+
+// buildCandidates loads every problem along with when it was last used as a
+// daily challenge (nil if never) and its global solve rate, for a
+// ChallengeSelector to weigh.
+func (s *DailyChallengeService) buildCandidates() ([]ChallengeCandidate, error) {
+	var problems []models.Problem
+	if err := s.db.Find(&problems).Error; err != nil {
+		return nil, fmt.Errorf("failed to load problems: %w", err)
+	}
+
+	lastUsed := make(map[uint]time.Time, len(problems))
+	var history []models.DailyChallenge
+	if err := s.db.Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to load challenge history: %w", err)
+	}
+	for _, h := range history {
+		if existing, ok := lastUsed[h.ProblemID]; !ok || h.ChallengeDate.After(existing) {
+			lastUsed[h.ProblemID] = h.ChallengeDate
+		}
+	}
+
+	// submissions.problem_id is stored as the problem's numeric ID rendered
+	// as text, so it's matched back to Problem.ID by parsing rather than a
+	// SQL join across the mismatched column types.
+	type solveRateRow struct {
+		ProblemID string
+		Total     int64
+		Accepted  int64
+	}
+	var rows []solveRateRow
+	if err := s.db.Model(&models.Submission{}).
+		Select("problem_id, COUNT(*) as total, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) as accepted", models.StatusAccepted).
+		Group("problem_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load solve rates: %w", err)
+	}
+
+	type rate struct {
+		value float64
+		ok    bool
+	}
+	solveRates := make(map[uint]rate, len(rows))
+	for _, row := range rows {
+		id, err := strconv.ParseUint(row.ProblemID, 10, 32)
+		if err != nil || row.Total == 0 {
+			continue
+		}
+		solveRates[uint(id)] = rate{value: float64(row.Accepted) / float64(row.Total) * 100, ok: true}
+	}
+
+	candidates := make([]ChallengeCandidate, 0, len(problems))
+	for _, p := range problems {
+		candidate := ChallengeCandidate{Problem: p}
+		if t, ok := lastUsed[p.ID]; ok {
+			t := t
+			candidate.LastUsed = &t
+		}
+		if r, ok := solveRates[p.ID]; ok {
+			candidate.SolveRate = r.value
+			candidate.HasSubmissions = r.ok
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
 // GetTodaysChallenge returns today's daily challenge
 func (s *DailyChallengeService) GetTodaysChallenge() (*models.DailyChallenge, error) {
 	today := time.Now().UTC().Truncate(24 * time.Hour)
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// Common errors for dashboard service
+var (
+	ErrDashboardNotFound  = errors.New("dashboard not found")
+	ErrDashboardForbidden = errors.New("user does not have access to this dashboard")
+)
+
+// cachedWidget is a RenderedWidget along with when its cache TTL expires.
+type cachedWidget struct {
+	rendered models.RenderedWidget
+	expires  time.Time
+}
+
+// DashboardService composes leaderboards, streaks, and the daily challenge
+// into per-user dashboards, and batches all of a dashboard's widget queries
+// into one RenderDashboard call instead of the frontend making N ad-hoc
+// requests to power a customizable landing page.
+type DashboardService struct {
+	db          *gorm.DB
+	leaderboard *LeaderboardService
+	streaks     *StreakService
+	challenges  *DailyChallengeService
+
+	cacheMu sync.Mutex
+	cache   map[uint]map[uint]cachedWidget // dashboard ID -> widget ID -> cached render
+}
+
+// NewDashboardService creates a new dashboard service instance
+func NewDashboardService(db *gorm.DB, leaderboard *LeaderboardService, streaks *StreakService, challenges *DailyChallengeService) *DashboardService {
+	return &DashboardService{
+		db:          db,
+		leaderboard: leaderboard,
+		streaks:     streaks,
+		challenges:  challenges,
+		cache:       make(map[uint]map[uint]cachedWidget),
+	}
+}
+
+// CreateDashboard creates ownerID's new dashboard with the given widgets.
+func (s *DashboardService) CreateDashboard(ownerID, name string, widgets []models.DashboardWidget) (*models.Dashboard, error) {
+	dashboard := &models.Dashboard{OwnerID: ownerID, Name: name, Widgets: widgets}
+	if err := s.db.Create(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to create dashboard: %w", err)
+	}
+	return dashboard, nil
+}
+
+// GetDashboard retrieves a dashboard by ID, as long as requestingUserID has
+// at least viewer access to it.
+func (s *DashboardService) GetDashboard(id uint, requestingUserID string) (*models.Dashboard, error) {
+	dashboard, err := s.loadDashboard(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireRole(dashboard, requestingUserID, models.DashboardRoleViewer); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// ListUserDashboards returns every dashboard userID owns or has been granted
+// access to.
+func (s *DashboardService) ListUserDashboards(userID string) ([]models.Dashboard, error) {
+	var owned []models.Dashboard
+	if err := s.db.Preload("Widgets").Where("owner_id = ?", userID).Find(&owned).Error; err != nil {
+		return nil, fmt.Errorf("failed to list owned dashboards: %w", err)
+	}
+
+	var sharedIDs []uint
+	if err := s.db.Model(&models.DashboardAccess{}).Where("user_id = ?", userID).Pluck("dashboard_id", &sharedIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list shared dashboards: %w", err)
+	}
+	if len(sharedIDs) == 0 {
+		return owned, nil
+	}
+
+	var shared []models.Dashboard
+	if err := s.db.Preload("Widgets").Where("id IN ?", sharedIDs).Find(&shared).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shared dashboards: %w", err)
+	}
+	return append(owned, shared...), nil
+}
+
+// UpdateDashboard renames the dashboard and replaces its widget set. Only a
+// dashboard admin (the owner, or a user granted DashboardRoleAdmin) may
+// update it.
+func (s *DashboardService) UpdateDashboard(id uint, requestingUserID, name string, widgets []models.DashboardWidget) (*models.Dashboard, error) {
+	dashboard, err := s.loadDashboard(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireRole(dashboard, requestingUserID, models.DashboardRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Where("dashboard_id = ?", id).Delete(&models.DashboardWidget{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear dashboard widgets: %w", err)
+	}
+	for i := range widgets {
+		widgets[i].DashboardID = id
+	}
+
+	dashboard.Name = name
+	dashboard.Widgets = widgets
+	if err := s.db.Save(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to update dashboard: %w", err)
+	}
+
+	s.invalidateCache(id)
+	return dashboard, nil
+}
+
+// DeleteDashboard removes the dashboard, its widgets, and its access grants.
+// Only a dashboard admin may delete it.
+func (s *DashboardService) DeleteDashboard(id uint, requestingUserID string) error {
+	dashboard, err := s.loadDashboard(id)
+	if err != nil {
+		return err
+	}
+	if err := s.requireRole(dashboard, requestingUserID, models.DashboardRoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.db.Where("dashboard_id = ?", id).Delete(&models.DashboardWidget{}).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard widgets: %w", err)
+	}
+	if err := s.db.Where("dashboard_id = ?", id).Delete(&models.DashboardAccess{}).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard access: %w", err)
+	}
+	if err := s.db.Delete(dashboard).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+
+	s.invalidateCache(id)
+	return nil
+}
+
+// RenderDashboard batches every widget's underlying leaderboard/streak/daily
+// challenge query into one response, reusing any still-fresh cached widget
+// render instead of re-querying it.
+func (s *DashboardService) RenderDashboard(id uint, requestingUserID string) (*models.RenderedDashboard, error) {
+	dashboard, err := s.GetDashboard(id, requestingUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rendered := make([]models.RenderedWidget, len(dashboard.Widgets))
+	for i, widget := range dashboard.Widgets {
+		if cached, ok := s.cachedWidget(id, widget.ID, now); ok {
+			rendered[i] = cached
+			continue
+		}
+
+		widgetResult := s.renderWidget(widget, dashboard.OwnerID, now)
+		rendered[i] = widgetResult
+		s.cacheWidget(id, widget.ID, widgetResult)
+	}
+
+	return &models.RenderedDashboard{
+		Dashboard:  *dashboard,
+		Widgets:    rendered,
+		RenderedAt: now,
+	}, nil
+}
+
+// renderWidget resolves one widget's data from the service it composes.
+func (s *DashboardService) renderWidget(widget models.DashboardWidget, ownerID string, now time.Time) models.RenderedWidget {
+	result := models.RenderedWidget{Widget: widget, CachedAt: now}
+
+	switch widget.Type {
+	case models.WidgetTypeLeaderboard:
+		page, err := s.leaderboard.GetLeaderboardForScope(widget.MetricType, widget.Scope, 1, 20)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Leaderboard = page
+
+		if leaderboardUserID, ok := parseLeaderboardUserID(ownerID); ok {
+			if rank, err := s.leaderboard.GetUserRankForScope(leaderboardUserID, widget.MetricType, widget.Scope); err == nil {
+				result.Rank = rank
+			}
+		}
+	case models.WidgetTypeStreak:
+		streak, err := s.streaks.GetStreak(ownerID)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Streak = streak
+	case models.WidgetTypeStreakHistory:
+		history, err := s.streaks.GetStreakHistory(ownerID, 30)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.StreakHistory = history
+	case models.WidgetTypeDailyChallenge:
+		challenge, err := s.challenges.GetTodaysChallenge()
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.DailyChallenge = challenge
+	default:
+		result.Error = fmt.Sprintf("unknown widget type: %s", widget.Type)
+	}
+
+	return result
+}
+
+// parseLeaderboardUserID bridges the dashboard/streak subsystems' string
+// user IDs to the leaderboard subsystem's numeric ones. It returns false if
+// ownerID isn't a bare numeric ID, in which case the leaderboard widget
+// still renders the leaderboard page itself but omits the owner's personal
+// rank.
+func parseLeaderboardUserID(ownerID string) (uint, bool) {
+	id, err := strconv.ParseUint(ownerID, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (s *DashboardService) loadDashboard(id uint) (*models.Dashboard, error) {
+	var dashboard models.Dashboard
+	if err := s.db.Preload("Widgets").First(&dashboard, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDashboardNotFound
+		}
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+	return &dashboard, nil
+}
+
+// requireRole returns ErrDashboardForbidden unless userID is the dashboard's
+// owner (always treated as admin) or has been granted at least minRole via
+// DashboardAccess. DashboardRoleAdmin is the only role above
+// DashboardRoleViewer, so this is a direct equality check rather than a
+// ranked hierarchy.
+func (s *DashboardService) requireRole(dashboard *models.Dashboard, userID string, minRole models.DashboardRole) error {
+	if dashboard.OwnerID == userID {
+		return nil
+	}
+
+	var access models.DashboardAccess
+	err := s.db.Where("dashboard_id = ? AND user_id = ?", dashboard.ID, userID).First(&access).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDashboardForbidden
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check dashboard access: %w", err)
+	}
+	if minRole == models.DashboardRoleAdmin && access.Role != models.DashboardRoleAdmin {
+		return ErrDashboardForbidden
+	}
+	return nil
+}
+
+func (s *DashboardService) cachedWidget(dashboardID, widgetID uint, now time.Time) (models.RenderedWidget, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	widgets, ok := s.cache[dashboardID]
+	if !ok {
+		return models.RenderedWidget{}, false
+	}
+	cached, ok := widgets[widgetID]
+	if !ok || now.After(cached.expires) {
+		return models.RenderedWidget{}, false
+	}
+	return cached.rendered, true
+}
+
+func (s *DashboardService) cacheWidget(dashboardID, widgetID uint, rendered models.RenderedWidget) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if _, ok := s.cache[dashboardID]; !ok {
+		s.cache[dashboardID] = make(map[uint]cachedWidget)
+	}
+	ttl := time.Duration(rendered.Widget.CacheTTLSeconds) * time.Second
+	s.cache[dashboardID][widgetID] = cachedWidget{rendered: rendered, expires: rendered.CachedAt.Add(ttl)}
+}
+
+// invalidateCache drops every cached widget render for dashboardID, so an
+// update/delete doesn't keep serving stale widget data for its TTL.
+func (s *DashboardService) invalidateCache(dashboardID uint) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	delete(s.cache, dashboardID)
+}
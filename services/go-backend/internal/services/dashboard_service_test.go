@@ -0,0 +1,215 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupDashboardTestDB creates an in-memory SQLite database for testing
+func setupDashboardTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(
+		&models.Dashboard{},
+		&models.DashboardWidget{},
+		&models.DashboardAccess{},
+		&models.UserStreak{},
+		&models.StreakHistory{},
+		&models.DailyChallenge{},
+		&models.Problem{},
+		&models.LeaderboardEntry{},
+		&TestUser{},
+	)
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+
+	return db
+}
+
+func newTestDashboardService(db *gorm.DB) *DashboardService {
+	leaderboard := NewLeaderboardService(db)
+	streaks := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+	challenges := NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig())
+	return NewDashboardService(db, leaderboard, streaks, challenges)
+}
+
+func TestCreateAndGetDashboard(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", []models.DashboardWidget{
+		{Type: models.WidgetTypeStreak, Position: 0},
+	})
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	fetched, err := service.GetDashboard(dashboard.ID, "42")
+	if err != nil {
+		t.Fatalf("GetDashboard failed: %v", err)
+	}
+	if fetched.Name != "My Dashboard" {
+		t.Errorf("expected name %q, got %q", "My Dashboard", fetched.Name)
+	}
+	if len(fetched.Widgets) != 1 {
+		t.Fatalf("expected 1 widget, got %d", len(fetched.Widgets))
+	}
+}
+
+func TestGetDashboard_ForbiddenForOtherUser(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", nil)
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	_, err = service.GetDashboard(dashboard.ID, "99")
+	if err != ErrDashboardForbidden {
+		t.Errorf("expected ErrDashboardForbidden, got %v", err)
+	}
+}
+
+func TestGetDashboard_ViewerAccessGranted(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", nil)
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+	db.Create(&models.DashboardAccess{DashboardID: dashboard.ID, UserID: "99", Role: models.DashboardRoleViewer})
+
+	if _, err := service.GetDashboard(dashboard.ID, "99"); err != nil {
+		t.Errorf("expected viewer access to succeed, got %v", err)
+	}
+
+	// A viewer may not update the dashboard.
+	if _, err := service.UpdateDashboard(dashboard.ID, "99", "renamed", nil); err != ErrDashboardForbidden {
+		t.Errorf("expected ErrDashboardForbidden for viewer update, got %v", err)
+	}
+}
+
+func TestUpdateDashboard_ReplacesWidgets(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", []models.DashboardWidget{
+		{Type: models.WidgetTypeStreak, Position: 0},
+	})
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	updated, err := service.UpdateDashboard(dashboard.ID, "42", "renamed", []models.DashboardWidget{
+		{Type: models.WidgetTypeDailyChallenge, Position: 0},
+		{Type: models.WidgetTypeStreakHistory, Position: 1},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDashboard failed: %v", err)
+	}
+	if updated.Name != "renamed" {
+		t.Errorf("expected name %q, got %q", "renamed", updated.Name)
+	}
+	if len(updated.Widgets) != 2 {
+		t.Fatalf("expected 2 widgets, got %d", len(updated.Widgets))
+	}
+}
+
+func TestDeleteDashboard(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", nil)
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	if err := service.DeleteDashboard(dashboard.ID, "42"); err != nil {
+		t.Fatalf("DeleteDashboard failed: %v", err)
+	}
+
+	if _, err := service.GetDashboard(dashboard.ID, "42"); err != ErrDashboardNotFound {
+		t.Errorf("expected ErrDashboardNotFound after delete, got %v", err)
+	}
+}
+
+func TestRenderDashboard_BatchesWidgets(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	db.Create(&models.UserStreak{UserID: "42", CurrentStreak: 3, LongestStreak: 5})
+	db.Create(&models.LeaderboardEntry{UserID: 42, MetricType: models.MetricProblemsSolved, Scope: models.ScopeGlobal, MetricValue: 10, Rank: 1})
+	db.Create(&TestUser{ID: 42, Username: "alice"})
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", []models.DashboardWidget{
+		{Type: models.WidgetTypeStreak, Position: 0, CacheTTLSeconds: 60},
+		{Type: models.WidgetTypeLeaderboard, MetricType: models.MetricProblemsSolved, Scope: models.ScopeGlobal, Position: 1, CacheTTLSeconds: 60},
+	})
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	rendered, err := service.RenderDashboard(dashboard.ID, "42")
+	if err != nil {
+		t.Fatalf("RenderDashboard failed: %v", err)
+	}
+	if len(rendered.Widgets) != 2 {
+		t.Fatalf("expected 2 rendered widgets, got %d", len(rendered.Widgets))
+	}
+
+	streakWidget := rendered.Widgets[0]
+	if streakWidget.Streak == nil || streakWidget.Streak.CurrentStreak != 3 {
+		t.Errorf("expected streak widget with current streak 3, got %+v", streakWidget.Streak)
+	}
+
+	leaderboardWidget := rendered.Widgets[1]
+	if leaderboardWidget.Leaderboard == nil {
+		t.Fatal("expected leaderboard widget to have a leaderboard page")
+	}
+	if leaderboardWidget.Rank == nil || leaderboardWidget.Rank.Rank != 1 {
+		t.Errorf("expected owner's rank to be 1, got %+v", leaderboardWidget.Rank)
+	}
+}
+
+func TestRenderDashboard_CachesWidgetsWithinTTL(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	service := newTestDashboardService(db)
+
+	db.Create(&models.UserStreak{UserID: "42", CurrentStreak: 1, LongestStreak: 1})
+
+	dashboard, err := service.CreateDashboard("42", "My Dashboard", []models.DashboardWidget{
+		{Type: models.WidgetTypeStreak, Position: 0, CacheTTLSeconds: 300},
+	})
+	if err != nil {
+		t.Fatalf("CreateDashboard failed: %v", err)
+	}
+
+	first, err := service.RenderDashboard(dashboard.ID, "42")
+	if err != nil {
+		t.Fatalf("RenderDashboard failed: %v", err)
+	}
+
+	// Streak changes after the first render, but within the TTL the cached
+	// widget should still be served.
+	db.Model(&models.UserStreak{}).Where("user_id = ?", "42").Update("current_streak", 99)
+
+	second, err := service.RenderDashboard(dashboard.ID, "42")
+	if err != nil {
+		t.Fatalf("RenderDashboard failed: %v", err)
+	}
+	if second.Widgets[0].Streak.CurrentStreak != first.Widgets[0].Streak.CurrentStreak {
+		t.Errorf("expected cached streak value %d, got %d", first.Widgets[0].Streak.CurrentStreak, second.Widgets[0].Streak.CurrentStreak)
+	}
+}
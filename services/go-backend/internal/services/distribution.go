@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/montanaflynn/stats"
+)
+
+// DistributionMetric is which submission metric a Distribution tracks.
+type DistributionMetric string
+
+const (
+	MetricRuntime DistributionMetric = "runtime"
+	MetricMemory  DistributionMetric = "memory"
+)
+
+// MinDistributionSample is the smallest sample size CalculatePercentileMetrics
+// will report a percentile rank for. Below this, "faster than 90%" is
+// computed from too few submissions to mean anything.
+const MinDistributionSample = 20
+
+// maxDistributionSamples caps how many samples a single Distribution keeps
+// in memory, evicting the oldest once full. Large enough that p50-p99 stay
+// accurate for even the most-submitted problems.
+const maxDistributionSamples = 5000
+
+// PercentileBands holds the standard percentile cuts computed over a
+// Distribution's current sample.
+type PercentileBands struct {
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// HistogramBucket is one bucket of a Distribution's histogram, suitable for
+// rendering a chart on the frontend.
+type HistogramBucket struct {
+	RangeStart float64 `json:"range_start"`
+	RangeEnd   float64 `json:"range_end"`
+	Count      int     `json:"count"`
+}
+
+// Distribution is a sorted, in-memory sample of one metric's values for a
+// single (problem, language) pair. New values are inserted in sorted
+// position rather than triggering a full re-sort, so a percentile rank is
+// then just a couple of binary searches away.
+type Distribution struct {
+	mu      sync.RWMutex
+	values  []float64
+	seqs    []int64 // insertion sequence numbers, parallel to values, for oldest-first eviction
+	nextSeq int64
+}
+
+func newDistribution() *Distribution {
+	return &Distribution{}
+}
+
+// Add inserts value into the distribution in sorted position, evicting the
+// oldest sample first if the distribution is already at capacity.
+func (d *Distribution) Add(value float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.values) >= maxDistributionSamples {
+		d.evictOldestLocked()
+	}
+
+	idx := sort.SearchFloat64s(d.values, value)
+	d.values = append(d.values, 0)
+	copy(d.values[idx+1:], d.values[idx:])
+	d.values[idx] = value
+
+	d.seqs = append(d.seqs, 0)
+	copy(d.seqs[idx+1:], d.seqs[idx:])
+	d.seqs[idx] = d.nextSeq
+	d.nextSeq++
+}
+
+// evictOldestLocked removes the sample with the smallest insertion sequence
+// number. Callers must hold d.mu.
+func (d *Distribution) evictOldestLocked() {
+	oldestIdx := 0
+	for i, seq := range d.seqs {
+		if seq < d.seqs[oldestIdx] {
+			oldestIdx = i
+		}
+	}
+	d.values = append(d.values[:oldestIdx], d.values[oldestIdx+1:]...)
+	d.seqs = append(d.seqs[:oldestIdx], d.seqs[oldestIdx+1:]...)
+}
+
+// PercentileRank reports what fraction of the distribution's samples are
+// worse than value (a higher raw value - slower runtime, more memory),
+// value's 1-based rank from best to worst, and the sample size the
+// calculation was based on.
+func (d *Distribution) PercentileRank(value float64) (percentile float64, rank int, total int) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	total = len(d.values)
+	if total == 0 {
+		return 0, 0, 0
+	}
+
+	rank = sort.SearchFloat64s(d.values, value) + 1
+	worse := total - sort.Search(total, func(i int) bool { return d.values[i] > value })
+	percentile = float64(worse) / float64(total) * 100
+
+	return percentile, rank, total
+}
+
+// Percentiles computes the standard percentile bands over the current
+// sample using montanaflynn/stats. The returned sample size is the number
+// of values the bands were computed from.
+func (d *Distribution) Percentiles() (PercentileBands, int, error) {
+	d.mu.RLock()
+	values := make([]float64, len(d.values))
+	copy(values, d.values)
+	d.mu.RUnlock()
+
+	if len(values) == 0 {
+		return PercentileBands{}, 0, nil
+	}
+
+	data := stats.Float64Data(values)
+	cuts := []struct {
+		target *float64
+		pct    float64
+	}{
+		{nil, 50}, {nil, 75}, {nil, 90}, {nil, 95}, {nil, 99},
+	}
+
+	var bands PercentileBands
+	cuts[0].target, cuts[1].target, cuts[2].target, cuts[3].target, cuts[4].target =
+		&bands.P50, &bands.P75, &bands.P90, &bands.P95, &bands.P99
+
+	for _, cut := range cuts {
+		v, err := data.Percentile(cut.pct)
+		if err != nil {
+			return PercentileBands{}, len(values), fmt.Errorf("failed to compute p%g: %w", cut.pct, err)
+		}
+		*cut.target = v
+	}
+
+	return bands, len(values), nil
+}
+
+// Histogram buckets the current sample into numBuckets equal-width buckets
+// spanning its min and max, suitable for rendering a chart.
+func (d *Distribution) Histogram(numBuckets int) []HistogramBucket {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if len(d.values) == 0 || numBuckets <= 0 {
+		return nil
+	}
+
+	min, max := d.values[0], d.values[len(d.values)-1]
+	if min == max {
+		return []HistogramBucket{{RangeStart: min, RangeEnd: max, Count: len(d.values)}}
+	}
+
+	width := (max - min) / float64(numBuckets)
+	buckets := make([]HistogramBucket, numBuckets)
+	for i := range buckets {
+		buckets[i].RangeStart = min + width*float64(i)
+		buckets[i].RangeEnd = min + width*float64(i+1)
+	}
+
+	for _, v := range d.values {
+		idx := int((v - min) / width)
+		if idx >= numBuckets {
+			idx = numBuckets - 1
+		}
+		buckets[idx].Count++
+	}
+
+	return buckets
+}
+
+// DistributionStore owns one Distribution per (problemID, language, metric)
+// combination, creating them lazily, plus a record of which (problemID,
+// language) pairs have been hydrated from the database in this process.
+type DistributionStore struct {
+	mu            sync.RWMutex
+	distributions map[string]*Distribution
+	hydrated      map[string]bool
+}
+
+// NewDistributionStore creates an empty DistributionStore.
+func NewDistributionStore() *DistributionStore {
+	return &DistributionStore{
+		distributions: make(map[string]*Distribution),
+		hydrated:      make(map[string]bool),
+	}
+}
+
+func distributionKey(problemID, language string, metric DistributionMetric) string {
+	return problemID + "|" + language + "|" + string(metric)
+}
+
+func hydrationKey(problemID, language string) string {
+	return problemID + "|" + language
+}
+
+// Get returns the Distribution for (problemID, language, metric), creating
+// an empty one on first use.
+func (s *DistributionStore) Get(problemID, language string, metric DistributionMetric) *Distribution {
+	key := distributionKey(problemID, language, metric)
+
+	s.mu.RLock()
+	d, ok := s.distributions[key]
+	s.mu.RUnlock()
+	if ok {
+		return d
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.distributions[key]; ok {
+		return d
+	}
+	d = newDistribution()
+	s.distributions[key] = d
+	return d
+}
+
+// Record adds an accepted submission's runtime and memory samples to the
+// relevant distributions. Called as new accepted submissions arrive so the
+// cached distributions stay current without ever needing a full re-sort
+// against the database.
+func (s *DistributionStore) Record(problemID, language string, executionTimeMs, memoryUsedKb int) {
+	s.Get(problemID, language, MetricRuntime).Add(float64(executionTimeMs))
+	s.Get(problemID, language, MetricMemory).Add(float64(memoryUsedKb))
+}
+
+// IsHydrated reports whether (problemID, language) has already been seeded
+// from the database in this process.
+func (s *DistributionStore) IsHydrated(problemID, language string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hydrated[hydrationKey(problemID, language)]
+}
+
+// MarkHydrated records that (problemID, language) has been seeded from the
+// database, so later calls skip re-hydrating it.
+func (s *DistributionStore) MarkHydrated(problemID, language string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hydrated[hydrationKey(problemID, language)] = true
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// eventQueueSize bounds how many published-but-undelivered events a single
+// topic's worker will buffer before Publish starts rejecting new ones.
+const eventQueueSize = 64
+
+// EventTopic names one channel of events on an EventBus.
+type EventTopic string
+
+const (
+	// TopicSubmissionAccepted fires once per accepted submission, carrying
+	// the metrics LeaderboardService needs to fold into its running
+	// per-user averages.
+	TopicSubmissionAccepted EventTopic = "submission_accepted"
+	// TopicStreakUpdated fires whenever a user's streak changes.
+	TopicStreakUpdated EventTopic = "streak_updated"
+	// TopicProblemSolved fires the first time a user solves a given
+	// problem (not on every accepted resubmission of an already-solved
+	// one).
+	TopicProblemSolved EventTopic = "problem_solved"
+	// TopicStreakBroken fires when StreakService.CheckStreak proactively
+	// zeroes a user's CurrentStreak because their grace window (plus any
+	// banked freezes) lapsed without a solve, for streak-loss notifications.
+	TopicStreakBroken EventTopic = "streak_broken"
+	// TopicStreakStarted fires the first time a user ever solves a daily
+	// challenge, starting their streak at 1.
+	TopicStreakStarted EventTopic = "streak_started"
+	// TopicStreakExtended fires whenever UpdateStreak increments an
+	// existing streak, whether by a consecutive-day solve or by spending a
+	// banked freeze to bridge a missed day.
+	TopicStreakExtended EventTopic = "streak_extended"
+	// TopicStreakRecord fires when UpdateStreak raises a user's
+	// LongestStreak, i.e. CurrentStreak has never been higher.
+	TopicStreakRecord EventTopic = "streak_record"
+	// TopicDailyChallengeSolved fires on every successful UpdateStreak call,
+	// regardless of whether it started, extended, or reset the streak.
+	TopicDailyChallengeSolved EventTopic = "daily_challenge_solved"
+)
+
+// SubmissionAcceptedEvent is published on TopicSubmissionAccepted.
+type SubmissionAcceptedEvent struct {
+	UserID          uint
+	ProblemID       uint
+	Language        string
+	ExecutionTimeMs int
+	MemoryUsedKb    int
+}
+
+// StreakUpdatedEvent is published on TopicStreakUpdated.
+type StreakUpdatedEvent struct {
+	UserID        uint
+	CurrentStreak int
+	LongestStreak int
+}
+
+// ProblemSolvedEvent is published on TopicProblemSolved.
+type ProblemSolvedEvent struct {
+	UserID    uint
+	ProblemID uint
+}
+
+// StreakBrokenEvent is published on TopicStreakBroken. UserID matches
+// models.UserStreak.UserID's string type, unlike the other events here
+// which predate StreakService's switch away from a uint user ID.
+type StreakBrokenEvent struct {
+	UserID         string
+	PreviousStreak int
+}
+
+// StreakStartedEvent is published on TopicStreakStarted.
+type StreakStartedEvent struct {
+	UserID string
+}
+
+// StreakExtendedEvent is published on TopicStreakExtended.
+type StreakExtendedEvent struct {
+	UserID        string
+	CurrentStreak int
+}
+
+// StreakRecordEvent is published on TopicStreakRecord.
+type StreakRecordEvent struct {
+	UserID        string
+	LongestStreak int
+}
+
+// DailyChallengeSolvedEvent is published on TopicDailyChallengeSolved.
+type DailyChallengeSolvedEvent struct {
+	UserID    string
+	ProblemID uint
+}
+
+// EventHandler processes one event delivered on a subscribed topic. A
+// returned error is logged by the bus rather than propagated - one slow or
+// failing subscriber shouldn't block delivery to the others or the
+// publisher.
+type EventHandler func(ctx context.Context, event interface{}) error
+
+// EventBus fans events out to every handler subscribed to their topic,
+// decoupling publishers (submission/streak services) from subscribers
+// (LeaderboardService's incremental updates, future achievement tracking,
+// ...). InMemoryEventBus is the default single-process implementation; a
+// NSQ/Redis-backed implementation of this same interface would let
+// subscribers on other replicas receive the same events.
+type EventBus interface {
+	// Publish delivers event to every handler subscribed to topic.
+	Publish(ctx context.Context, topic EventTopic, event interface{}) error
+	// Subscribe registers handler to receive every event published to
+	// topic from then on.
+	Subscribe(topic EventTopic, handler EventHandler)
+}
+
+// publishedEvent is one entry on a topic's delivery queue.
+type publishedEvent struct {
+	ctx   context.Context
+	event interface{}
+}
+
+// InMemoryEventBus is an EventBus that delivers asynchronously, in-process:
+// each topic gets its own worker goroutine and bounded queue, so Publish
+// never blocks on a subscriber. Within a single delivery, every handler
+// subscribed to the topic runs in its own goroutine, so one slow or stuck
+// handler can't delay its siblings; the topic's next queued event is not
+// delivered until every handler for the current one has returned. It's the
+// default EventBus and requires no external dependency.
+type InMemoryEventBus struct {
+	mu       sync.RWMutex
+	handlers map[EventTopic][]EventHandler
+	queues   map[EventTopic]chan publishedEvent
+}
+
+// NewInMemoryEventBus creates an empty InMemoryEventBus.
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{
+		handlers: make(map[EventTopic][]EventHandler),
+		queues:   make(map[EventTopic]chan publishedEvent),
+	}
+}
+
+// Subscribe registers handler for topic, starting that topic's delivery
+// worker on first use. Safe to call concurrently with Publish.
+func (b *InMemoryEventBus) Subscribe(topic EventTopic, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[topic] = append(b.handlers[topic], handler)
+	if _, ok := b.queues[topic]; !ok {
+		queue := make(chan publishedEvent, eventQueueSize)
+		b.queues[topic] = queue
+		go b.deliverTopic(topic, queue)
+	}
+}
+
+// deliverTopic runs as the single worker for topic, pulling events off queue
+// in order and fanning each one out to every currently-subscribed handler
+// concurrently.
+func (b *InMemoryEventBus) deliverTopic(topic EventTopic, queue chan publishedEvent) {
+	for pe := range queue {
+		b.mu.RLock()
+		handlers := append([]EventHandler(nil), b.handlers[topic]...)
+		b.mu.RUnlock()
+
+		var wg sync.WaitGroup
+		for _, handler := range handlers {
+			wg.Add(1)
+			go func(handler EventHandler) {
+				defer wg.Done()
+				if err := handler(pe.ctx, pe.event); err != nil {
+					log.Printf("event bus: handler for topic %s failed: %v", topic, err)
+				}
+			}(handler)
+		}
+		wg.Wait()
+	}
+}
+
+// Publish enqueues event for asynchronous delivery to topic's subscribers
+// and returns immediately. If nobody has subscribed to topic yet, the event
+// is dropped - there is no queue to buffer it on.
+func (b *InMemoryEventBus) Publish(ctx context.Context, topic EventTopic, event interface{}) error {
+	b.mu.RLock()
+	queue, ok := b.queues[topic]
+	b.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case queue <- publishedEvent{ctx: ctx, event: event}:
+		return nil
+	default:
+		return fmt.Errorf("event bus: topic %s queue is full", topic)
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemoryEventBus_DeliversToMultipleSubscribers(t *testing.T) {
+	bus := NewInMemoryEventBus()
+
+	var mu sync.Mutex
+	var gotA, gotB StreakStartedEvent
+	doneA := make(chan struct{})
+	doneB := make(chan struct{})
+
+	bus.Subscribe(TopicStreakStarted, func(ctx context.Context, event interface{}) error {
+		mu.Lock()
+		gotA = event.(StreakStartedEvent)
+		mu.Unlock()
+		close(doneA)
+		return nil
+	})
+	bus.Subscribe(TopicStreakStarted, func(ctx context.Context, event interface{}) error {
+		mu.Lock()
+		gotB = event.(StreakStartedEvent)
+		mu.Unlock()
+		close(doneB)
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), TopicStreakStarted, StreakStartedEvent{UserID: "user1"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	for _, done := range []chan struct{}{doneA, doneB} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected both subscribers to be notified")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotA.UserID != "user1" || gotB.UserID != "user1" {
+		t.Errorf("expected both subscribers to see UserID user1, got %q and %q", gotA.UserID, gotB.UserID)
+	}
+}
+
+func TestInMemoryEventBus_SlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	bus := NewInMemoryEventBus()
+
+	blockForever := make(chan struct{})
+	t.Cleanup(func() { close(blockForever) })
+
+	bus.Subscribe(TopicStreakExtended, func(ctx context.Context, event interface{}) error {
+		<-blockForever
+		return nil
+	})
+
+	fast := make(chan struct{})
+	bus.Subscribe(TopicStreakExtended, func(ctx context.Context, event interface{}) error {
+		close(fast)
+		return nil
+	})
+
+	if err := bus.Publish(context.Background(), TopicStreakExtended, StreakExtendedEvent{UserID: "user1", CurrentStreak: 2}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case <-fast:
+	case <-time.After(time.Second):
+		t.Fatal("expected the fast subscriber to be notified without waiting on the slow one")
+	}
+}
+
+func TestInMemoryEventBus_PublishWithNoSubscribersIsANoOp(t *testing.T) {
+	bus := NewInMemoryEventBus()
+	if err := bus.Publish(context.Background(), TopicStreakBroken, StreakBrokenEvent{UserID: "user1"}); err != nil {
+		t.Errorf("expected Publish with no subscribers to succeed as a no-op, got %v", err)
+	}
+}
+
+func TestUpdateStreak_PublishesLifecycleEvents(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+	bus := NewInMemoryEventBus()
+	service.AttachEventBus(bus)
+
+	started := make(chan StreakStartedEvent, 1)
+	solved := make(chan DailyChallengeSolvedEvent, 1)
+	record := make(chan StreakRecordEvent, 1)
+	bus.Subscribe(TopicStreakStarted, func(ctx context.Context, event interface{}) error {
+		started <- event.(StreakStartedEvent)
+		return nil
+	})
+	bus.Subscribe(TopicDailyChallengeSolved, func(ctx context.Context, event interface{}) error {
+		solved <- event.(DailyChallengeSolvedEvent)
+		return nil
+	})
+	bus.Subscribe(TopicStreakRecord, func(ctx context.Context, event interface{}) error {
+		record <- event.(StreakRecordEvent)
+		return nil
+	})
+
+	setDailyChallenge(t, db, 7)
+	if _, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1"); err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+
+	select {
+	case event := <-started:
+		if event.UserID != "user1" {
+			t.Errorf("expected streak_started for user1, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a streak_started event")
+	}
+	select {
+	case event := <-solved:
+		if event.UserID != "user1" || event.ProblemID != 7 {
+			t.Errorf("expected daily_challenge_solved for user1/problem 7, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a daily_challenge_solved event")
+	}
+	select {
+	case event := <-record:
+		if event.LongestStreak != 1 {
+			t.Errorf("expected streak_record with LongestStreak 1, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a streak_record event for a first-ever solve")
+	}
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+)
+
+// JobExecutor runs an execution job to completion. *k8s.JobManager
+// implements it directly, running jobs in-process against the local
+// Kubernetes client; internal/runner.Pool implements it by dialing one or
+// more standalone runner services instead. A Dispatcher only depends on
+// this interface, so it can be backed by either without any change to how
+// it submits jobs and waits on results.
+type JobExecutor interface {
+	ExecuteAndWait(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// flagSubscriberBuffer is how many pending messages a subscriber's outbound
+// channel holds before the broker considers it too slow and disconnects it,
+// rather than letting a stalled admin connection block every other one.
+const flagSubscriberBuffer = 16
+
+// FlagStreamFilter narrows which events a FlagBroker subscriber receives.
+// The zero value matches everything.
+type FlagStreamFilter struct {
+	Reason      models.FlagReason
+	MinSeverity int
+}
+
+// matches reports whether flag passes f's reason and severity filters.
+func (f FlagStreamFilter) matches(flag models.FlaggedSubmission) bool {
+	if f.Reason != "" && flag.Reason != f.Reason {
+		return false
+	}
+	if flag.Reason.Severity() < f.MinSeverity {
+		return false
+	}
+	return true
+}
+
+// FlagStreamMessage is one JSON frame pushed to admin flag stream
+// subscribers.
+type FlagStreamMessage struct {
+	Type  string                    `json:"type"` // "flag", "stats", or "ping"
+	Flag  *models.FlaggedSubmission `json:"flag,omitempty"`
+	Stats *FlagStats                `json:"stats,omitempty"`
+}
+
+// FlagSubscriber is one live admin connection's outbound queue. Handlers
+// read from Send and write each message to their WebSocket as its own
+// frame.
+type FlagSubscriber struct {
+	filter FlagStreamFilter
+	Send   chan []byte
+}
+
+// flagBrokerMessage is what FlagBroker's internal broadcast channel carries:
+// either a newly flagged submission or a fresh stats snapshot.
+type flagBrokerMessage struct {
+	flag  *models.FlaggedSubmission
+	stats *FlagStats
+}
+
+// FlagBroker fans newly created flags and periodic stats snapshots out to
+// every subscribed admin connection. A single goroutine (started by Run)
+// owns the subscriber set, so Register/Unregister/Publish never need their
+// own locking - they just hand work to that goroutine over channels.
+type FlagBroker struct {
+	statsFn       func() (*FlagStats, error)
+	statsInterval time.Duration
+
+	register   chan *FlagSubscriber
+	unregister chan *FlagSubscriber
+	broadcast  chan flagBrokerMessage
+}
+
+// NewFlagBroker creates a FlagBroker that polls statsFn every statsInterval
+// and pushes the full aggregate stats snapshot it returns to subscribers
+// (not a difference from the previous snapshot), alongside individual flags
+// published via Publish.
+func NewFlagBroker(statsFn func() (*FlagStats, error), statsInterval time.Duration) *FlagBroker {
+	return &FlagBroker{
+		statsFn:       statsFn,
+		statsInterval: statsInterval,
+		register:      make(chan *FlagSubscriber),
+		unregister:    make(chan *FlagSubscriber),
+		broadcast:     make(chan flagBrokerMessage),
+	}
+}
+
+// Run owns the subscriber set until ctx is cancelled, handling
+// registration, unregistration, flag/stats broadcasts, and the periodic
+// stats poll. Intended to be called once in its own goroutine, right after
+// NewFlagBroker.
+func (b *FlagBroker) Run(ctx context.Context) {
+	subscribers := make(map[*FlagSubscriber]struct{})
+
+	ticker := time.NewTicker(b.statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for sub := range subscribers {
+				close(sub.Send)
+			}
+			return
+
+		case sub := <-b.register:
+			subscribers[sub] = struct{}{}
+
+		case sub := <-b.unregister:
+			if _, ok := subscribers[sub]; ok {
+				delete(subscribers, sub)
+				close(sub.Send)
+			}
+
+		case msg := <-b.broadcast:
+			for sub := range subscribers {
+				data, ok := encodeFlagMessage(msg, sub.filter)
+				if !ok {
+					continue
+				}
+				select {
+				case sub.Send <- data:
+				default:
+					// Subscriber's buffer is full; it's too slow to keep up,
+					// drop it rather than block every other subscriber.
+					delete(subscribers, sub)
+					close(sub.Send)
+				}
+			}
+
+		case <-ticker.C:
+			stats, err := b.statsFn()
+			if err != nil {
+				continue
+			}
+			b.broadcastNow(subscribers, flagBrokerMessage{stats: stats})
+		}
+	}
+}
+
+// broadcastNow sends msg to every subscriber directly, used for the
+// periodic stats tick where there's no separate publisher goroutine to
+// hand the message to Run via the broadcast channel.
+func (b *FlagBroker) broadcastNow(subscribers map[*FlagSubscriber]struct{}, msg flagBrokerMessage) {
+	for sub := range subscribers {
+		data, ok := encodeFlagMessage(msg, sub.filter)
+		if !ok {
+			continue
+		}
+		select {
+		case sub.Send <- data:
+		default:
+			delete(subscribers, sub)
+			close(sub.Send)
+		}
+	}
+}
+
+// encodeFlagMessage applies filter to msg and marshals it to JSON, reporting
+// false when filter excludes msg's flag (stats messages always pass).
+func encodeFlagMessage(msg flagBrokerMessage, filter FlagStreamFilter) ([]byte, bool) {
+	frame := FlagStreamMessage{}
+	switch {
+	case msg.flag != nil:
+		if !filter.matches(*msg.flag) {
+			return nil, false
+		}
+		frame.Type = "flag"
+		frame.Flag = msg.flag
+	case msg.stats != nil:
+		frame.Type = "stats"
+		frame.Stats = msg.stats
+	}
+
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Register subscribes a new admin connection matching filter, returning the
+// subscriber the caller should read Send from until it's closed. Safe to
+// call concurrently from multiple handler goroutines.
+func (b *FlagBroker) Register(filter FlagStreamFilter) *FlagSubscriber {
+	sub := &FlagSubscriber{filter: filter, Send: make(chan []byte, flagSubscriberBuffer)}
+	b.register <- sub
+	return sub
+}
+
+// Unregister removes sub from the broadcast set. Safe to call even if Run
+// already dropped sub itself (e.g. for filling its send buffer).
+func (b *FlagBroker) Unregister(sub *FlagSubscriber) {
+	b.unregister <- sub
+}
+
+// Publish fans flag out to every matching subscriber. Intended to be called
+// by AntiCheatService.FlagSubmission once its DB insert succeeds.
+func (b *FlagBroker) Publish(flag models.FlaggedSubmission) {
+	b.broadcast <- flagBrokerMessage{flag: &flag}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/logging"
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrJudgeQueueDraining is returned by CreateSubmission/Enqueue once
+// Shutdown has been called - no new work is accepted while in-flight jobs
+// drain.
+var ErrJudgeQueueDraining = errors.New("judge queue is draining")
+
+// JudgeQueueConfig controls JudgeQueue's retry/backoff policy for transient
+// executor errors.
+type JudgeQueueConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Multiplier     float64
+}
+
+// DefaultJudgeQueueConfig returns sensible defaults: 5 attempts, starting at
+// 500ms and doubling the wait after each retry (500ms, 1s, 2s, 4s).
+func DefaultJudgeQueueConfig() JudgeQueueConfig {
+	return JudgeQueueConfig{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+// JudgeQueue moves submission execution off the request path. CreateSubmission
+// persists a models.Submission as StatusQueued and returns immediately;
+// dispatching to the executor and retrying happen in the background, with
+// exponential backoff between attempts on a transient executor error. A
+// submission that exhausts config.MaxAttempts is parked in
+// submission_dead_letters for admin replay via ReplayDeadLetter instead of
+// being retried forever.
+//
+// JudgeQueue doesn't implement its own queueing or worker pool - it drives
+// dispatcher.Submit, so the actual backend (in-process InMemoryQueue for
+// dev, or a RedisQueue shared across replicas for production) is whichever
+// one dispatcher's queueName queue was registered with. NATS JetStream and
+// true Redis Streams consumer groups aren't implemented - this module has
+// no dependency manifest to add either client to, and RedisQueue's
+// list-plus-reclaim design already gives an at-least-once, multi-replica
+// backend without one.
+//
+// Library code only: cmd/server/main.go constructs neither a
+// SubmissionService nor a JudgeQueue, and its submission routes are still
+// the "TODO: Add submission endpoints" placeholder, so CreateSubmission
+// here isn't reachable from any route.
+// TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions
+// (submission_pipeline_integration_test.go) exercises CreateSubmission
+// end to end against ContestService and PlagiarismService on a shared DB,
+// so the inertness here is a missing caller, not unproven behavior.
+type JudgeQueue struct {
+	db          *gorm.DB
+	dispatcher  *Dispatcher
+	submissions *SubmissionService
+	queueName   string
+	config      JudgeQueueConfig
+
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// NewJudgeQueue creates a JudgeQueue dispatching onto dispatcher's
+// queueName queue (already registered via RegisterQueue or
+// RegisterQueueWithBackend). A zero config.MaxAttempts/InitialBackoff/
+// Multiplier falls back to DefaultJudgeQueueConfig's value for that field.
+func NewJudgeQueue(db *gorm.DB, dispatcher *Dispatcher, submissions *SubmissionService, queueName string, config JudgeQueueConfig) *JudgeQueue {
+	defaults := DefaultJudgeQueueConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.Multiplier <= 0 {
+		config.Multiplier = defaults.Multiplier
+	}
+
+	return &JudgeQueue{
+		db:          db,
+		dispatcher:  dispatcher,
+		submissions: submissions,
+		queueName:   queueName,
+		config:      config,
+	}
+}
+
+// CreateSubmission persists a new models.Submission with StatusQueued and
+// enqueues it for execution in the background - the single-submission
+// counterpart to BatchSubmissionService.CreateBatch. It returns as soon as
+// the submission is queued; callers poll GetSubmissionByID or use
+// SubmissionService.Subscribe for the result.
+func (q *JudgeQueue) CreateSubmission(userID, problemID, language, code string, testCases interface{}) (string, error) {
+	submission := models.Submission{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		ProblemID: problemID,
+		Language:  language,
+		Code:      code,
+		Status:    models.StatusQueued,
+	}
+	if err := q.db.Create(&submission).Error; err != nil {
+		return "", fmt.Errorf("failed to persist submission: %w", err)
+	}
+
+	params := k8s.ExecutionJobParams{
+		SubmissionID: submission.ID,
+		ProblemID:    submission.ProblemID,
+		Code:         submission.Code,
+		TestCases:    testCases,
+		Language:     submission.Language,
+	}
+	if err := q.Enqueue(submission.ID, params); err != nil {
+		return "", err
+	}
+
+	return submission.ID, nil
+}
+
+// Enqueue schedules submissionID for execution in the background, retrying
+// a transient executor error with exponential backoff up to
+// config.MaxAttempts before dead-lettering it. The caller is responsible
+// for submissionID already existing and being StatusQueued - CreateSubmission
+// and ReplayDeadLetter are the two callers within this package.
+func (q *JudgeQueue) Enqueue(submissionID string, params k8s.ExecutionJobParams) error {
+	q.mu.Lock()
+	if q.draining {
+		q.mu.Unlock()
+		return ErrJudgeQueueDraining
+	}
+	q.wg.Add(1)
+	q.mu.Unlock()
+
+	go func() {
+		defer q.wg.Done()
+		q.run(submissionID, params)
+	}()
+
+	return nil
+}
+
+// run drives the retry loop for one submission until it either succeeds or
+// exhausts config.MaxAttempts and is dead-lettered.
+func (q *JudgeQueue) run(submissionID string, params k8s.ExecutionJobParams) {
+	backoff := q.config.InitialBackoff
+	var lastErr error
+
+	for attempt := 1; attempt <= q.config.MaxAttempts; attempt++ {
+		if err := q.db.Model(&models.Submission{}).Where("id = ?", submissionID).Update("attempts", attempt).Error; err != nil {
+			logging.WithStacktrace(context.Background(), err)
+		}
+
+		result, err := q.dispatcher.Submit(context.Background(), q.queueName, params)
+		if err == nil {
+			q.applyResult(submissionID, result)
+			return
+		}
+
+		lastErr = err
+		if attempt == q.config.MaxAttempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff = time.Duration(float64(backoff) * q.config.Multiplier)
+	}
+
+	q.deadLetter(submissionID, params, lastErr)
+}
+
+// applyResult persists a completed execution's outcome and fans the status
+// change out to any subscriber, the same shape as
+// BatchSubmissionService.dispatchOne's success path.
+func (q *JudgeQueue) applyResult(submissionID string, result *k8s.ExecutionResult) {
+	updates := map[string]interface{}{
+		"status":            result.Status,
+		"error_message":     result.ErrorMessage,
+		"execution_time_ms": result.ExecutionTimeMs,
+		"memory_used_kb":    result.MemoryUsedKB,
+		"tests_passed":      result.TestsPassed,
+		"tests_total":       result.TestsTotal,
+	}
+	if err := q.db.Model(&models.Submission{}).Where("id = ?", submissionID).Updates(updates).Error; err != nil {
+		logging.WithStacktrace(context.Background(), err)
+		return
+	}
+	_ = q.submissions.PublishStatus(submissionID, result.Status, result.ExecutionTimeMs, result.MemoryUsedKB)
+}
+
+// deadLetter records submissionID's payload and last error in
+// submission_dead_letters and marks it StatusRuntimeError, once every
+// retry in run has been exhausted.
+func (q *JudgeQueue) deadLetter(submissionID string, params k8s.ExecutionJobParams, lastErr error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		logging.WithStacktrace(context.Background(), fmt.Errorf("failed to marshal dead letter payload for submission %s: %w", submissionID, err))
+	}
+
+	deadLetter := models.SubmissionDeadLetter{
+		SubmissionID: submissionID,
+		Payload:      string(payload),
+		LastError:    lastErr.Error(),
+		Attempts:     q.config.MaxAttempts,
+	}
+	if err := q.db.Create(&deadLetter).Error; err != nil {
+		logging.WithStacktrace(context.Background(), fmt.Errorf("failed to dead-letter submission %s: %w", submissionID, err))
+	}
+
+	updates := map[string]interface{}{
+		"status":        models.StatusRuntimeError,
+		"error_message": lastErr.Error(),
+	}
+	if err := q.db.Model(&models.Submission{}).Where("id = ?", submissionID).Updates(updates).Error; err != nil {
+		logging.WithStacktrace(context.Background(), err)
+		return
+	}
+	_ = q.submissions.PublishStatus(submissionID, models.StatusRuntimeError, 0, 0)
+}
+
+// ReplayDeadLetter re-enqueues a dead-lettered submission using its original
+// dispatch payload, then removes the dead-letter row - for an admin to
+// retry a poisoned job once its underlying cause (a flaky node, an executor
+// outage) has cleared.
+func (q *JudgeQueue) ReplayDeadLetter(id uint) error {
+	var deadLetter models.SubmissionDeadLetter
+	if err := q.db.First(&deadLetter, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("dead letter %d not found", id)
+		}
+		return fmt.Errorf("failed to load dead letter %d: %w", id, err)
+	}
+
+	var params k8s.ExecutionJobParams
+	if err := json.Unmarshal([]byte(deadLetter.Payload), &params); err != nil {
+		return fmt.Errorf("failed to decode dead letter %d payload: %w", id, err)
+	}
+
+	if err := q.submissions.UpdateStatus(deadLetter.SubmissionID, models.StatusQueued); err != nil {
+		return fmt.Errorf("failed to requeue submission %s: %w", deadLetter.SubmissionID, err)
+	}
+	if err := q.Enqueue(deadLetter.SubmissionID, params); err != nil {
+		return err
+	}
+
+	if err := q.db.Delete(&deadLetter).Error; err != nil {
+		return fmt.Errorf("failed to remove dead letter %d: %w", id, err)
+	}
+	return nil
+}
+
+// Shutdown stops accepting new submissions and waits for every in-flight
+// retry loop (including any sleeping between backoff attempts) to finish,
+// bounded by ctx.
+func (q *JudgeQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.draining = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// fakeJudgeExecutor implements JobExecutor for judge queue tests: it fails
+// its first `failures` calls (recording each call's time), then succeeds
+// on every call after that - so a test can force a fixed number of
+// transient failures before the retry loop succeeds, or set failures
+// higher than any test's MaxAttempts to simulate a permanently poisoned
+// job.
+type fakeJudgeExecutor struct {
+	mu       sync.Mutex
+	failures int
+	calls    []time.Time
+}
+
+func (f *fakeJudgeExecutor) ExecuteAndWait(ctx context.Context, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, time.Now())
+	attempt := len(f.calls)
+	failures := f.failures
+	f.mu.Unlock()
+
+	if attempt <= failures {
+		return nil, errors.New("executor unavailable")
+	}
+	return &k8s.ExecutionResult{
+		Status:          models.StatusAccepted,
+		ExecutionTimeMs: 1,
+		MemoryUsedKB:    1,
+		TestsPassed:     1,
+		TestsTotal:      1,
+	}, nil
+}
+
+func (f *fakeJudgeExecutor) callTimes() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time{}, f.calls...)
+}
+
+// newTestJudgeQueue wires up a JudgeQueue backed by an in-memory SQLite DB
+// and a single-queue Dispatcher around executor.
+func newTestJudgeQueue(t *testing.T, executor JobExecutor, config JudgeQueueConfig) *JudgeQueue {
+	t.Helper()
+	db := setupSubmissionTestDB(t)
+	if err := db.AutoMigrate(&models.SubmissionDeadLetter{}); err != nil {
+		t.Fatalf("failed to migrate dead letter table: %v", err)
+	}
+
+	submissionService := NewSubmissionService(db)
+
+	dispatcher := NewDispatcher(executor)
+	if err := dispatcher.RegisterQueue(DefaultQueueName, 4, 16); err != nil {
+		t.Fatalf("failed to register queue: %v", err)
+	}
+	t.Cleanup(func() { _ = dispatcher.Shutdown(context.Background()) })
+
+	return NewJudgeQueue(db, dispatcher, submissionService, DefaultQueueName, config)
+}
+
+// waitForSubmissionStatus polls submissionID until its status matches want
+// or the deadline passes.
+func waitForSubmissionStatus(t *testing.T, q *JudgeQueue, submissionID, want string) models.Submission {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var submission models.Submission
+		if err := q.db.First(&submission, "id = ?", submissionID).Error; err != nil {
+			t.Fatalf("failed to reload submission: %v", err)
+		}
+		if submission.Status == want {
+			return submission
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for submission %s to reach status %q", submissionID, want)
+	return models.Submission{}
+}
+
+func TestCreateSubmission_QueuesThenRunsToCompletion(t *testing.T) {
+	executor := &fakeJudgeExecutor{}
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+
+	submission := waitForSubmissionStatus(t, q, submissionID, models.StatusAccepted)
+	if submission.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1 (succeeded on the first try)", submission.Attempts)
+	}
+}
+
+func TestJudgeQueue_RetriesTransientFailureThenSucceeds(t *testing.T) {
+	executor := &fakeJudgeExecutor{failures: 2}
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+
+	submission := waitForSubmissionStatus(t, q, submissionID, models.StatusAccepted)
+	if submission.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3 (2 failures then a successful 3rd try)", submission.Attempts)
+	}
+
+	var deadLetters int64
+	if err := q.db.Model(&models.SubmissionDeadLetter{}).Count(&deadLetters).Error; err != nil {
+		t.Fatalf("failed to count dead letters: %v", err)
+	}
+	if deadLetters != 0 {
+		t.Errorf("expected no dead letters for a submission that eventually succeeded, got %d", deadLetters)
+	}
+}
+
+func TestJudgeQueue_BackoffGrowsExponentially(t *testing.T) {
+	executor := &fakeJudgeExecutor{failures: 3}
+	initialBackoff := 40 * time.Millisecond
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 5, InitialBackoff: initialBackoff, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+	waitForSubmissionStatus(t, q, submissionID, models.StatusAccepted)
+
+	calls := executor.callTimes()
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 calls (3 failures + 1 success), got %d", len(calls))
+	}
+
+	// Each gap should be roughly initialBackoff * multiplier^attempt, and
+	// strictly growing - allow generous slack for scheduler jitter rather
+	// than asserting an exact duration.
+	wantMin := initialBackoff
+	for i := 1; i < len(calls); i++ {
+		gap := calls[i].Sub(calls[i-1])
+		if gap < wantMin/2 {
+			t.Errorf("gap between call %d and %d = %v, want at least ~%v", i, i+1, gap, wantMin/2)
+		}
+		wantMin *= 2
+	}
+}
+
+func TestJudgeQueue_DeadLettersAfterMaxAttempts(t *testing.T) {
+	executor := &fakeJudgeExecutor{failures: 1000}
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+
+	submission := waitForSubmissionStatus(t, q, submissionID, models.StatusRuntimeError)
+	if submission.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", submission.Attempts)
+	}
+
+	var deadLetter models.SubmissionDeadLetter
+	if err := q.db.Where("submission_id = ?", submissionID).First(&deadLetter).Error; err != nil {
+		t.Fatalf("expected a dead letter row for the poisoned submission: %v", err)
+	}
+	if deadLetter.Attempts != 3 {
+		t.Errorf("dead letter Attempts = %d, want 3", deadLetter.Attempts)
+	}
+	if deadLetter.LastError == "" {
+		t.Error("expected dead letter to record the last error")
+	}
+	if deadLetter.Payload == "" {
+		t.Error("expected dead letter to record the dispatch payload")
+	}
+}
+
+func TestReplayDeadLetter_RequeuesAndRemovesRow(t *testing.T) {
+	executor := &fakeJudgeExecutor{failures: 2}
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+	waitForSubmissionStatus(t, q, submissionID, models.StatusRuntimeError)
+
+	var deadLetter models.SubmissionDeadLetter
+	if err := q.db.Where("submission_id = ?", submissionID).First(&deadLetter).Error; err != nil {
+		t.Fatalf("expected a dead letter row: %v", err)
+	}
+
+	// The underlying transient cause has now cleared - the next call
+	// succeeds.
+	if err := q.ReplayDeadLetter(deadLetter.ID); err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+
+	waitForSubmissionStatus(t, q, submissionID, models.StatusAccepted)
+
+	var remaining int64
+	if err := q.db.Model(&models.SubmissionDeadLetter{}).Where("id = ?", deadLetter.ID).Count(&remaining).Error; err != nil {
+		t.Fatalf("failed to count dead letters: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected the dead letter row to be removed after replay, found %d", remaining)
+	}
+}
+
+func TestJudgeQueue_ShutdownDrainsInFlightJobs(t *testing.T) {
+	executor := &fakeJudgeExecutor{failures: 1}
+	q := newTestJudgeQueue(t, executor, JudgeQueueConfig{MaxAttempts: 3, InitialBackoff: 20 * time.Millisecond, Multiplier: 2})
+
+	submissionID, err := q.CreateSubmission("u1", "p1", "python", "print(1)", nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := q.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v, want the in-flight retry to finish before the deadline", err)
+	}
+
+	var submission models.Submission
+	if err := q.db.First(&submission, "id = ?", submissionID).Error; err != nil {
+		t.Fatalf("failed to reload submission: %v", err)
+	}
+	if submission.Status != models.StatusAccepted {
+		t.Errorf("status = %q, want %q (Shutdown should wait for the in-flight job to finish)", submission.Status, models.StatusAccepted)
+	}
+
+	if err := q.Enqueue(submissionID, k8s.ExecutionJobParams{}); !errors.Is(err, ErrJudgeQueueDraining) {
+		t.Errorf("Enqueue() after Shutdown error = %v, want ErrJudgeQueueDraining", err)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/redis/go-redis/v9"
+)
+
+// LeaderboardCache is the pluggable read-through cache
+// LeaderboardHandler wraps LeaderboardService.GetLeaderboard and
+// GetUserAllRanks with, so repeated requests for the same page or user
+// don't re-run the underlying aggregation query. Get/Set operate on
+// already-serialized (JSON) values so the same interface covers both an
+// in-process and a Redis-backed implementation. Invalidate evicts every
+// key with the given prefix - used by ComputeLeaderboards to drop stale
+// entries once it has written fresh rankings.
+type LeaderboardCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Invalidate(ctx context.Context, prefix string) error
+}
+
+// defaultLeaderboardCacheSize bounds how many entries an
+// InProcessLeaderboardCache keeps at once. A page evicted from the LRU is
+// simply recomputed on its next request.
+const defaultLeaderboardCacheSize = 2000
+
+// InProcessLeaderboardCache is an in-process LeaderboardCache backed by an
+// LRU, for single-replica deployments or local development where spinning
+// up Redis isn't worth it. Prefix invalidation is a linear scan of the
+// LRU's keys, which is fine at this cache's size - ComputeLeaderboards
+// runs on a cron cadence, not per-request.
+type InProcessLeaderboardCache struct {
+	mu    sync.Mutex
+	cache *lru.Cache
+	ttl   map[string]time.Time
+}
+
+// NewInProcessLeaderboardCache creates an InProcessLeaderboardCache holding
+// at most size entries. size defaults to defaultLeaderboardCacheSize when
+// <= 0.
+func NewInProcessLeaderboardCache(size int) *InProcessLeaderboardCache {
+	if size <= 0 {
+		size = defaultLeaderboardCacheSize
+	}
+	c, _ := lru.New(size)
+	return &InProcessLeaderboardCache{cache: c, ttl: make(map[string]time.Time)}
+}
+
+// Get implements LeaderboardCache.
+func (c *InProcessLeaderboardCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if expiresAt, ok := c.ttl[key]; ok && time.Now().After(expiresAt) {
+		c.cache.Remove(key)
+		delete(c.ttl, key)
+		return nil, false, nil
+	}
+	return value.([]byte), true, nil
+}
+
+// Set implements LeaderboardCache.
+func (c *InProcessLeaderboardCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Add(key, value)
+	c.ttl[key] = time.Now().Add(ttl)
+	return nil
+}
+
+// Invalidate implements LeaderboardCache by dropping every key with the
+// given prefix.
+func (c *InProcessLeaderboardCache) Invalidate(_ context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range c.cache.Keys() {
+		k := key.(string)
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			c.cache.Remove(k)
+			delete(c.ttl, k)
+		}
+	}
+	return nil
+}
+
+// RedisLeaderboardCache is a LeaderboardCache backed by Redis, so cached
+// leaderboard pages and user ranks are shared across every scribble
+// replica instead of each keeping (and separately recomputing) its own
+// copy.
+type RedisLeaderboardCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisLeaderboardCache creates a RedisLeaderboardCache against client,
+// namespacing its keys under keyPrefix (e.g. "scribble:cache").
+func NewRedisLeaderboardCache(client *redis.Client, keyPrefix string) *RedisLeaderboardCache {
+	return &RedisLeaderboardCache{client: client, prefix: keyPrefix}
+}
+
+func (c *RedisLeaderboardCache) key(key string) string {
+	return c.prefix + ":" + key
+}
+
+// Get implements LeaderboardCache.
+func (c *RedisLeaderboardCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("RedisLeaderboardCache: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set implements LeaderboardCache.
+func (c *RedisLeaderboardCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.key(key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("RedisLeaderboardCache: %w", err)
+	}
+	return nil
+}
+
+// Invalidate implements LeaderboardCache by scanning for every key under
+// prefix and deleting them. SCAN is used instead of KEYS so this doesn't
+// block other Redis clients while it walks a large keyspace.
+func (c *RedisLeaderboardCache) Invalidate(ctx context.Context, prefix string) error {
+	pattern := c.key(prefix) + "*"
+	var keys []string
+	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("RedisLeaderboardCache: scan failed: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("RedisLeaderboardCache: del failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInProcessLeaderboardCache_GetMissThenHit(t *testing.T) {
+	c := NewInProcessLeaderboardCache(0)
+	ctx := context.Background()
+
+	if _, hit, err := c.Get(ctx, "lb:problems_solved:global:1:20"); err != nil || hit {
+		t.Fatalf("Get() on empty cache = hit %v, err %v, want false, nil", hit, err)
+	}
+
+	if err := c.Set(ctx, "lb:problems_solved:global:1:20", []byte(`{"page":1}`), time.Minute); err != nil {
+		t.Fatalf("Set() returned error: %v", err)
+	}
+
+	value, hit, err := c.Get(ctx, "lb:problems_solved:global:1:20")
+	if err != nil || !hit {
+		t.Fatalf("Get() after Set = hit %v, err %v, want true, nil", hit, err)
+	}
+	if string(value) != `{"page":1}` {
+		t.Errorf("Get() = %q, want %q", value, `{"page":1}`)
+	}
+}
+
+func TestInProcessLeaderboardCache_TTLExpiry(t *testing.T) {
+	c := NewInProcessLeaderboardCache(0)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "lb:user:1", []byte(`{}`), time.Millisecond); err != nil {
+		t.Fatalf("Set() returned error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, hit, err := c.Get(ctx, "lb:user:1"); err != nil || hit {
+		t.Fatalf("Get() after TTL expiry = hit %v, err %v, want false, nil", hit, err)
+	}
+}
+
+func TestInProcessLeaderboardCache_InvalidatePrefix(t *testing.T) {
+	c := NewInProcessLeaderboardCache(0)
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "lb:problems_solved:global:1:20", []byte(`{}`), time.Minute)
+	_ = c.Set(ctx, "lb:problems_solved:global:2:20", []byte(`{}`), time.Minute)
+	_ = c.Set(ctx, "lb:fastest_avg:global:1:20", []byte(`{}`), time.Minute)
+	_ = c.Set(ctx, "lb:user:1", []byte(`{}`), time.Minute)
+
+	if err := c.Invalidate(ctx, "lb:problems_solved:"); err != nil {
+		t.Fatalf("Invalidate() returned error: %v", err)
+	}
+
+	if _, hit, _ := c.Get(ctx, "lb:problems_solved:global:1:20"); hit {
+		t.Error("expected lb:problems_solved:global:1:20 to be invalidated")
+	}
+	if _, hit, _ := c.Get(ctx, "lb:problems_solved:global:2:20"); hit {
+		t.Error("expected lb:problems_solved:global:2:20 to be invalidated")
+	}
+	if _, hit, _ := c.Get(ctx, "lb:fastest_avg:global:1:20"); !hit {
+		t.Error("expected lb:fastest_avg:global:1:20 to survive an unrelated metric's invalidation")
+	}
+	if _, hit, _ := c.Get(ctx, "lb:user:1"); !hit {
+		t.Error("expected lb:user:1 to survive a metric-prefix invalidation")
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/skiplist"
+)
+
+// LeaderboardRankStore maintains a sorted set of (userID, value) pairs per
+// metric type so LeaderboardService can re-rank just the neighborhood
+// around a changed value instead of recomputing every user's rank.
+// InMemoryRankStore is the default; a Redis ZSET-backed implementation can
+// satisfy this same interface to share ranks across replicas.
+type LeaderboardRankStore interface {
+	// Upsert records userID's current value for metricType, inserting it
+	// in sorted order (ascending is the caller's responsibility - lower or
+	// higher "better" is a LeaderboardService concern, not the store's).
+	Upsert(ctx context.Context, metricType models.MetricType, userID uint, value float64) error
+	// Neighborhood returns the radius entries on either side of userID's
+	// current position for metricType (inclusive of userID), in sorted
+	// order, for a cheap partial re-rank.
+	Neighborhood(ctx context.Context, metricType models.MetricType, userID uint, radius int) ([]RankedUser, error)
+}
+
+// RankedUser is one entry in a LeaderboardRankStore's sorted order.
+type RankedUser struct {
+	UserID uint
+	Value  float64
+	Rank   int // 1-based position in the full sorted set
+}
+
+// InMemoryRankStore is a LeaderboardRankStore backed by one sorted slice per
+// metric type, kept in ascending order via binary-search insert. Finding a
+// user's entry (in both Upsert and Neighborhood) is a linear scan, and
+// insert/update is O(n) to shift the slice, which is acceptable for the bounded per-metric population this
+// tracks (one entry per user).
+type InMemoryRankStore struct {
+	mu    sync.Mutex
+	byKey map[models.MetricType][]RankedUser
+}
+
+// NewInMemoryRankStore creates an empty InMemoryRankStore.
+func NewInMemoryRankStore() *InMemoryRankStore {
+	return &InMemoryRankStore{byKey: make(map[models.MetricType][]RankedUser)}
+}
+
+// Upsert implements LeaderboardRankStore.
+func (s *InMemoryRankStore) Upsert(ctx context.Context, metricType models.MetricType, userID uint, value float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.byKey[metricType]
+
+	for i, e := range entries {
+		if e.UserID == userID {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].Value >= value })
+	entries = append(entries, RankedUser{})
+	copy(entries[idx+1:], entries[idx:])
+	entries[idx] = RankedUser{UserID: userID, Value: value}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	s.byKey[metricType] = entries
+
+	return nil
+}
+
+// Neighborhood implements LeaderboardRankStore.
+func (s *InMemoryRankStore) Neighborhood(ctx context.Context, metricType models.MetricType, userID uint, radius int) ([]RankedUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.byKey[metricType]
+
+	pos := -1
+	for i, e := range entries {
+		if e.UserID == userID {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return nil, nil
+	}
+
+	start := pos - radius
+	if start < 0 {
+		start = 0
+	}
+	end := pos + radius + 1
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	neighborhood := make([]RankedUser, end-start)
+	copy(neighborhood, entries[start:end])
+
+	return neighborhood, nil
+}
+
+// SkiplistRankStore adapts skiplist.RankCache to LeaderboardRankStore,
+// trading InMemoryRankStore's O(n) insert/neighborhood for the skip list's
+// O(log n) - the tradeoff that starts to matter once a metric's population
+// grows past a few thousand users. See internal/skiplist for the
+// underlying order-statistics skip list.
+type SkiplistRankStore struct {
+	cache *skiplist.RankCache
+}
+
+// NewSkiplistRankStore creates an empty SkiplistRankStore.
+func NewSkiplistRankStore() *SkiplistRankStore {
+	return &SkiplistRankStore{cache: skiplist.NewRankCache()}
+}
+
+// Upsert implements LeaderboardRankStore.
+func (s *SkiplistRankStore) Upsert(ctx context.Context, metricType models.MetricType, userID uint, value float64) error {
+	s.cache.Upsert(metricType, userID, value)
+	return nil
+}
+
+// Neighborhood implements LeaderboardRankStore.
+func (s *SkiplistRankStore) Neighborhood(ctx context.Context, metricType models.MetricType, userID uint, radius int) ([]RankedUser, error) {
+	rank, ok := s.cache.RankOf(metricType, userID)
+	if !ok {
+		return nil, nil
+	}
+
+	offset := rank - 1 - radius
+	if offset < 0 {
+		offset = 0
+	}
+
+	keys := s.cache.Range(metricType, offset, radius*2+1)
+	neighborhood := make([]RankedUser, len(keys))
+	for i, k := range keys {
+		neighborhood[i] = RankedUser{UserID: k.UserID, Value: k.Value, Rank: offset + i + 1}
+	}
+	return neighborhood, nil
+}
@@ -1,7 +1,11 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"time"
 
 	"github.com/nahtao97/scribble/internal/models"
@@ -9,9 +13,27 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// leaderboardRerankRadius is how many entries on either side of a changed
+// user get their Rank column refreshed by an incremental update, instead of
+// recomputing every rank from scratch.
+const leaderboardRerankRadius = 25
+
+// leaderboardHigherIsBetter says which metric types rank higher raw values
+// first. The others (execution time, memory) rank lower values first.
+var leaderboardHigherIsBetter = map[models.MetricType]bool{
+	models.MetricFastestAvg:             false,
+	models.MetricLowestMemoryAvg:        false,
+	models.MetricProblemsSolved:         true,
+	models.MetricLongestStreak:          true,
+	models.MetricFastestPercentile:      true,
+	models.MetricLowestMemoryPercentile: true,
+}
+
 // LeaderboardService handles leaderboard computation and retrieval
 type LeaderboardService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	eventBus  EventBus
+	rankStore LeaderboardRankStore
 }
 
 // NewLeaderboardService creates a new leaderboard service instance
@@ -21,7 +43,175 @@ func NewLeaderboardService(db *gorm.DB) *LeaderboardService {
 	}
 }
 
-// ComputeAllLeaderboards computes rankings for all metric types
+// AttachEventBus subscribes the service to bus so individual
+// SubmissionAccepted/StreakUpdated/ProblemSolved events update the affected
+// user's rank incrementally via rankStore, instead of every accepted
+// submission waiting on the next full ComputeAllLeaderboards cron run (see
+// Scheduler.RegisterLeaderboardJobs, which remains the fallback path that
+// corrects any drift from incremental updates).
+func (s *LeaderboardService) AttachEventBus(bus EventBus, rankStore LeaderboardRankStore) {
+	s.eventBus = bus
+	s.rankStore = rankStore
+
+	bus.Subscribe(TopicSubmissionAccepted, s.handleSubmissionAccepted)
+	bus.Subscribe(TopicProblemSolved, s.handleProblemSolved)
+	bus.Subscribe(TopicStreakUpdated, s.handleStreakUpdated)
+}
+
+// handleSubmissionAccepted folds a newly accepted submission into the
+// user's running execution-time and memory averages, then incrementally
+// re-ranks the neighborhood around their new values.
+func (s *LeaderboardService) handleSubmissionAccepted(ctx context.Context, event interface{}) error {
+	e, ok := event.(SubmissionAcceptedEvent)
+	if !ok {
+		return fmt.Errorf("leaderboard: unexpected event type for %s", TopicSubmissionAccepted)
+	}
+
+	avgTime, err := s.incrementRunningAverage(models.MetricFastestAvg, e.UserID, float64(e.ExecutionTimeMs))
+	if err != nil {
+		return err
+	}
+	if err := s.applyIncrementalUpdate(ctx, models.MetricFastestAvg, e.UserID, avgTime); err != nil {
+		return err
+	}
+
+	avgMemory, err := s.incrementRunningAverage(models.MetricLowestMemoryAvg, e.UserID, float64(e.MemoryUsedKb))
+	if err != nil {
+		return err
+	}
+	if err := s.applyIncrementalUpdate(ctx, models.MetricLowestMemoryAvg, e.UserID, avgMemory); err != nil {
+		return err
+	}
+
+	// RecomputeProblemStats only touches e.ProblemID's own perf stats rows,
+	// so this stays cheap on the hot accepted-submission path; the
+	// percentile metrics themselves are still read at the next
+	// ComputeLeaderboard run, same as every other metric here.
+	return s.RecomputeProblemStats(e.ProblemID)
+}
+
+// handleProblemSolved folds a newly solved problem into the user's solved
+// count and incrementally re-ranks around their new value.
+func (s *LeaderboardService) handleProblemSolved(ctx context.Context, event interface{}) error {
+	e, ok := event.(ProblemSolvedEvent)
+	if !ok {
+		return fmt.Errorf("leaderboard: unexpected event type for %s", TopicProblemSolved)
+	}
+
+	count, err := s.incrementSolvedCount(e.UserID)
+	if err != nil {
+		return err
+	}
+	return s.applyIncrementalUpdate(ctx, models.MetricProblemsSolved, e.UserID, float64(count))
+}
+
+// handleStreakUpdated incrementally re-ranks MetricLongestStreak around a
+// user's new longest streak, already computed by StreakService.
+func (s *LeaderboardService) handleStreakUpdated(ctx context.Context, event interface{}) error {
+	e, ok := event.(StreakUpdatedEvent)
+	if !ok {
+		return fmt.Errorf("leaderboard: unexpected event type for %s", TopicStreakUpdated)
+	}
+
+	return s.applyIncrementalUpdate(ctx, models.MetricLongestStreak, e.UserID, float64(e.LongestStreak))
+}
+
+// incrementRunningAverage folds value into userID's running sum/count for
+// metricType and returns the updated average, without re-scanning every
+// submission for that user.
+func (s *LeaderboardService) incrementRunningAverage(metricType models.MetricType, userID uint, value float64) (float64, error) {
+	stat := models.LeaderboardRunningStat{UserID: userID, MetricType: metricType, Sum: value, Count: 1}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "metric_type"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"sum":   gorm.Expr("leaderboard_running_stats.sum + ?", value),
+			"count": gorm.Expr("leaderboard_running_stats.count + 1"),
+		}),
+	}).Create(&stat).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to update running average for %s: %w", metricType, err)
+	}
+
+	var updated models.LeaderboardRunningStat
+	if err := s.db.Where("user_id = ? AND metric_type = ?", userID, metricType).First(&updated).Error; err != nil {
+		return 0, fmt.Errorf("failed to load running average for %s: %w", metricType, err)
+	}
+
+	return updated.Sum / float64(updated.Count), nil
+}
+
+// incrementSolvedCount bumps userID's MetricProblemsSolved running count and
+// returns its new value.
+func (s *LeaderboardService) incrementSolvedCount(userID uint) (int64, error) {
+	stat := models.LeaderboardRunningStat{UserID: userID, MetricType: models.MetricProblemsSolved, Count: 1}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "metric_type"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("leaderboard_running_stats.count + 1")}),
+	}).Create(&stat).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to update solved count: %w", err)
+	}
+
+	var updated models.LeaderboardRunningStat
+	if err := s.db.Where("user_id = ? AND metric_type = ?", userID, models.MetricProblemsSolved).First(&updated).Error; err != nil {
+		return 0, fmt.Errorf("failed to load solved count: %w", err)
+	}
+
+	return updated.Count, nil
+}
+
+// applyIncrementalUpdate upserts userID's new metric_value into
+// leaderboard_cache, then (when a rank store is attached) updates the
+// sorted rank store and refreshes the Rank column for everyone in the
+// neighborhood around userID's new position. It only maintains the
+// global-scope entry for metricType; weekly/daily/language/difficulty
+// scopes are refreshed by the cron-scheduled ComputeLeaderboardLockedForScope
+// instead (see Scheduler.RegisterLeaderboardScopeJobs).
+func (s *LeaderboardService) applyIncrementalUpdate(ctx context.Context, metricType models.MetricType, userID uint, value float64) error {
+	entry := models.LeaderboardEntry{
+		UserID:      userID,
+		MetricType:  metricType,
+		Scope:       models.ScopeGlobal,
+		MetricValue: value,
+		ComputedAt:  time.Now(),
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "metric_type"}, {Name: "scope"}},
+		DoUpdates: clause.AssignmentColumns([]string{"metric_value", "computed_at"}),
+	}).Create(&entry).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert leaderboard entry for %s: %w", metricType, err)
+	}
+
+	if s.rankStore == nil {
+		return nil
+	}
+
+	rankValue := value
+	if leaderboardHigherIsBetter[metricType] {
+		rankValue = -value
+	}
+	if err := s.rankStore.Upsert(ctx, metricType, userID, rankValue); err != nil {
+		return fmt.Errorf("failed to update rank store for %s: %w", metricType, err)
+	}
+
+	neighborhood, err := s.rankStore.Neighborhood(ctx, metricType, userID, leaderboardRerankRadius)
+	if err != nil {
+		return fmt.Errorf("failed to fetch rank neighborhood for %s: %w", metricType, err)
+	}
+
+	for _, ranked := range neighborhood {
+		if err := s.db.Model(&models.LeaderboardEntry{}).
+			Where("user_id = ? AND metric_type = ? AND scope = ?", ranked.UserID, metricType, models.ScopeGlobal).
+			Update("rank", ranked.Rank).Error; err != nil {
+			return fmt.Errorf("failed to update rank for user %d on %s: %w", ranked.UserID, metricType, err)
+		}
+	}
+
+	return nil
+}
+
+// ComputeAllLeaderboards computes global-scope rankings for all metric types
 func (s *LeaderboardService) ComputeAllLeaderboards() ([]models.ComputeResult, error) {
 	var results []models.ComputeResult
 
@@ -36,8 +226,21 @@ func (s *LeaderboardService) ComputeAllLeaderboards() ([]models.ComputeResult, e
 	return results, nil
 }
 
-// ComputeLeaderboard computes rankings for a specific metric type
+// ComputeLeaderboard computes global-scope rankings for a specific metric
+// type. It's a convenience wrapper around ComputeLeaderboardForScope for
+// the common case; see that method for weekly/daily/language/difficulty
+// scopes.
 func (s *LeaderboardService) ComputeLeaderboard(metricType models.MetricType) (*models.ComputeResult, error) {
+	return s.ComputeLeaderboardForScope(metricType, models.ScopeGlobal)
+}
+
+// ComputeLeaderboardForScope computes rankings for metricType restricted to
+// scope: ScopeGlobal (all time, no filter), ScopeWeekly/ScopeDaily (rolling
+// since the most recent Monday/midnight UTC), or a LanguageScope/
+// DifficultyScope dimension filter. MetricLongestStreak has no per-
+// submission timestamp or language/difficulty to filter by, so every scope
+// computes the same global ranking for it.
+func (s *LeaderboardService) ComputeLeaderboardForScope(metricType models.MetricType, scope models.LeaderboardScope) (*models.ComputeResult, error) {
 	now := time.Now()
 
 	var entries []models.LeaderboardEntry
@@ -45,13 +248,23 @@ func (s *LeaderboardService) ComputeLeaderboard(metricType models.MetricType) (*
 
 	switch metricType {
 	case models.MetricFastestAvg:
-		entries, err = s.computeFastestAvg()
+		entries, err = s.computeFastestAvg(scope)
 	case models.MetricLowestMemoryAvg:
-		entries, err = s.computeLowestMemoryAvg()
+		entries, err = s.computeLowestMemoryAvg(scope)
 	case models.MetricProblemsSolved:
-		entries, err = s.computeProblemsSolved()
+		entries, err = s.computeProblemsSolved(scope)
 	case models.MetricLongestStreak:
-		entries, err = s.computeLongestStreak()
+		entries, err = s.computeLongestStreak(scope)
+	case models.MetricMedianRuntime:
+		entries, err = s.computePercentileRuntime(models.MetricMedianRuntime, 0.5, scope)
+	case models.MetricP95Runtime:
+		entries, err = s.computePercentileRuntime(models.MetricP95Runtime, 0.95, scope)
+	case models.MetricWeightedScore:
+		entries, err = s.computeWeightedScore(scope)
+	case models.MetricFastestPercentile:
+		entries, err = s.computeFastestPercentile(scope)
+	case models.MetricLowestMemoryPercentile:
+		entries, err = s.computeLowestMemoryPercentile(scope)
 	default:
 		return nil, fmt.Errorf("unknown metric type: %s", metricType)
 	}
@@ -60,12 +273,21 @@ func (s *LeaderboardService) ComputeLeaderboard(metricType models.MetricType) (*
 		return nil, err
 	}
 
+	scopePeriod, _, err := s.scopeWindow(scope, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Scope = scope
+		entries[i].ScopePeriod = scopePeriod
+	}
+
 	// Upsert entries into leaderboard_cache
 	if len(entries) > 0 {
 		// Use ON CONFLICT to update existing entries
 		result := s.db.Clauses(clause.OnConflict{
-			Columns:   []clause.Column{{Name: "user_id"}, {Name: "metric_type"}},
-			DoUpdates: clause.AssignmentColumns([]string{"metric_value", "rank", "computed_at"}),
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "metric_type"}, {Name: "scope"}},
+			DoUpdates: clause.AssignmentColumns([]string{"metric_value", "rank", "scope_period", "computed_at"}),
 		}).Create(&entries)
 
 		if result.Error != nil {
@@ -75,14 +297,87 @@ func (s *LeaderboardService) ComputeLeaderboard(metricType models.MetricType) (*
 
 	return &models.ComputeResult{
 		MetricType:     metricType,
+		Scope:          scope,
 		EntriesUpdated: len(entries),
 		ComputedAt:     now,
 	}, nil
 }
 
+// scopeWindow returns the submitted-at bounds a time-windowed scope ranks
+// from: the most recent Monday 00:00 UTC for ScopeWeekly, today's midnight
+// UTC for ScopeDaily, the first of the month 00:00 UTC for ScopeMonthly,
+// or a Season's own StartsAt/EndsAt for a SeasonScope (looked up from the
+// seasons table, since a season's window doesn't follow the calendar).
+// start is nil for scopes with no time window (global, language:*,
+// difficulty:*); end is non-nil only once a season has been closed.
+func (s *LeaderboardService) scopeWindow(scope models.LeaderboardScope, now time.Time) (start, end *time.Time, err error) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch scope {
+	case models.ScopeDaily:
+		return &midnight, nil, nil
+	case models.ScopeWeekly:
+		// time.Weekday is Sunday=0..Saturday=6; shift so Monday=0..Sunday=6.
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		monday := midnight.AddDate(0, 0, -daysSinceMonday)
+		return &monday, nil, nil
+	case models.ScopeMonthly:
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return &monthStart, nil, nil
+	}
+
+	if seasonID, ok := scope.Season(); ok {
+		var season models.Season
+		if err := s.db.First(&season, seasonID).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to load season %d: %w", seasonID, err)
+		}
+		seasonStart := season.StartsAt
+		return &seasonStart, season.EndsAt, nil
+	}
+
+	return nil, nil, nil
+}
+
+// applyLeaderboardScope filters a query over submissions (optionally
+// aliased) to scope: a submitted-at range for ScopeWeekly/ScopeDaily/
+// ScopeMonthly/SeasonScope, or a language equality filter for a
+// LanguageScope. DifficultyScope isn't handled here since it requires a
+// join with problems, which not every caller already has.
+func (s *LeaderboardService) applyLeaderboardScope(query *gorm.DB, submissionsTable string, scope models.LeaderboardScope, now time.Time) (*gorm.DB, error) {
+	start, end, err := s.scopeWindow(scope, now)
+	if err != nil {
+		return nil, err
+	}
+	if start != nil {
+		query = query.Where(submissionsTable+".created_at >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where(submissionsTable+".created_at < ?", *end)
+	}
+	if language, ok := scope.Language(); ok {
+		query = query.Where(submissionsTable+".language = ?", language)
+	}
+	return query, nil
+}
+
+// applyDifficultyScope joins problems and filters on its difficulty when
+// scope is a DifficultyScope, for queries that don't already join problems
+// themselves. computeWeightedScore already joins problems for its own
+// reasons, so it applies the filter directly instead of calling this.
+func (s *LeaderboardService) applyDifficultyScope(query *gorm.DB, submissionsTable string, scope models.LeaderboardScope) *gorm.DB {
+	difficulty, ok := scope.Difficulty()
+	if !ok {
+		return query
+	}
+	return query.
+		Joins("JOIN problems ON problems.id = "+submissionsTable+".problem_id").
+		Where("problems.difficulty = ?", difficulty)
+}
+
 // computeFastestAvg calculates average execution time rankings
 // Lower is better - ranks users by their average execution time for accepted submissions
-func (s *LeaderboardService) computeFastestAvg() ([]models.LeaderboardEntry, error) {
+func (s *LeaderboardService) computeFastestAvg(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
 	type userAvg struct {
 		UserID uint
 		Avg    float64
@@ -91,9 +386,16 @@ func (s *LeaderboardService) computeFastestAvg() ([]models.LeaderboardEntry, err
 	var results []userAvg
 
 	// Calculate average execution time per user for accepted submissions
-	err := s.db.Table("submissions").
+	query := s.db.Table("submissions").
 		Select("user_id, AVG(execution_time_ms) as avg").
-		Where("status = 'accepted' AND execution_time_ms IS NOT NULL").
+		Where("status = 'accepted' AND execution_time_ms IS NOT NULL")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query = s.applyDifficultyScope(query, "submissions", scope)
+
+	err = query.
 		Group("user_id").
 		Having("COUNT(*) >= 1"). // Require at least 1 accepted submission
 		Order("avg ASC").        // Lower is better
@@ -119,7 +421,7 @@ func (s *LeaderboardService) computeFastestAvg() ([]models.LeaderboardEntry, err
 
 // computeLowestMemoryAvg calculates average memory usage rankings
 // Lower is better - ranks users by their average memory usage for accepted submissions
-func (s *LeaderboardService) computeLowestMemoryAvg() ([]models.LeaderboardEntry, error) {
+func (s *LeaderboardService) computeLowestMemoryAvg(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
 	type userAvg struct {
 		UserID uint
 		Avg    float64
@@ -127,9 +429,16 @@ func (s *LeaderboardService) computeLowestMemoryAvg() ([]models.LeaderboardEntry
 
 	var results []userAvg
 
-	err := s.db.Table("submissions").
+	query := s.db.Table("submissions").
 		Select("user_id, AVG(memory_used_kb) as avg").
-		Where("status = 'accepted' AND memory_used_kb IS NOT NULL").
+		Where("status = 'accepted' AND memory_used_kb IS NOT NULL")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query = s.applyDifficultyScope(query, "submissions", scope)
+
+	err = query.
 		Group("user_id").
 		Having("COUNT(*) >= 1").
 		Order("avg ASC"). // Lower is better
@@ -154,8 +463,13 @@ func (s *LeaderboardService) computeLowestMemoryAvg() ([]models.LeaderboardEntry
 }
 
 // computeProblemsSolved calculates unique problems solved rankings
-// Higher is better - counts distinct problems with at least one accepted submission
-func (s *LeaderboardService) computeProblemsSolved() ([]models.LeaderboardEntry, error) {
+// Higher is better - counts distinct problems with at least one accepted
+// submission. ScopeGlobal reads the count straight out of the
+// user_metrics table (kept current by MetricsService.RefreshUserMetrics/
+// RefreshAllMetrics) instead of re-aggregating every submission; the
+// time-windowed and language/difficulty scopes have no equivalent in that
+// table, so they still aggregate submissions directly.
+func (s *LeaderboardService) computeProblemsSolved(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
 	type userCount struct {
 		UserID uint
 		Count  int
@@ -163,9 +477,39 @@ func (s *LeaderboardService) computeProblemsSolved() ([]models.LeaderboardEntry,
 
 	var results []userCount
 
-	err := s.db.Table("submissions").
+	if scope == models.ScopeGlobal {
+		err := s.db.Table("user_metrics").
+			Select("user_id, problems_solved as count").
+			Where("problems_solved > 0").
+			Order("count DESC"). // Higher is better
+			Scan(&results).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute problems solved: %w", err)
+		}
+
+		entries := make([]models.LeaderboardEntry, len(results))
+		for i, r := range results {
+			entries[i] = models.LeaderboardEntry{
+				UserID:      r.UserID,
+				MetricType:  models.MetricProblemsSolved,
+				MetricValue: float64(r.Count),
+				Rank:        i + 1,
+				ComputedAt:  time.Now(),
+			}
+		}
+		return entries, nil
+	}
+
+	query := s.db.Table("submissions").
 		Select("user_id, COUNT(DISTINCT problem_id) as count").
-		Where("status = 'accepted'").
+		Where("status = 'accepted'")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query = s.applyDifficultyScope(query, "submissions", scope)
+
+	err = query.
 		Group("user_id").
 		Order("count DESC"). // Higher is better
 		Scan(&results).Error
@@ -189,8 +533,11 @@ func (s *LeaderboardService) computeProblemsSolved() ([]models.LeaderboardEntry,
 }
 
 // computeLongestStreak calculates longest streak rankings
-// Higher is better - based on the streaks table
-func (s *LeaderboardService) computeLongestStreak() ([]models.LeaderboardEntry, error) {
+// Higher is better - based on user_metrics (kept current by
+// MetricsService.RefreshUserMetrics/RefreshAllMetrics), which has no
+// submission timestamp or language/difficulty dimension to filter by, so
+// every scope computes the same global ranking here.
+func (s *LeaderboardService) computeLongestStreak(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
 	type userStreak struct {
 		UserID        uint
 		LongestStreak int
@@ -198,7 +545,7 @@ func (s *LeaderboardService) computeLongestStreak() ([]models.LeaderboardEntry,
 
 	var results []userStreak
 
-	err := s.db.Table("streaks").
+	err := s.db.Table("user_metrics").
 		Select("user_id, longest_streak").
 		Where("longest_streak > 0").
 		Order("longest_streak DESC"). // Higher is better
@@ -222,8 +569,501 @@ func (s *LeaderboardService) computeLongestStreak() ([]models.LeaderboardEntry,
 	return entries, nil
 }
 
-// GetLeaderboard retrieves paginated leaderboard for a metric type
+// computePercentileRuntime ranks users by a percentile (e.g. 0.5 for
+// median, 0.95 for p95) of their accepted submissions' execution time.
+// Lower is better. Computed via percentile_cont on Postgres; SQLite (used
+// in tests) has no percentile_cont, so it falls back to a sorted
+// in-memory computation of the same percentile.
+func (s *LeaderboardService) computePercentileRuntime(metricType models.MetricType, percentile float64, scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	if s.db.Dialector.Name() == "sqlite" {
+		return s.computePercentileRuntimeInMemory(metricType, percentile, scope)
+	}
+
+	type userPercentile struct {
+		UserID uint
+		Value  float64
+	}
+
+	var results []userPercentile
+	query := s.db.Table("submissions").
+		Select("user_id, percentile_cont(?) WITHIN GROUP (ORDER BY execution_time_ms) as value", percentile).
+		Where("status = 'accepted' AND execution_time_ms IS NOT NULL")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query = s.applyDifficultyScope(query, "submissions", scope)
+
+	err = query.
+		Group("user_id").
+		Order("value ASC"). // Lower is better
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute %s: %w", metricType, err)
+	}
+
+	entries := make([]models.LeaderboardEntry, len(results))
+	for i, r := range results {
+		entries[i] = models.LeaderboardEntry{
+			UserID:      r.UserID,
+			MetricType:  metricType,
+			MetricValue: r.Value,
+			Rank:        i + 1,
+			ComputedAt:  time.Now(),
+		}
+	}
+
+	return entries, nil
+}
+
+// computePercentileRuntimeInMemory is the SQLite-compatible fallback for
+// computePercentileRuntime: it loads every accepted submission's execution
+// time, groups it by user, and computes the percentile over each user's
+// sorted values in Go instead of in the database.
+func (s *LeaderboardService) computePercentileRuntimeInMemory(metricType models.MetricType, percentile float64, scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	type userTime struct {
+		UserID          uint
+		ExecutionTimeMs int
+	}
+
+	query := s.db.Table("submissions").
+		Select("user_id, execution_time_ms").
+		Where("status = 'accepted' AND execution_time_ms IS NOT NULL")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	query = s.applyDifficultyScope(query, "submissions", scope)
+
+	var rows []userTime
+	err = query.Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute %s: %w", metricType, err)
+	}
+
+	timesByUser := make(map[uint][]float64)
+	order := make([]uint, 0)
+	for _, r := range rows {
+		if _, seen := timesByUser[r.UserID]; !seen {
+			order = append(order, r.UserID)
+		}
+		timesByUser[r.UserID] = append(timesByUser[r.UserID], float64(r.ExecutionTimeMs))
+	}
+
+	type userPercentile struct {
+		UserID uint
+		Value  float64
+	}
+	results := make([]userPercentile, 0, len(order))
+	for _, userID := range order {
+		results = append(results, userPercentile{UserID: userID, Value: percentileOf(timesByUser[userID], percentile)})
+	}
+
+	// Stable tie-break by user ID, matching ORDER BY value ASC with
+	// deterministic ties for users sharing the exact same percentile.
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Value != results[j].Value {
+			return results[i].Value < results[j].Value
+		}
+		return results[i].UserID < results[j].UserID
+	})
+
+	entries := make([]models.LeaderboardEntry, len(results))
+	for i, r := range results {
+		entries[i] = models.LeaderboardEntry{
+			UserID:      r.UserID,
+			MetricType:  metricType,
+			MetricValue: r.Value,
+			Rank:        i + 1,
+			ComputedAt:  time.Now(),
+		}
+	}
+
+	return entries, nil
+}
+
+// percentileOf returns the linear-interpolated percentile (matching
+// Postgres's percentile_cont) of values, which must be non-empty.
+func percentileOf(values []float64, percentile float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := percentile * float64(len(sorted)-1)
+	lower := int(rank)
+	upper := lower + 1
+	if upper >= len(sorted) {
+		return sorted[lower]
+	}
+
+	frac := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
+
+// computeWeightedScore ranks users by a difficulty-weighted score: for each
+// problem a user has submitted, the fraction of that problem's test case
+// weight (TestCase.Weight) passed by their best submission, times the
+// problem's difficulty multiplier, summed across every problem they've
+// attempted. Higher is better. A DifficultyScope filters to only that
+// difficulty's problems rather than weighting all difficulties together.
+func (s *LeaderboardService) computeWeightedScore(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	type submissionRow struct {
+		UserID       uint
+		ProblemID    uint
+		Difficulty   string
+		WeightPassed float64
+		WeightTotal  float64
+	}
+
+	query := s.db.Table("submissions").
+		Select(`submissions.user_id, submissions.problem_id, problems.difficulty,
+			SUM(CASE WHEN test_results.passed THEN test_cases.weight ELSE 0 END) as weight_passed,
+			SUM(test_cases.weight) as weight_total`).
+		Joins("JOIN problems ON problems.id = submissions.problem_id").
+		Joins("JOIN test_results ON test_results.submission_id = submissions.id").
+		Joins("JOIN test_cases ON test_cases.id = test_results.test_case_id")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if difficulty, ok := scope.Difficulty(); ok {
+		query = query.Where("problems.difficulty = ?", difficulty)
+	}
+
+	var rows []submissionRow
+	err = query.
+		Group("submissions.id, submissions.user_id, submissions.problem_id, problems.difficulty").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute weighted score: %w", err)
+	}
+
+	// Keep only each user's best submission per problem (highest weighted
+	// fraction of test cases passed), so retries don't inflate the score.
+	type key struct {
+		userID, problemID uint
+	}
+	best := make(map[key]submissionRow)
+	for _, r := range rows {
+		if r.WeightTotal == 0 {
+			continue
+		}
+		k := key{r.UserID, r.ProblemID}
+		existing, ok := best[k]
+		if !ok || r.WeightPassed/r.WeightTotal > existing.WeightPassed/existing.WeightTotal {
+			best[k] = r
+		}
+	}
+
+	scoreByUser := make(map[uint]float64)
+	order := make([]uint, 0)
+	for _, r := range best {
+		if _, seen := scoreByUser[r.UserID]; !seen {
+			order = append(order, r.UserID)
+		}
+		fraction := r.WeightPassed / r.WeightTotal
+		scoreByUser[r.UserID] += fraction * models.DifficultyWeight(r.Difficulty)
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	sort.SliceStable(order, func(i, j int) bool { return scoreByUser[order[i]] > scoreByUser[order[j]] })
+
+	entries := make([]models.LeaderboardEntry, len(order))
+	for i, userID := range order {
+		entries[i] = models.LeaderboardEntry{
+			UserID:      userID,
+			MetricType:  models.MetricWeightedScore,
+			MetricValue: scoreByUser[userID],
+			Rank:        i + 1,
+			ComputedAt:  time.Now(),
+		}
+	}
+
+	return entries, nil
+}
+
+// computeFastestPercentile ranks users by the difficulty-weighted mean
+// percentile of their best accepted execution time per problem solved,
+// against that problem/language's own runtime distribution.
+func (s *LeaderboardService) computeFastestPercentile(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return s.computePercentileScore(models.MetricFastestPercentile, MetricRuntime, "execution_time_ms", scope)
+}
+
+// computeLowestMemoryPercentile ranks users by the difficulty-weighted mean
+// percentile of their best accepted memory usage per problem solved,
+// against that problem/language's own memory distribution.
+func (s *LeaderboardService) computeLowestMemoryPercentile(scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return s.computePercentileScore(models.MetricLowestMemoryPercentile, MetricMemory, "memory_used_kb", scope)
+}
+
+// computePercentileScore is the shared implementation behind
+// computeFastestPercentile/computeLowestMemoryPercentile: for each user it
+// takes their best (lowest column value) accepted submission per problem,
+// looks up the percentile that value falls at within its problem/language's
+// TDigest (persisted to problem_perf_stats by RecomputeProblemStats), and
+// averages those percentiles weighted by problem difficulty. This ranks
+// users on how they compare to others on the same problem, instead of
+// rewarding whoever only attempted easy problems with tiny inputs the way
+// a raw AVG(column) would. Problems with fewer than MinDistributionSample
+// accepted submissions are skipped - their percentile would be too noisy to
+// mean anything.
+func (s *LeaderboardService) computePercentileScore(metricType models.MetricType, distMetric DistributionMetric, column string, scope models.LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	type submissionRow struct {
+		UserID     uint
+		ProblemID  uint
+		Language   string
+		Value      float64
+		Difficulty string
+	}
+
+	query := s.db.Table("submissions").
+		Select(fmt.Sprintf("submissions.user_id, submissions.problem_id, submissions.language, submissions.%s as value, problems.difficulty", column)).
+		Joins("JOIN problems ON problems.id = submissions.problem_id").
+		Where("submissions.status = 'accepted' AND submissions." + column + " IS NOT NULL")
+	query, err := s.applyLeaderboardScope(query, "submissions", scope, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if difficulty, ok := scope.Difficulty(); ok {
+		query = query.Where("problems.difficulty = ?", difficulty)
+	}
+
+	var rows []submissionRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute %s: %w", metricType, err)
+	}
+
+	// Keep only each user's best (lowest value) submission per problem.
+	type key struct {
+		userID    uint
+		problemID uint
+	}
+	best := make(map[key]submissionRow)
+	for _, r := range rows {
+		k := key{r.UserID, r.ProblemID}
+		if existing, ok := best[k]; !ok || r.Value < existing.Value {
+			best[k] = r
+		}
+	}
+
+	type digestKey struct {
+		problemID uint
+		language  string
+	}
+	digestCache := make(map[digestKey]*TDigest)
+	weightedSum := make(map[uint]float64)
+	weightTotal := make(map[uint]float64)
+	order := make([]uint, 0)
+
+	for _, r := range best {
+		cacheKey := digestKey{r.ProblemID, r.Language}
+		digest, cached := digestCache[cacheKey]
+		if !cached {
+			loaded, err := s.loadProblemDigest(r.ProblemID, r.Language, distMetric)
+			if err != nil {
+				return nil, err
+			}
+			digest = loaded
+			digestCache[cacheKey] = digest
+		}
+		if digest == nil || digest.Count() < MinDistributionSample {
+			continue
+		}
+
+		percentile := 1 - digest.CDF(r.Value)
+		weight := models.DifficultyWeight(r.Difficulty)
+
+		if _, seen := weightTotal[r.UserID]; !seen {
+			order = append(order, r.UserID)
+		}
+		weightedSum[r.UserID] += percentile * weight
+		weightTotal[r.UserID] += weight
+	}
+
+	scoreByUser := make(map[uint]float64, len(order))
+	for _, userID := range order {
+		if weightTotal[userID] > 0 {
+			scoreByUser[userID] = weightedSum[userID] / weightTotal[userID]
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+	sort.SliceStable(order, func(i, j int) bool { return scoreByUser[order[i]] > scoreByUser[order[j]] })
+
+	entries := make([]models.LeaderboardEntry, len(order))
+	for i, userID := range order {
+		entries[i] = models.LeaderboardEntry{
+			UserID:      userID,
+			MetricType:  metricType,
+			MetricValue: scoreByUser[userID],
+			Rank:        i + 1,
+			ComputedAt:  time.Now(),
+		}
+	}
+
+	return entries, nil
+}
+
+// loadProblemDigest loads and decodes the TDigest persisted to
+// problem_perf_stats for (problemID, language, metric), returning (nil,
+// nil) if RecomputeProblemStats hasn't computed one yet.
+func (s *LeaderboardService) loadProblemDigest(problemID uint, language string, metric DistributionMetric) (*TDigest, error) {
+	var stats models.ProblemPerfStats
+	err := s.db.Where("problem_id = ? AND language = ? AND metric = ?", problemID, language, string(metric)).
+		First(&stats).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load perf stats for problem %d/%s/%s: %w", problemID, language, metric, err)
+	}
+
+	digest := NewTDigest()
+	if err := digest.LoadSnapshot(stats.Digest); err != nil {
+		return nil, fmt.Errorf("failed to decode perf stats digest for problem %d/%s/%s: %w", problemID, language, metric, err)
+	}
+	return digest, nil
+}
+
+// RecomputeProblemStats rebuilds problemID's runtime and memory TDigests -
+// one pair per language it's been submitted in - from every one of its
+// accepted submissions, then persists each as a problem_perf_stats row.
+// Called after each accepted submission (handleSubmissionAccepted) so
+// MetricFastestPercentile/MetricLowestMemoryPercentile's next
+// ComputeLeaderboard run can look up a percentile in O(log k) against a
+// current digest, instead of rescanning every accepted submission to every
+// problem on every leaderboard computation. Since it only rescans the one
+// problem's own submissions, this is a full scan of a bounded slice, not of
+// the submissions table.
+func (s *LeaderboardService) RecomputeProblemStats(problemID uint) error {
+	type submissionRow struct {
+		Language        string
+		ExecutionTimeMs int
+		MemoryUsedKb    int
+	}
+
+	var rows []submissionRow
+	err := s.db.Table("submissions").
+		Select("language, execution_time_ms, memory_used_kb").
+		Where("problem_id = ? AND status = 'accepted' AND execution_time_ms IS NOT NULL AND memory_used_kb IS NOT NULL", problemID).
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to load accepted submissions for problem %d: %w", problemID, err)
+	}
+
+	digests := make(map[string]map[DistributionMetric]*TDigest)
+	for _, r := range rows {
+		if _, ok := digests[r.Language]; !ok {
+			digests[r.Language] = map[DistributionMetric]*TDigest{
+				MetricRuntime: NewTDigest(),
+				MetricMemory:  NewTDigest(),
+			}
+		}
+		digests[r.Language][MetricRuntime].Add(float64(r.ExecutionTimeMs))
+		digests[r.Language][MetricMemory].Add(float64(r.MemoryUsedKb))
+	}
+
+	for language, metrics := range digests {
+		for metric, digest := range metrics {
+			snapshot, err := digest.MarshalSnapshot()
+			if err != nil {
+				return fmt.Errorf("failed to marshal perf stats digest for problem %d/%s/%s: %w", problemID, language, metric, err)
+			}
+
+			stats := models.ProblemPerfStats{
+				ProblemID: problemID,
+				Language:  language,
+				Metric:    string(metric),
+				Count:     int64(digest.Count()),
+				Digest:    snapshot,
+				UpdatedAt: time.Now(),
+			}
+			err = s.db.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "problem_id"}, {Name: "language"}, {Name: "metric"}},
+				DoUpdates: clause.AssignmentColumns([]string{"count", "digest", "updated_at"}),
+			}).Create(&stats).Error
+			if err != nil {
+				return fmt.Errorf("failed to persist perf stats for problem %d/%s/%s: %w", problemID, language, metric, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// leaderboardLockKey derives a stable pg_try_advisory_lock key from a
+// metric type and scope. pg_try_advisory_lock takes a single bigint key, so
+// the pair is hashed into one rather than needing a lock-table row per
+// metric/scope.
+func leaderboardLockKey(metricType models.MetricType, scope models.LeaderboardScope) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(metricType))
+	h.Write([]byte{0})
+	h.Write([]byte(scope))
+	return int64(h.Sum64())
+}
+
+// ComputeLeaderboardLocked wraps ComputeLeaderboard (global scope) with a
+// pg_try_advisory_lock so only one server replica recomputes a given metric
+// at a time. See ComputeLeaderboardLockedForScope for other scopes.
+func (s *LeaderboardService) ComputeLeaderboardLocked(metricType models.MetricType) (*models.ComputeResult, error) {
+	return s.ComputeLeaderboardLockedForScope(metricType, models.ScopeGlobal)
+}
+
+// ComputeLeaderboardLockedForScope wraps ComputeLeaderboardForScope with a
+// pg_try_advisory_lock so only one server replica recomputes a given
+// metric/scope pair at a time, and records the outcome (start time,
+// duration, entries updated, error) to leaderboard_computations for
+// observability into a subsystem that otherwise runs unattended on a cron
+// schedule (see Scheduler.RegisterLeaderboardJobs and
+// Scheduler.RegisterLeaderboardScopeJobs). Returns (nil, nil) without error
+// when another replica already holds the lock for metricType/scope.
+func (s *LeaderboardService) ComputeLeaderboardLockedForScope(metricType models.MetricType, scope models.LeaderboardScope) (*models.ComputeResult, error) {
+	key := leaderboardLockKey(metricType, scope)
+
+	var acquired bool
+	if err := s.db.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+		return nil, fmt.Errorf("failed to acquire leaderboard lock for %s/%s: %w", metricType, scope, err)
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer s.db.Exec("SELECT pg_advisory_unlock(?)", key)
+
+	startedAt := time.Now()
+	result, computeErr := s.ComputeLeaderboardForScope(metricType, scope)
+
+	record := models.LeaderboardComputation{
+		MetricType: metricType,
+		Scope:      scope,
+		StartedAt:  startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+	}
+	if computeErr != nil {
+		record.Error = computeErr.Error()
+	} else {
+		record.EntriesUpdated = result.EntriesUpdated
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		fmt.Printf("warning: failed to record leaderboard computation for %s/%s: %v\n", metricType, scope, err)
+	}
+
+	return result, computeErr
+}
+
+// GetLeaderboard retrieves a paginated global-scope leaderboard for a
+// metric type. See GetLeaderboardForScope for weekly/daily/language/
+// difficulty scopes.
 func (s *LeaderboardService) GetLeaderboard(metricType models.MetricType, page, pageSize int) (*models.LeaderboardPage, error) {
+	return s.GetLeaderboardForScope(metricType, models.ScopeGlobal, page, pageSize)
+}
+
+// GetLeaderboardForScope retrieves a paginated leaderboard for a metric
+// type restricted to scope.
+func (s *LeaderboardService) GetLeaderboardForScope(metricType models.MetricType, scope models.LeaderboardScope, page, pageSize int) (*models.LeaderboardPage, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -234,7 +1074,7 @@ func (s *LeaderboardService) GetLeaderboard(metricType models.MetricType, page,
 	// Get total count
 	var total int64
 	s.db.Model(&models.LeaderboardEntry{}).
-		Where("metric_type = ?", metricType).
+		Where("metric_type = ? AND scope = ?", metricType, scope).
 		Count(&total)
 
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
@@ -246,7 +1086,7 @@ func (s *LeaderboardService) GetLeaderboard(metricType models.MetricType, page,
 	err := s.db.Table("leaderboard_cache lc").
 		Select("lc.*, u.username, u.avatar_url").
 		Joins("JOIN users u ON lc.user_id = u.id").
-		Where("lc.metric_type = ?", metricType).
+		Where("lc.metric_type = ? AND lc.scope = ?", metricType, scope).
 		Order("lc.rank ASC").
 		Offset(offset).
 		Limit(pageSize).
@@ -259,6 +1099,7 @@ func (s *LeaderboardService) GetLeaderboard(metricType models.MetricType, page,
 	return &models.LeaderboardPage{
 		Entries:    entries,
 		MetricType: metricType,
+		Scope:      scope,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
@@ -266,11 +1107,18 @@ func (s *LeaderboardService) GetLeaderboard(metricType models.MetricType, page,
 	}, nil
 }
 
-// GetUserRank retrieves a user's rank for a specific metric
+// GetUserRank retrieves a user's global-scope rank for a specific metric.
+// See GetUserRankForScope for weekly/daily/language/difficulty scopes.
 func (s *LeaderboardService) GetUserRank(userID uint, metricType models.MetricType) (*models.LeaderboardEntry, error) {
+	return s.GetUserRankForScope(userID, metricType, models.ScopeGlobal)
+}
+
+// GetUserRankForScope retrieves a user's rank for a specific metric within
+// scope.
+func (s *LeaderboardService) GetUserRankForScope(userID uint, metricType models.MetricType, scope models.LeaderboardScope) (*models.LeaderboardEntry, error) {
 	var entry models.LeaderboardEntry
 
-	result := s.db.Where("user_id = ? AND metric_type = ?", userID, metricType).First(&entry)
+	result := s.db.Where("user_id = ? AND metric_type = ? AND scope = ?", userID, metricType, scope).First(&entry)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil // User not ranked yet
@@ -281,7 +1129,7 @@ func (s *LeaderboardService) GetUserRank(userID uint, metricType models.MetricTy
 	return &entry, nil
 }
 
-// GetUserAllRanks retrieves a user's ranks for all metrics
+// GetUserAllRanks retrieves a user's ranks for all metrics and scopes
 func (s *LeaderboardService) GetUserAllRanks(userID uint) ([]models.LeaderboardEntry, error) {
 	var entries []models.LeaderboardEntry
 
@@ -292,3 +1140,86 @@ func (s *LeaderboardService) GetUserAllRanks(userID uint) ([]models.LeaderboardE
 
 	return entries, nil
 }
+
+// OpenSeason creates a new open Season starting at startsAt, ready to rank
+// under models.SeasonScope(season.ID) once submissions start coming in.
+func (s *LeaderboardService) OpenSeason(name string, startsAt time.Time) (*models.Season, error) {
+	season := models.Season{
+		Name:     name,
+		Status:   models.SeasonOpen,
+		StartsAt: startsAt,
+	}
+	if err := s.db.Create(&season).Error; err != nil {
+		return nil, fmt.Errorf("failed to open season %q: %w", name, err)
+	}
+	return &season, nil
+}
+
+// CloseSeason ends seasonID's window at the current time, recomputes every
+// metric one final time under its SeasonScope so the snapshot reflects
+// every submission up to the close, then freezes those rankings into the
+// immutable leaderboard_archive table. A closed season's scope keeps
+// working for historical reads (GetLeaderboardForScope,
+// GetUserRankForScope), but CloseSeason refuses to run twice for the same
+// season so the archive is written exactly once.
+func (s *LeaderboardService) CloseSeason(seasonID uint) (*models.ComputeResult, error) {
+	var season models.Season
+	if err := s.db.First(&season, seasonID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load season %d: %w", seasonID, err)
+	}
+	if season.Status == models.SeasonClosed {
+		return nil, fmt.Errorf("season %d is already closed", seasonID)
+	}
+
+	now := time.Now()
+	season.Status = models.SeasonClosed
+	season.EndsAt = &now
+	if err := s.db.Save(&season).Error; err != nil {
+		return nil, fmt.Errorf("failed to close season %d: %w", seasonID, err)
+	}
+
+	scope := models.SeasonScope(seasonID)
+	var lastResult *models.ComputeResult
+	for _, metricType := range models.AllMetricTypes() {
+		result, err := s.ComputeLeaderboardForScope(metricType, scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute final %s for season %d: %w", metricType, seasonID, err)
+		}
+		lastResult = result
+
+		var entries []models.LeaderboardEntry
+		if err := s.db.Where("metric_type = ? AND scope = ?", metricType, scope).Find(&entries).Error; err != nil {
+			return nil, fmt.Errorf("failed to load final %s entries for season %d: %w", metricType, seasonID, err)
+		}
+
+		archived := make([]models.LeaderboardArchiveEntry, len(entries))
+		for i, e := range entries {
+			archived[i] = models.LeaderboardArchiveEntry{
+				SeasonID:    seasonID,
+				UserID:      e.UserID,
+				MetricType:  e.MetricType,
+				MetricValue: e.MetricValue,
+				Rank:        e.Rank,
+				ArchivedAt:  now,
+			}
+		}
+		if len(archived) > 0 {
+			if err := s.db.Create(&archived).Error; err != nil {
+				return nil, fmt.Errorf("failed to archive %s for season %d: %w", metricType, seasonID, err)
+			}
+		}
+	}
+
+	return lastResult, nil
+}
+
+// GetUserHistoricalRanks returns userID's rank trajectory across every
+// closed season, ordered from most to least recently archived.
+func (s *LeaderboardService) GetUserHistoricalRanks(userID uint) ([]models.LeaderboardArchiveEntry, error) {
+	var entries []models.LeaderboardArchiveEntry
+	err := s.db.Where("user_id = ?", userID).Order("archived_at DESC").Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical ranks for user %d: %w", userID, err)
+	}
+	return entries, nil
+}
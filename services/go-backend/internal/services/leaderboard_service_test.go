@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/nahtao97/scribble/internal/db/migrations"
 	"github.com/nahtao97/scribble/internal/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -12,12 +14,14 @@ import (
 
 // Mock submission model for testing
 type TestSubmission struct {
-	ID              uint    `gorm:"primaryKey"`
-	UserID          uint    `gorm:"not null"`
-	ProblemID       uint    `gorm:"not null"`
-	Status          string  `gorm:"not null"`
-	ExecutionTimeMs *int    `gorm:"column:execution_time_ms"`
-	MemoryUsedKb    *int    `gorm:"column:memory_used_kb"`
+	ID              uint      `gorm:"primaryKey"`
+	UserID          uint      `gorm:"not null"`
+	ProblemID       uint      `gorm:"not null"`
+	Status          string    `gorm:"not null"`
+	Language        string    `gorm:"column:language"`
+	ExecutionTimeMs *int      `gorm:"column:execution_time_ms"`
+	MemoryUsedKb    *int      `gorm:"column:memory_used_kb"`
+	CreatedAt       time.Time `gorm:"column:created_at"`
 }
 
 func (TestSubmission) TableName() string {
@@ -36,10 +40,23 @@ func (TestStreak) TableName() string {
 	return "streaks"
 }
 
+// Mock user_metrics model for testing - mirrors models.UserMetrics, which
+// computeProblemsSolved/computeLongestStreak read for the global scope.
+type TestUserMetrics struct {
+	UserID         uint `gorm:"primaryKey"`
+	ProblemsSolved int  `gorm:"default:0"`
+	CurrentStreak  int  `gorm:"default:0"`
+	LongestStreak  int  `gorm:"default:0"`
+}
+
+func (TestUserMetrics) TableName() string {
+	return "user_metrics"
+}
+
 // Mock user model for testing
 type TestUser struct {
-	ID        uint    `gorm:"primaryKey"`
-	Username  string  `gorm:"not null"`
+	ID        uint   `gorm:"primaryKey"`
+	Username  string `gorm:"not null"`
 	AvatarURL *string
 }
 
@@ -47,6 +64,39 @@ func (TestUser) TableName() string {
 	return "users"
 }
 
+// Mock problem model for testing
+type TestProblem struct {
+	ID         uint `gorm:"primaryKey"`
+	Difficulty string
+}
+
+func (TestProblem) TableName() string {
+	return "problems"
+}
+
+// Mock test case model for testing
+type TestTestCase struct {
+	ID        uint `gorm:"primaryKey"`
+	ProblemID uint `gorm:"not null"`
+	Weight    float64
+}
+
+func (TestTestCase) TableName() string {
+	return "test_cases"
+}
+
+// Mock test result model for testing
+type TestTestResult struct {
+	ID           uint `gorm:"primaryKey"`
+	SubmissionID uint `gorm:"not null"`
+	TestCaseID   uint `gorm:"not null"`
+	Passed       bool
+}
+
+func (TestTestResult) TableName() string {
+	return "test_results"
+}
+
 // setupLeaderboardTestDB creates an in-memory SQLite database for testing
 func setupLeaderboardTestDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
@@ -56,15 +106,13 @@ func setupLeaderboardTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	// Migrate all required tables
-	err = db.AutoMigrate(
-		&models.LeaderboardEntry{},
-		&TestSubmission{},
-		&TestStreak{},
-		&TestUser{},
-	)
-	if err != nil {
-		t.Fatalf("failed to migrate test tables: %v", err)
+	// Run the real versioned migrations (internal/db/migrations) rather than
+	// GORM's AutoMigrate, so these tests exercise the same schema the
+	// sqlite-dialect migration files describe - including the columns
+	// LeaderboardEntry's uniqueIndex tags imply, which AutoMigrate applies
+	// independently of whatever the migration files say.
+	if err := migrations.NewRunner(db).MigrateTo(context.Background(), migrations.Latest); err != nil {
+		t.Fatalf("failed to run test migrations: %v", err)
 	}
 
 	return db
@@ -83,7 +131,7 @@ func TestComputeFastestAvg(t *testing.T) {
 		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100)},
 		{UserID: 1, ProblemID: 2, Status: "accepted", ExecutionTimeMs: intPtr(200)}, // User 1 avg: 150
 		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50)},
-		{UserID: 2, ProblemID: 2, Status: "accepted", ExecutionTimeMs: intPtr(50)},  // User 2 avg: 50 (faster)
+		{UserID: 2, ProblemID: 2, Status: "accepted", ExecutionTimeMs: intPtr(50)},     // User 2 avg: 50 (faster)
 		{UserID: 3, ProblemID: 1, Status: "wrong_answer", ExecutionTimeMs: intPtr(10)}, // Not counted - wrong answer
 	}
 	for _, s := range submissions {
@@ -131,7 +179,7 @@ func TestComputeLowestMemoryAvg(t *testing.T) {
 		{UserID: 1, ProblemID: 1, Status: "accepted", MemoryUsedKb: intPtr(1000)},
 		{UserID: 1, ProblemID: 2, Status: "accepted", MemoryUsedKb: intPtr(2000)}, // User 1 avg: 1500
 		{UserID: 2, ProblemID: 1, Status: "accepted", MemoryUsedKb: intPtr(500)},
-		{UserID: 2, ProblemID: 2, Status: "accepted", MemoryUsedKb: intPtr(500)},  // User 2 avg: 500 (lower)
+		{UserID: 2, ProblemID: 2, Status: "accepted", MemoryUsedKb: intPtr(500)}, // User 2 avg: 500 (lower)
 	}
 	for _, s := range submissions {
 		db.Create(&s)
@@ -160,21 +208,16 @@ func TestComputeProblemsSolved(t *testing.T) {
 	db := setupLeaderboardTestDB(t)
 	service := NewLeaderboardService(db)
 
-	// Create test submissions
-	submissions := []TestSubmission{
-		// User 1: solved problems 1, 2, 3 (3 unique)
-		{UserID: 1, ProblemID: 1, Status: "accepted"},
-		{UserID: 1, ProblemID: 2, Status: "accepted"},
-		{UserID: 1, ProblemID: 3, Status: "accepted"},
-		// User 2: solved problems 1, 2 (2 unique)
-		{UserID: 2, ProblemID: 1, Status: "accepted"},
-		{UserID: 2, ProblemID: 2, Status: "accepted"},
-		{UserID: 2, ProblemID: 2, Status: "accepted"}, // Duplicate problem - shouldn't count twice
-		// User 3: no accepted solutions
-		{UserID: 3, ProblemID: 1, Status: "wrong_answer"},
+	// computeProblemsSolved's global scope reads problems_solved straight out
+	// of user_metrics, kept current by MetricsService, rather than
+	// aggregating submissions itself.
+	metrics := []TestUserMetrics{
+		{UserID: 1, ProblemsSolved: 3},
+		{UserID: 2, ProblemsSolved: 2},
+		{UserID: 3, ProblemsSolved: 0}, // No accepted solutions - not counted
 	}
-	for _, s := range submissions {
-		db.Create(&s)
+	for _, m := range metrics {
+		db.Create(&m)
 	}
 
 	result, err := service.ComputeLeaderboard(models.MetricProblemsSolved)
@@ -213,14 +256,15 @@ func TestComputeLongestStreak(t *testing.T) {
 	db := setupLeaderboardTestDB(t)
 	service := NewLeaderboardService(db)
 
-	// Create test streaks
-	streaks := []TestStreak{
+	// Create test user_metrics rows - computeLongestStreak reads
+	// longest_streak from there, kept current by MetricsService.
+	metrics := []TestUserMetrics{
 		{UserID: 1, CurrentStreak: 5, LongestStreak: 10},
 		{UserID: 2, CurrentStreak: 3, LongestStreak: 15}, // User 2 has longest streak
 		{UserID: 3, CurrentStreak: 0, LongestStreak: 0},  // User 3 has no streak
 	}
-	for _, s := range streaks {
-		db.Create(&s)
+	for _, m := range metrics {
+		db.Create(&m)
 	}
 
 	result, err := service.ComputeLeaderboard(models.MetricLongestStreak)
@@ -253,7 +297,7 @@ func TestComputeAllLeaderboards(t *testing.T) {
 
 	// Create minimal test data
 	db.Create(&TestSubmission{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), MemoryUsedKb: intPtr(1000)})
-	db.Create(&TestStreak{UserID: 1, CurrentStreak: 1, LongestStreak: 5})
+	db.Create(&TestUserMetrics{UserID: 1, ProblemsSolved: 1, CurrentStreak: 1, LongestStreak: 5})
 
 	results, err := service.ComputeAllLeaderboards()
 	if err != nil {
@@ -402,8 +446,8 @@ func TestComputeLeaderboard_UnknownMetricType(t *testing.T) {
 func TestAllMetricTypes(t *testing.T) {
 	types := models.AllMetricTypes()
 
-	if len(types) != 4 {
-		t.Errorf("expected 4 metric types, got %d", len(types))
+	if len(types) != 9 {
+		t.Errorf("expected 9 metric types, got %d", len(types))
 	}
 
 	expected := map[models.MetricType]bool{
@@ -411,6 +455,11 @@ func TestAllMetricTypes(t *testing.T) {
 		models.MetricLowestMemoryAvg: false,
 		models.MetricProblemsSolved:  false,
 		models.MetricLongestStreak:   false,
+		models.MetricMedianRuntime:   false,
+		models.MetricP95Runtime:      false,
+		models.MetricWeightedScore:   false,
+		models.MetricFastestPercentile:      false,
+		models.MetricLowestMemoryPercentile: false,
 	}
 
 	for _, mt := range types {
@@ -426,3 +475,391 @@ func TestAllMetricTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestComputeMedianRuntime(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100)},
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(200)},
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(300)}, // User 1 median: 200
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50)},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(60)}, // User 2 median: 55
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboard(models.MetricMedianRuntime)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if result.EntriesUpdated != 2 {
+		t.Errorf("expected 2 entries updated, got %d", result.EntriesUpdated)
+	}
+
+	var entries []models.LeaderboardEntry
+	db.Where("metric_type = ?", models.MetricMedianRuntime).Order("rank ASC").Find(&entries)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].UserID != 2 || entries[0].MetricValue != 55 {
+		t.Errorf("expected user 2 at rank 1 with median 55, got user %d with median %v", entries[0].UserID, entries[0].MetricValue)
+	}
+	if entries[1].UserID != 1 || entries[1].MetricValue != 200 {
+		t.Errorf("expected user 1 at rank 2 with median 200, got user %d with median %v", entries[1].UserID, entries[1].MetricValue)
+	}
+}
+
+func TestComputeMedianRuntime_TieBreaksByUserID(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	submissions := []TestSubmission{
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100)},
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100)},
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	if _, err := service.ComputeLeaderboard(models.MetricMedianRuntime); err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+
+	var entries []models.LeaderboardEntry
+	db.Where("metric_type = ?", models.MetricMedianRuntime).Order("rank ASC").Find(&entries)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].UserID != 1 || entries[1].UserID != 2 {
+		t.Errorf("expected tie broken by ascending user ID (1, 2), got (%d, %d)", entries[0].UserID, entries[1].UserID)
+	}
+}
+
+func TestComputeMedianRuntime_NoSubmissions(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	result, err := service.ComputeLeaderboard(models.MetricMedianRuntime)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if result.EntriesUpdated != 0 {
+		t.Errorf("expected 0 entries updated, got %d", result.EntriesUpdated)
+	}
+}
+
+func TestComputeP95Runtime(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(10)},
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(20)},
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(1000)},
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboard(models.MetricP95Runtime)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	db.Where("metric_type = ? AND user_id = ?", models.MetricP95Runtime, 1).First(&entry)
+	if entry.MetricValue <= 20 {
+		t.Errorf("expected p95 to be pulled toward the 1000ms outlier, got %v", entry.MetricValue)
+	}
+}
+
+func TestComputeWeightedScore(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	db.Create(&TestProblem{ID: 1, Difficulty: "hard"})
+	db.Create(&TestTestCase{ID: 1, ProblemID: 1, Weight: 1})
+	db.Create(&TestTestCase{ID: 2, ProblemID: 1, Weight: 3})
+
+	// User 1: passes only the low-weight test case (weighted fraction 1/4)
+	sub1 := TestSubmission{UserID: 1, ProblemID: 1, Status: "wrong_answer"}
+	db.Create(&sub1)
+	db.Create(&TestTestResult{SubmissionID: sub1.ID, TestCaseID: 1, Passed: true})
+	db.Create(&TestTestResult{SubmissionID: sub1.ID, TestCaseID: 2, Passed: false})
+
+	// User 2: passes both test cases (weighted fraction 4/4)
+	sub2 := TestSubmission{UserID: 2, ProblemID: 1, Status: "accepted"}
+	db.Create(&sub2)
+	db.Create(&TestTestResult{SubmissionID: sub2.ID, TestCaseID: 1, Passed: true})
+	db.Create(&TestTestResult{SubmissionID: sub2.ID, TestCaseID: 2, Passed: true})
+
+	result, err := service.ComputeLeaderboard(models.MetricWeightedScore)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if result.EntriesUpdated != 2 {
+		t.Errorf("expected 2 entries updated, got %d", result.EntriesUpdated)
+	}
+
+	var entries []models.LeaderboardEntry
+	db.Where("metric_type = ?", models.MetricWeightedScore).Order("rank ASC").Find(&entries)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	// User 2 solved the "hard" problem fully: score = 1.0 * 7 = 7
+	if entries[0].UserID != 2 || entries[0].MetricValue != 7 {
+		t.Errorf("expected user 2 at rank 1 with score 7, got user %d with score %v", entries[0].UserID, entries[0].MetricValue)
+	}
+	// User 1 passed 1/4 of the weight: score = 0.25 * 7 = 1.75
+	if entries[1].UserID != 1 || entries[1].MetricValue != 1.75 {
+		t.Errorf("expected user 1 at rank 2 with score 1.75, got user %d with score %v", entries[1].UserID, entries[1].MetricValue)
+	}
+}
+
+func TestComputeLeaderboardForScope_WeeklyExcludesOldSubmissions(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	now := time.Now().UTC()
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), CreatedAt: now},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50), CreatedAt: now.AddDate(0, 0, -8)}, // outside the weekly window
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.ScopeWeekly)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entries []models.LeaderboardEntry
+	db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.ScopeWeekly).Find(&entries)
+	if len(entries) != 1 || entries[0].UserID != 1 {
+		t.Fatalf("expected only user 1's recent submission in the weekly scope, got %+v", entries)
+	}
+
+	// The global scope should still include both submissions.
+	globalResult, err := service.ComputeLeaderboard(models.MetricFastestAvg)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if globalResult.EntriesUpdated != 2 {
+		t.Errorf("expected 2 entries updated globally, got %d", globalResult.EntriesUpdated)
+	}
+}
+
+func TestComputeLeaderboardForScope_Daily(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	now := time.Now().UTC()
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), CreatedAt: now},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50), CreatedAt: now.AddDate(0, 0, -1)}, // yesterday, outside the daily window
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.ScopeDaily)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	if err := db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.ScopeDaily).First(&entry).Error; err != nil {
+		t.Fatalf("expected a daily-scope entry: %v", err)
+	}
+	if entry.UserID != 1 {
+		t.Errorf("expected user 1 in the daily scope, got user %d", entry.UserID)
+	}
+	if entry.ScopePeriod == nil {
+		t.Error("expected ScopePeriod to be set for a daily-scoped entry")
+	}
+}
+
+func TestComputeLeaderboardForScope_Language(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), Language: "python"},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50), Language: "go"},
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.LanguageScope("python"))
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	if err := db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.LanguageScope("python")).First(&entry).Error; err != nil {
+		t.Fatalf("expected a language-scoped entry: %v", err)
+	}
+	if entry.UserID != 1 {
+		t.Errorf("expected user 1 (python submitter), got user %d", entry.UserID)
+	}
+}
+
+func TestComputeLeaderboardForScope_Difficulty(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	db.Create(&TestProblem{ID: 1, Difficulty: "hard"})
+	db.Create(&TestProblem{ID: 2, Difficulty: "easy"})
+
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100)},
+		{UserID: 2, ProblemID: 2, Status: "accepted", ExecutionTimeMs: intPtr(50)},
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.DifficultyScope("hard"))
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	if err := db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.DifficultyScope("hard")).First(&entry).Error; err != nil {
+		t.Fatalf("expected a difficulty-scoped entry: %v", err)
+	}
+	if entry.UserID != 1 {
+		t.Errorf("expected user 1 (hard-problem submitter), got user %d", entry.UserID)
+	}
+}
+
+func TestComputeLeaderboardForScope_Monthly(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	now := time.Now().UTC()
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), CreatedAt: now},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50), CreatedAt: now.AddDate(0, -2, 0)}, // outside the monthly window
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.ScopeMonthly)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	if err := db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.ScopeMonthly).First(&entry).Error; err != nil {
+		t.Fatalf("expected a monthly-scope entry: %v", err)
+	}
+	if entry.UserID != 1 {
+		t.Errorf("expected user 1 in the monthly scope, got user %d", entry.UserID)
+	}
+}
+
+func TestComputeLeaderboardForScope_SeasonUsesSeasonBounds(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	now := time.Now().UTC()
+	season, err := service.OpenSeason("Summer Cup", now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("OpenSeason failed: %v", err)
+	}
+
+	submissions := []TestSubmission{
+		{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), CreatedAt: now},
+		{UserID: 2, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(50), CreatedAt: now.AddDate(0, 0, -5)}, // before the season started
+	}
+	for _, s := range submissions {
+		db.Create(&s)
+	}
+
+	result, err := service.ComputeLeaderboardForScope(models.MetricFastestAvg, models.SeasonScope(season.ID))
+	if err != nil {
+		t.Fatalf("ComputeLeaderboardForScope failed: %v", err)
+	}
+	if result.EntriesUpdated != 1 {
+		t.Errorf("expected 1 entry updated, got %d", result.EntriesUpdated)
+	}
+
+	var entry models.LeaderboardEntry
+	if err := db.Where("metric_type = ? AND scope = ?", models.MetricFastestAvg, models.SeasonScope(season.ID)).First(&entry).Error; err != nil {
+		t.Fatalf("expected a season-scoped entry: %v", err)
+	}
+	if entry.UserID != 1 {
+		t.Errorf("expected user 1 in the season scope, got user %d", entry.UserID)
+	}
+}
+
+func TestCloseSeason_ArchivesFinalRankingsAndRejectsReclose(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	now := time.Now().UTC()
+	season, err := service.OpenSeason("Winter Cup", now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("OpenSeason failed: %v", err)
+	}
+
+	db.Create(&TestSubmission{UserID: 1, ProblemID: 1, Status: "accepted", ExecutionTimeMs: intPtr(100), CreatedAt: now})
+
+	if _, err := service.CloseSeason(season.ID); err != nil {
+		t.Fatalf("CloseSeason failed: %v", err)
+	}
+
+	history, err := service.GetUserHistoricalRanks(1)
+	if err != nil {
+		t.Fatalf("GetUserHistoricalRanks failed: %v", err)
+	}
+	if len(history) == 0 {
+		t.Fatal("expected at least one archived rank for user 1")
+	}
+	if history[0].SeasonID != season.ID || history[0].MetricType != models.MetricFastestAvg {
+		t.Errorf("unexpected archived entry: %+v", history[0])
+	}
+
+	if _, err := service.CloseSeason(season.ID); err == nil {
+		t.Error("expected closing an already-closed season to fail")
+	}
+}
+
+func TestComputeWeightedScore_NoSubmissions(t *testing.T) {
+	db := setupLeaderboardTestDB(t)
+	service := NewLeaderboardService(db)
+
+	result, err := service.ComputeLeaderboard(models.MetricWeightedScore)
+	if err != nil {
+		t.Fatalf("ComputeLeaderboard failed: %v", err)
+	}
+	if result.EntriesUpdated != 0 {
+		t.Errorf("expected 0 entries updated, got %d", result.EntriesUpdated)
+	}
+}
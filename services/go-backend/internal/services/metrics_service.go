@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MetricsService computes per-user aggregate statistics (problems solved,
+// acceptance rate, streaks, favorite language) from the submissions table
+// and materializes them into user_metrics, so readers like
+// LeaderboardService don't have to re-aggregate raw submissions on every
+// call.
+type MetricsService struct {
+	db *gorm.DB
+}
+
+// NewMetricsService creates a new metrics service instance
+func NewMetricsService(db *gorm.DB) *MetricsService {
+	return &MetricsService{db: db}
+}
+
+// RefreshUserMetrics recomputes userID's materialized row from the
+// submissions table and upserts it into user_metrics. CurrentStreak and
+// LongestStreak are derived incrementally from the previously stored row
+// (see nextStreakState) rather than replayed from full submission history.
+func (s *MetricsService) RefreshUserMetrics(userID uint) (*models.UserMetrics, error) {
+	var existing models.UserMetrics
+	err := s.db.Where("user_id = ?", userID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load existing metrics for user %d: %w", userID, err)
+	}
+
+	metrics, err := s.computeUserMetrics(userID, &existing)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"problems_solved", "acceptance_rate", "current_streak",
+			"longest_streak", "last_solved_date", "favorite_language", "updated_at",
+		}),
+	}).Create(metrics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist metrics for user %d: %w", userID, err)
+	}
+
+	return metrics, nil
+}
+
+// RefreshAllMetrics recomputes and upserts user_metrics for every user with
+// at least one submission. Mirrors LeaderboardService.ComputeAllLeaderboards:
+// meant to be triggered by an admin endpoint or a cron sweep rather than run
+// inline on a request path.
+func (s *MetricsService) RefreshAllMetrics() (*models.MetricsRefreshResult, error) {
+	var userIDs []uint
+	err := s.db.Table("submissions").Distinct("user_id").Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users with submissions: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := s.RefreshUserMetrics(userID); err != nil {
+			return nil, fmt.Errorf("failed to refresh metrics for user %d: %w", userID, err)
+		}
+	}
+
+	return &models.MetricsRefreshResult{
+		UsersUpdated: len(userIDs),
+		ComputedAt:   time.Now(),
+	}, nil
+}
+
+// computeUserMetrics builds userID's fresh UserMetrics row from the
+// submissions table, using existing (the previously materialized row, which
+// may be a zero value if userID has never been refreshed before) only to
+// seed the streak calculation in nextStreakState.
+func (s *MetricsService) computeUserMetrics(userID uint, existing *models.UserMetrics) (*models.UserMetrics, error) {
+	var total int64
+	if err := s.db.Table("submissions").Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count submissions for user %d: %w", userID, err)
+	}
+
+	var accepted int64
+	err := s.db.Table("submissions").
+		Where("user_id = ? AND status = ?", userID, models.StatusAccepted).
+		Count(&accepted).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count accepted submissions for user %d: %w", userID, err)
+	}
+
+	var problemsSolved int64
+	err = s.db.Table("submissions").
+		Where("user_id = ? AND status = ?", userID, models.StatusAccepted).
+		Distinct("problem_id").
+		Count(&problemsSolved).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count problems solved for user %d: %w", userID, err)
+	}
+
+	type languageCount struct {
+		Language string
+		Count    int
+	}
+	var topLanguage []languageCount
+	err = s.db.Table("submissions").
+		Select("language, COUNT(*) as count").
+		Where("user_id = ? AND status = ?", userID, models.StatusAccepted).
+		Group("language").
+		Order("count DESC").
+		Limit(1).
+		Scan(&topLanguage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find favorite language for user %d: %w", userID, err)
+	}
+	var favoriteLanguage *string
+	if len(topLanguage) > 0 {
+		favoriteLanguage = &topLanguage[0].Language
+	}
+
+	type lastSolved struct {
+		LastAcceptedAt *time.Time
+	}
+	var last lastSolved
+	err = s.db.Table("submissions").
+		Select("MAX(created_at) as last_accepted_at").
+		Where("user_id = ? AND status = ?", userID, models.StatusAccepted).
+		Scan(&last).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find last solved date for user %d: %w", userID, err)
+	}
+
+	var acceptanceRate float64
+	if total > 0 {
+		acceptanceRate = float64(accepted) / float64(total) * 100
+	}
+
+	currentStreak, longestStreak, lastSolvedDate := nextStreakState(existing, last.LastAcceptedAt)
+
+	return &models.UserMetrics{
+		UserID:           userID,
+		ProblemsSolved:   int(problemsSolved),
+		AcceptanceRate:   acceptanceRate,
+		CurrentStreak:    currentStreak,
+		LongestStreak:    longestStreak,
+		LastSolvedDate:   lastSolvedDate,
+		FavoriteLanguage: favoriteLanguage,
+		UpdatedAt:        time.Now(),
+	}, nil
+}
+
+// nextStreakState derives CurrentStreak/LongestStreak/LastSolvedDate for a
+// fresh refresh, given the previously materialized row (zero-valued on a
+// user's first refresh) and the timestamp of their most recent accepted
+// submission (nil if they have none). It compares UTC calendar days against
+// existing.LastSolvedDate rather than replaying full submission history: the
+// same day as before is streak-preserving, the day before it extends the
+// streak by one, and anything older resets it to 1. LongestStreak is always
+// raised to match a new CurrentStreak high, never lowered.
+func nextStreakState(existing *models.UserMetrics, lastAcceptedAt *time.Time) (currentStreak, longestStreak int, lastSolvedDate *time.Time) {
+	longestStreak = existing.LongestStreak
+	if lastAcceptedAt == nil {
+		return 0, longestStreak, nil
+	}
+
+	today := utcDay(*lastAcceptedAt)
+	switch {
+	case existing.LastSolvedDate == nil:
+		currentStreak = 1
+	case utcDay(*existing.LastSolvedDate).Equal(today):
+		currentStreak = existing.CurrentStreak
+	case utcDay(*existing.LastSolvedDate).Equal(today.AddDate(0, 0, -1)):
+		currentStreak = existing.CurrentStreak + 1
+	default:
+		currentStreak = 1
+	}
+
+	if currentStreak > longestStreak {
+		longestStreak = currentStreak
+	}
+
+	return currentStreak, longestStreak, &today
+}
+
+// utcDay truncates t to midnight on its calendar day in UTC.
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
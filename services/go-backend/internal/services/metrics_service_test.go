@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/db/migrations"
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupMetricsTestDB creates an in-memory SQLite database for testing,
+// running the real versioned migrations so user_metrics has the same schema
+// as production.
+func setupMetricsTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := migrations.NewRunner(db).MigrateTo(context.Background(), migrations.Latest); err != nil {
+		t.Fatalf("failed to run test migrations: %v", err)
+	}
+
+	return db
+}
+
+func createSubmission(t *testing.T, db *gorm.DB, userID, problemID uint, status, language string, createdAt time.Time) {
+	t.Helper()
+	err := db.Table("submissions").Create(map[string]interface{}{
+		"user_id":    userID,
+		"problem_id": problemID,
+		"status":     status,
+		"language":   language,
+		"created_at": createdAt,
+		"updated_at": createdAt,
+	}).Error
+	if err != nil {
+		t.Fatalf("failed to create test submission: %v", err)
+	}
+}
+
+func TestRefreshUserMetrics_ComputesSolvedAndAcceptance(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	now := time.Now().UTC()
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", now)
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", now) // Retried problem 1 - not a new problem
+	createSubmission(t, db, 1, 2, models.StatusWrongAnswer, "go", now)
+	createSubmission(t, db, 1, 3, models.StatusAccepted, "python", now)
+
+	metrics, err := service.RefreshUserMetrics(1)
+	if err != nil {
+		t.Fatalf("RefreshUserMetrics failed: %v", err)
+	}
+
+	if metrics.ProblemsSolved != 2 {
+		t.Errorf("expected 2 problems solved, got %d", metrics.ProblemsSolved)
+	}
+
+	wantRate := float64(3) / float64(4) * 100
+	if metrics.AcceptanceRate != wantRate {
+		t.Errorf("expected acceptance rate %f, got %f", wantRate, metrics.AcceptanceRate)
+	}
+
+	if metrics.FavoriteLanguage == nil || *metrics.FavoriteLanguage != "go" {
+		t.Errorf("expected favorite language go, got %v", metrics.FavoriteLanguage)
+	}
+
+	if metrics.CurrentStreak != 1 {
+		t.Errorf("expected current streak 1 on first refresh, got %d", metrics.CurrentStreak)
+	}
+	if metrics.LongestStreak != 1 {
+		t.Errorf("expected longest streak 1 on first refresh, got %d", metrics.LongestStreak)
+	}
+}
+
+func TestRefreshUserMetrics_StreakPreservedSameDay(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	today := time.Now().UTC()
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", today)
+	if _, err := service.RefreshUserMetrics(1); err != nil {
+		t.Fatalf("first RefreshUserMetrics failed: %v", err)
+	}
+
+	// A second accepted submission later the same UTC day shouldn't bump the
+	// streak again.
+	createSubmission(t, db, 1, 2, models.StatusAccepted, "go", today.Add(time.Hour))
+	metrics, err := service.RefreshUserMetrics(1)
+	if err != nil {
+		t.Fatalf("second RefreshUserMetrics failed: %v", err)
+	}
+
+	if metrics.CurrentStreak != 1 {
+		t.Errorf("expected streak to stay at 1 for same-day solves, got %d", metrics.CurrentStreak)
+	}
+}
+
+func TestRefreshUserMetrics_StreakExtendsOnConsecutiveDay(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", yesterday)
+	if _, err := service.RefreshUserMetrics(1); err != nil {
+		t.Fatalf("first RefreshUserMetrics failed: %v", err)
+	}
+
+	createSubmission(t, db, 1, 2, models.StatusAccepted, "go", time.Now().UTC())
+	metrics, err := service.RefreshUserMetrics(1)
+	if err != nil {
+		t.Fatalf("second RefreshUserMetrics failed: %v", err)
+	}
+
+	if metrics.CurrentStreak != 2 {
+		t.Errorf("expected streak to extend to 2, got %d", metrics.CurrentStreak)
+	}
+	if metrics.LongestStreak != 2 {
+		t.Errorf("expected longest streak to follow to 2, got %d", metrics.LongestStreak)
+	}
+}
+
+func TestRefreshUserMetrics_StreakResetsAfterGap(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	weekAgo := time.Now().UTC().AddDate(0, 0, -7)
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", weekAgo)
+	if _, err := service.RefreshUserMetrics(1); err != nil {
+		t.Fatalf("first RefreshUserMetrics failed: %v", err)
+	}
+
+	createSubmission(t, db, 1, 2, models.StatusAccepted, "go", time.Now().UTC())
+	metrics, err := service.RefreshUserMetrics(1)
+	if err != nil {
+		t.Fatalf("second RefreshUserMetrics failed: %v", err)
+	}
+
+	if metrics.CurrentStreak != 1 {
+		t.Errorf("expected streak to reset to 1 after a gap, got %d", metrics.CurrentStreak)
+	}
+	if metrics.LongestStreak != 1 {
+		t.Errorf("expected longest streak to stay at 1, got %d", metrics.LongestStreak)
+	}
+}
+
+func TestRefreshUserMetrics_NoSubmissions(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	metrics, err := service.RefreshUserMetrics(99)
+	if err != nil {
+		t.Fatalf("RefreshUserMetrics failed: %v", err)
+	}
+
+	if metrics.ProblemsSolved != 0 || metrics.CurrentStreak != 0 || metrics.AcceptanceRate != 0 {
+		t.Errorf("expected zero-valued metrics for a user with no submissions, got %+v", metrics)
+	}
+	if metrics.LastSolvedDate != nil {
+		t.Errorf("expected nil last solved date, got %v", metrics.LastSolvedDate)
+	}
+}
+
+func TestRefreshAllMetrics(t *testing.T) {
+	db := setupMetricsTestDB(t)
+	service := NewMetricsService(db)
+
+	now := time.Now().UTC()
+	createSubmission(t, db, 1, 1, models.StatusAccepted, "go", now)
+	createSubmission(t, db, 2, 1, models.StatusAccepted, "python", now)
+
+	result, err := service.RefreshAllMetrics()
+	if err != nil {
+		t.Fatalf("RefreshAllMetrics failed: %v", err)
+	}
+
+	if result.UsersUpdated != 2 {
+		t.Errorf("expected 2 users updated, got %d", result.UsersUpdated)
+	}
+
+	var count int64
+	db.Table("user_metrics").Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 user_metrics rows, got %d", count)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+// plagiarismDefaultThreshold is the similarity above which Scan reports a
+// match when NewPlagiarismService isn't given its own.
+const plagiarismDefaultThreshold = 0.8
+
+// PlagiarismMatch is one other submission Scan found to be suspiciously
+// similar to the one being scanned. Named distinctly from
+// similarity_service.go's SimilarityMatch - the two are independent,
+// never-cross-referenced near-duplicate detectors (see PlagiarismService's
+// doc comment) that happen to report a similar shape of result.
+type PlagiarismMatch struct {
+	SubmissionID       uint            `json:"submission_id"`
+	UserID             uint            `json:"user_id"`
+	Similarity         float64         `json:"similarity"`
+	SharedFingerprints int             `json:"shared_fingerprints"`
+	MatchedRegions     []MatchedRegion `json:"matched_regions"`
+}
+
+// PlagiarismService answers "which prior submissions on this problem are
+// suspiciously similar to submission X?" on top of the Winnowing
+// fingerprints AntiCheatService already extracts and stores in
+// submission_fingerprints (see code_similarity.go) - it doesn't run its own
+// fingerprinting pass, only a different similarity metric (containment
+// rather than Jaccard) and an admin reindex path. Library code only: the
+// ticket asked for Scan/RebuildIndex as service methods, not an admin
+// route, so neither is reachable from cmd/ yet - wiring RebuildIndex
+// behind an authenticated admin endpoint is follow-up work.
+// TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions
+// (submission_pipeline_integration_test.go) drives RebuildIndex/Scan off
+// submissions JudgeQueue actually queued and judged, rather than rows a
+// fixture inserted directly, so the two don't silently disagree on what
+// "accepted" looks like.
+type PlagiarismService struct {
+	db        *gorm.DB
+	anticheat *AntiCheatService
+	// Threshold is the minimum |A ∩ B| / min(|A|, |B|) for Scan to report a
+	// match.
+	Threshold float64
+}
+
+// NewPlagiarismService builds a PlagiarismService using threshold (or
+// plagiarismDefaultThreshold if <= 0) as Scan's similarity cutoff.
+func NewPlagiarismService(db *gorm.DB, anticheat *AntiCheatService, threshold float64) *PlagiarismService {
+	if threshold <= 0 {
+		threshold = plagiarismDefaultThreshold
+	}
+	return &PlagiarismService{db: db, anticheat: anticheat, Threshold: threshold}
+}
+
+// Scan returns every other submission to submissionID's problem whose
+// fingerprint containment - |A ∩ B| / min(|A|, |B|), which unlike Jaccard
+// isn't diluted when one submission is much longer than the other - against
+// it is at least p.Threshold, sorted by similarity descending, with the
+// matched source regions clustered the same way FindSimilarSubmissions does.
+// This only reads already-stored fingerprints (written by RebuildIndex or
+// AntiCheatService.AnalyzeSimilarity) - it neither fingerprints new code nor
+// flags anything, so it's safe to call from an unauthenticated read
+// endpoint.
+func (p *PlagiarismService) Scan(submissionID uint) ([]PlagiarismMatch, error) {
+	var own []models.SubmissionFingerprint
+	if err := p.db.Where("submission_id = ?", submissionID).Find(&own).Error; err != nil {
+		return nil, fmt.Errorf("failed to load submission fingerprints: %w", err)
+	}
+	if len(own) == 0 {
+		return nil, nil
+	}
+	problemID := own[0].ProblemID
+
+	positionsByHash := make(map[uint64][]int, len(own))
+	hashes := make([]uint64, 0, len(own))
+	for _, fp := range own {
+		if _, ok := positionsByHash[fp.Fingerprint]; !ok {
+			hashes = append(hashes, fp.Fingerprint)
+		}
+		positionsByHash[fp.Fingerprint] = append(positionsByHash[fp.Fingerprint], fp.Position)
+	}
+	ownSize := len(hashes)
+
+	var others []models.SubmissionFingerprint
+	if err := p.db.Where("problem_id = ? AND fingerprint IN ? AND submission_id != ?", problemID, hashes, submissionID).
+		Find(&others).Error; err != nil {
+		return nil, fmt.Errorf("failed to find similarity matches: %w", err)
+	}
+
+	type candidate struct {
+		userID    uint
+		hashes    map[uint64]struct{}
+		positions map[int]struct{}
+	}
+	candidates := make(map[uint]*candidate)
+	for _, fp := range others {
+		c, ok := candidates[fp.SubmissionID]
+		if !ok {
+			c = &candidate{userID: fp.UserID, hashes: make(map[uint64]struct{}), positions: make(map[int]struct{})}
+			candidates[fp.SubmissionID] = c
+		}
+		c.hashes[fp.Fingerprint] = struct{}{}
+		for _, pos := range positionsByHash[fp.Fingerprint] {
+			c.positions[pos] = struct{}{}
+		}
+	}
+
+	matches := make([]PlagiarismMatch, 0, len(candidates))
+	for candidateID, c := range candidates {
+		var otherSize int64
+		if err := p.db.Model(&models.SubmissionFingerprint{}).
+			Where("submission_id = ?", candidateID).
+			Distinct("fingerprint").
+			Count(&otherSize).Error; err != nil {
+			return nil, fmt.Errorf("failed to count candidate fingerprints: %w", err)
+		}
+
+		shared := len(c.hashes)
+		minSize := ownSize
+		if int(otherSize) < minSize {
+			minSize = int(otherSize)
+		}
+		if minSize == 0 {
+			continue
+		}
+		similarity := float64(shared) / float64(minSize)
+		if similarity < p.Threshold {
+			continue
+		}
+
+		matches = append(matches, PlagiarismMatch{
+			SubmissionID:       candidateID,
+			UserID:             c.userID,
+			Similarity:         similarity,
+			SharedFingerprints: shared,
+			MatchedRegions:     clusterMatchedRegions(c.positions),
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+
+	return matches, nil
+}
+
+// submissionFingerprintKey resolves submissionID's real UUID to its stable
+// uint surrogate in models.SubmissionFingerprint.SubmissionID - a uint
+// column that predates any submission in this codebase having a UUID id
+// (see code_similarity.go's own uint-keyed AnalyzeSimilarity/
+// FindSimilarSubmissions, whose submissionID parameter was never backed by
+// a real Submission row either). The mapping is created in
+// models.SubmissionFingerprintKey on first use; its uniqueIndex on
+// SubmissionID makes this collision-free, unlike hashing the UUID down to a
+// fixed-width int.
+func (p *PlagiarismService) submissionFingerprintKey(submissionID string) (uint, error) {
+	var key models.SubmissionFingerprintKey
+	if err := p.db.Where(models.SubmissionFingerprintKey{SubmissionID: submissionID}).FirstOrCreate(&key).Error; err != nil {
+		return 0, fmt.Errorf("failed to resolve fingerprint key for submission %s: %w", submissionID, err)
+	}
+	return key.ID, nil
+}
+
+// RebuildIndex re-derives every submission_fingerprints row for problemID
+// from scratch: it deletes problemID's existing rows, then re-fingerprints
+// every accepted submission to it via AntiCheatService's fingerprint-store
+// logic, without AnalyzeSimilarity's flagging side effect. Intended as an
+// admin operation after changing the winnowing parameters
+// (codeSimKgramSize/codeSimWindowSize) or to repair a corrupted index.
+func (p *PlagiarismService) RebuildIndex(problemID uint) error {
+	if err := p.db.Where("problem_id = ?", problemID).Delete(&models.SubmissionFingerprint{}).Error; err != nil {
+		return fmt.Errorf("failed to clear existing fingerprints: %w", err)
+	}
+
+	var submissions []models.Submission
+	if err := p.db.Where("problem_id = ? AND status = ?", strconv.FormatUint(uint64(problemID), 10), models.StatusAccepted).
+		Find(&submissions).Error; err != nil {
+		return fmt.Errorf("failed to load accepted submissions for problem %d: %w", problemID, err)
+	}
+
+	for _, submission := range submissions {
+		userID, err := strconv.ParseUint(submission.UserID, 10, 32)
+		if err != nil {
+			continue
+		}
+		submissionID, err := p.submissionFingerprintKey(submission.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := p.anticheat.storeFingerprints(submissionID, uint(userID), problemID, submission.Language, submission.Code); err != nil {
+			return fmt.Errorf("failed to rebuild fingerprints for submission %s: %w", submission.ID, err)
+		}
+	}
+
+	return nil
+}
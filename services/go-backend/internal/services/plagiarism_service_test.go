@@ -0,0 +1,211 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupPlagiarismTestDB creates an in-memory SQLite database migrated with
+// the tables PlagiarismService reads and writes.
+func setupPlagiarismTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.Problem{}, &models.Submission{}, &models.SubmissionFingerprint{}, &models.SubmissionFingerprintKey{})
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+
+	return db
+}
+
+// createAcceptedSubmissionForPlagiarism inserts an accepted submission to
+// problemID with the given code, returning it so tests can derive its
+// fingerprint key via submissionFingerprintKey.
+func createAcceptedSubmissionForPlagiarism(t *testing.T, db *gorm.DB, userID uint, problemID uint, language, code string) models.Submission {
+	t.Helper()
+	submission := models.Submission{
+		ID:        uuid.NewString(),
+		UserID:    strconv.FormatUint(uint64(userID), 10),
+		ProblemID: strconv.FormatUint(uint64(problemID), 10),
+		Language:  language,
+		Code:      code,
+		Status:    models.StatusAccepted,
+	}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create submission: %v", err)
+	}
+	return submission
+}
+
+const (
+	plagiarismOriginal = `
+def solve(a, b):
+    total = a + b
+    if total > 10:
+        return total
+    return 0
+`
+	// plagiarismRenamed is plagiarismOriginal with every identifier renamed -
+	// the LanguageFrontend canonicalizes identifiers to "ID", so this should
+	// still fingerprint identically.
+	plagiarismRenamed = `
+def solve(x, y):
+    result = x + y
+    if result > 10:
+        return result
+    return 0
+`
+	plagiarismUnrelated = `
+def bubble_sort(items):
+    n = len(items)
+    for i in range(n):
+        for j in range(n - i - 1):
+            if items[j] > items[j + 1]:
+                items[j], items[j + 1] = items[j + 1], items[j]
+    return items
+`
+)
+
+func TestRebuildIndexAndScan_ObviousClone(t *testing.T) {
+	db := setupPlagiarismTestDB(t)
+	anticheat := NewAntiCheatService(db)
+	plagiarism := NewPlagiarismService(db, anticheat, 0)
+
+	a := createAcceptedSubmissionForPlagiarism(t, db, 1, 1, "python", plagiarismOriginal)
+	b := createAcceptedSubmissionForPlagiarism(t, db, 2, 1, "python", plagiarismOriginal)
+
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("RebuildIndex() error = %v", err)
+	}
+
+	aKey, err := plagiarism.submissionFingerprintKey(a.ID)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey(a) error = %v", err)
+	}
+	bKey, err := plagiarism.submissionFingerprintKey(b.ID)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey(b) error = %v", err)
+	}
+
+	matches, err := plagiarism.Scan(aKey)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(matches))
+	}
+	if matches[0].SubmissionID != bKey {
+		t.Errorf("matched submission = %d, want %d", matches[0].SubmissionID, bKey)
+	}
+	if matches[0].Similarity < 0.99 {
+		t.Errorf("Similarity = %v, want ~1.0 for an identical clone", matches[0].Similarity)
+	}
+	if len(matches[0].MatchedRegions) == 0 {
+		t.Error("expected at least one matched region")
+	}
+}
+
+func TestRebuildIndexAndScan_RenamedVariableClone(t *testing.T) {
+	db := setupPlagiarismTestDB(t)
+	anticheat := NewAntiCheatService(db)
+	plagiarism := NewPlagiarismService(db, anticheat, 0)
+
+	a := createAcceptedSubmissionForPlagiarism(t, db, 1, 1, "python", plagiarismOriginal)
+	createAcceptedSubmissionForPlagiarism(t, db, 2, 1, "python", plagiarismRenamed)
+
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("RebuildIndex() error = %v", err)
+	}
+
+	aKey, err := plagiarism.submissionFingerprintKey(a.ID)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey() error = %v", err)
+	}
+
+	matches, err := plagiarism.Scan(aKey)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected renamed-variable clone to still match, got %d matches", len(matches))
+	}
+	if matches[0].Similarity < 0.99 {
+		t.Errorf("Similarity = %v, want ~1.0 once identifiers are canonicalized", matches[0].Similarity)
+	}
+}
+
+func TestRebuildIndexAndScan_UnrelatedCodeNoMatch(t *testing.T) {
+	db := setupPlagiarismTestDB(t)
+	anticheat := NewAntiCheatService(db)
+	plagiarism := NewPlagiarismService(db, anticheat, 0)
+
+	a := createAcceptedSubmissionForPlagiarism(t, db, 1, 1, "python", plagiarismOriginal)
+	createAcceptedSubmissionForPlagiarism(t, db, 2, 1, "python", plagiarismUnrelated)
+
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("RebuildIndex() error = %v", err)
+	}
+
+	aKey, err := plagiarism.submissionFingerprintKey(a.ID)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey() error = %v", err)
+	}
+
+	matches, err := plagiarism.Scan(aKey)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no matches against unrelated code, got %d", len(matches))
+	}
+}
+
+func TestRebuildIndex_ClearsStaleFingerprintsBeforeReindexing(t *testing.T) {
+	db := setupPlagiarismTestDB(t)
+	anticheat := NewAntiCheatService(db)
+	plagiarism := NewPlagiarismService(db, anticheat, 0)
+
+	a := createAcceptedSubmissionForPlagiarism(t, db, 1, 1, "python", plagiarismOriginal)
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("RebuildIndex() error = %v", err)
+	}
+
+	var firstCount int64
+	db.Model(&models.SubmissionFingerprint{}).Where("problem_id = ?", 1).Count(&firstCount)
+
+	// Reindexing again with the same single submission shouldn't duplicate
+	// its fingerprint rows.
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("second RebuildIndex() error = %v", err)
+	}
+
+	var secondCount int64
+	db.Model(&models.SubmissionFingerprint{}).Where("problem_id = ?", 1).Count(&secondCount)
+	if secondCount != firstCount {
+		t.Errorf("fingerprint count after reindex = %d, want %d (unchanged)", secondCount, firstCount)
+	}
+
+	aKey, err := plagiarism.submissionFingerprintKey(a.ID)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey() error = %v", err)
+	}
+
+	matches, err := plagiarism.Scan(aKey)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no matches for a lone submission, got %d", len(matches))
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/nahtao97/scribble/internal/models"
+	"github.com/nahtao97/scribble/internal/problems"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProblemService handles business logic for problem operations
@@ -58,6 +60,74 @@ func (s *ProblemService) GetTestCasesByProblemID(problemID uint, sampleOnly bool
 	return testCases, nil
 }
 
+// ImportProblem upserts def by slug - creating it, or updating an existing
+// problem with the same slug - and replaces its test cases wholesale with
+// def.TestCases, all in one transaction. Used by the
+// POST /internal/problems/import endpoint; problems.Loader.Watch reloads are
+// not fed into it.
+func (s *ProblemService) ImportProblem(def *problems.ProblemDef) (*models.Problem, error) {
+	problem := models.Problem{
+		Title:              def.Title,
+		Slug:               def.Slug,
+		Difficulty:         def.Difficulty,
+		Description:        def.Description,
+		Constraints:        def.Constraints,
+		Hints:              def.Hints,
+		Category:           def.Category,
+		Tags:               def.Tags,
+		Judge:              string(def.Judge),
+		FloatTolerance:     def.FloatTolerance,
+		SpecialJudgeBinary: def.SpecialJudgeBinary,
+		LanguageLimits:     def.Limits,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "slug"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"title", "difficulty", "description", "constraints", "hints",
+				"category", "tags", "judge", "float_tolerance",
+				"special_judge_binary", "language_limits",
+			}),
+		}).Create(&problem).Error; err != nil {
+			return fmt.Errorf("failed to upsert problem: %w", err)
+		}
+
+		// Create doesn't reliably populate ID for the DO UPDATE branch of an
+		// upsert, so re-fetch by the slug we just wrote to instead of
+		// trusting problem.ID here.
+		if err := tx.Where("slug = ?", problem.Slug).First(&problem).Error; err != nil {
+			return fmt.Errorf("failed to look up upserted problem: %w", err)
+		}
+
+		if err := tx.Where("problem_id = ?", problem.ID).Delete(&models.TestCase{}).Error; err != nil {
+			return fmt.Errorf("failed to clear existing test cases: %w", err)
+		}
+
+		testCases := make([]models.TestCase, len(def.TestCases))
+		for i, tc := range def.TestCases {
+			testCases[i] = models.TestCase{
+				ProblemID:      problem.ID,
+				Input:          tc.Input,
+				ExpectedOutput: tc.ExpectedOutput,
+				IsSample:       tc.IsSample,
+			}
+		}
+		if len(testCases) > 0 {
+			if err := tx.Create(&testCases).Error; err != nil {
+				return fmt.Errorf("failed to create test cases: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &problem, nil
+}
+
 // GetDailyChallengeByDate retrieves the daily challenge for a specific date
 // Date should be in YYYY-MM-DD format (UTC)
 // Returns the challenge with the associated problem preloaded
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+)
+
+// QueuedJob is the wire representation of a job as it sits in a Queue
+// backend. Unlike ExecutionJob it carries no Go channel, so it can be
+// serialized and handed to a backend like Redis.
+type QueuedJob struct {
+	ID         string                 `json:"id"`
+	Params     k8s.ExecutionJobParams `json:"params"`
+	EnqueuedAt time.Time              `json:"enqueued_at"`
+
+	// ctx is only meaningful for jobs processed in the same process that
+	// enqueued them (the InMemoryQueue case) - it doesn't serialize and
+	// isn't set when a job is loaded back from Redis. Workers fall back to
+	// context.Background() when it's nil.
+	ctx context.Context
+}
+
+// Queue is the durable job queue abstraction that a named queue's workers
+// pull from. WorkerPool/Dispatcher used to read directly off a Go channel;
+// that behavior is now the InMemoryQueue implementation of this interface,
+// and a separate RedisQueue implementation lets multiple scribble replicas
+// share one backlog.
+type Queue interface {
+	// Enqueue adds a job to the queue. Implementations should be safe to
+	// call concurrently.
+	Enqueue(ctx context.Context, job *QueuedJob) error
+
+	// Dequeue blocks until a job is available, ctx is cancelled, or the
+	// backend's own poll interval elapses (returning nil, nil so callers
+	// can re-check for shutdown).
+	Dequeue(ctx context.Context) (*QueuedJob, error)
+
+	// Ack marks a job as successfully processed, allowing an at-least-once
+	// backend to stop redelivering it.
+	Ack(ctx context.Context, jobID string) error
+
+	// Nack marks a job as failed so an at-least-once backend can redeliver
+	// it (e.g. after a crashed worker let its visibility timeout expire).
+	Nack(ctx context.Context, jobID string) error
+
+	// Len reports the approximate number of jobs currently queued,
+	// excluding ones already claimed by a worker. Used for the
+	// ErrQueueFull circuit breaker.
+	Len(ctx context.Context) (int, error)
+}
+
+// ResultBroker delivers the outcome of a job back to whichever caller is
+// waiting on it, potentially from a different process than the one that
+// processed the job.
+type ResultBroker interface {
+	Publish(ctx context.Context, jobID string, result *ExecutionJobResult) error
+	Wait(ctx context.Context, jobID string) (*ExecutionJobResult, error)
+}
+
+// InMemoryQueue is a Queue backed by a buffered Go channel - the original
+// WorkerPool behavior. Ack/Nack are no-ops because delivery is at-most-once:
+// a crashed worker simply drops the job, same as before this refactor.
+type InMemoryQueue struct {
+	jobs chan *QueuedJob
+}
+
+// NewInMemoryQueue creates an InMemoryQueue with the given buffer size
+func NewInMemoryQueue(size int) *InMemoryQueue {
+	return &InMemoryQueue{jobs: make(chan *QueuedJob, size)}
+}
+
+// Enqueue adds a job to the channel, or returns ErrQueueFull if it's full
+func (q *InMemoryQueue) Enqueue(ctx context.Context, job *QueuedJob) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Dequeue blocks on the channel or ctx cancellation
+func (q *InMemoryQueue) Dequeue(ctx context.Context) (*QueuedJob, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case job, ok := <-q.jobs:
+		if !ok {
+			return nil, nil
+		}
+		return job, nil
+	}
+}
+
+// Ack is a no-op for the in-memory backend
+func (q *InMemoryQueue) Ack(ctx context.Context, jobID string) error { return nil }
+
+// Nack is a no-op for the in-memory backend - there's nothing to redeliver
+func (q *InMemoryQueue) Nack(ctx context.Context, jobID string) error { return nil }
+
+// Len returns the number of jobs currently buffered in the channel
+func (q *InMemoryQueue) Len(ctx context.Context) (int, error) {
+	return len(q.jobs), nil
+}
+
+// Capacity returns the channel's buffer size
+func (q *InMemoryQueue) Capacity() int {
+	return cap(q.jobs)
+}
+
+// localResultBroker delivers results via in-process channels, matching the
+// original ResultChan-on-ExecutionJob behavior. It only works within a
+// single process, which is sufficient for InMemoryQueue.
+type localResultBroker struct {
+	mu      sync.Mutex
+	waiters map[string]chan *ExecutionJobResult
+}
+
+// newLocalResultBroker creates a localResultBroker
+func newLocalResultBroker() *localResultBroker {
+	return &localResultBroker{waiters: make(map[string]chan *ExecutionJobResult)}
+}
+
+func (b *localResultBroker) register(jobID string) chan *ExecutionJobResult {
+	ch := make(chan *ExecutionJobResult, 1)
+	b.mu.Lock()
+	b.waiters[jobID] = ch
+	b.mu.Unlock()
+	return ch
+}
+
+// Publish delivers the result to the registered waiter, if any
+func (b *localResultBroker) Publish(ctx context.Context, jobID string, result *ExecutionJobResult) error {
+	b.mu.Lock()
+	ch, ok := b.waiters[jobID]
+	delete(b.waiters, jobID)
+	b.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	ch <- result
+	close(ch)
+	return nil
+}
+
+// Wait blocks until the result for jobID is published or ctx is cancelled
+func (b *localResultBroker) Wait(ctx context.Context, jobID string) (*ExecutionJobResult, error) {
+	ch := b.register(jobID)
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		delete(b.waiters, jobID)
+		b.mu.Unlock()
+		return nil, ctx.Err()
+	case result := <-ch:
+		return result, nil
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// defaultLimiterCacheSize bounds how many callers' TokenBucketLimiter
+// buckets are kept in memory at once. A caller evicted from the LRU simply
+// gets a fresh bucket on its next request - an acceptable tradeoff for the
+// hot path this replaces.
+const defaultLimiterCacheSize = 10000
+
+// RateLimitBackend is the pluggable rate-limiting strategy
+// AntiCheatService.CheckSubmission checks before allowing a submission
+// through. Allow reports whether key (a user ID, optionally namespaced by
+// endpoint - see AntiCheatService.rateLimitKey) may proceed right now; when
+// it can't, retryAfter is how long the caller should wait before trying
+// again. Reset clears key's tracked state, for the admin override endpoint.
+type RateLimitBackend interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+	Reset(ctx context.Context, key string) error
+}
+
+// RateLimitTier names a caller's rate limit policy. AntiCheatService looks
+// up the RateLimitBackend registered for the caller's tier; a tier with no
+// registered backend falls back to TierAuthenticated's.
+type RateLimitTier string
+
+const (
+	TierAnonymous     RateLimitTier = "anonymous"
+	TierAuthenticated RateLimitTier = "authenticated"
+	TierAdmin         RateLimitTier = "admin"
+)
+
+// RateLimitPolicy is one tier's submission cap: at most MaxSubmissions
+// within any Window. A MaxSubmissions of 0 means unlimited.
+type RateLimitPolicy struct {
+	MaxSubmissions int
+	Window         time.Duration
+}
+
+// DefaultRateLimitPolicies returns the out-of-the-box per-tier policy set:
+// anonymous callers get a much tighter cap than authenticated users, and
+// admins aren't limited at all. TierAuthenticated matches
+// models.DefaultRateLimitConfig's historical 10-per-5-minutes default.
+func DefaultRateLimitPolicies() map[RateLimitTier]RateLimitPolicy {
+	return map[RateLimitTier]RateLimitPolicy{
+		TierAnonymous:     {MaxSubmissions: 3, Window: time.Minute},
+		TierAuthenticated: {MaxSubmissions: 10, Window: 5 * time.Minute},
+		TierAdmin:         {MaxSubmissions: 0, Window: time.Minute},
+	}
+}
+
+// TokenBucketLimiter is an in-process RateLimitBackend: an LRU of
+// *rate.Limiter keyed by caller, refilling continuously rather than
+// resetting abruptly at a window boundary. It's the cheapest backend (no
+// I/O) but, since the LRU is local to this process, only enforces the limit
+// within one replica.
+type TokenBucketLimiter struct {
+	cache *lru.Cache
+	rate  rate.Limit
+	burst int
+}
+
+// NewTokenBucketLimiter creates a TokenBucketLimiter from policy: rate =
+// MaxSubmissions/Window, burst = MaxSubmissions. cacheSize defaults to
+// defaultLimiterCacheSize when <= 0.
+func NewTokenBucketLimiter(policy RateLimitPolicy, cacheSize int) *TokenBucketLimiter {
+	if cacheSize <= 0 {
+		cacheSize = defaultLimiterCacheSize
+	}
+
+	// cacheSize is always positive by this point, and lru.New only ever
+	// errors on a non-positive size.
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		panic(fmt.Sprintf("token bucket rate limiter: %v", err))
+	}
+
+	return &TokenBucketLimiter{
+		cache: cache,
+		rate:  rate.Limit(float64(policy.MaxSubmissions) / policy.Window.Seconds()),
+		burst: policy.MaxSubmissions,
+	}
+}
+
+// Allow implements RateLimitBackend.
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l.burst <= 0 {
+		return true, 0, nil
+	}
+
+	limiter := l.limiterFor(key)
+	now := time.Now()
+
+	if limiter.AllowN(now, 1) {
+		return true, 0, nil
+	}
+
+	// AllowN already consumed no tokens on denial, so Reserve here is safe
+	// to immediately Cancel - it's only used to learn the wait time.
+	reservation := limiter.ReserveN(now, 1)
+	retryAfter := reservation.DelayFrom(now)
+	reservation.Cancel()
+
+	return false, retryAfter, nil
+}
+
+func (l *TokenBucketLimiter) limiterFor(key string) *rate.Limiter {
+	if cached, ok := l.cache.Get(key); ok {
+		return cached.(*rate.Limiter)
+	}
+
+	limiter := rate.NewLimiter(l.rate, l.burst)
+	l.cache.Add(key, limiter)
+	return limiter
+}
+
+// Reset implements RateLimitBackend by dropping key's bucket, so its next
+// request starts over with a full burst.
+func (l *TokenBucketLimiter) Reset(ctx context.Context, key string) error {
+	l.cache.Remove(key)
+	return nil
+}
+
+// SQLFixedWindowLimiter is a RateLimitBackend backed by the
+// rate_limit_entries table: a fixed window that resets abruptly once
+// Window has elapsed since the window's start, rather than sliding
+// continuously. It's the original implementation AntiCheatService used
+// before TokenBucketLimiter, kept for deployments that would rather read
+// their rate-limit state from Postgres than keep it in process memory.
+type SQLFixedWindowLimiter struct {
+	db     *gorm.DB
+	policy RateLimitPolicy
+}
+
+// NewSQLFixedWindowLimiter creates a SQLFixedWindowLimiter against db.
+func NewSQLFixedWindowLimiter(db *gorm.DB, policy RateLimitPolicy) *SQLFixedWindowLimiter {
+	return &SQLFixedWindowLimiter{db: db, policy: policy}
+}
+
+// Allow implements RateLimitBackend. key must parse as a uint - the
+// rate_limit_entries table's user_id column is one, matching the rest of
+// the anticheat schema.
+func (l *SQLFixedWindowLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l.policy.MaxSubmissions <= 0 {
+		return true, 0, nil
+	}
+
+	userID, err := strconv.ParseUint(key, 10, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("SQLFixedWindowLimiter: invalid key %q: %w", key, err)
+	}
+
+	now := time.Now()
+	allowed := true
+	var retryAfter time.Duration
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entry models.RateLimitEntry
+		txErr := tx.Where("user_id = ?", userID).First(&entry).Error
+		switch {
+		case errors.Is(txErr, gorm.ErrRecordNotFound):
+			return tx.Create(&models.RateLimitEntry{
+				UserID:      uint(userID),
+				Submissions: 1,
+				WindowStart: now,
+				LastSubmit:  now,
+			}).Error
+		case txErr != nil:
+			return txErr
+		}
+
+		if now.Sub(entry.WindowStart) >= l.policy.Window {
+			entry.Submissions = 1
+			entry.WindowStart = now
+			entry.LastSubmit = now
+			return tx.Save(&entry).Error
+		}
+
+		if entry.Submissions >= l.policy.MaxSubmissions {
+			allowed = false
+			retryAfter = l.policy.Window - now.Sub(entry.WindowStart)
+			return nil
+		}
+
+		entry.Submissions++
+		entry.LastSubmit = now
+		return tx.Save(&entry).Error
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("SQLFixedWindowLimiter: %w", err)
+	}
+
+	return allowed, retryAfter, nil
+}
+
+// Reset implements RateLimitBackend by deleting key's row, so its next
+// request starts a fresh window.
+func (l *SQLFixedWindowLimiter) Reset(ctx context.Context, key string) error {
+	userID, err := strconv.ParseUint(key, 10, 64)
+	if err != nil {
+		return fmt.Errorf("SQLFixedWindowLimiter: invalid key %q: %w", key, err)
+	}
+	return l.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RateLimitEntry{}).Error
+}
+
+// GCRALimiter is a RateLimitBackend implementing the Generic Cell Rate
+// Algorithm ("leaky bucket as a meter"): rather than counting submissions in
+// a window, it tracks a single theoretical arrival time (TAT) per caller in
+// rate_limit_entries.tat. Each call computes newTAT = max(now, tat) +
+// emissionInterval, where emissionInterval = Window/MaxSubmissions; the call
+// is denied only if newTAT would run more than burstTolerance past now. This
+// paces callers smoothly across the whole window instead of
+// SQLFixedWindowLimiter's abrupt per-window reset, and gives an exact
+// retryAfter: newTAT - now - burstTolerance.
+type GCRALimiter struct {
+	db             *gorm.DB
+	policy         RateLimitPolicy
+	burstTolerance time.Duration
+}
+
+// NewGCRALimiter creates a GCRALimiter against db for policy, with
+// burstTolerance as the budget a caller may run ahead of its steady-state
+// pace before being denied (AntiCheatService.rateLimitConfig.CooldownDuration
+// is the default source for this).
+func NewGCRALimiter(db *gorm.DB, policy RateLimitPolicy, burstTolerance time.Duration) *GCRALimiter {
+	return &GCRALimiter{db: db, policy: policy, burstTolerance: burstTolerance}
+}
+
+// Allow implements RateLimitBackend. key must parse as a uint, matching the
+// rate_limit_entries.user_id column.
+func (l *GCRALimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l.policy.MaxSubmissions <= 0 {
+		return true, 0, nil
+	}
+
+	userID, err := strconv.ParseUint(key, 10, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("GCRALimiter: invalid key %q: %w", key, err)
+	}
+
+	emissionInterval := l.policy.Window / time.Duration(l.policy.MaxSubmissions)
+	now := time.Now()
+	allowed := true
+	var retryAfter time.Duration
+
+	err = l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entry models.RateLimitEntry
+		txErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&entry).Error
+		if txErr != nil && !errors.Is(txErr, gorm.ErrRecordNotFound) {
+			return txErr
+		}
+		exists := !errors.Is(txErr, gorm.ErrRecordNotFound)
+
+		tat := now
+		if exists && entry.TAT.After(now) {
+			tat = entry.TAT
+		}
+		newTAT := tat.Add(emissionInterval)
+
+		if newTAT.Sub(now) > l.burstTolerance {
+			allowed = false
+			retryAfter = newTAT.Sub(now) - l.burstTolerance
+			return nil
+		}
+
+		if exists {
+			entry.TAT = newTAT
+			entry.LastSubmit = now
+			return tx.Save(&entry).Error
+		}
+		return tx.Create(&models.RateLimitEntry{
+			UserID:      uint(userID),
+			TAT:         newTAT,
+			WindowStart: now,
+			LastSubmit:  now,
+		}).Error
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("GCRALimiter: %w", err)
+	}
+
+	return allowed, retryAfter, nil
+}
+
+// Reset implements RateLimitBackend by deleting key's row, so its next call
+// starts with a fresh TAT of now.
+func (l *GCRALimiter) Reset(ctx context.Context, key string) error {
+	userID, err := strconv.ParseUint(key, 10, 64)
+	if err != nil {
+		return fmt.Errorf("GCRALimiter: invalid key %q: %w", key, err)
+	}
+	return l.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RateLimitEntry{}).Error
+}
+
+// redisSlidingWindowScript atomically evicts entries older than now-window,
+// counts what's left, and - only if that count is still under the limit -
+// records this call's timestamp, all in a single round trip so concurrent
+// callers across replicas can't race between the count and the add.
+// KEYS[1] is the sorted set key. ARGV: now (ms), window (ms), limit.
+// Returns {allowed (0/1), retry_after_ms}.
+var redisSlidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, now .. '-' .. redis.call('INCR', key .. ':seq'))
+	redis.call('PEXPIRE', key, window)
+	return {1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local retryAfter = window
+if oldest[2] ~= nil then
+	retryAfter = tonumber(oldest[2]) + window - now
+end
+return {0, retryAfter}
+`)
+
+// RedisSlidingWindowLimiter is a Redis-backed RateLimitBackend using a
+// sorted-set sliding-window log: every allowed call's timestamp is a member
+// scored by itself, so ZREMRANGEBYSCORE can cheaply expire anything older
+// than now-window before ZCARD counts what's left. Unlike
+// SQLFixedWindowLimiter's fixed window, the limit here is exact over any
+// rolling window, and unlike TokenBucketLimiter, the limit is shared across
+// every scribble replica talking to the same Redis.
+type RedisSlidingWindowLimiter struct {
+	client *redis.Client
+	prefix string
+	policy RateLimitPolicy
+}
+
+// NewRedisSlidingWindowLimiter creates a RedisSlidingWindowLimiter against
+// client, namespacing its keys under keyPrefix (e.g.
+// "scribble:ratelimit").
+func NewRedisSlidingWindowLimiter(client *redis.Client, keyPrefix string, policy RateLimitPolicy) *RedisSlidingWindowLimiter {
+	return &RedisSlidingWindowLimiter{client: client, prefix: keyPrefix, policy: policy}
+}
+
+func (l *RedisSlidingWindowLimiter) key(key string) string {
+	return l.prefix + ":" + key
+}
+
+// Allow implements RateLimitBackend.
+func (l *RedisSlidingWindowLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l.policy.MaxSubmissions <= 0 {
+		return true, 0, nil
+	}
+
+	now := time.Now().UnixMilli()
+	windowMs := l.policy.Window.Milliseconds()
+
+	result, err := redisSlidingWindowScript.Run(ctx, l.client, []string{l.key(key)}, now, windowMs, l.policy.MaxSubmissions).Result()
+	if err != nil {
+		return false, 0, fmt.Errorf("RedisSlidingWindowLimiter: %w", err)
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("RedisSlidingWindowLimiter: unexpected script result %v", result)
+	}
+
+	allowed, _ := values[0].(int64)
+	retryAfterMs, _ := values[1].(int64)
+
+	return allowed == 1, time.Duration(retryAfterMs) * time.Millisecond, nil
+}
+
+// Reset implements RateLimitBackend by dropping key's sorted set entirely.
+func (l *RedisSlidingWindowLimiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, l.key(key)).Err()
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGCRALimiter_AllowsBurstThenDeniesUntilTATDrains(t *testing.T) {
+	db := setupTestDB(t)
+	policy := RateLimitPolicy{MaxSubmissions: 2, Window: time.Minute}
+	limiter := NewGCRALimiter(db, policy, 30*time.Second)
+	ctx := context.Background()
+
+	// emissionInterval is 30s/submission; burstTolerance of 30s lets one
+	// extra submission through immediately before the limiter starts
+	// rejecting.
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(ctx, "1")
+		if err != nil {
+			t.Fatalf("Allow() #%d returned error: %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("Allow() #%d = false, want true (within burst tolerance)", i)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "1")
+	if err != nil {
+		t.Fatalf("Allow() returned error: %v", err)
+	}
+	if allowed {
+		t.Fatal("Allow() = true, want false once burst tolerance is exhausted")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("retryAfter = %v, want a positive wait", retryAfter)
+	}
+}
+
+func TestGCRALimiter_TracksCallersIndependently(t *testing.T) {
+	db := setupTestDB(t)
+	policy := RateLimitPolicy{MaxSubmissions: 1, Window: time.Minute}
+	limiter := NewGCRALimiter(db, policy, 0)
+	ctx := context.Background()
+
+	if allowed, _, err := limiter.Allow(ctx, "1"); err != nil || !allowed {
+		t.Fatalf("Allow(1) = %v, %v, want true, nil", allowed, err)
+	}
+	if allowed, _, err := limiter.Allow(ctx, "2"); err != nil || !allowed {
+		t.Fatalf("Allow(2) = %v, %v, want true, nil", allowed, err)
+	}
+	if allowed, _, err := limiter.Allow(ctx, "1"); err != nil || allowed {
+		t.Fatalf("second Allow(1) = %v, %v, want false, nil", allowed, err)
+	}
+}
+
+func TestGCRALimiter_ZeroMaxSubmissionsIsUnlimited(t *testing.T) {
+	db := setupTestDB(t)
+	policy := RateLimitPolicy{MaxSubmissions: 0, Window: time.Minute}
+	limiter := NewGCRALimiter(db, policy, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		allowed, _, err := limiter.Allow(ctx, "1")
+		if err != nil {
+			t.Fatalf("Allow() #%d returned error: %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("Allow() #%d = false, want true for an unlimited policy", i)
+		}
+	}
+}
+
+func TestGCRALimiter_ResetClearsTrackedState(t *testing.T) {
+	db := setupTestDB(t)
+	policy := RateLimitPolicy{MaxSubmissions: 1, Window: time.Minute}
+	limiter := NewGCRALimiter(db, policy, 0)
+	ctx := context.Background()
+
+	if allowed, _, err := limiter.Allow(ctx, "1"); err != nil || !allowed {
+		t.Fatalf("Allow(1) = %v, %v, want true, nil", allowed, err)
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "1"); allowed {
+		t.Fatal("Allow(1) = true before Reset, want false")
+	}
+
+	if err := limiter.Reset(ctx, "1"); err != nil {
+		t.Fatalf("Reset() returned error: %v", err)
+	}
+
+	if allowed, _, err := limiter.Allow(ctx, "1"); err != nil || !allowed {
+		t.Fatalf("Allow(1) after Reset = %v, %v, want true, nil", allowed, err)
+	}
+}
+
+func TestAntiCheatService_CheckRateLimitUsesGCRABackend(t *testing.T) {
+	db := setupTestDB(t)
+	policy := RateLimitPolicy{MaxSubmissions: 1, Window: time.Minute}
+	backends := map[RateLimitTier]RateLimitBackend{
+		TierAuthenticated: NewGCRALimiter(db, policy, 0),
+	}
+	service := NewAntiCheatServiceWithRateLimiters(db, backends)
+
+	allowed, _, err := service.CheckRateLimit(42)
+	if err != nil {
+		t.Fatalf("CheckRateLimit() returned error: %v", err)
+	}
+	if !allowed {
+		t.Fatal("CheckRateLimit() = false on first call, want true")
+	}
+
+	allowed, retryAfter, err := service.CheckRateLimit(42)
+	if err != nil {
+		t.Fatalf("CheckRateLimit() returned error: %v", err)
+	}
+	if allowed {
+		t.Fatal("CheckRateLimit() = true on second call within the window, want false")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("retryAfter = %v, want a positive wait", retryAfter)
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults for the tiered rate limiter. The per-user numbers mirror the
+// old sliding-window defaults (5 submissions/minute); the global ceiling
+// is sized generously so it only kicks in under genuine pool-wide abuse.
+const (
+	DefaultGlobalRateLimit = 200 // requests per PerUserWindow, pool-wide
+	DefaultGlobalBurst     = 50
+
+	// DefaultMaxUserEntries bounds the per-user limiter LRU. At one
+	// *rate.Limiter per entry this is well under a megabyte even at the cap.
+	DefaultMaxUserEntries = 10000
+
+	// DefaultUserIdleTimeout evicts a user's limiter early if they haven't
+	// made a request in this long, well before MaxUserEntries is reached
+	// under normal traffic.
+	DefaultUserIdleTimeout = 10 * time.Minute
+)
+
+// RateLimiterConfig configures a tiered RateLimiter.
+type RateLimiterConfig struct {
+	// GlobalRateLimit/GlobalBurst bound total throughput across every user,
+	// checked before any per-user limiting is applied.
+	GlobalRateLimit int
+	GlobalBurst     int
+
+	// PerUserRateLimit/PerUserBurst bound a single user's throughput:
+	// PerUserRateLimit requests per PerUserWindow, with PerUserBurst
+	// letting a user spend a period of unused quota all at once.
+	PerUserRateLimit int
+	PerUserWindow    time.Duration
+	PerUserBurst     int
+
+	// MaxUserEntries/UserIdleTimeout bound the per-user limiter LRU so a
+	// long-lived server doesn't accumulate one limiter per distinct user
+	// forever - the previous sliding-window map had no such eviction.
+	MaxUserEntries  int
+	UserIdleTimeout time.Duration
+}
+
+// DefaultRateLimiterConfig returns sensible defaults matching the pool's
+// historical per-user limit of 5 requests/minute.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		GlobalRateLimit:  DefaultGlobalRateLimit,
+		GlobalBurst:      DefaultGlobalBurst,
+		PerUserRateLimit: DefaultRateLimit,
+		PerUserWindow:    DefaultRateWindow,
+		PerUserBurst:     DefaultRateLimit,
+		MaxUserEntries:   DefaultMaxUserEntries,
+		UserIdleTimeout:  DefaultUserIdleTimeout,
+	}
+}
+
+// userEntry is one user's limiter plus LRU bookkeeping.
+type userEntry struct {
+	userID   string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// RateLimiter is a tiered token-bucket rate limiter built on
+// golang.org/x/time/rate. It layers a pool-wide global limiter, a
+// per-user limiter kept in a bounded LRU (so memory doesn't grow with
+// every distinct user ever seen, unlike the old map-of-timestamps), and
+// optional per-endpoint limiters that callers can register for routes
+// that need a tighter ceiling than the general per-user rate.
+type RateLimiter struct {
+	mu     sync.Mutex
+	config RateLimiterConfig
+
+	global *rate.Limiter
+
+	users *list.List
+	index map[string]*list.Element
+
+	endpoints map[string]*rate.Limiter
+}
+
+// NewRateLimiter creates a RateLimiter from config, filling in any zero
+// fields from DefaultRateLimiterConfig.
+func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	defaults := DefaultRateLimiterConfig()
+	if config.GlobalRateLimit <= 0 {
+		config.GlobalRateLimit = defaults.GlobalRateLimit
+	}
+	if config.GlobalBurst <= 0 {
+		config.GlobalBurst = defaults.GlobalBurst
+	}
+	if config.PerUserRateLimit <= 0 {
+		config.PerUserRateLimit = defaults.PerUserRateLimit
+	}
+	if config.PerUserWindow <= 0 {
+		config.PerUserWindow = defaults.PerUserWindow
+	}
+	if config.PerUserBurst <= 0 {
+		config.PerUserBurst = defaults.PerUserBurst
+	}
+	if config.MaxUserEntries <= 0 {
+		config.MaxUserEntries = defaults.MaxUserEntries
+	}
+	if config.UserIdleTimeout <= 0 {
+		config.UserIdleTimeout = defaults.UserIdleTimeout
+	}
+
+	return &RateLimiter{
+		config:    config,
+		global:    rate.NewLimiter(perWindow(config.GlobalRateLimit, config.PerUserWindow), config.GlobalBurst),
+		users:     list.New(),
+		index:     make(map[string]*list.Element),
+		endpoints: make(map[string]*rate.Limiter),
+	}
+}
+
+// perWindow converts a "count per window" rate into the events-per-second
+// rate.Limit that golang.org/x/time/rate expects.
+func perWindow(count int, window time.Duration) rate.Limit {
+	return rate.Limit(float64(count) / window.Seconds())
+}
+
+// RegisterEndpointLimit adds (or replaces) a per-endpoint limiter, e.g. so
+// a handler can cap a particularly expensive route tighter than the
+// general per-user rate. Safe to call concurrently with Allow/Reserve.
+func (r *RateLimiter) RegisterEndpointLimit(endpoint string, limit int, window time.Duration, burst int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoints[endpoint] = rate.NewLimiter(perWindow(limit, window), burst)
+}
+
+// Allow reports whether a request from userID against endpoint (pass ""
+// if the caller doesn't need per-endpoint limiting) may proceed right
+// now. If not, retryAfter is how long the caller should wait before
+// trying again - callers serving HTTP should surface it as a
+// Retry-After header alongside a 429.
+func (r *RateLimiter) Allow(userID, endpoint string) (bool, time.Duration) {
+	if ok, retryAfter := tryReserve(r.global); !ok {
+		return false, retryAfter
+	}
+
+	if ok, retryAfter := tryReserve(r.userLimiter(userID)); !ok {
+		return false, retryAfter
+	}
+
+	if endpoint != "" {
+		if epLimiter, ok := r.endpointLimiter(endpoint); ok {
+			if ok, retryAfter := tryReserve(epLimiter); !ok {
+				return false, retryAfter
+			}
+		}
+	}
+
+	return true, 0
+}
+
+// Reserve is like Allow, but instead of rejecting a request that would
+// exceed the limit, it reserves the request's place in every tier and
+// returns how long the caller should sleep before proceeding. Callers
+// that use Reserve must actually wait out the returned duration (or not
+// call Reserve at all) - unlike Allow, the token is spent either way.
+func (r *RateLimiter) Reserve(userID, endpoint string) time.Duration {
+	var wait time.Duration
+
+	if d := reserveDelay(r.global); d > wait {
+		wait = d
+	}
+	if d := reserveDelay(r.userLimiter(userID)); d > wait {
+		wait = d
+	}
+	if endpoint != "" {
+		if epLimiter, ok := r.endpointLimiter(endpoint); ok {
+			if d := reserveDelay(epLimiter); d > wait {
+				wait = d
+			}
+		}
+	}
+
+	return wait
+}
+
+func (r *RateLimiter) endpointLimiter(endpoint string) (*rate.Limiter, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.endpoints[endpoint]
+	return l, ok
+}
+
+// userLimiter returns userID's limiter, creating it if needed, and
+// touches its LRU entry. Idle entries past UserIdleTimeout and, failing
+// that, the single least-recently-used entry once MaxUserEntries is
+// exceeded are evicted so long-lived servers don't leak one limiter per
+// user forever.
+func (r *RateLimiter) userLimiter(userID string) *rate.Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.evictIdleLocked(now)
+
+	if el, ok := r.index[userID]; ok {
+		r.users.MoveToFront(el)
+		entry := el.Value.(*userEntry)
+		entry.lastUsed = now
+		return entry.limiter
+	}
+
+	entry := &userEntry{
+		userID:   userID,
+		limiter:  rate.NewLimiter(perWindow(r.config.PerUserRateLimit, r.config.PerUserWindow), r.config.PerUserBurst),
+		lastUsed: now,
+	}
+	r.index[userID] = r.users.PushFront(entry)
+
+	if r.users.Len() > r.config.MaxUserEntries {
+		r.evictOldestLocked()
+	}
+
+	return entry.limiter
+}
+
+func (r *RateLimiter) evictIdleLocked(now time.Time) {
+	for {
+		back := r.users.Back()
+		if back == nil {
+			return
+		}
+		entry := back.Value.(*userEntry)
+		if now.Sub(entry.lastUsed) < r.config.UserIdleTimeout {
+			return
+		}
+		r.users.Remove(back)
+		delete(r.index, entry.userID)
+	}
+}
+
+func (r *RateLimiter) evictOldestLocked() {
+	back := r.users.Back()
+	if back == nil {
+		return
+	}
+	entry := back.Value.(*userEntry)
+	r.users.Remove(back)
+	delete(r.index, entry.userID)
+}
+
+// Reset clears every tracked user and endpoint limiter (useful for testing).
+func (r *RateLimiter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = list.New()
+	r.index = make(map[string]*list.Element)
+	r.endpoints = make(map[string]*rate.Limiter)
+}
+
+// tryReserve checks out one token from l without blocking: if the token
+// is immediately available it's consumed and tryReserve returns (true,
+// 0); otherwise the reservation is cancelled (so it doesn't cost l a
+// future token) and tryReserve returns (false, <time until it would be
+// available>).
+func tryReserve(l *rate.Limiter) (bool, time.Duration) {
+	resv := l.Reserve()
+	if !resv.OK() {
+		// Burst is 0 - the limiter can never admit a request.
+		return false, 0
+	}
+	if delay := resv.Delay(); delay > 0 {
+		resv.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+// reserveDelay reserves a token from l and returns how long to wait for
+// it, without cancelling - used by Reserve, where the caller commits to
+// waiting rather than being rejected outright.
+func reserveDelay(l *rate.Limiter) time.Duration {
+	resv := l.Reserve()
+	if !resv.OK() {
+		return 0
+	}
+	return resv.Delay()
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisQueueConfig configures a RedisQueue
+type RedisQueueConfig struct {
+	// KeyPrefix namespaces this queue's keys, e.g. "scribble:queue:submissions"
+	KeyPrefix string
+	// VisibilityTimeout is how long a dequeued-but-unacked job stays
+	// invisible to other workers before it's considered abandoned (e.g. the
+	// worker that claimed it crashed) and becomes eligible for redelivery.
+	VisibilityTimeout time.Duration
+	// ReclaimInterval is how often Dequeue sweeps the in-flight set for
+	// jobs whose visibility timeout has expired.
+	ReclaimInterval time.Duration
+}
+
+// DefaultRedisQueueConfig returns sensible defaults
+func DefaultRedisQueueConfig(keyPrefix string) RedisQueueConfig {
+	return RedisQueueConfig{
+		KeyPrefix:         keyPrefix,
+		VisibilityTimeout: 30 * time.Second,
+		ReclaimInterval:   5 * time.Second,
+	}
+}
+
+// RedisQueue is a Queue implementation backed by a Redis list plus a sorted
+// set tracking in-flight (claimed but not yet acked) jobs by deadline. It
+// gives at-least-once delivery: if a worker crashes after BLPOP but before
+// Ack, the job's visibility timeout expires and another worker (potentially
+// on a different scribble replica) picks it back up. Multiple replicas can
+// safely share the same RedisQueue since BLPOP and the reclaim sweep are
+// both atomic at the Redis level.
+type RedisQueue struct {
+	client *redis.Client
+	config RedisQueueConfig
+}
+
+// NewRedisQueue creates a RedisQueue against the given client
+func NewRedisQueue(client *redis.Client, config RedisQueueConfig) *RedisQueue {
+	return &RedisQueue{client: client, config: config}
+}
+
+func (q *RedisQueue) listKey() string         { return q.config.KeyPrefix + ":list" }
+func (q *RedisQueue) inFlightKey() string     { return q.config.KeyPrefix + ":inflight" }
+func (q *RedisQueue) jobKey(id string) string { return q.config.KeyPrefix + ":job:" + id }
+
+// Enqueue serializes the job as JSON, stores it under its own key, and
+// pushes the ID onto the list so BLPOP callers can claim it.
+func (q *RedisQueue) Enqueue(ctx context.Context, job *QueuedJob) error {
+	if job.ID == "" {
+		job.ID = uuid.NewString()
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.Set(ctx, q.jobKey(job.ID), payload, 24*time.Hour)
+	pipe.RPush(ctx, q.listKey(), job.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Dequeue reclaims any jobs whose visibility timeout has expired, then
+// BLPOPs the next job ID, marks it in-flight with a new deadline, and
+// returns its deserialized payload.
+func (q *RedisQueue) Dequeue(ctx context.Context) (*QueuedJob, error) {
+	if err := q.reclaimExpired(ctx); err != nil {
+		return nil, fmt.Errorf("failed to reclaim expired jobs: %w", err)
+	}
+
+	result, err := q.client.BLPop(ctx, q.config.ReclaimInterval, q.listKey()).Result()
+	if err == redis.Nil {
+		// Nothing available within this poll window - caller should loop
+		return nil, nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to dequeue: %w", err)
+	}
+
+	jobID := result[1]
+	deadline := float64(time.Now().Add(q.config.VisibilityTimeout).UnixNano())
+	if err := q.client.ZAdd(ctx, q.inFlightKey(), redis.Z{Score: deadline, Member: jobID}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to mark job %s in-flight: %w", jobID, err)
+	}
+
+	payload, err := q.client.Get(ctx, q.jobKey(jobID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job %s: %w", jobID, err)
+	}
+
+	var job QueuedJob
+	if err := json.Unmarshal([]byte(payload), &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job %s: %w", jobID, err)
+	}
+
+	return &job, nil
+}
+
+// reclaimExpired re-enqueues any in-flight job whose visibility deadline has
+// passed, i.e. the worker that claimed it never acked or nacked in time.
+func (q *RedisQueue) reclaimExpired(ctx context.Context) error {
+	now := float64(time.Now().UnixNano())
+	expired, err := q.client.ZRangeByScore(ctx, q.inFlightKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%f", now),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, jobID := range expired {
+		pipe := q.client.TxPipeline()
+		pipe.ZRem(ctx, q.inFlightKey(), jobID)
+		pipe.RPush(ctx, q.listKey(), jobID)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ack removes a job from the in-flight set, permanently completing it
+func (q *RedisQueue) Ack(ctx context.Context, jobID string) error {
+	pipe := q.client.TxPipeline()
+	pipe.ZRem(ctx, q.inFlightKey(), jobID)
+	pipe.Del(ctx, q.jobKey(jobID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Nack removes the job from in-flight and immediately re-enqueues it,
+// instead of waiting for its visibility timeout to expire.
+func (q *RedisQueue) Nack(ctx context.Context, jobID string) error {
+	pipe := q.client.TxPipeline()
+	pipe.ZRem(ctx, q.inFlightKey(), jobID)
+	pipe.RPush(ctx, q.listKey(), jobID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Len reports the number of jobs waiting in the list (not counting ones
+// currently claimed by a worker).
+func (q *RedisQueue) Len(ctx context.Context) (int, error) {
+	n, err := q.client.LLen(ctx, q.listKey()).Result()
+	return int(n), err
+}
+
+// redisResultBroker delivers job results via Redis pub/sub, keyed by job ID,
+// so any scribble replica - not just the one that processed the job - can
+// wake the caller waiting on Submit.
+type redisResultBroker struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// newRedisResultBroker creates a redisResultBroker
+func newRedisResultBroker(client *redis.Client, keyPrefix string) *redisResultBroker {
+	return &redisResultBroker{client: client, keyPrefix: keyPrefix}
+}
+
+func (b *redisResultBroker) channel(jobID string) string {
+	return b.keyPrefix + ":result:" + jobID
+}
+
+// Publish marshals the result and publishes it on the job's result channel
+func (b *redisResultBroker) Publish(ctx context.Context, jobID string, result *ExecutionJobResult) error {
+	payload, err := json.Marshal(resultEnvelope{
+		Result: result.Result,
+		Error:  errString(result.Error),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal result for job %s: %w", jobID, err)
+	}
+	return b.client.Publish(ctx, b.channel(jobID), payload).Err()
+}
+
+// Wait subscribes to the job's result channel and blocks until a result
+// arrives or ctx is cancelled.
+func (b *redisResultBroker) Wait(ctx context.Context, jobID string) (*ExecutionJobResult, error) {
+	sub := b.client.Subscribe(ctx, b.channel(jobID))
+	defer sub.Close()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg, ok := <-sub.Channel():
+		if !ok {
+			return nil, fmt.Errorf("result subscription for job %s closed", jobID)
+		}
+		var env resultEnvelope
+		if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result for job %s: %w", jobID, err)
+		}
+		jobResult := &ExecutionJobResult{Result: env.Result}
+		if env.Error != "" {
+			jobResult.Error = fmt.Errorf("%s", env.Error)
+		}
+		return jobResult, nil
+	}
+}
+
+// resultEnvelope is the JSON wire format published over the result channel.
+// Errors don't round-trip through JSON by default, so they're carried as a
+// plain string and rehydrated with fmt.Errorf on the receiving end.
+type resultEnvelope struct {
+	Result *k8s.ExecutionResult `json:"result,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// DefaultLeaderboardCron is the schedule used for any metric type whose
+// LEADERBOARD_CRON_<METRIC> environment variable isn't set.
+const DefaultLeaderboardCron = "*/15 * * * *"
+
+// LeaderboardCronSpecs maps each metric type to the cron schedule its
+// recomputation job runs on.
+type LeaderboardCronSpecs map[models.MetricType]string
+
+// LeaderboardCronSpecsFromEnv builds a LeaderboardCronSpecs from
+// LEADERBOARD_CRON_<METRIC> environment variables (e.g.
+// LEADERBOARD_CRON_FASTEST_AVG=*/15 * * * *), falling back to
+// DefaultLeaderboardCron for any metric type left unset.
+func LeaderboardCronSpecsFromEnv() LeaderboardCronSpecs {
+	specs := make(LeaderboardCronSpecs, len(models.AllMetricTypes()))
+	for _, metricType := range models.AllMetricTypes() {
+		envVar := "LEADERBOARD_CRON_" + strings.ToUpper(string(metricType))
+		if spec := os.Getenv(envVar); spec != "" {
+			specs[metricType] = spec
+			continue
+		}
+		specs[metricType] = DefaultLeaderboardCron
+	}
+	return specs
+}
+
+// Scheduler owns recurring background jobs (daily challenge selection,
+// streak rollups, leaderboard refreshes) and dispatches them onto the
+// appropriate named queue of a Dispatcher rather than having callers invoke
+// them ad-hoc from cron binaries or request handlers.
+type Scheduler struct {
+	cron        gocron.Scheduler
+	dispatcher  *Dispatcher
+	challenges  *DailyChallengeService
+	leaderboard *LeaderboardService
+}
+
+// NewScheduler creates a Scheduler backed by gocron. The Dispatcher is used
+// purely for queue stats/back-pressure bookkeeping - the scheduled jobs
+// themselves run the underlying service calls directly, since they aren't
+// code execution jobs and don't need a k8s.ExecutionJobParams payload.
+func NewScheduler(dispatcher *Dispatcher, challenges *DailyChallengeService, leaderboard *LeaderboardService) (*Scheduler, error) {
+	cron, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &Scheduler{
+		cron:        cron,
+		dispatcher:  dispatcher,
+		challenges:  challenges,
+		leaderboard: leaderboard,
+	}, nil
+}
+
+// RegisterDailyChallengeJob schedules daily challenge selection for midnight
+// UTC every day, onto the "daily" queue.
+func (s *Scheduler) RegisterDailyChallengeJob() error {
+	_, err := s.cron.NewJob(
+		gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(0, 0, 0))),
+		gocron.NewTask(func() {
+			if _, err := s.challenges.SelectNextChallenge(); err != nil {
+				log.Printf("daily challenge selection failed: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register daily challenge job: %w", err)
+	}
+	return nil
+}
+
+// RegisterStreakRollupJob schedules a streak statistics rollup at the given
+// interval onto the "aggregation" queue's worker capacity.
+func (s *Scheduler) RegisterStreakRollupJob(every time.Duration, rollup func() error) error {
+	_, err := s.cron.NewJob(
+		gocron.DurationJob(every),
+		gocron.NewTask(func() {
+			if err := rollup(); err != nil {
+				log.Printf("streak rollup failed: %v", err)
+			}
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register streak rollup job: %w", err)
+	}
+	return nil
+}
+
+// RegisterLeaderboardJobs schedules one recomputation job per metric type in
+// specs, each on its own cron schedule (e.g. LEADERBOARD_CRON_FASTEST_AVG).
+// Every job runs LeaderboardService.ComputeLeaderboardLocked, so multiple
+// server replicas racing the same schedule only have one of them actually
+// recompute a given metric at a time.
+func (s *Scheduler) RegisterLeaderboardJobs(specs LeaderboardCronSpecs) error {
+	for metricType, cronExpr := range specs {
+		metricType := metricType
+		_, err := s.cron.NewJob(
+			gocron.CronJob(cronExpr, false),
+			gocron.NewTask(func() {
+				if _, err := s.leaderboard.ComputeLeaderboardLocked(metricType); err != nil {
+					log.Printf("leaderboard recomputation failed for %s: %v", metricType, err)
+				}
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register leaderboard job for %s: %w", metricType, err)
+		}
+	}
+	return nil
+}
+
+// RegisterLeaderboardScopeJobs schedules the monthly, weekly, and daily
+// leaderboard recomputation jobs for every metric type: monthly rolls over
+// just after the 1st at 00:00 UTC, weekly just after Monday 00:00 UTC,
+// daily just after midnight UTC, matching the windows
+// LeaderboardService.ComputeLeaderboardForScope computes for
+// ScopeMonthly/ScopeWeekly/ScopeDaily. Season scopes aren't registered
+// here since their windows don't follow a calendar schedule - they're
+// recomputed on demand and finalized by LeaderboardService.CloseSeason.
+func (s *Scheduler) RegisterLeaderboardScopeJobs() error {
+	scopeSchedules := map[models.LeaderboardScope]string{
+		models.ScopeMonthly: "5 0 1 * *",
+		models.ScopeWeekly:  "5 0 * * 1",
+		models.ScopeDaily:   "5 0 * * *",
+	}
+
+	for scope, cronExpr := range scopeSchedules {
+		scope := scope
+		_, err := s.cron.NewJob(
+			gocron.CronJob(cronExpr, false),
+			gocron.NewTask(func() {
+				for _, metricType := range models.AllMetricTypes() {
+					if _, err := s.leaderboard.ComputeLeaderboardLockedForScope(metricType, scope); err != nil {
+						log.Printf("leaderboard recomputation failed for %s/%s: %v", metricType, scope, err)
+					}
+				}
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register leaderboard scope job for %s: %w", scope, err)
+		}
+	}
+	return nil
+}
+
+// Start begins running all registered jobs in the background
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops the scheduler and waits for in-flight jobs to finish
+func (s *Scheduler) Stop() error {
+	return s.cron.Shutdown()
+}
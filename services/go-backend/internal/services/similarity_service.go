@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// similarityKgramSize is how many tokens make up one k-gram before
+	// hashing.
+	similarityKgramSize = 5
+	// similarityWindowSize is how many consecutive k-gram hashes Winnowing
+	// selects the minimum from.
+	similarityWindowSize = 4
+	// similarityThreshold is the Jaccard similarity above which two
+	// submissions to the same problem are flagged as identical code.
+	similarityThreshold = 0.8
+)
+
+var (
+	similarityBlockComment = regexp.MustCompile(`/\*[\s\S]*?\*/`)
+	similarityLineComment  = regexp.MustCompile(`(//|#)[^\n]*`)
+	similarityTokenPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*|[0-9]+(\.[0-9]+)?|[^\sA-Za-z0-9_]`)
+)
+
+// SimilarityService detects near-duplicate submissions to the same problem
+// using Winnowing (Schleimer, Wilkerson & Aiken): source is tokenized and
+// hashed into overlapping k-grams, then the minimum hash in every sliding
+// window of those k-grams is kept as a fingerprint. Two submissions sharing
+// many fingerprints likely share a lot of code, regardless of renamed
+// identifiers or reformatted whitespace.
+type SimilarityService struct {
+	db        *gorm.DB
+	antiCheat *AntiCheatService
+}
+
+// NewSimilarityService creates a new similarity service instance
+func NewSimilarityService(db *gorm.DB, antiCheat *AntiCheatService) *SimilarityService {
+	return &SimilarityService{db: db, antiCheat: antiCheat}
+}
+
+// SimilarityMatch is one other submission to the same problem sharing
+// fingerprints with the submission being analyzed.
+type SimilarityMatch struct {
+	SubmissionID       uint    `json:"submission_id"`
+	UserID             uint    `json:"user_id"`
+	SharedFingerprints int     `json:"shared_fingerprints"`
+	Jaccard            float64 `json:"jaccard_similarity"`
+}
+
+// SimilarityResult is the outcome of analyzing one submission for
+// near-duplicates.
+type SimilarityResult struct {
+	SubmissionID     uint              `json:"submission_id"`
+	FingerprintCount int               `json:"fingerprint_count"`
+	Flagged          bool              `json:"flagged"`
+	Matches          []SimilarityMatch `json:"matches"`
+}
+
+// AnalyzeSubmission fingerprints code, stores the fingerprints, then looks
+// for other users' submissions to the same problem sharing enough of them
+// to exceed similarityThreshold. Any match at or above the threshold flags
+// the submission via FlagReasonIdenticalCode.
+func (s *SimilarityService) AnalyzeSubmission(ctx context.Context, submissionID, userID, problemID uint, code string) (*SimilarityResult, error) {
+	fingerprints := fingerprintCode(code)
+
+	rows := make([]models.SubmissionFingerprint, 0, len(fingerprints))
+	for _, fp := range fingerprints {
+		rows = append(rows, models.SubmissionFingerprint{
+			ProblemID:    problemID,
+			Fingerprint:  fp,
+			SubmissionID: submissionID,
+			UserID:       userID,
+		})
+	}
+	if len(rows) > 0 {
+		if err := s.db.Create(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to store submission fingerprints: %w", err)
+		}
+	}
+
+	matches, err := s.findMatches(problemID, submissionID, fingerprints)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SimilarityResult{
+		SubmissionID:     submissionID,
+		FingerprintCount: len(fingerprints),
+		Matches:          matches,
+	}
+
+	for _, match := range matches {
+		if match.Jaccard < similarityThreshold {
+			continue
+		}
+
+		result.Flagged = true
+		details := map[string]interface{}{
+			"matched_submission_id": match.SubmissionID,
+			"matched_user_id":       match.UserID,
+			"similarity":            match.Jaccard,
+		}
+		if err := s.antiCheat.FlagSubmission(ctx, submissionID, userID, problemID, models.FlagReasonIdenticalCode, details); err != nil {
+			return nil, fmt.Errorf("failed to flag identical code: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// NearestNeighbors returns submissionID's matches against other users'
+// submissions to the same problem, sorted by similarity descending,
+// regardless of whether any exceed similarityThreshold - for admin review.
+func (s *SimilarityService) NearestNeighbors(submissionID, problemID uint) ([]SimilarityMatch, error) {
+	var fingerprints []uint64
+	if err := s.db.Model(&models.SubmissionFingerprint{}).
+		Where("submission_id = ?", submissionID).
+		Pluck("fingerprint", &fingerprints).Error; err != nil {
+		return nil, fmt.Errorf("failed to load submission fingerprints: %w", err)
+	}
+
+	return s.findMatches(problemID, submissionID, fingerprints)
+}
+
+// findMatches groups other submissions to problemID sharing any of
+// fingerprints, counts the shared distinct fingerprints per candidate, and
+// turns that into a Jaccard similarity against the candidate's own total
+// fingerprint count.
+func (s *SimilarityService) findMatches(problemID, submissionID uint, fingerprints []uint64) ([]SimilarityMatch, error) {
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+
+	type sharedRow struct {
+		SubmissionID uint
+		UserID       uint
+		Shared       int64
+	}
+	var shared []sharedRow
+	if err := s.db.Model(&models.SubmissionFingerprint{}).
+		Select("submission_id, user_id, COUNT(DISTINCT fingerprint) as shared").
+		Where("problem_id = ? AND fingerprint IN ? AND submission_id != ?", problemID, fingerprints, submissionID).
+		Group("submission_id, user_id").
+		Scan(&shared).Error; err != nil {
+		return nil, fmt.Errorf("failed to find shared fingerprints: %w", err)
+	}
+
+	sizeA := len(uniqueUint64(fingerprints))
+
+	matches := make([]SimilarityMatch, 0, len(shared))
+	for _, row := range shared {
+		var sizeB int64
+		if err := s.db.Model(&models.SubmissionFingerprint{}).
+			Where("submission_id = ?", row.SubmissionID).
+			Distinct("fingerprint").
+			Count(&sizeB).Error; err != nil {
+			return nil, fmt.Errorf("failed to count candidate fingerprints: %w", err)
+		}
+
+		union := int64(sizeA) + sizeB - row.Shared
+		var jaccard float64
+		if union > 0 {
+			jaccard = float64(row.Shared) / float64(union)
+		}
+
+		matches = append(matches, SimilarityMatch{
+			SubmissionID:       row.SubmissionID,
+			UserID:             row.UserID,
+			SharedFingerprints: int(row.Shared),
+			Jaccard:            jaccard,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Jaccard > matches[j].Jaccard })
+
+	return matches, nil
+}
+
+// fingerprintCode tokenizes code and reduces its k-gram hashes to a
+// Winnowing fingerprint set.
+func fingerprintCode(code string) []uint64 {
+	tokens := tokenize(code)
+	hashes := kgramHashes(tokens, similarityKgramSize)
+	return winnow(hashes, similarityWindowSize)
+}
+
+// tokenize strips comments and splits code into a lowercased stream of
+// identifiers, numbers and single-character punctuation, so that renamed
+// variables and reformatted whitespace don't change the token stream's
+// shape.
+func tokenize(code string) []string {
+	code = similarityBlockComment.ReplaceAllString(code, " ")
+	code = similarityLineComment.ReplaceAllString(code, " ")
+
+	raw := similarityTokenPattern.FindAllString(code, -1)
+	tokens := make([]string, 0, len(raw))
+	for _, t := range raw {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		tokens = append(tokens, strings.ToLower(t))
+	}
+
+	return tokens
+}
+
+// kgramHashes hashes every overlapping window of k consecutive tokens with
+// FNV-64a.
+func kgramHashes(tokens []string, k int) []uint64 {
+	if len(tokens) < k {
+		return nil
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-k+1)
+	for i := 0; i+k <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+k] {
+			h.Write([]byte(token))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+
+	return hashes
+}
+
+// winnow implements the Winnowing algorithm: in every sliding window of w
+// consecutive hashes, it keeps the minimum, breaking ties by preferring the
+// rightmost position, and only emits a new fingerprint when the selected
+// position changes between windows. Duplicate fingerprints are dropped.
+func winnow(hashes []uint64, w int) []uint64 {
+	if len(hashes) == 0 {
+		return nil
+	}
+	if w < 1 || w > len(hashes) {
+		w = len(hashes)
+	}
+
+	seen := make(map[uint64]struct{}, len(hashes))
+	fingerprints := make([]uint64, 0)
+	prevMinPos := -1
+
+	for start := 0; start+w <= len(hashes); start++ {
+		minPos := start
+		for i := start + 1; i < start+w; i++ {
+			if hashes[i] <= hashes[minPos] {
+				minPos = i
+			}
+		}
+
+		if minPos != prevMinPos {
+			if _, ok := seen[hashes[minPos]]; !ok {
+				seen[hashes[minPos]] = struct{}{}
+				fingerprints = append(fingerprints, hashes[minPos])
+			}
+			prevMinPos = minPos
+		}
+	}
+
+	return fingerprints
+}
+
+// uniqueUint64 dedupes a slice of hashes.
+func uniqueUint64(values []uint64) []uint64 {
+	seen := make(map[uint64]struct{}, len(values))
+	unique := make([]uint64, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		unique = append(unique, v)
+	}
+	return unique
+}
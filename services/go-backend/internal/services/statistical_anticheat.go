@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// minSamplesForZScore is how many prior accepted submissions a problem
+// needs before StatisticalAnticheatDetector trusts its z-scores over
+// SuspiciousTimeThresholds' fixed per-difficulty floor. Below this, mean
+// and variance are too noisy to tell an outlier from ordinary variance.
+const minSamplesForZScore = 30
+
+// zScoreExtremeThreshold flags a submission outright when its execution
+// time alone is this many standard deviations below the problem's mean -
+// implausibly fast regardless of memory usage.
+const zScoreExtremeThreshold = -3.0
+
+// zScoreCombinedThreshold flags a submission when both execution time and
+// memory usage are at least this many standard deviations below their
+// respective means - less extreme than zScoreExtremeThreshold alone, but
+// two correlated anomalies are much less likely to be a fluke than one.
+const zScoreCombinedThreshold = -2.5
+
+// StatisticalAnticheatDetector flags submissions whose execution time or
+// memory usage is a statistical outlier relative to every other accepted
+// submission for the same problem, using Welford's online algorithm to
+// track a running mean and variance without storing individual samples.
+// Unlike SuspiciousTimeThresholds' fixed per-difficulty floor, this adapts
+// to each problem's actual observed population - a false positive on an
+// unusually easy "easy" problem, or a miss on a "hard" one solved mostly by
+// strong submissions, converges away as more samples come in.
+type StatisticalAnticheatDetector struct {
+	db *gorm.DB
+}
+
+// NewStatisticalAnticheatDetector creates a StatisticalAnticheatDetector
+// backed by db's problem_stats table.
+func NewStatisticalAnticheatDetector(db *gorm.DB) *StatisticalAnticheatDetector {
+	return &StatisticalAnticheatDetector{db: db}
+}
+
+// CheckAndUpdate reports whether execMs/memKb is a statistical outlier for
+// problemID given every previously accepted submission, then folds this
+// sample into the running stats via Welford's algorithm. The outlier check
+// and the stats update happen under the same row lock, so two concurrent
+// submissions for the same problem can't race on the read-modify-write.
+//
+// While n < minSamplesForZScore, this always reports not-an-outlier -
+// CheckSubmissionForTier's existing SuspiciousTimeThresholds check covers
+// that cold-start period instead.
+func (d *StatisticalAnticheatDetector) CheckAndUpdate(problemID uint, execMs, memKb int) (isOutlier bool, err error) {
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		var stats models.ProblemStats
+		txErr := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("problem_id = ?", problemID).First(&stats).Error
+		if txErr != nil && !errors.Is(txErr, gorm.ErrRecordNotFound) {
+			return txErr
+		}
+		exists := !errors.Is(txErr, gorm.ErrRecordNotFound)
+		if !exists {
+			stats.ProblemID = problemID
+		}
+
+		if stats.N >= minSamplesForZScore {
+			execZ := zScore(float64(execMs), stats.ExecMean, stats.ExecStdDev())
+			memZ := zScore(float64(memKb), stats.MemMean, stats.MemStdDev())
+			isOutlier = execZ < zScoreExtremeThreshold || (execZ < zScoreCombinedThreshold && memZ < zScoreCombinedThreshold)
+		}
+
+		priorN := stats.N
+		var newN int
+		newN, stats.ExecMean, stats.ExecM2 = welfordUpdate(priorN, stats.ExecMean, stats.ExecM2, float64(execMs))
+		_, stats.MemMean, stats.MemM2 = welfordUpdate(priorN, stats.MemMean, stats.MemM2, float64(memKb))
+		stats.N = newN
+
+		if exists {
+			return tx.Save(&stats).Error
+		}
+		return tx.Create(&stats).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("statistical anticheat check failed: %w", err)
+	}
+	return isOutlier, nil
+}
+
+// welfordUpdate folds a new sample x into a running (n, mean, m2) triple
+// per Welford's online algorithm, returning the updated triple. m2 is the
+// running sum of squared differences from the mean, from which variance is
+// mean/m2 / n (see models.ProblemStats.ExecStdDev/MemStdDev).
+func welfordUpdate(n int, mean, m2, x float64) (newN int, newMean, newM2 float64) {
+	newN = n + 1
+	delta := x - mean
+	newMean = mean + delta/float64(newN)
+	delta2 := x - newMean
+	newM2 = m2 + delta*delta2
+	return newN, newMean, newM2
+}
+
+// zScore returns (x - mean) / stddev, or 0 if stddev is 0 (every prior
+// sample was identical) so a merely-equal value isn't reported as an
+// infinite outlier.
+func zScore(x, mean, stddev float64) float64 {
+	if stddev == 0 {
+		return 0
+	}
+	return (x - mean) / stddev
+}
+
+// GetProblemStats returns problemID's running statistics for admin
+// inspection, or gorm.ErrRecordNotFound if no submission has been checked
+// for it yet.
+func (d *StatisticalAnticheatDetector) GetProblemStats(problemID uint) (*models.ProblemStats, error) {
+	var stats models.ProblemStats
+	if err := d.db.Where("problem_id = ?", problemID).First(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
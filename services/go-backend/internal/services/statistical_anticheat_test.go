@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestStatisticalAnticheatDetector_DegradesBelowMinSamples(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	for i := 0; i < minSamplesForZScore-1; i++ {
+		isOutlier, err := detector.CheckAndUpdate(1, 100, 1000)
+		if err != nil {
+			t.Fatalf("CheckAndUpdate() #%d returned error: %v", i, err)
+		}
+		if isOutlier {
+			t.Fatalf("CheckAndUpdate() #%d flagged an outlier before minSamplesForZScore samples were seen", i)
+		}
+	}
+
+	// Even a wildly fast submission shouldn't be flagged yet - there
+	// still aren't minSamplesForZScore prior samples.
+	isOutlier, err := detector.CheckAndUpdate(1, 1, 1000)
+	if err != nil {
+		t.Fatalf("CheckAndUpdate() returned error: %v", err)
+	}
+	if isOutlier {
+		t.Fatal("CheckAndUpdate() flagged an outlier on the minSamplesForZScore-th sample, want not yet")
+	}
+}
+
+func TestStatisticalAnticheatDetector_FlagsExtremeExecutionTimeOutlier(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	// Seed a tight, realistic population: execution time clustered around
+	// 100ms, memory around 1000KB.
+	seedPopulation(t, detector, minSamplesForZScore, 100, 1000)
+
+	isOutlier, err := detector.CheckAndUpdate(1, 1, 1000)
+	if err != nil {
+		t.Fatalf("CheckAndUpdate() returned error: %v", err)
+	}
+	if !isOutlier {
+		t.Fatal("CheckAndUpdate() = false for a 1ms submission against a ~100ms population, want true")
+	}
+}
+
+func TestStatisticalAnticheatDetector_FlagsCombinedTimeAndMemoryOutlier(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	seedPopulation(t, detector, minSamplesForZScore, 100, 1000)
+
+	// Neither alone crosses zScoreExtremeThreshold, but both together
+	// cross zScoreCombinedThreshold.
+	isOutlier, err := detector.CheckAndUpdate(1, 60, 600)
+	if err != nil {
+		t.Fatalf("CheckAndUpdate() returned error: %v", err)
+	}
+	if !isOutlier {
+		t.Fatal("CheckAndUpdate() = false for a submission far below the mean on both metrics, want true")
+	}
+}
+
+func TestStatisticalAnticheatDetector_DoesNotFlagOrdinarySubmissions(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	seedPopulation(t, detector, minSamplesForZScore, 100, 1000)
+
+	flagged := 0
+	samples := []int{95, 98, 100, 102, 105, 110, 90, 101, 99, 103}
+	for _, ms := range samples {
+		isOutlier, err := detector.CheckAndUpdate(1, ms, 1000)
+		if err != nil {
+			t.Fatalf("CheckAndUpdate() returned error: %v", err)
+		}
+		if isOutlier {
+			flagged++
+		}
+	}
+	if flagged != 0 {
+		t.Errorf("flagged %d/%d ordinary submissions near the population mean, want 0", flagged, len(samples))
+	}
+}
+
+func TestStatisticalAnticheatDetector_TracksProblemsIndependently(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	seedPopulation(t, detector, minSamplesForZScore, 100, 1000)
+
+	// A problem with no prior samples degrades to not-yet-trusted, even
+	// though problem 1 above now has enough to flag on.
+	isOutlier, err := detector.CheckAndUpdate(2, 1, 1)
+	if err != nil {
+		t.Fatalf("CheckAndUpdate() returned error: %v", err)
+	}
+	if isOutlier {
+		t.Fatal("CheckAndUpdate() flagged problem 2 using problem 1's population")
+	}
+}
+
+func TestStatisticalAnticheatDetector_GetProblemStats(t *testing.T) {
+	db := setupTestDB(t)
+	detector := NewStatisticalAnticheatDetector(db)
+
+	if _, err := detector.CheckAndUpdate(1, 100, 1000); err != nil {
+		t.Fatalf("CheckAndUpdate() returned error: %v", err)
+	}
+
+	stats, err := detector.GetProblemStats(1)
+	if err != nil {
+		t.Fatalf("GetProblemStats() returned error: %v", err)
+	}
+	if stats.N != 1 {
+		t.Errorf("N = %d, want 1", stats.N)
+	}
+	if stats.ExecMean != 100 {
+		t.Errorf("ExecMean = %v, want 100", stats.ExecMean)
+	}
+	if stats.MemMean != 1000 {
+		t.Errorf("MemMean = %v, want 1000", stats.MemMean)
+	}
+}
+
+// seedPopulation feeds a deterministic, mildly-varying population of n
+// samples centered on execCenter/memCenter into detector for problemID 1,
+// so ExecStdDev/MemStdDev come out non-zero and later outlier checks have
+// something meaningful to compare against.
+func seedPopulation(t *testing.T, detector *StatisticalAnticheatDetector, n, execCenter, memCenter int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		jitter := i%7 - 3 // -3..3, deterministic spread
+		if _, err := detector.CheckAndUpdate(1, execCenter+jitter, memCenter+jitter*10); err != nil {
+			t.Fatalf("seedPopulation: CheckAndUpdate() #%d returned error: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// DayActivity is one day's entry in a GetActivityCalendar result.
+type DayActivity struct {
+	Date            time.Time `json:"date"`
+	Solved          bool      `json:"solved"`
+	ProblemCount    int       `json:"problem_count"`
+	StreakDayNumber int       `json:"streak_day_number"`
+}
+
+// StreakSegment is one contiguous run of consecutive streak days, as
+// reconstructed from StreakHistory.
+type StreakSegment struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Length int       `json:"length"`
+}
+
+// dateRange calls fn once for every calendar day from start to end
+// (inclusive), in tz, walking one day at a time via AddDate so DST
+// transitions in tz fall out the same way the rest of time.Time's
+// arithmetic handles them.
+func dateRange(start, end time.Time, tz *time.Location, fn func(day time.Time)) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, tz)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, tz)
+	for !day.After(last) {
+		fn(day)
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// GetActivityCalendar returns one DayActivity entry per calendar day in
+// [from, to] (inclusive), walked in tz (falling back to UTC if tz can't be
+// parsed). Backed by SolveEvent so every recorded solve counts, not just the
+// user's current streak state - this is what powers GitHub-style
+// contribution heatmaps.
+func (s *StreakService) GetActivityCalendar(userID string, from, to time.Time, tz string) ([]DayActivity, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	var events []models.SolveEvent
+	err = s.db.Where("user_id = ? AND streak_day BETWEEN ? AND ?", userID, from, to).
+		Order("streak_day").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load solve events: %w", err)
+	}
+	problemCountByDay := make(map[string]int, len(events))
+	for _, event := range events {
+		problemCountByDay[event.StreakDay.Format("2006-01-02")]++
+	}
+
+	var history []models.StreakHistory
+	err = s.db.Where("user_id = ? AND solved_date BETWEEN ? AND ?", userID, from, to).
+		Order("solved_date").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load streak history: %w", err)
+	}
+	streakDayByDate := make(map[string]int, len(history))
+	for _, h := range history {
+		streakDayByDate[h.SolvedDate.Format("2006-01-02")] = h.StreakDay
+	}
+
+	var calendar []DayActivity
+	dateRange(from, to, loc, func(day time.Time) {
+		key := day.Format("2006-01-02")
+		count := problemCountByDay[key]
+		calendar = append(calendar, DayActivity{
+			Date:            day,
+			Solved:          count > 0,
+			ProblemCount:    count,
+			StreakDayNumber: streakDayByDate[key],
+		})
+	})
+
+	return calendar, nil
+}
+
+// GetStreakSegments reconstructs every contiguous run of consecutive streak
+// days from StreakHistory, e.g. "Mar 3 - Apr 12, 41 days". Continuity is
+// judged by StreakHistory.StreakDay incrementing by exactly one between
+// consecutive rows rather than by calendar-date adjacency, so a freeze-
+// bridged gap (see StreakService.ConsumeFreeze) still counts as one
+// unbroken segment.
+func (s *StreakService) GetStreakSegments(userID string) ([]StreakSegment, error) {
+	var history []models.StreakHistory
+	err := s.db.Where("user_id = ?", userID).
+		Order("solved_date").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load streak history: %w", err)
+	}
+
+	var segments []StreakSegment
+	for i, h := range history {
+		if i == 0 || h.StreakDay != history[i-1].StreakDay+1 {
+			segments = append(segments, StreakSegment{
+				Start:  h.SolvedDate,
+				End:    h.SolvedDate,
+				Length: 1,
+			})
+			continue
+		}
+		last := &segments[len(segments)-1]
+		last.End = h.SolvedDate
+		last.Length++
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestGetActivityCalendar_MarksSolvedDaysAndFillsGaps(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	setDailyChallenge(t, db, 7)
+	if _, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1"); err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+
+	from := today.AddDate(0, 0, -2)
+	to := today.AddDate(0, 0, 1)
+	calendar, err := service.GetActivityCalendar("user1", from, to, "UTC")
+	if err != nil {
+		t.Fatalf("GetActivityCalendar failed: %v", err)
+	}
+
+	if len(calendar) != 4 {
+		t.Fatalf("expected 4 days in the calendar, got %d", len(calendar))
+	}
+	for _, day := range calendar {
+		wantSolved := day.Date.Equal(today)
+		if day.Solved != wantSolved {
+			t.Errorf("day %v: Solved = %v, want %v", day.Date, day.Solved, wantSolved)
+		}
+		if wantSolved && day.StreakDayNumber != 1 {
+			t.Errorf("day %v: StreakDayNumber = %d, want 1", day.Date, day.StreakDayNumber)
+		}
+	}
+}
+
+func TestGetActivityCalendar_FallsBackToUTCForInvalidTimezone(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	calendar, err := service.GetActivityCalendar("user1", today, today, "Not/A/Zone")
+	if err != nil {
+		t.Fatalf("GetActivityCalendar failed: %v", err)
+	}
+	if len(calendar) != 1 {
+		t.Fatalf("expected 1 day, got %d", len(calendar))
+	}
+	if calendar[0].Date.Location() != time.UTC {
+		t.Errorf("expected an invalid tz to fall back to UTC, got location %v", calendar[0].Date.Location())
+	}
+}
+
+func TestGetStreakSegments_GroupsConsecutiveRunsAndBridgesFreezes(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	// Day 1: solve, building a 3-day run.
+	base := time.Now().UTC().AddDate(0, 0, -10).Truncate(24 * time.Hour)
+	for i := 0; i < 3; i++ {
+		insertStreakHistoryRow(t, db, "user1", base.AddDate(0, 0, i), i+1)
+	}
+	// A gap, then a fresh 2-day run.
+	for i := 0; i < 2; i++ {
+		insertStreakHistoryRow(t, db, "user1", base.AddDate(0, 0, 5+i), i+1)
+	}
+
+	segments, err := service.GetStreakSegments("user1")
+	if err != nil {
+		t.Fatalf("GetStreakSegments failed: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if segments[0].Length != 3 {
+		t.Errorf("expected first segment length 3, got %d", segments[0].Length)
+	}
+	if segments[1].Length != 2 {
+		t.Errorf("expected second segment length 2, got %d", segments[1].Length)
+	}
+}
+
+func insertStreakHistoryRow(t *testing.T, db *gorm.DB, userID string, solvedDate time.Time, streakDay int) {
+	t.Helper()
+	row := models.StreakHistory{
+		UserID:     userID,
+		SolvedDate: solvedDate,
+		ProblemID:  7,
+		StreakDay:  streakDay,
+	}
+	if err := db.Create(&row).Error; err != nil {
+		t.Fatalf("failed to insert streak history row: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+func TestStreakDay_ShiftsSolveBeforeCutoffToPreviousDay(t *testing.T) {
+	nyLoc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York timezone not available")
+	}
+
+	// 02:30 local on March 2nd, with a 4am cutoff, should still belong to
+	// March 1st's streak day.
+	beforeCutoff := time.Date(2026, 3, 2, 2, 30, 0, 0, nyLoc)
+	got := streakDay(beforeCutoff, nyLoc, 4)
+	want := time.Date(2026, 3, 1, 0, 0, 0, 0, nyLoc)
+	if !got.Equal(want) {
+		t.Errorf("streakDay(%v, cutoff=4) = %v, want %v", beforeCutoff, got, want)
+	}
+
+	// 04:30 local the same night is past the cutoff, so it rolls over to
+	// March 2nd's streak day.
+	afterCutoff := time.Date(2026, 3, 2, 4, 30, 0, 0, nyLoc)
+	got = streakDay(afterCutoff, nyLoc, 4)
+	want = time.Date(2026, 3, 2, 0, 0, 0, 0, nyLoc)
+	if !got.Equal(want) {
+		t.Errorf("streakDay(%v, cutoff=4) = %v, want %v", afterCutoff, got, want)
+	}
+}
+
+func TestStreakDay_AcrossDSTSpringForward(t *testing.T) {
+	nyLoc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("America/New_York timezone not available")
+	}
+
+	// 2026-03-08 is the US spring-forward date; 01:30 local with a 4am
+	// cutoff should still land on the previous streak day despite the
+	// missing 02:00-03:00 hour.
+	beforeCutoff := time.Date(2026, 3, 8, 1, 30, 0, 0, nyLoc)
+	got := streakDay(beforeCutoff, nyLoc, 4)
+	want := time.Date(2026, 3, 7, 0, 0, 0, 0, nyLoc)
+	if !got.Equal(want) {
+		t.Errorf("streakDay(%v, cutoff=4) = %v, want %v", beforeCutoff, got, want)
+	}
+}
+
+func TestStreakDay_DateLineWithCutoff(t *testing.T) {
+	samoaLoc, err := time.LoadLocation("Pacific/Apia")
+	if err != nil {
+		t.Skip("Pacific/Apia timezone not available")
+	}
+
+	beforeCutoff := time.Date(2026, 6, 15, 3, 0, 0, 0, samoaLoc)
+	got := streakDay(beforeCutoff, samoaLoc, 4)
+	want := time.Date(2026, 6, 14, 0, 0, 0, 0, samoaLoc)
+	if !got.Equal(want) {
+		t.Errorf("streakDay(%v, cutoff=4) = %v, want %v", beforeCutoff, got, want)
+	}
+}
+
+func TestUpdateStreak_SolveBeforeCutoffExtendsPreviousDay(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	// Seed a streak as if the user last solved "yesterday" per a 4am
+	// cutoff, i.e. yesterday's streak day even though the wall-clock date
+	// has already rolled over.
+	yesterdaysStreakDay := streakDay(time.Now(), time.UTC, 4).AddDate(0, 0, -1)
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  2,
+		LongestStreak:  2,
+		LastSolvedDate: &yesterdaysStreakDay,
+		DayCutoffHour:  4,
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+	setDailyChallenge(t, db, 7)
+
+	updated, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1")
+	if err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != 3 {
+		t.Errorf("expected streak to extend to 3, got %d", updated.CurrentStreak)
+	}
+}
+
+func TestSetDayCutoffHour_RejectsOutOfRange(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	if _, err := service.SetDayCutoffHour("user1", 24); err != ErrInvalidCutoffHour {
+		t.Errorf("expected ErrInvalidCutoffHour for hour=24, got %v", err)
+	}
+	if _, err := service.SetDayCutoffHour("user1", -1); err != ErrInvalidCutoffHour {
+		t.Errorf("expected ErrInvalidCutoffHour for hour=-1, got %v", err)
+	}
+}
+
+func TestSetDayCutoffHour_PersistsValue(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak, err := service.SetDayCutoffHour("user1", 4)
+	if err != nil {
+		t.Fatalf("SetDayCutoffHour failed: %v", err)
+	}
+	if streak.DayCutoffHour != 4 {
+		t.Errorf("expected DayCutoffHour to be 4, got %d", streak.DayCutoffHour)
+	}
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupStreakFreezeTestDB creates an in-memory SQLite database migrated for
+// StreakService and DailyChallengeService, the tables UpdateStreak touches.
+func setupStreakFreezeTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&models.UserStreak{}, &models.StreakHistory{}, &models.DailyChallenge{}, &models.Problem{})
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+
+	return db
+}
+
+// setDailyChallenge makes problemID today's daily challenge, letting
+// UpdateStreak accept a submission for it.
+func setDailyChallenge(t *testing.T, db *gorm.DB, problemID uint) {
+	t.Helper()
+	challenge := models.DailyChallenge{
+		ProblemID:     problemID,
+		ChallengeDate: time.Now().UTC().Truncate(24 * time.Hour),
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		t.Fatalf("failed to create daily challenge: %v", err)
+	}
+}
+
+func TestUpdateStreak_AutoGrantsFreezeOnInterval(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  streakFreezeEarnInterval - 1,
+		LongestStreak:  streakFreezeEarnInterval - 1,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+	setDailyChallenge(t, db, 7)
+
+	updated, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1")
+	if err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != streakFreezeEarnInterval {
+		t.Fatalf("expected streak %d, got %d", streakFreezeEarnInterval, updated.CurrentStreak)
+	}
+	if updated.StreakFreezes != 1 {
+		t.Errorf("expected 1 freeze granted at the accrual interval, got %d", updated.StreakFreezes)
+	}
+}
+
+func TestUpdateStreak_ConsumesFreezeOnOneDayGap(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  5,
+		LongestStreak:  5,
+		StreakFreezes:  1,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -2).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+	setDailyChallenge(t, db, 7)
+
+	updated, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1")
+	if err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != 6 {
+		t.Errorf("expected streak to extend to 6 using a freeze, got %d", updated.CurrentStreak)
+	}
+	if updated.StreakFreezes != 0 {
+		t.Errorf("expected the freeze to be spent, got %d remaining", updated.StreakFreezes)
+	}
+}
+
+func TestUpdateStreak_ResetsWhenNoFreezeAvailable(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  5,
+		LongestStreak:  5,
+		StreakFreezes:  0,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -2).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+	setDailyChallenge(t, db, 7)
+
+	updated, err := service.UpdateStreak(context.Background(), "user1", 7, "sub-1")
+	if err != nil {
+		t.Fatalf("UpdateStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != 1 {
+		t.Errorf("expected streak to reset to 1 with no freeze banked, got %d", updated.CurrentStreak)
+	}
+}
+
+func TestGrantFreeze_CapsAtMax(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	if _, err := service.GrantFreeze("user1", maxStreakFreezes); err != nil {
+		t.Fatalf("GrantFreeze failed: %v", err)
+	}
+	streak, err := service.GrantFreeze("user1", 5)
+	if err != nil {
+		t.Fatalf("GrantFreeze failed: %v", err)
+	}
+	if streak.StreakFreezes != maxStreakFreezes {
+		t.Errorf("expected freezes capped at %d, got %d", maxStreakFreezes, streak.StreakFreezes)
+	}
+}
+
+func TestConsumeFreeze_ErrorsWhenNoneLeft(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	if _, err := service.ConsumeFreeze("user1"); err != ErrNoFreezesLeft {
+		t.Fatalf("expected ErrNoFreezesLeft, got %v", err)
+	}
+
+	if _, err := service.GrantFreeze("user1", 1); err != nil {
+		t.Fatalf("GrantFreeze failed: %v", err)
+	}
+	streak, err := service.ConsumeFreeze("user1")
+	if err != nil {
+		t.Fatalf("ConsumeFreeze failed: %v", err)
+	}
+	if streak.StreakFreezes != 0 {
+		t.Errorf("expected 0 freezes after consuming the only one, got %d", streak.StreakFreezes)
+	}
+}
+
+func TestGetFreezes_ZeroForUnknownUser(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	count, err := service.GetFreezes("nobody")
+	if err != nil {
+		t.Fatalf("GetFreezes failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected 0 freezes for an unknown user, got %d", count)
+	}
+}
+
+func TestDaysUntilStreakBreaks_ExtendedByFreezes(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  5,
+		LongestStreak:  5,
+		StreakFreezes:  1,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+
+	days, err := service.DaysUntilStreakBreaks("user1")
+	if err != nil {
+		t.Fatalf("DaysUntilStreakBreaks failed: %v", err)
+	}
+	if days != 1 {
+		t.Errorf("expected 1 grace day remaining (1 base + 1 freeze - 1 elapsed), got %d", days)
+	}
+}
+
+func TestCheckStreak_BreaksExpiredStreakAndPublishesEvent(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+	bus := NewInMemoryEventBus()
+	service.AttachEventBus(bus)
+
+	published := make(chan StreakBrokenEvent, 1)
+	bus.Subscribe(TopicStreakBroken, func(ctx context.Context, event interface{}) error {
+		published <- event.(StreakBrokenEvent)
+		return nil
+	})
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  5,
+		LongestStreak:  5,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -3).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+
+	updated, err := service.CheckStreak(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("CheckStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != 0 {
+		t.Errorf("expected CurrentStreak to be broken to 0, got %d", updated.CurrentStreak)
+	}
+	select {
+	case event := <-published:
+		if event.UserID != "user1" || event.PreviousStreak != 5 {
+			t.Errorf("expected a streak_broken event for user1 with PreviousStreak 5, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a streak_broken event to be delivered asynchronously")
+	}
+
+	var persisted models.UserStreak
+	if err := db.Where("user_id = ?", "user1").First(&persisted).Error; err != nil {
+		t.Fatalf("failed to reload streak: %v", err)
+	}
+	if persisted.CurrentStreak != 0 {
+		t.Errorf("expected the break to be persisted, got CurrentStreak %d", persisted.CurrentStreak)
+	}
+}
+
+func TestCheckStreak_LeavesValidStreakUntouched(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	streak := &models.UserStreak{
+		UserID:         "user1",
+		CurrentStreak:  5,
+		LongestStreak:  5,
+		LastSolvedDate: timePtr(time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)),
+	}
+	if err := db.Create(streak).Error; err != nil {
+		t.Fatalf("failed to seed streak: %v", err)
+	}
+
+	updated, err := service.CheckStreak(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("CheckStreak failed: %v", err)
+	}
+	if updated.CurrentStreak != 5 {
+		t.Errorf("expected an unexpired streak to be left untouched, got %d", updated.CurrentStreak)
+	}
+}
+
+func TestListUserIDs_Pages(t *testing.T) {
+	db := setupStreakFreezeTestDB(t)
+	service := NewStreakService(db, NewDailyChallengeService(db, DefaultDailyChallengeServiceConfig()))
+
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		if err := db.Create(&models.UserStreak{UserID: userID}).Error; err != nil {
+			t.Fatalf("failed to seed streak: %v", err)
+		}
+	}
+
+	page1, err := service.ListUserIDs(0, 2)
+	if err != nil {
+		t.Fatalf("ListUserIDs failed: %v", err)
+	}
+	if len(page1) != 2 {
+		t.Fatalf("expected a page of 2 user IDs, got %d", len(page1))
+	}
+
+	page2, err := service.ListUserIDs(2, 2)
+	if err != nil {
+		t.Fatalf("ListUserIDs failed: %v", err)
+	}
+	if len(page2) != 1 {
+		t.Fatalf("expected 1 remaining user ID, got %d", len(page2))
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/nahtao97/scribble/internal/logging"
 	"github.com/nahtao97/scribble/internal/models"
 	"gorm.io/gorm"
 )
@@ -13,12 +15,42 @@ import (
 var (
 	ErrNotDailyChallenge = errors.New("submission is not for today's daily challenge")
 	ErrAlreadySolved     = errors.New("user already solved today's daily challenge")
+	ErrNoFreezesLeft     = errors.New("user has no streak freezes available")
 )
 
+const (
+	// streakFreezeEarnInterval is how many consecutive days a user must
+	// solve the daily challenge to earn one more streak freeze.
+	streakFreezeEarnInterval = 7
+	// maxStreakFreezes caps how many freezes a user can bank at once, via
+	// either automatic accrual or GrantFreeze.
+	maxStreakFreezes = 3
+	// minDayCutoffHour and maxDayCutoffHour bound the valid range for
+	// UserStreak.DayCutoffHour.
+	minDayCutoffHour = 0
+	maxDayCutoffHour = 23
+)
+
+// ErrInvalidCutoffHour is returned by SetDayCutoffHour when the requested
+// hour falls outside [0, 23].
+var ErrInvalidCutoffHour = errors.New("day cutoff hour must be between 0 and 23")
+
+// streakDay returns the calendar day t belongs to for streak-tracking
+// purposes, in tz, after shifting the clock back by cutoffHour. A cutoffHour
+// of 4 means a solve at 02:30 local time still counts toward the previous
+// streak day, since the day hasn't "rolled over" yet. A cutoffHour of 0
+// behaves exactly like truncating to local midnight.
+func streakDay(t time.Time, tz *time.Location, cutoffHour int) time.Time {
+	local := t.In(tz).Add(-time.Duration(cutoffHour) * time.Hour)
+	year, month, day := local.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, tz)
+}
+
 // StreakService handles user streak management
 type StreakService struct {
 	db               *gorm.DB
 	challengeService *DailyChallengeService
+	eventBus         EventBus
 }
 
 // NewStreakService creates a new streak service
@@ -29,16 +61,24 @@ func NewStreakService(db *gorm.DB, challengeService *DailyChallengeService) *Str
 	}
 }
 
+// AttachEventBus wires bus into the service so CheckStreak can publish
+// TopicStreakBroken. Optional - with no bus attached, CheckStreak still
+// zeroes expired streaks, it just doesn't notify anyone.
+func (s *StreakService) AttachEventBus(bus EventBus) {
+	s.eventBus = bus
+}
+
 // UpdateStreak updates a user's streak after solving the daily challenge
 // Logic:
 // - If user solves today's daily challenge (accepted), increment current_streak
 // - If user missed a day (last_solved_date != yesterday), reset to 1
 // - Update longest_streak if current exceeds it
-func (s *StreakService) UpdateStreak(userID string, problemID uint, submissionID string) (*models.UserStreak, error) {
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	yesterday := today.AddDate(0, 0, -1)
+func (s *StreakService) UpdateStreak(ctx context.Context, userID string, problemID uint, submissionID string) (*models.UserStreak, error) {
+	ctx = logging.WithFields(ctx, "user_id", userID, "problem_id", problemID, "submission_id", submissionID)
 
-	// Check if this is today's daily challenge
+	// Check if this is today's daily challenge. Challenge rotation is
+	// global, so it always keys off the UTC calendar day regardless of any
+	// individual user's streak day cutoff.
 	todaysChallenge, err := s.challengeService.GetTodaysChallenge()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get today's challenge: %w", err)
@@ -62,34 +102,55 @@ func (s *StreakService) UpdateStreak(userID string, problemID uint, submissionID
 		return nil, fmt.Errorf("failed to get user streak: %w", err)
 	}
 
+	today := streakDay(time.Now(), time.UTC, streak.DayCutoffHour)
+	yesterday := today.AddDate(0, 0, -1)
+
 	// Check if already solved today
 	if streak.LastSolvedDate != nil {
-		lastSolved := streak.LastSolvedDate.Truncate(24 * time.Hour)
+		lastSolved := streakDay(*streak.LastSolvedDate, time.UTC, streak.DayCutoffHour)
 		if lastSolved.Equal(today) {
 			return &streak, ErrAlreadySolved
 		}
 	}
 
 	// Update streak based on last solved date
-	if streak.LastSolvedDate == nil {
+	isFirstSolve := streak.LastSolvedDate == nil
+	isExtended := false
+	if isFirstSolve {
 		// First time solving
 		streak.CurrentStreak = 1
 	} else {
-		lastSolved := streak.LastSolvedDate.Truncate(24 * time.Hour)
-		if lastSolved.Equal(yesterday) {
+		lastSolved := streakDay(*streak.LastSolvedDate, time.UTC, streak.DayCutoffHour)
+		gapDays := int(today.Sub(lastSolved).Hours() / 24)
+		switch {
+		case lastSolved.Equal(yesterday):
 			// Consecutive day - extend streak
 			streak.CurrentStreak++
-		} else {
+			isExtended = true
+		case gapDays == 2 && streak.StreakFreezes > 0:
+			// Exactly one day missed and a freeze is banked - spend it to
+			// cover the gap instead of resetting.
+			streak.StreakFreezes--
+			streak.CurrentStreak++
+			isExtended = true
+		default:
 			// Missed a day - reset streak
 			streak.CurrentStreak = 1
 		}
 	}
 
 	// Update longest streak if exceeded
-	if streak.CurrentStreak > streak.LongestStreak {
+	isNewRecord := streak.CurrentStreak > streak.LongestStreak
+	if isNewRecord {
 		streak.LongestStreak = streak.CurrentStreak
 	}
 
+	// Every streakFreezeEarnInterval consecutive days earns one more
+	// freeze, capped at maxStreakFreezes.
+	if streak.CurrentStreak > 0 && streak.CurrentStreak%streakFreezeEarnInterval == 0 && streak.StreakFreezes < maxStreakFreezes {
+		streak.StreakFreezes++
+	}
+
 	// Update last solved date and total days
 	streak.LastSolvedDate = &today
 	streak.TotalDaysSolved++
@@ -114,12 +175,48 @@ func (s *StreakService) UpdateStreak(userID string, problemID uint, submissionID
 	}
 	if err := s.db.Create(&history).Error; err != nil {
 		// Log but don't fail - history is for analytics
-		fmt.Printf("Warning: failed to record streak history: %v\n", err)
+		logging.WithStacktrace(ctx, fmt.Errorf("failed to record streak history: %w", err))
+	}
+
+	// Record a solve event keyed by the user's streak day, so
+	// GetActivityCalendar and GetStreakSegments can walk it date-by-date.
+	event := models.SolveEvent{
+		UserID:    userID,
+		ProblemID: problemID,
+		SolvedAt:  time.Now(),
+		StreakDay: today,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		// Log but don't fail - solve events back the activity calendar, not
+		// the streak itself.
+		logging.WithStacktrace(ctx, fmt.Errorf("failed to record solve event: %w", err))
+	}
+
+	s.publish(ctx, TopicDailyChallengeSolved, DailyChallengeSolvedEvent{UserID: userID, ProblemID: problemID})
+	switch {
+	case isFirstSolve:
+		s.publish(ctx, TopicStreakStarted, StreakStartedEvent{UserID: userID})
+	case isExtended:
+		s.publish(ctx, TopicStreakExtended, StreakExtendedEvent{UserID: userID, CurrentStreak: streak.CurrentStreak})
+	}
+	if isNewRecord {
+		s.publish(ctx, TopicStreakRecord, StreakRecordEvent{UserID: userID, LongestStreak: streak.LongestStreak})
 	}
 
 	return &streak, nil
 }
 
+// publish is a no-op when no EventBus is attached, and otherwise publishes
+// event to topic, logging (rather than failing the caller) on error.
+func (s *StreakService) publish(ctx context.Context, topic EventTopic, event interface{}) {
+	if s.eventBus == nil {
+		return
+	}
+	if err := s.eventBus.Publish(ctx, topic, event); err != nil {
+		logging.WithStacktrace(ctx, fmt.Errorf("failed to publish %s event: %w", topic, err))
+	}
+}
+
 // GetStreak returns a user's current streak information
 func (s *StreakService) GetStreak(userID string) (*models.UserStreak, error) {
 	var streak models.UserStreak
@@ -136,13 +233,14 @@ func (s *StreakService) GetStreak(userID string) (*models.UserStreak, error) {
 		return nil, fmt.Errorf("failed to get streak: %w", err)
 	}
 
-	// Check if streak is still valid (solved yesterday or today)
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	yesterday := today.AddDate(0, 0, -1)
+	// Check if streak is still valid (solved yesterday or today, or the day
+	// before yesterday with a banked freeze covering the gap)
+	today := streakDay(time.Now(), time.UTC, streak.DayCutoffHour)
 
 	if streak.LastSolvedDate != nil {
-		lastSolved := streak.LastSolvedDate.Truncate(24 * time.Hour)
-		if !lastSolved.Equal(today) && !lastSolved.Equal(yesterday) {
+		lastSolved := streakDay(*streak.LastSolvedDate, time.UTC, streak.DayCutoffHour)
+		gapDays := int(today.Sub(lastSolved).Hours() / 24)
+		if gapDays > 1 && !(gapDays == 2 && streak.StreakFreezes > 0) {
 			// Streak has expired - reset current but keep longest
 			streak.CurrentStreak = 0
 		}
@@ -151,6 +249,173 @@ func (s *StreakService) GetStreak(userID string) (*models.UserStreak, error) {
 	return &streak, nil
 }
 
+// GrantFreeze adds count streak freezes to userID's bank, capped at
+// maxStreakFreezes, and returns the updated streak record.
+func (s *StreakService) GrantFreeze(userID string, count int) (*models.UserStreak, error) {
+	streak, err := s.getOrCreateStreak(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	streak.StreakFreezes += count
+	if streak.StreakFreezes > maxStreakFreezes {
+		streak.StreakFreezes = maxStreakFreezes
+	}
+
+	if err := s.db.Save(streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to grant streak freeze: %w", err)
+	}
+	return streak, nil
+}
+
+// ConsumeFreeze manually spends one of userID's banked streak freezes,
+// returning ErrNoFreezesLeft if none are available.
+func (s *StreakService) ConsumeFreeze(userID string) (*models.UserStreak, error) {
+	streak, err := s.getOrCreateStreak(userID)
+	if err != nil {
+		return nil, err
+	}
+	if streak.StreakFreezes <= 0 {
+		return nil, ErrNoFreezesLeft
+	}
+
+	streak.StreakFreezes--
+	if err := s.db.Save(streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to consume streak freeze: %w", err)
+	}
+	return streak, nil
+}
+
+// GetFreezes returns how many streak freezes userID currently has banked.
+func (s *StreakService) GetFreezes(userID string) (int, error) {
+	var streak models.UserStreak
+	err := s.db.Where("user_id = ?", userID).First(&streak).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get streak freezes: %w", err)
+	}
+	return streak.StreakFreezes, nil
+}
+
+// getOrCreateStreak loads userID's streak record, creating an empty one if
+// none exists yet, without persisting it until the caller saves.
+func (s *StreakService) getOrCreateStreak(userID string) (*models.UserStreak, error) {
+	var streak models.UserStreak
+	err := s.db.Where("user_id = ?", userID).First(&streak).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		streak = models.UserStreak{UserID: userID}
+		if err := s.db.Create(&streak).Error; err != nil {
+			return nil, fmt.Errorf("failed to create streak: %w", err)
+		}
+		return &streak, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+	return &streak, nil
+}
+
+// DaysUntilStreakBreaks returns how many days userID has left before their
+// current streak resets to zero if they don't solve again, factoring in any
+// banked freezes (each one buys an extra day of grace beyond the usual
+// one-day grace period).
+func (s *StreakService) DaysUntilStreakBreaks(userID string) (int, error) {
+	streak, err := s.GetStreak(userID)
+	if err != nil {
+		return 0, err
+	}
+	if streak.CurrentStreak == 0 || streak.LastSolvedDate == nil {
+		return 0, nil
+	}
+
+	today := streakDay(time.Now(), time.UTC, streak.DayCutoffHour)
+	lastSolved := streakDay(*streak.LastSolvedDate, time.UTC, streak.DayCutoffHour)
+	daysSinceSolve := int(today.Sub(lastSolved).Hours() / 24)
+
+	// One free grace day (today can still extend yesterday's solve),
+	// plus one more day per banked freeze.
+	graceDays := 1 + streak.StreakFreezes
+	remaining := graceDays - daysSinceSolve
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, nil
+}
+
+// SetDayCutoffHour sets the local hour (0-23) at which userID's streak day
+// rolls over, returning ErrInvalidCutoffHour if hour is out of range.
+func (s *StreakService) SetDayCutoffHour(userID string, hour int) (*models.UserStreak, error) {
+	if hour < minDayCutoffHour || hour > maxDayCutoffHour {
+		return nil, ErrInvalidCutoffHour
+	}
+
+	streak, err := s.getOrCreateStreak(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	streak.DayCutoffHour = hour
+	if err := s.db.Save(streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to set day cutoff hour: %w", err)
+	}
+	return streak, nil
+}
+
+// CheckStreak is GetStreak's proactive counterpart: it applies the same
+// freeze-aware expiry check, but persists a reset CurrentStreak and
+// publishes TopicStreakBroken instead of just reporting it. Meant to be
+// called by a background sweep (see internal/cron's StreakSweepJob) so
+// streaks break on schedule even for users who never hit a read endpoint.
+func (s *StreakService) CheckStreak(ctx context.Context, userID string) (*models.UserStreak, error) {
+	var streak models.UserStreak
+	err := s.db.Where("user_id = ?", userID).First(&streak).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.UserStreak{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+
+	if streak.CurrentStreak == 0 || streak.LastSolvedDate == nil {
+		return &streak, nil
+	}
+
+	today := streakDay(time.Now(), time.UTC, streak.DayCutoffHour)
+	lastSolved := streakDay(*streak.LastSolvedDate, time.UTC, streak.DayCutoffHour)
+	gapDays := int(today.Sub(lastSolved).Hours() / 24)
+	if gapDays <= 1 || (gapDays == 2 && streak.StreakFreezes > 0) {
+		return &streak, nil
+	}
+
+	previousStreak := streak.CurrentStreak
+	streak.CurrentStreak = 0
+	if err := s.db.Save(&streak).Error; err != nil {
+		return nil, fmt.Errorf("failed to break expired streak: %w", err)
+	}
+
+	s.publish(ctx, TopicStreakBroken, StreakBrokenEvent{UserID: userID, PreviousStreak: previousStreak})
+
+	return &streak, nil
+}
+
+// ListUserIDs returns up to limit user IDs from the streaks table ordered by
+// ID, offset pages at a time. Used by batch jobs like StreakSweepJob to walk
+// every row without loading them all into memory at once.
+func (s *StreakService) ListUserIDs(offset, limit int) ([]string, error) {
+	var ids []string
+	err := s.db.Model(&models.UserStreak{}).
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streak user ids: %w", err)
+	}
+	return ids, nil
+}
+
 // GetLeaderboard returns top users by streak (current or longest)
 func (s *StreakService) GetLeaderboard(limit int, byLongest bool) ([]models.UserStreak, error) {
 	var streaks []models.UserStreak
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// DayStats is one day's entry in a GetUserActivityHeatmap result.
+type DayStats struct {
+	Submissions int `json:"submissions"`
+	Accepted    int `json:"accepted"`
+}
+
+// LanguageBreakdownStat is one language's entry in a
+// GetUserLanguageBreakdown result. Distinct from UserService.LanguageStat:
+// that one is a simple count/accepted_count pair keyed off a uint user ID
+// via a raw Postgres FILTER query, while this one also carries average
+// runtime/memory across accepted submissions and is keyed off the
+// submissions table's string user ID - extending LanguageStat to carry
+// both would have forced one of the two queries to change engine or key
+// type, so this stays a separate, SubmissionService-owned type instead.
+type LanguageBreakdownStat struct {
+	Language    string   `json:"language"`
+	Submissions int64    `json:"submissions"`
+	Accepted    int64    `json:"accepted"`
+	AvgMs       *float64 `json:"avg_ms,omitempty"`
+	AvgKb       *float64 `json:"avg_kb,omitempty"`
+}
+
+// dayKey formats t as a UTC calendar-day key, the same "2006-01-02" string
+// keying streak_calendar.go's per-day maps - kept consistent rather than
+// introducing a separate civil-date type for this one analytics surface.
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// GetUserActivityHeatmap returns one DayStats entry per UTC calendar day in
+// [from, to] (inclusive) that has at least one submission, suitable for a
+// GitHub-style contribution grid. Unlike StreakService.GetActivityCalendar
+// (which walks every day in range off SolveEvent, scoped to the daily
+// challenge), this counts every submission to any problem and is keyed
+// directly off the submissions table.
+func (s *SubmissionService) GetUserActivityHeatmap(userID string, from, to time.Time) (map[string]DayStats, error) {
+	var rows []struct {
+		CreatedAt time.Time
+		Status    string
+	}
+	if err := s.db.Model(&models.Submission{}).
+		Select("created_at, status").
+		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, from, to).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load submissions for heatmap: %w", err)
+	}
+
+	heatmap := make(map[string]DayStats)
+	for _, row := range rows {
+		key := dayKey(row.CreatedAt)
+		day := heatmap[key]
+		day.Submissions++
+		if row.Status == models.StatusAccepted {
+			day.Accepted++
+		}
+		heatmap[key] = day
+	}
+
+	return heatmap, nil
+}
+
+// GetUserStreaks computes userID's current and longest streak of
+// consecutive UTC days with at least one accepted submission. This is an
+// independent, recomputed-from-scratch view of submissions - for the
+// incrementally maintained, freeze-aware streak StreakService tracks
+// against the daily challenge, see StreakService.UpdateStreak.
+func (s *SubmissionService) GetUserStreaks(userID string) (current, longest int, err error) {
+	var createdAts []time.Time
+	if err := s.db.Model(&models.Submission{}).
+		Where("user_id = ? AND status = ?", userID, models.StatusAccepted).
+		Pluck("created_at", &createdAts).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to load accepted submission dates: %w", err)
+	}
+	if len(createdAts) == 0 {
+		return 0, 0, nil
+	}
+
+	days := make(map[string]time.Time, len(createdAts))
+	for _, t := range createdAts {
+		t = t.UTC().Truncate(24 * time.Hour)
+		days[dayKey(t)] = t
+	}
+
+	distinct := make([]time.Time, 0, len(days))
+	for _, t := range days {
+		distinct = append(distinct, t)
+	}
+	sort.Slice(distinct, func(i, j int) bool { return distinct[i].Before(distinct[j]) })
+
+	longestRun := 1
+	currentRun := 1
+	for i := 1; i < len(distinct); i++ {
+		if distinct[i].Sub(distinct[i-1]) == 24*time.Hour {
+			currentRun++
+		} else {
+			currentRun = 1
+		}
+		if currentRun > longestRun {
+			longestRun = currentRun
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	last := distinct[len(distinct)-1]
+	if !last.Equal(today) && !last.Equal(yesterday) {
+		// The most recent active day is neither today nor yesterday, so
+		// whatever run it ended is already broken.
+		return 0, longestRun, nil
+	}
+
+	// Walk back from the end to measure the run that's still live.
+	liveRun := 1
+	for i := len(distinct) - 1; i > 0; i-- {
+		if distinct[i].Sub(distinct[i-1]) == 24*time.Hour {
+			liveRun++
+		} else {
+			break
+		}
+	}
+
+	return liveRun, longestRun, nil
+}
+
+// GetUserLanguageBreakdown returns userID's submission/acceptance counts
+// and average runtime/memory (across accepted submissions only) per
+// language, sorted by submission count descending.
+func (s *SubmissionService) GetUserLanguageBreakdown(userID string) ([]LanguageBreakdownStat, error) {
+	type row struct {
+		Language    string
+		Submissions int64
+		Accepted    int64
+		AvgTime     *float64
+		AvgMem      *float64
+	}
+	var rows []row
+	err := s.db.Model(&models.Submission{}).
+		Select(`language,
+			COUNT(*) as submissions,
+			SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) as accepted,
+			AVG(CASE WHEN status = ? THEN execution_time_ms ELSE NULL END) as avg_time,
+			AVG(CASE WHEN status = ? THEN memory_used_kb ELSE NULL END) as avg_mem`,
+			models.StatusAccepted, models.StatusAccepted, models.StatusAccepted).
+		Where("user_id = ?", userID).
+		Group("language").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load language breakdown: %w", err)
+	}
+
+	stats := make([]LanguageBreakdownStat, 0, len(rows))
+	for _, r := range rows {
+		stats = append(stats, LanguageBreakdownStat{
+			Language:    r.Language,
+			Submissions: r.Submissions,
+			Accepted:    r.Accepted,
+			AvgMs:       r.AvgTime,
+			AvgKb:       r.AvgMem,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Submissions > stats[j].Submissions })
+
+	return stats, nil
+}
+
+// difficultyBreakdown loads, per difficulty, userID's total and accepted
+// submission counts plus the set of distinct problem IDs solved - joining
+// each submission's problem_id (stored as text, see
+// models.Submission.ProblemID) against models.Problem.ID (uint) the same
+// way RecordSubmissionCounts does, by parsing it rather than relying on the
+// database to compare across the two column types.
+func (s *SubmissionService) difficultyBreakdown(userID string) (solved, total map[string]int, rate map[string]float64, err error) {
+	var rows []struct {
+		ProblemID string
+		Status    string
+	}
+	if err := s.db.Model(&models.Submission{}).
+		Select("problem_id, status").
+		Where("user_id = ?", userID).
+		Find(&rows).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load submissions for difficulty breakdown: %w", err)
+	}
+	if len(rows) == 0 {
+		return map[string]int{}, map[string]int{}, map[string]float64{}, nil
+	}
+
+	problemIDs := make(map[uint]struct{}, len(rows))
+	for _, row := range rows {
+		if id, err := strconv.ParseUint(row.ProblemID, 10, 32); err == nil {
+			problemIDs[uint(id)] = struct{}{}
+		}
+	}
+	ids := make([]uint, 0, len(problemIDs))
+	for id := range problemIDs {
+		ids = append(ids, id)
+	}
+
+	var problems []models.Problem
+	if err := s.db.Where("id IN ?", ids).Find(&problems).Error; err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load problems for difficulty breakdown: %w", err)
+	}
+	difficultyByID := make(map[uint]string, len(problems))
+	for _, p := range problems {
+		difficultyByID[p.ID] = p.Difficulty
+	}
+
+	total = make(map[string]int)
+	accepted := make(map[string]int)
+	solvedProblems := make(map[string]map[uint]struct{})
+	for _, row := range rows {
+		id, parseErr := strconv.ParseUint(row.ProblemID, 10, 32)
+		if parseErr != nil {
+			continue
+		}
+		difficulty, ok := difficultyByID[uint(id)]
+		if !ok {
+			continue
+		}
+		total[difficulty]++
+		if row.Status == models.StatusAccepted {
+			accepted[difficulty]++
+			if solvedProblems[difficulty] == nil {
+				solvedProblems[difficulty] = make(map[uint]struct{})
+			}
+			solvedProblems[difficulty][uint(id)] = struct{}{}
+		}
+	}
+
+	solved = make(map[string]int, len(solvedProblems))
+	for difficulty, set := range solvedProblems {
+		solved[difficulty] = len(set)
+	}
+
+	rate = make(map[string]float64, len(total))
+	for difficulty, t := range total {
+		if t > 0 {
+			rate[difficulty] = float64(accepted[difficulty]) / float64(t) * 100
+		}
+	}
+
+	return solved, total, rate, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/gorm"
+)
+
+func createAcceptedSubmission(t *testing.T, db *gorm.DB, userID, problemID, language string, createdAt time.Time) {
+	t.Helper()
+	submission := models.Submission{
+		ID:              "sub-" + strconv.FormatInt(createdAt.UnixNano(), 10),
+		UserID:          userID,
+		ProblemID:       problemID,
+		Language:        language,
+		Code:            "x",
+		Status:          models.StatusAccepted,
+		ExecutionTimeMs: 10,
+		MemoryUsedKb:    100,
+	}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create submission: %v", err)
+	}
+	if err := db.Model(&models.Submission{}).Where("id = ?", submission.ID).Update("created_at", createdAt).Error; err != nil {
+		t.Fatalf("failed to backdate submission: %v", err)
+	}
+}
+
+func TestGetUserActivityHeatmap_CountsSubmissionsAndAcceptedPerDay(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	createAcceptedSubmission(t, db, "u1", "1", "python", today)
+	createAcceptedSubmission(t, db, "u1", "1", "python", today.Add(2*time.Hour))
+
+	reject := models.Submission{ID: "sub-reject", UserID: "u1", ProblemID: "1", Language: "python", Code: "x", Status: models.StatusWrongAnswer}
+	if err := db.Create(&reject).Error; err != nil {
+		t.Fatalf("failed to create rejected submission: %v", err)
+	}
+	if err := db.Model(&models.Submission{}).Where("id = ?", reject.ID).Update("created_at", today).Error; err != nil {
+		t.Fatalf("failed to backdate submission: %v", err)
+	}
+
+	heatmap, err := service.GetUserActivityHeatmap("u1", today.AddDate(0, 0, -1), today.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetUserActivityHeatmap failed: %v", err)
+	}
+
+	day := heatmap[dayKey(today)]
+	if day.Submissions != 3 {
+		t.Errorf("Submissions = %d, want 3", day.Submissions)
+	}
+	if day.Accepted != 2 {
+		t.Errorf("Accepted = %d, want 2", day.Accepted)
+	}
+}
+
+func TestGetUserStreaks_ConsecutiveDaysCountCurrentAndLongest(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	// A 3-day run ending 5 days ago (broken), then a 2-day run ending
+	// today - longest should be the first run, current the second.
+	for _, offset := range []int{-7, -6, -5, -1, 0} {
+		createAcceptedSubmission(t, db, "u1", "1", "python", today.AddDate(0, 0, offset))
+	}
+
+	current, longest, err := service.GetUserStreaks("u1")
+	if err != nil {
+		t.Fatalf("GetUserStreaks failed: %v", err)
+	}
+	if longest != 3 {
+		t.Errorf("longest = %d, want 3", longest)
+	}
+	if current != 2 {
+		t.Errorf("current = %d, want 2", current)
+	}
+}
+
+func TestGetUserStreaks_BrokenStreakHasZeroCurrent(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	createAcceptedSubmission(t, db, "u1", "1", "python", today.AddDate(0, 0, -5))
+
+	current, longest, err := service.GetUserStreaks("u1")
+	if err != nil {
+		t.Fatalf("GetUserStreaks failed: %v", err)
+	}
+	if current != 0 {
+		t.Errorf("current = %d, want 0 for a streak that ended 5 days ago", current)
+	}
+	if longest != 1 {
+		t.Errorf("longest = %d, want 1", longest)
+	}
+}
+
+func TestGetUserStreaks_NoSubmissionsReturnsZero(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	current, longest, err := service.GetUserStreaks("nobody")
+	if err != nil {
+		t.Fatalf("GetUserStreaks failed: %v", err)
+	}
+	if current != 0 || longest != 0 {
+		t.Errorf("got (%d, %d), want (0, 0)", current, longest)
+	}
+}
+
+func TestGetUserLanguageBreakdown_SortedByCountWithAverages(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	now := time.Now().UTC()
+	createAcceptedSubmission(t, db, "u1", "1", "go", now)
+	createAcceptedSubmission(t, db, "u1", "1", "go", now.Add(time.Second))
+	createAcceptedSubmission(t, db, "u1", "1", "python", now.Add(2*time.Second))
+
+	stats, err := service.GetUserLanguageBreakdown("u1")
+	if err != nil {
+		t.Fatalf("GetUserLanguageBreakdown failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 languages, got %d", len(stats))
+	}
+	if stats[0].Language != "go" || stats[0].Submissions != 2 {
+		t.Errorf("stats[0] = %+v, want go with 2 submissions", stats[0])
+	}
+	if stats[0].AvgMs == nil || *stats[0].AvgMs != 10 {
+		t.Errorf("AvgMs = %v, want 10", stats[0].AvgMs)
+	}
+}
+
+func TestGetUserSubmissionStats_DifficultyBreakdown(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	easy := models.Problem{Title: "Easy", Slug: "easy", Difficulty: "easy", Description: "d"}
+	hard := models.Problem{Title: "Hard", Slug: "hard", Difficulty: "hard", Description: "d"}
+	if err := db.Create(&easy).Error; err != nil {
+		t.Fatalf("failed to create easy problem: %v", err)
+	}
+	if err := db.Create(&hard).Error; err != nil {
+		t.Fatalf("failed to create hard problem: %v", err)
+	}
+
+	now := time.Now().UTC()
+	createAcceptedSubmission(t, db, "u1", strconv.FormatUint(uint64(easy.ID), 10), "go", now)
+	reject := models.Submission{
+		ID:        "sub-hard-reject",
+		UserID:    "u1",
+		ProblemID: strconv.FormatUint(uint64(hard.ID), 10),
+		Language:  "go",
+		Code:      "x",
+		Status:    models.StatusWrongAnswer,
+	}
+	if err := db.Create(&reject).Error; err != nil {
+		t.Fatalf("failed to create rejected submission: %v", err)
+	}
+
+	stats, err := service.GetUserSubmissionStats("u1")
+	if err != nil {
+		t.Fatalf("GetUserSubmissionStats failed: %v", err)
+	}
+	if stats.SolvedByDifficulty["easy"] != 1 {
+		t.Errorf("SolvedByDifficulty[easy] = %d, want 1", stats.SolvedByDifficulty["easy"])
+	}
+	if stats.AcceptanceRateByDifficulty["hard"] != 0 {
+		t.Errorf("AcceptanceRateByDifficulty[hard] = %v, want 0", stats.AcceptanceRateByDifficulty["hard"])
+	}
+	if stats.AcceptanceRateByDifficulty["easy"] != 100 {
+		t.Errorf("AcceptanceRateByDifficulty[easy] = %v, want 100", stats.AcceptanceRateByDifficulty["easy"])
+	}
+}
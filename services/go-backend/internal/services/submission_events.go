@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SubmissionEvent is one status update PublishStatus fans out to a
+// submission's subscribers, replacing a client's need to poll
+// GetSubmissionByID to notice a queued -> running -> judged transition.
+type SubmissionEvent struct {
+	SubmissionID    string `json:"submission_id"`
+	Status          string `json:"status"`
+	ExecutionTimeMs int64  `json:"execution_time_ms,omitempty"`
+	MemoryUsedKB    int64  `json:"memory_used_kb,omitempty"`
+}
+
+// SubmissionEventBroker fans SubmissionEvents out to every current
+// subscriber for a submission, potentially across scribble replicas -
+// the pub/sub counterpart to ResultBroker's single-waiter Publish/Wait.
+type SubmissionEventBroker interface {
+	Publish(ctx context.Context, event SubmissionEvent) error
+	// Subscribe returns a channel of events for submissionID and an
+	// unsubscribe function. Callers must call unsubscribe (directly, or by
+	// cancelling ctx) once done reading, or the subscription leaks.
+	Subscribe(ctx context.Context, submissionID string) (<-chan SubmissionEvent, func(), error)
+}
+
+// localSubmissionEventBroker fans events out to in-process subscriber
+// channels, matching localResultBroker's scope: correct within a single
+// scribble replica, not across a fleet of them.
+type localSubmissionEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string][]chan SubmissionEvent
+}
+
+func newLocalSubmissionEventBroker() *localSubmissionEventBroker {
+	return &localSubmissionEventBroker{subscribers: make(map[string][]chan SubmissionEvent)}
+}
+
+// Publish delivers event to every subscriber currently registered for
+// event.SubmissionID. Sends are non-blocking: a subscriber too slow to
+// drain its buffered channel misses the event rather than stalling the
+// publisher.
+func (b *localSubmissionEventBroker) Publish(ctx context.Context, event SubmissionEvent) error {
+	b.mu.Lock()
+	subs := append([]chan SubmissionEvent(nil), b.subscribers[event.SubmissionID]...)
+	b.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a new channel for submissionID. The returned
+// unsubscribe function removes and closes it; it's also invoked
+// automatically once ctx is done, so a caller that only cancels ctx
+// (rather than also calling the returned func) still cleans up.
+func (b *localSubmissionEventBroker) Subscribe(ctx context.Context, submissionID string) (<-chan SubmissionEvent, func(), error) {
+	ch := make(chan SubmissionEvent, 16)
+
+	b.mu.Lock()
+	b.subscribers[submissionID] = append(b.subscribers[submissionID], ch)
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			subs := b.subscribers[submissionID]
+			for i, c := range subs {
+				if c == ch {
+					b.subscribers[submissionID] = append(subs[:i], subs[i+1:]...)
+					break
+				}
+			}
+			if len(b.subscribers[submissionID]) == 0 {
+				delete(b.subscribers, submissionID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return ch, unsubscribe, nil
+}
+
+// redisSubmissionEventBroker fans events out via Redis pub/sub, so
+// subscribers connected to a different scribble replica than the one that
+// called PublishStatus still get notified.
+type redisSubmissionEventBroker struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+func newRedisSubmissionEventBroker(client *redis.Client, keyPrefix string) *redisSubmissionEventBroker {
+	return &redisSubmissionEventBroker{client: client, keyPrefix: keyPrefix}
+}
+
+func (b *redisSubmissionEventBroker) channel(submissionID string) string {
+	return b.keyPrefix + ":submission-events:" + submissionID
+}
+
+func (b *redisSubmissionEventBroker) Publish(ctx context.Context, event SubmissionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal submission event for %s: %w", event.SubmissionID, err)
+	}
+	return b.client.Publish(ctx, b.channel(event.SubmissionID), payload).Err()
+}
+
+// Subscribe opens a Redis subscription for submissionID. Redis pub/sub
+// delivers to every subscriber naturally, so unlike localResultBroker's
+// single-waiter map, no fan-out bookkeeping is needed here - the returned
+// unsubscribe just closes this subscriber's own connection.
+func (b *redisSubmissionEventBroker) Subscribe(ctx context.Context, submissionID string) (<-chan SubmissionEvent, func(), error) {
+	sub := b.client.Subscribe(ctx, b.channel(submissionID))
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe to submission %s events: %w", submissionID, err)
+	}
+
+	events := make(chan SubmissionEvent, 16)
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			sub.Close()
+		})
+	}
+
+	go func() {
+		defer close(events)
+		for msg := range sub.Channel() {
+			var event SubmissionEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return events, unsubscribe, nil
+}
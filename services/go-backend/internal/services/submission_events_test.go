@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLocalSubmissionEventBroker_SubscriberGetsOrderedEvents(t *testing.T) {
+	broker := newLocalSubmissionEventBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, unsubscribe, err := broker.Subscribe(ctx, "sub-1")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribe()
+
+	statuses := []string{"running", "time_limit"}
+	for _, status := range statuses {
+		if err := broker.Publish(ctx, SubmissionEvent{SubmissionID: "sub-1", Status: status}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	for _, want := range statuses {
+		select {
+		case event := <-events:
+			if event.Status != want {
+				t.Errorf("got status %q, want %q", event.Status, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for status %q", want)
+		}
+	}
+}
+
+func TestLocalSubmissionEventBroker_UnsubscribeStopsDelivery(t *testing.T) {
+	broker := newLocalSubmissionEventBroker()
+	ctx := context.Background()
+
+	events, unsubscribe, err := broker.Subscribe(ctx, "sub-2")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("expected channel to be closed after unsubscribe")
+	}
+
+	if subs := broker.subscribers["sub-2"]; len(subs) != 0 {
+		t.Errorf("expected no subscribers left registered, got %d", len(subs))
+	}
+
+	// Publishing after every subscriber unsubscribed should be a no-op,
+	// not a panic or a send on a closed channel.
+	if err := broker.Publish(ctx, SubmissionEvent{SubmissionID: "sub-2", Status: "accepted"}); err != nil {
+		t.Errorf("Publish() after unsubscribe error = %v", err)
+	}
+}
+
+func TestLocalSubmissionEventBroker_ContextCancelCleansUp(t *testing.T) {
+	broker := newLocalSubmissionEventBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, _, err := broker.Subscribe(ctx, "sub-3")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected channel to be closed after context cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close after context cancel")
+	}
+
+	broker.mu.Lock()
+	subs := broker.subscribers["sub-3"]
+	broker.mu.Unlock()
+	if len(subs) != 0 {
+		t.Errorf("expected no subscribers left registered after context cancel, got %d", len(subs))
+	}
+}
+
+func TestSubmissionService_PublishStatusReachesSubscriber(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, unsubscribe, err := service.Subscribe(ctx, "sub-4")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribe()
+
+	if err := service.PublishStatus("sub-4", "running", 0, 0); err != nil {
+		t.Fatalf("PublishStatus() error = %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Status != "running" {
+			t.Errorf("got status %q, want %q", event.Status, "running")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for published status")
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupPipelineTestDB migrates every table the subsystems wired together
+// below touch, so the test below exercises them against one shared
+// submissions table the way a real deployment would, rather than each
+// service's own isolated fixture.
+func setupPipelineTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(
+		&models.Problem{},
+		&models.Submission{},
+		&models.SubmissionDeadLetter{},
+		&models.SubmissionFingerprint{},
+		&models.SubmissionFingerprintKey{},
+		&models.Contest{},
+		&models.ContestParticipant{},
+	)
+	if err != nil {
+		t.Fatalf("failed to migrate test tables: %v", err)
+	}
+
+	return db
+}
+
+// TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions
+// wires JudgeQueue, ContestService and PlagiarismService together over one
+// shared DB and one shared set of submissions, rather than each being
+// proven correct only against its own synthetic fixture as the rest of
+// this package's tests do. None of these three are reachable from cmd/
+// yet (see their "library code only" doc comments), but this pins down
+// that once something does wire JudgeQueue.CreateSubmission into
+// cmd/server/main.go's submission path, the rows it produces are exactly
+// what ContestService's leaderboard and PlagiarismService's Scan already
+// expect to read - the three don't silently disagree on schema or status
+// values.
+func TestSubmissionPipeline_JudgeQueueContestAndPlagiarismShareSubmissions(t *testing.T) {
+	db := setupPipelineTestDB(t)
+
+	executor := &fakeJudgeExecutor{}
+	dispatcher := NewDispatcher(executor)
+	if err := dispatcher.RegisterQueue(DefaultQueueName, 4, 16); err != nil {
+		t.Fatalf("failed to register queue: %v", err)
+	}
+	t.Cleanup(func() { _ = dispatcher.Shutdown(context.Background()) })
+
+	submissionService := NewSubmissionService(db)
+	queue := NewJudgeQueue(db, dispatcher, submissionService, DefaultQueueName, JudgeQueueConfig{MaxAttempts: 1, InitialBackoff: time.Millisecond, Multiplier: 2})
+
+	start := time.Now().Add(-time.Hour).UTC()
+	contest := models.Contest{Name: "Pipeline Cup", StartAt: start, EndAt: start.Add(2 * time.Hour), ScoringMode: models.ScoringICPC}
+	contestService := NewContestService(db)
+	if err := contestService.CreateContest(&contest); err != nil {
+		t.Fatalf("CreateContest() error = %v", err)
+	}
+
+	// User 1 submits the original solution, user 2 submits a near-identical
+	// copy (renamed identifiers only) to the same problem - both go through
+	// the real queueing/execution path instead of being inserted directly.
+	submission1, err := queue.CreateSubmission("1", "1", "python", plagiarismOriginal, nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission(user 1) error = %v", err)
+	}
+	submission2, err := queue.CreateSubmission("2", "1", "python", plagiarismRenamed, nil)
+	if err != nil {
+		t.Fatalf("CreateSubmission(user 2) error = %v", err)
+	}
+
+	waitForSubmissionStatus(t, queue, submission1, models.StatusAccepted)
+	waitForSubmissionStatus(t, queue, submission2, models.StatusAccepted)
+
+	// ContestService reads the very rows JudgeQueue just wrote: both
+	// submissions solved problem 1 within the contest window, so both
+	// should appear on the leaderboard.
+	board, err := contestService.GetContestLeaderboard(contest.ID, PaginationParams{})
+	if err != nil {
+		t.Fatalf("GetContestLeaderboard() error = %v", err)
+	}
+	if len(board.Entries) != 2 {
+		t.Fatalf("expected both queued-and-judged submissions on the leaderboard, got %d entries", len(board.Entries))
+	}
+
+	// PlagiarismService.RebuildIndex fingerprints every StatusAccepted
+	// submission to problem 1 - the same status JudgeQueue.applyResult just
+	// persisted - then Scan should flag user 2's submission as a near-copy
+	// of user 1's.
+	anticheat := NewAntiCheatService(db)
+	plagiarism := NewPlagiarismService(db, anticheat, 0)
+	if err := plagiarism.RebuildIndex(1); err != nil {
+		t.Fatalf("RebuildIndex() error = %v", err)
+	}
+
+	key, err := plagiarism.submissionFingerprintKey(submission2)
+	if err != nil {
+		t.Fatalf("submissionFingerprintKey() error = %v", err)
+	}
+	matches, err := plagiarism.Scan(key)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(matches) == 0 {
+		t.Fatal("expected Scan to flag user 2's renamed-identifier copy of user 1's accepted submission")
+	}
+	if matches[0].UserID != 1 {
+		t.Errorf("matched UserID = %d, want 1", matches[0].UserID)
+	}
+}
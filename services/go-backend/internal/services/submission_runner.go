@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/logging"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// jobEventStatus maps a k8s.JobEventType to the models.Submission status
+// it implies. JobEventPending and JobEventSucceeded are intentionally
+// absent: ClaimForExecution already moves a submission to StatusRunning
+// before its Job exists, and the pass/fail verdict behind a succeeded Job
+// (accepted, wrong_answer, ...) comes from the ExecutionResult that
+// JobManager.ExecuteAndWait/collectJobResult parses, not from the Job's
+// lifecycle alone.
+var jobEventStatus = map[k8s.JobEventType]string{
+	k8s.JobEventRunning:          models.StatusRunning,
+	k8s.JobEventDeadlineExceeded: models.StatusTimeLimit,
+	k8s.JobEventOOMKilled:        models.StatusMemoryLimit,
+	k8s.JobEventFailed:           models.StatusRuntimeError,
+	k8s.JobEventImagePullBackOff: models.StatusRuntimeError,
+}
+
+// jobWatcher is the subset of *k8s.JobManager that SubmissionRunner needs,
+// narrowed to a package-local interface so tests can drive Run's status
+// mapping against a fake event stream instead of a real JobManager.
+type jobWatcher interface {
+	WatchJob(ctx context.Context, jobName string) (<-chan k8s.JobEvent, error)
+}
+
+// SubmissionRunner keeps a submission's models.Submission.Status in sync
+// with its execution Job's lifecycle by consuming JobManager.WatchJob
+// instead of the caller having to poll - the watch-based counterpart to
+// JobManager.ExecuteAndWait, for callers that dispatch a Job themselves
+// (e.g. a ManagedBy submission an external controller has picked up) and
+// just need status kept current while it runs. Library code only: nothing
+// under cmd/ constructs one yet, since no caller here dispatches its own
+// Job without waiting on it today - wiring one in is follow-up work for
+// whichever subsystem takes on that pattern first, not this change.
+// Run's status mapping is covered directly by submission_runner_test.go
+// against a fake jobWatcher, and WatchJob's event sequencing (the other
+// half of this path) by watch_job_test.go against a fake Kubernetes
+// clientset.
+type SubmissionRunner struct {
+	jobs        jobWatcher
+	submissions *SubmissionService
+}
+
+// NewSubmissionRunner creates a SubmissionRunner.
+func NewSubmissionRunner(jobs *k8s.JobManager, submissions *SubmissionService) *SubmissionRunner {
+	return &SubmissionRunner{jobs: jobs, submissions: submissions}
+}
+
+// Run watches jobName's lifecycle and updates submissionID's status for
+// every event jobEventStatus maps, until the job reaches a terminal state
+// or ctx is cancelled. A status update failure is logged, not returned -
+// it shouldn't stop the runner from observing the rest of the job's
+// lifecycle.
+func (r *SubmissionRunner) Run(ctx context.Context, submissionID, jobName string) error {
+	events, err := r.jobs.WatchJob(ctx, jobName)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		status, ok := jobEventStatus[event.Type]
+		if !ok {
+			continue
+		}
+		if err := r.submissions.UpdateStatus(submissionID, status); err != nil {
+			logging.WithStacktrace(ctx, err)
+		}
+	}
+
+	return nil
+}
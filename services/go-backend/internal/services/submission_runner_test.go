@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nahtao97/scribble/internal/k8s"
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// fakeJobWatcher is a test-only jobWatcher backed by a channel the test
+// feeds directly, standing in for JobManager.WatchJob's real apiserver
+// watch.
+type fakeJobWatcher struct {
+	events chan k8s.JobEvent
+}
+
+func (f *fakeJobWatcher) WatchJob(ctx context.Context, jobName string) (<-chan k8s.JobEvent, error) {
+	return f.events, nil
+}
+
+func TestSubmissionRunner_RunMapsJobEventsToSubmissionStatus(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	submissions := NewSubmissionService(db)
+
+	submission := models.Submission{
+		ID:        uuid.NewString(),
+		UserID:    "1",
+		ProblemID: "1",
+		Language:  "python",
+		Code:      "x",
+		Status:    models.StatusPending,
+	}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create submission: %v", err)
+	}
+
+	watcher := &fakeJobWatcher{events: make(chan k8s.JobEvent, 4)}
+	runner := &SubmissionRunner{jobs: watcher, submissions: submissions}
+
+	// Pending and Succeeded are intentionally absent from jobEventStatus
+	// (see its doc comment) so they must not move the submission's status;
+	// Running then OOMKilled should each land as-is.
+	watcher.events <- k8s.JobEvent{Type: k8s.JobEventPending}
+	watcher.events <- k8s.JobEvent{Type: k8s.JobEventRunning}
+	watcher.events <- k8s.JobEvent{Type: k8s.JobEventOOMKilled}
+	close(watcher.events)
+
+	if err := runner.Run(context.Background(), submission.ID, "job-1"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var got models.Submission
+	if err := db.First(&got, "id = ?", submission.ID).Error; err != nil {
+		t.Fatalf("failed to reload submission: %v", err)
+	}
+	if got.Status != models.StatusMemoryLimit {
+		t.Errorf("Status = %q, want %q (last mapped event wins)", got.Status, models.StatusMemoryLimit)
+	}
+}
+
+func TestSubmissionRunner_RunStopsWhenWatchJobErrors(t *testing.T) {
+	submissions := NewSubmissionService(setupSubmissionTestDB(t))
+	watcher := &erroringJobWatcher{err: context.DeadlineExceeded}
+	runner := &SubmissionRunner{jobs: watcher, submissions: submissions}
+
+	if err := runner.Run(context.Background(), "sub-1", "job-1"); err != context.DeadlineExceeded {
+		t.Errorf("Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+type erroringJobWatcher struct {
+	err error
+}
+
+func (e *erroringJobWatcher) WatchJob(ctx context.Context, jobName string) (<-chan k8s.JobEvent, error) {
+	return nil, e.err
+}
@@ -1,25 +1,81 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"time"
 
+	"github.com/nahtao97/scribble/internal/counters"
 	"github.com/nahtao97/scribble/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SubmissionService handles business logic for submission operations
 type SubmissionService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	distributions *DistributionStore
+	digests       *TDigestStore
+	counters      *counters.Counters
+	events        SubmissionEventBroker
 }
 
-// NewSubmissionService creates a new submission service instance
+// NewSubmissionService creates a new submission service instance, with an
+// in-process SubmissionEventBroker - sufficient for a single replica. Use
+// NewSubmissionServiceWithEventBroker for a Redis-backed broker so
+// Subscribe works across multiple app instances.
 func NewSubmissionService(db *gorm.DB) *SubmissionService {
+	return newSubmissionService(db, newLocalSubmissionEventBroker())
+}
+
+// NewSubmissionServiceWithEventBroker creates a SubmissionService backed by
+// a caller-supplied SubmissionEventBroker (e.g. a Redis-backed one shared
+// across replicas).
+func NewSubmissionServiceWithEventBroker(db *gorm.DB, events SubmissionEventBroker) *SubmissionService {
+	return newSubmissionService(db, events)
+}
+
+func newSubmissionService(db *gorm.DB, events SubmissionEventBroker) *SubmissionService {
+	submissionCounters := counters.New(db)
+	if err := submissionCounters.WarmFromDB(time.Now().Add(-counters.DefaultFlushInterval)); err != nil {
+		fmt.Printf("warning: failed to warm submission counters: %v\n", err)
+	}
+
 	return &SubmissionService{
-		db: db,
+		db:            db,
+		distributions: NewDistributionStore(),
+		digests:       NewTDigestStore(),
+		counters:      submissionCounters,
+		events:        events,
 	}
 }
 
+// Subscribe returns a channel of SubmissionEvents for submissionID and an
+// unsubscribe function, so a caller (e.g. an SSE/WebSocket handler) can
+// push status changes to a client instead of having it poll
+// GetSubmissionByID. The channel is also cleaned up automatically if ctx
+// is cancelled.
+func (s *SubmissionService) Subscribe(ctx context.Context, submissionID string) (<-chan SubmissionEvent, func(), error) {
+	return s.events.Subscribe(ctx, submissionID)
+}
+
+// PublishStatus fans a status update out to submissionID's subscribers.
+// Judging code calls this as a submission's status changes (queued ->
+// running -> judged) in addition to persisting the change via UpdateStatus
+// - the two are independent, since not every status change necessarily
+// has a subscriber listening.
+func (s *SubmissionService) PublishStatus(submissionID, status string, execTimeMs, memoryKB int64) error {
+	return s.events.Publish(context.Background(), SubmissionEvent{
+		SubmissionID:    submissionID,
+		Status:          status,
+		ExecutionTimeMs: execTimeMs,
+		MemoryUsedKB:    memoryKB,
+	})
+}
+
 // GetSubmissionByID retrieves a submission by its ID
 func (s *SubmissionService) GetSubmissionByID(id uint) (*models.Submission, error) {
 	var submission models.Submission
@@ -50,10 +106,256 @@ func (s *SubmissionService) GetSubmissionsByUserAndProblem(userID, problemID uin
 	return submissions, nil
 }
 
+// SubmissionHistoryParams filters and paginates GetUserSubmissionHistory.
+// ProblemID, BatchID, ContestID, Status and Language are optional - a zero
+// value (empty string) means "don't filter on this field".
+type SubmissionHistoryParams struct {
+	UserID    string
+	ProblemID string
+	BatchID   string
+	ContestID string
+	Status    string
+	Language  string
+	Page      int
+	PageSize  int
+}
+
+// SubmissionHistoryPage is a page of a user's submissions, in the same
+// Page/PageSize/Total/TotalPages shape as models.LeaderboardPage.
+type SubmissionHistoryPage struct {
+	Submissions []models.Submission `json:"submissions"`
+	Page        int                 `json:"page"`
+	PageSize    int                 `json:"page_size"`
+	Total       int64               `json:"total"`
+	TotalPages  int                 `json:"total_pages"`
+}
+
+// GetUserSubmissionHistory returns a paginated, most-recent-first page of
+// params.UserID's submissions, optionally narrowed by ProblemID, BatchID,
+// Status and/or Language.
+func (s *SubmissionService) GetUserSubmissionHistory(params SubmissionHistoryParams) (*SubmissionHistoryPage, error) {
+	page := params.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := params.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := s.db.Model(&models.Submission{}).Where("user_id = ?", params.UserID)
+	if params.ProblemID != "" {
+		query = query.Where("problem_id = ?", params.ProblemID)
+	}
+	if params.BatchID != "" {
+		query = query.Where("batch_id = ?", params.BatchID)
+	}
+	if params.ContestID != "" {
+		query = query.Where("contest_id = ?", params.ContestID)
+	}
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Language != "" {
+		query = query.Where("language = ?", params.Language)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count submission history: %w", err)
+	}
+
+	var submissions []models.Submission
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&submissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve submission history: %w", err)
+	}
+
+	return &SubmissionHistoryPage{
+		Submissions: submissions,
+		Page:        page,
+		PageSize:    pageSize,
+		Total:       total,
+		TotalPages:  int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// SubmissionStats is the per-user analog of ProblemSubmissionStats, scoped
+// to one user's submissions across every problem.
+type SubmissionStats struct {
+	TotalSubmissions    int64    `json:"total_submissions"`
+	AcceptedSubmissions int64    `json:"accepted_submissions"`
+	ProblemsSolved      int64    `json:"problems_solved"`
+	AcceptanceRate      float64  `json:"acceptance_rate"`
+	AvgExecutionTimeMs  *float64 `json:"avg_execution_time_ms,omitempty"`
+	AvgMemoryUsedKb     *float64 `json:"avg_memory_used_kb,omitempty"`
+
+	// SolvedByDifficulty counts distinct problems solved per
+	// models.Problem.Difficulty ("easy", "medium", "hard"); submissions
+	// whose ProblemID doesn't resolve to a known Problem are excluded.
+	SolvedByDifficulty map[string]int `json:"solved_by_difficulty,omitempty"`
+	// AcceptanceRateByDifficulty is accepted/total submissions (0-100) for
+	// each difficulty, same exclusion as SolvedByDifficulty.
+	AcceptanceRateByDifficulty map[string]float64 `json:"acceptance_rate_by_difficulty,omitempty"`
+}
+
+// GetUserSubmissionStats returns aggregate submission statistics for
+// userID: totals, acceptance rate, distinct problems solved, and average
+// runtime/memory across accepted submissions.
+func (s *SubmissionService) GetUserSubmissionStats(userID string) (*SubmissionStats, error) {
+	return s.GetUserSubmissionStatsScoped(SubmissionStatsParams{UserID: userID})
+}
+
+// SubmissionStatsParams scopes GetUserSubmissionStatsScoped. ContestID is
+// optional - a zero value (empty string) scores every submission, the same
+// as GetUserSubmissionStats.
+type SubmissionStatsParams struct {
+	UserID    string
+	ContestID string
+}
+
+// GetUserSubmissionStatsScoped is GetUserSubmissionStats with an optional
+// ContestID filter, so callers (e.g. ContestService.GetUserContestHistory)
+// can get a user's stats for just one contest's submissions.
+func (s *SubmissionService) GetUserSubmissionStatsScoped(params SubmissionStatsParams) (*SubmissionStats, error) {
+	stats := &SubmissionStats{}
+
+	base := s.db.Model(&models.Submission{}).Where("user_id = ?", params.UserID)
+	if params.ContestID != "" {
+		base = base.Where("contest_id = ?", params.ContestID)
+	}
+
+	if err := base.Session(&gorm.Session{}).Count(&stats.TotalSubmissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count submissions: %w", err)
+	}
+
+	if err := base.Session(&gorm.Session{}).Where("status = ?", models.StatusAccepted).
+		Count(&stats.AcceptedSubmissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count accepted submissions: %w", err)
+	}
+
+	if err := base.Session(&gorm.Session{}).Where("status = ?", models.StatusAccepted).
+		Distinct("problem_id").
+		Count(&stats.ProblemsSolved).Error; err != nil {
+		return nil, fmt.Errorf("failed to count problems solved: %w", err)
+	}
+
+	if stats.TotalSubmissions > 0 {
+		stats.AcceptanceRate = float64(stats.AcceptedSubmissions) / float64(stats.TotalSubmissions) * 100
+	}
+
+	if stats.AcceptedSubmissions > 0 {
+		var avg struct {
+			AvgTime float64
+			AvgMem  float64
+		}
+		if err := base.Session(&gorm.Session{}).
+			Select("AVG(execution_time_ms) as avg_time, AVG(memory_used_kb) as avg_mem").
+			Where("status = ?", models.StatusAccepted).
+			Scan(&avg).Error; err != nil {
+			return nil, fmt.Errorf("failed to average accepted submission metrics: %w", err)
+		}
+		stats.AvgExecutionTimeMs = &avg.AvgTime
+		stats.AvgMemoryUsedKb = &avg.AvgMem
+	}
+
+	if params.ContestID == "" {
+		solved, _, rate, err := s.difficultyBreakdown(params.UserID)
+		if err != nil {
+			return nil, err
+		}
+		stats.SolvedByDifficulty = solved
+		stats.AcceptanceRateByDifficulty = rate
+	}
+
+	return stats, nil
+}
+
+// ErrSubmissionNotClaimable is returned by ClaimForExecution when a
+// submission isn't (or is no longer) pending - it's already been claimed
+// by another worker, or has already finished.
+var ErrSubmissionNotClaimable = errors.New("submission is not in a claimable state")
+
+// ErrManagedByImmutable is returned by SetManagedBy once a submission has
+// left StatusPending.
+var ErrManagedByImmutable = errors.New("managed_by cannot change once a submission starts executing")
+
+// SetManagedBy assigns which controller owns submissionID's execution Job
+// (models.ManagedByExecutor for scribble's own JobManager, or an external
+// controller ID such as "kueue.x-k8s.io/multikueue"). It only succeeds
+// while the submission is still pending: once ClaimForExecution (or any
+// other transition out of StatusPending) has run, the field is immutable,
+// since reassigning ownership of an in-flight Job would leave it dangling
+// for both controllers.
+func (s *SubmissionService) SetManagedBy(submissionID, managedBy string) error {
+	result := s.db.Model(&models.Submission{}).
+		Where("id = ? AND status = ?", submissionID, models.StatusPending).
+		Update("managed_by", managedBy)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set managed_by for submission %s: %w", submissionID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrManagedByImmutable
+	}
+	return nil
+}
+
+// ClaimForExecution atomically transitions submissionID from pending to
+// running and returns it, row-locked for the duration of the transaction
+// so concurrent callers - multiple scribble workers, or scribble racing an
+// external queue controller for a ManagedBy submission - can't both claim
+// the same submission and execute it twice.
+func (s *SubmissionService) ClaimForExecution(submissionID string) (*models.Submission, error) {
+	var submission models.Submission
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&submission, "id = ?", submissionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("submission %s not found", submissionID)
+			}
+			return fmt.Errorf("failed to lock submission %s: %w", submissionID, err)
+		}
+		if submission.Status != models.StatusPending {
+			return ErrSubmissionNotClaimable
+		}
+		if err := tx.Model(&submission).Update("status", models.StatusRunning).Error; err != nil {
+			return fmt.Errorf("failed to claim submission %s: %w", submissionID, err)
+		}
+		submission.Status = models.StatusRunning
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// UpdateStatus sets submissionID's status directly, unconditionally -
+// unlike SetManagedBy/ClaimForExecution it isn't gated on the submission's
+// current status, since it's meant for a SubmissionRunner relaying a
+// k8s.JobManager's own lifecycle events (running, time_limit,
+// memory_limit, runtime_error, ...) as the execution progresses.
+func (s *SubmissionService) UpdateStatus(submissionID, status string) error {
+	result := s.db.Model(&models.Submission{}).
+		Where("id = ?", submissionID).
+		Update("status", status)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update status for submission %s: %w", submissionID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("submission %s not found", submissionID)
+	}
+	return nil
+}
+
 // CalculatePercentileMetrics computes percentile rankings for a submission
-// Compares against all accepted submissions for the same problem
+// against its problem/language's runtime and memory TDigests, falling back
+// to the slower Distribution-based rank when a digest isn't warmed with
+// enough weight yet. Execution time and memory fields are omitted when the
+// sample size behind them is below MinDistributionSample - too few
+// submissions for a percentile to mean anything.
 func (s *SubmissionService) CalculatePercentileMetrics(submissionID uint) (*models.PercentileMetrics, error) {
-	// Get the target submission
 	submission, err := s.GetSubmissionByID(submissionID)
 	if err != nil {
 		return nil, err
@@ -67,121 +369,252 @@ func (s *SubmissionService) CalculatePercentileMetrics(submissionID uint) (*mode
 	metrics := &models.PercentileMetrics{
 		SubmissionID: submission.ID,
 		ProblemID:    submission.ProblemID,
+		Language:     submission.Language,
 	}
 
-	// Get count of all accepted submissions for this problem
-	var totalAccepted int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ?", submission.ProblemID, models.StatusAccepted).
-		Count(&totalAccepted)
+	runtimePercentile, runtimeRank, runtimeSample, err := s.percentileFor(submission.ProblemID, submission.Language, MetricRuntime, float64(submission.ExecutionTimeMs))
+	if err != nil {
+		return nil, err
+	}
+	metrics.SampleSize = runtimeSample
+	if runtimeSample >= MinDistributionSample {
+		metrics.ExecutionTimePercentile = &runtimePercentile
+		metrics.ExecutionTimeRank = &runtimeRank
+		metrics.ExecutionTimeMessage = formatPercentileMessage(runtimePercentile, "faster")
+	}
 
-	metrics.TotalSubmissions = int(totalAccepted)
+	memoryPercentile, memoryRank, memorySample, err := s.percentileFor(submission.ProblemID, submission.Language, MetricMemory, float64(submission.MemoryUsedKb))
+	if err != nil {
+		return nil, err
+	}
+	if memorySample >= MinDistributionSample {
+		metrics.MemoryPercentile = &memoryPercentile
+		metrics.MemoryRank = &memoryRank
+		metrics.MemoryMessage = formatPercentileMessage(memoryPercentile, "less memory")
+	}
 
-	// Calculate execution time percentile
-	if submission.ExecutionTimeMs != nil {
-		percentile, rank := s.calculateTimePercentile(submission.ProblemID, *submission.ExecutionTimeMs)
-		metrics.ExecutionTimePercentile = percentile
-		metrics.ExecutionTimeRank = rank
+	return metrics, nil
+}
 
-		if percentile != nil {
-			metrics.ExecutionTimeMessage = formatPercentileMessage(*percentile, "faster")
+// percentileFor returns value's percentile rank, an approximate 1-based
+// rank, and the sample size behind them: the problem/language/metric
+// TDigest's O(log n) CDF when it's warmed with at least
+// MinDistributionSample weight, falling back to hydrating and querying the
+// older Distribution-based rank when the digest isn't warmed yet.
+func (s *SubmissionService) percentileFor(problemID, language string, metric DistributionMetric, value float64) (percentile float64, rank int, sampleSize int, err error) {
+	digest := s.digests.Get(problemID, language, metric)
+	if !s.digests.IsHydrated(problemID, language) {
+		if err := s.ensureDigestHydrated(problemID, language); err != nil {
+			return 0, 0, 0, err
 		}
 	}
 
-	// Calculate memory usage percentile
-	if submission.MemoryUsedKb != nil {
-		percentile, rank := s.calculateMemoryPercentile(submission.ProblemID, *submission.MemoryUsedKb)
-		metrics.MemoryPercentile = percentile
-		metrics.MemoryRank = rank
+	if count := int(digest.Count()); count >= MinDistributionSample {
+		percentile = digest.CDF(value) * 100
+		rank = int(math.Round(percentile/100*float64(count))) + 1
+		return percentile, rank, count, nil
+	}
+
+	if err := s.ensureDistributionHydrated(problemID, language); err != nil {
+		return 0, 0, 0, err
+	}
+	dist := s.distributions.Get(problemID, language, metric)
+	percentile, rank, sampleSize = dist.PercentileRank(value)
+
+	return percentile, rank, sampleSize, nil
+}
+
+// ensureDigestHydrated seeds problemID/language's runtime and memory
+// TDigests the first time they're queried in this process: for each
+// metric it first tries to load the latest problem_digest_snapshots row,
+// and only falls back to replaying every accepted submission's value if no
+// snapshot has been persisted yet.
+func (s *SubmissionService) ensureDigestHydrated(problemID, language string) error {
+	if s.digests.IsHydrated(problemID, language) {
+		return nil
+	}
+
+	for _, metric := range []DistributionMetric{MetricRuntime, MetricMemory} {
+		digest := s.digests.Get(problemID, language, metric)
+
+		var snapshot models.ProblemDigestSnapshotRow
+		err := s.db.Where("problem_id = ? AND language = ? AND metric = ?", problemID, language, string(metric)).
+			First(&snapshot).Error
+		if err == nil {
+			if loadErr := digest.LoadSnapshot(snapshot.Snapshot); loadErr == nil {
+				continue
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to load digest snapshot: %w", err)
+		}
 
-		if percentile != nil {
-			metrics.MemoryMessage = formatPercentileMessage(*percentile, "less memory")
+		var submissions []models.Submission
+		if err := s.db.Where("problem_id = ? AND language = ? AND status = ?", problemID, language, models.StatusAccepted).
+			Find(&submissions).Error; err != nil {
+			return fmt.Errorf("failed to replay submissions into digest: %w", err)
+		}
+
+		for _, submission := range submissions {
+			value := float64(submission.ExecutionTimeMs)
+			if metric == MetricMemory {
+				value = float64(submission.MemoryUsedKb)
+			}
+			digest.Add(value)
 		}
 	}
 
-	return metrics, nil
+	s.digests.MarkHydrated(problemID, language)
+
+	return nil
 }
 
-// calculateTimePercentile calculates what percentage of submissions are slower
-// Returns (percentile, rank) where percentile is 0-100 and rank is 1-based position
-func (s *SubmissionService) calculateTimePercentile(problemID uint, executionTimeMs int) (*float64, *int) {
-	// Count submissions with slower or equal execution time
-	var slowerCount int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND execution_time_ms IS NOT NULL AND execution_time_ms >= ?",
-			problemID, models.StatusAccepted, executionTimeMs).
-		Count(&slowerCount)
-
-	// Count submissions with strictly faster execution time (for rank)
-	var fasterCount int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND execution_time_ms IS NOT NULL AND execution_time_ms < ?",
-			problemID, models.StatusAccepted, executionTimeMs).
-		Count(&fasterCount)
-
-	// Get total with valid execution time
-	var total int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND execution_time_ms IS NOT NULL",
-			problemID, models.StatusAccepted).
-		Count(&total)
+// snapshotDigest persists digest's current state to
+// problem_digest_snapshots, upserting over any previous snapshot for the
+// same (problem, language, metric) so a restart can warm from it instead
+// of replaying every accepted submission. Failures are logged rather than
+// returned, since a failed snapshot shouldn't fail the submission that
+// triggered it.
+func (s *SubmissionService) snapshotDigest(problemID, language string, metric DistributionMetric, digest *TDigest) {
+	data, err := digest.MarshalSnapshot()
+	if err != nil {
+		fmt.Printf("warning: failed to marshal digest snapshot for problem %s: %v\n", problemID, err)
+		return
+	}
 
-	if total == 0 {
-		return nil, nil
+	row := models.ProblemDigestSnapshotRow{
+		ProblemID: problemID,
+		Language:  language,
+		Metric:    string(metric),
+		Snapshot:  data,
+		UpdatedAt: time.Now(),
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "problem_id"}, {Name: "language"}, {Name: "metric"}},
+		DoUpdates: clause.AssignmentColumns([]string{"snapshot", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		fmt.Printf("warning: failed to persist digest snapshot for problem %s: %v\n", problemID, err)
 	}
+}
+
+// SnapshotAllDigests persists every tracked problem/language/metric
+// digest's current state. Intended to be called on shutdown, alongside the
+// periodic per-update snapshots triggered from RecordAcceptedSubmission.
+func (s *SubmissionService) SnapshotAllDigests() {
+	s.digests.ForEach(func(problemID, language string, metric DistributionMetric, digest *TDigest) {
+		s.snapshotDigest(problemID, language, metric, digest)
+	})
+}
+
+// GetProblemDistribution returns the percentile bands, histogram and sample
+// size for one of a problem's cached metric distributions, hydrating it
+// from the database on first use.
+func (s *SubmissionService) GetProblemDistribution(problemID uint, language string, metric DistributionMetric) (*ProblemDistribution, error) {
+	key := fmt.Sprintf("%d", problemID)
 
-	// Percentile = percentage of submissions that are slower
-	// Exclude self from count for accurate comparison
-	percentile := float64(slowerCount-1) / float64(total) * 100
-	if percentile < 0 {
-		percentile = 0
+	if err := s.ensureDistributionHydrated(key, language); err != nil {
+		return nil, err
 	}
-	percentile = math.Round(percentile*100) / 100 // Round to 2 decimal places
 
-	rank := int(fasterCount + 1)
+	dist := s.distributions.Get(key, language, metric)
+	bands, sampleSize, err := dist.Percentiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute percentiles: %w", err)
+	}
 
-	return &percentile, &rank
+	return &ProblemDistribution{
+		ProblemID:   problemID,
+		Language:    language,
+		Metric:      metric,
+		SampleSize:  sampleSize,
+		Percentiles: bands,
+		Histogram:   dist.Histogram(defaultHistogramBuckets),
+	}, nil
 }
 
-// calculateMemoryPercentile calculates what percentage of submissions use more memory
-// Returns (percentile, rank) where percentile is 0-100 and rank is 1-based position
-func (s *SubmissionService) calculateMemoryPercentile(problemID uint, memoryUsedKb int) (*float64, *int) {
-	// Count submissions with higher or equal memory usage
-	var higherCount int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND memory_used_kb IS NOT NULL AND memory_used_kb >= ?",
-			problemID, models.StatusAccepted, memoryUsedKb).
-		Count(&higherCount)
+// RecordAcceptedSubmission folds a newly-accepted submission's runtime and
+// memory into its problem/language distribution and TDigests so later
+// percentile and distribution queries reflect it immediately, without a
+// full re-sort against the database. Intended to be called from wherever
+// submissions are persisted once accepted (currently a TODO in
+// ExecuteHandler).
+func (s *SubmissionService) RecordAcceptedSubmission(submission *models.Submission) {
+	s.distributions.Record(submission.ProblemID, submission.Language, submission.ExecutionTimeMs, submission.MemoryUsedKb)
+
+	runtimeDigest := s.digests.Get(submission.ProblemID, submission.Language, MetricRuntime)
+	if runtimeDigest.Add(float64(submission.ExecutionTimeMs)) {
+		s.snapshotDigest(submission.ProblemID, submission.Language, MetricRuntime, runtimeDigest)
+	}
 
-	// Count submissions with strictly lower memory usage (for rank)
-	var lowerCount int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND memory_used_kb IS NOT NULL AND memory_used_kb < ?",
-			problemID, models.StatusAccepted, memoryUsedKb).
-		Count(&lowerCount)
+	memoryDigest := s.digests.Get(submission.ProblemID, submission.Language, MetricMemory)
+	if memoryDigest.Add(float64(submission.MemoryUsedKb)) {
+		s.snapshotDigest(submission.ProblemID, submission.Language, MetricMemory, memoryDigest)
+	}
+}
 
-	// Get total with valid memory metrics
-	var total int64
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ? AND memory_used_kb IS NOT NULL",
-			problemID, models.StatusAccepted).
-		Count(&total)
+// RecordSubmissionCounts folds a submission of any outcome into its
+// problem's counters bucket, so GetProblemSubmissionStats's totals and
+// acceptance rate reflect it immediately. Intended to be called from
+// wherever submissions are persisted (currently a TODO in ExecuteHandler),
+// alongside RecordAcceptedSubmission for accepted ones.
+func (s *SubmissionService) RecordSubmissionCounts(submission *models.Submission) {
+	problemID, err := strconv.ParseUint(submission.ProblemID, 10, 32)
+	if err != nil {
+		return
+	}
+	s.counters.Record(uint(problemID), submission.Status == models.StatusAccepted, submission.ExecutionTimeMs, submission.MemoryUsedKb)
+}
+
+// StartCountersFlusher starts the periodic flush of accumulated
+// problem_submission_stats counters until ctx is cancelled. Intended to be
+// called once at startup, alongside NewSubmissionService.
+func (s *SubmissionService) StartCountersFlusher(ctx context.Context) {
+	s.counters.StartFlusher(ctx, counters.DefaultFlushInterval, func(err error) {
+		fmt.Printf("warning: failed to flush submission counters: %v\n", err)
+	})
+}
 
-	if total == 0 {
-		return nil, nil
+// ensureDistributionHydrated seeds the in-memory distribution for
+// (problemID, language) from already-accepted submissions in the database
+// the first time it's queried in this process. Distributions are in-memory
+// only, so a freshly started replica starts cold until this runs once per
+// (problem, language) pair it sees.
+func (s *SubmissionService) ensureDistributionHydrated(problemID, language string) error {
+	if s.distributions.IsHydrated(problemID, language) {
+		return nil
 	}
 
-	// Percentile = percentage of submissions that use more memory
-	// Exclude self from count for accurate comparison
-	percentile := float64(higherCount-1) / float64(total) * 100
-	if percentile < 0 {
-		percentile = 0
+	var submissions []models.Submission
+	result := s.db.Model(&models.Submission{}).
+		Where("problem_id = ? AND language = ? AND status = ?", problemID, language, models.StatusAccepted).
+		Find(&submissions)
+	if result.Error != nil {
+		return fmt.Errorf("failed to hydrate distribution: %w", result.Error)
+	}
+
+	for _, submission := range submissions {
+		s.distributions.Record(problemID, language, submission.ExecutionTimeMs, submission.MemoryUsedKb)
 	}
-	percentile = math.Round(percentile*100) / 100 // Round to 2 decimal places
+	s.distributions.MarkHydrated(problemID, language)
 
-	rank := int(lowerCount + 1)
+	return nil
+}
 
-	return &percentile, &rank
+// defaultHistogramBuckets is how many buckets GetProblemDistribution splits
+// a sample into - enough resolution for a chart without being noisy.
+const defaultHistogramBuckets = 20
+
+// ProblemDistribution is the response for GET
+// /internal/problems/:id/distribution - percentile bands and histogram
+// buckets for one metric of a problem's accepted submissions in one
+// language.
+type ProblemDistribution struct {
+	ProblemID   uint               `json:"problem_id"`
+	Language    string             `json:"language"`
+	Metric      DistributionMetric `json:"metric"`
+	SampleSize  int                `json:"sample_size"`
+	Percentiles PercentileBands    `json:"percentiles"`
+	Histogram   []HistogramBucket  `json:"histogram"`
 }
 
 // formatPercentileMessage creates a human-readable percentile message
@@ -193,48 +626,55 @@ func formatPercentileMessage(percentile float64, comparison string) string {
 	return fmt.Sprintf("%s than %d%% of submissions", comparison, rounded)
 }
 
-// GetProblemSubmissionStats returns aggregate statistics for a problem's submissions
+// recentStatsWindows is how many flushed problem_submission_stats rows
+// GetProblemSubmissionStats folds in alongside the counters' currently
+// active bucket, instead of scanning the submissions table.
+const recentStatsWindows = 4
+
+// GetProblemSubmissionStats returns aggregate statistics for a problem's
+// submissions, composed from the last recentStatsWindows flushed
+// problem_submission_stats rows plus the in-memory counters bucket that
+// hasn't been flushed yet, instead of running COUNT/AVG queries against
+// the submissions table on every call.
 func (s *SubmissionService) GetProblemSubmissionStats(problemID uint) (*ProblemSubmissionStats, error) {
-	stats := &ProblemSubmissionStats{
-		ProblemID: problemID,
+	var rows []models.ProblemSubmissionStatsRow
+	if err := s.db.Where("problem_id = ?", problemID).
+		Order("window_start DESC").
+		Limit(recentStatsWindows).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load flushed submission stats: %w", err)
 	}
 
-	// Count total submissions
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ?", problemID).
-		Count(&stats.TotalSubmissions)
+	var submissions, accepted, sumTimeMs, sumMemKb int64
+	for _, row := range rows {
+		submissions += row.Submissions
+		accepted += row.Accepted
+		sumTimeMs += row.SumTime
+		sumMemKb += row.SumMem
+	}
 
-	// Count accepted submissions
-	s.db.Model(&models.Submission{}).
-		Where("problem_id = ? AND status = ?", problemID, models.StatusAccepted).
-		Count(&stats.AcceptedSubmissions)
+	activeSubmissions, activeAccepted, activeSumTimeMs, activeSumMemKb := s.counters.Snapshot(problemID)
+	submissions += activeSubmissions
+	accepted += activeAccepted
+	sumTimeMs += activeSumTimeMs
+	sumMemKb += activeSumMemKb
 
-	// Calculate acceptance rate
-	if stats.TotalSubmissions > 0 {
-		stats.AcceptanceRate = float64(stats.AcceptedSubmissions) / float64(stats.TotalSubmissions) * 100
+	stats := &ProblemSubmissionStats{
+		ProblemID:           problemID,
+		TotalSubmissions:    submissions,
+		AcceptedSubmissions: accepted,
 	}
 
-	// Get average execution time for accepted submissions
-	var avgTime struct {
-		Avg *float64
+	if stats.TotalSubmissions > 0 {
+		stats.AcceptanceRate = float64(stats.AcceptedSubmissions) / float64(stats.TotalSubmissions) * 100
 	}
-	s.db.Model(&models.Submission{}).
-		Select("AVG(execution_time_ms) as avg").
-		Where("problem_id = ? AND status = ? AND execution_time_ms IS NOT NULL",
-			problemID, models.StatusAccepted).
-		Scan(&avgTime)
-	stats.AvgExecutionTimeMs = avgTime.Avg
 
-	// Get average memory usage for accepted submissions
-	var avgMem struct {
-		Avg *float64
+	if accepted > 0 {
+		avgTime := float64(sumTimeMs) / float64(accepted)
+		avgMem := float64(sumMemKb) / float64(accepted)
+		stats.AvgExecutionTimeMs = &avgTime
+		stats.AvgMemoryUsedKb = &avgMem
 	}
-	s.db.Model(&models.Submission{}).
-		Select("AVG(memory_used_kb) as avg").
-		Where("problem_id = ? AND status = ? AND memory_used_kb IS NOT NULL",
-			problemID, models.StatusAccepted).
-		Scan(&avgMem)
-	stats.AvgMemoryUsedKb = avgMem.Avg
 
 	return stats, nil
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -544,3 +545,61 @@ func TestGetUserSubmissionStats_NoDuplicateProblemCount(t *testing.T) {
 		t.Errorf("expected 1 problem solved (unique), got %d", stats.ProblemsSolved)
 	}
 }
+
+func TestSetManagedBy(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	submission := models.Submission{ID: "sub-1", UserID: "u1", ProblemID: "1", Language: "python", Code: "print(1)", Status: models.StatusPending}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create test submission: %v", err)
+	}
+
+	if err := service.SetManagedBy(submission.ID, "kueue.x-k8s.io/multikueue"); err != nil {
+		t.Fatalf("SetManagedBy failed: %v", err)
+	}
+
+	var got models.Submission
+	if err := db.First(&got, "id = ?", submission.ID).Error; err != nil {
+		t.Fatalf("failed to reload submission: %v", err)
+	}
+	if got.ManagedBy != "kueue.x-k8s.io/multikueue" {
+		t.Errorf("ManagedBy = %q, want kueue.x-k8s.io/multikueue", got.ManagedBy)
+	}
+}
+
+func TestSetManagedBy_ImmutableOnceExecutionStarts(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	submission := models.Submission{ID: "sub-2", UserID: "u1", ProblemID: "1", Language: "python", Code: "print(1)", Status: models.StatusRunning}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create test submission: %v", err)
+	}
+
+	if err := service.SetManagedBy(submission.ID, "kueue.x-k8s.io/multikueue"); !errors.Is(err, ErrManagedByImmutable) {
+		t.Fatalf("SetManagedBy() = %v, want ErrManagedByImmutable", err)
+	}
+}
+
+func TestClaimForExecution(t *testing.T) {
+	db := setupSubmissionTestDB(t)
+	service := NewSubmissionService(db)
+
+	submission := models.Submission{ID: "sub-3", UserID: "u1", ProblemID: "1", Language: "python", Code: "print(1)", Status: models.StatusPending}
+	if err := db.Create(&submission).Error; err != nil {
+		t.Fatalf("failed to create test submission: %v", err)
+	}
+
+	claimed, err := service.ClaimForExecution(submission.ID)
+	if err != nil {
+		t.Fatalf("ClaimForExecution failed: %v", err)
+	}
+	if claimed.Status != models.StatusRunning {
+		t.Errorf("Status = %q, want %q", claimed.Status, models.StatusRunning)
+	}
+
+	if _, err := service.ClaimForExecution(submission.ID); !errors.Is(err, ErrSubmissionNotClaimable) {
+		t.Fatalf("second ClaimForExecution() = %v, want ErrSubmissionNotClaimable", err)
+	}
+}
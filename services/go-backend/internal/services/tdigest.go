@@ -0,0 +1,356 @@
+package services
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// tdigestCompression trades accuracy for footprint: higher keeps more
+// centroids (more accurate, more memory). 100 keeps a TDigest to a few KB
+// per problem while still resolving extreme quantiles like p99 accurately.
+const tdigestCompression = 100.0
+
+// tdigestSnapshotInterval is how many Add calls a TDigest absorbs between
+// the snapshots SubmissionService persists to problem_digest_snapshots.
+const tdigestSnapshotInterval = 50
+
+// tdigestRecompressInterval is how many Add calls accumulate before a
+// TDigest rebuilds itself to keep its centroid count bounded.
+const tdigestRecompressInterval = 50
+
+// tdigestCentroid is one weighted mean in a TDigest's summary of a
+// distribution. Exported so gob can (de)serialize it directly.
+type tdigestCentroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// TDigest is a t-digest (Dunning & Ertl): a sorted set of weighted
+// centroids approximating a distribution's CDF/quantiles in bounded
+// memory, with finer resolution near the tails than the middle. A new
+// point is merged into its nearest centroid as long as that centroid's
+// weight stays under the k-scale bound k(q) = (compression/2*pi) *
+// asin(2q-1); once a centroid would exceed that bound, the point starts a
+// new centroid of its own instead.
+type TDigest struct {
+	mu            sync.RWMutex
+	compression   float64
+	centroids     []tdigestCentroid
+	count         float64
+	sinceCompress int
+	sinceSnapshot int
+}
+
+// NewTDigest creates an empty TDigest at the default compression.
+func NewTDigest() *TDigest {
+	return &TDigest{compression: tdigestCompression}
+}
+
+// Add folds value into the digest with weight 1, recompressing
+// periodically to keep the centroid count bounded. It reports whether this
+// update crossed tdigestSnapshotInterval, so callers know to persist a
+// snapshot.
+func (d *TDigest) Add(value float64) (shouldSnapshot bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.insertLocked(value, 1)
+
+	d.sinceCompress++
+	if d.sinceCompress >= tdigestRecompressInterval || len(d.centroids) > int(d.compression)*10 {
+		d.compressLocked()
+		d.sinceCompress = 0
+	}
+
+	d.sinceSnapshot++
+	if d.sinceSnapshot >= tdigestSnapshotInterval {
+		d.sinceSnapshot = 0
+		return true
+	}
+
+	return false
+}
+
+// Count returns the digest's total weight (number of points absorbed).
+func (d *TDigest) Count() float64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.count
+}
+
+// CDF returns the fraction of the digest's weighted points at or below x,
+// via a linear scan over centroid boundaries with interpolation between
+// the surrounding pair - O(n) in the centroid count, which is bounded by
+// the compression rather than the number of points absorbed.
+func (d *TDigest) CDF(x float64) float64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if len(d.centroids) == 0 || d.count == 0 {
+		return 0
+	}
+	if x < d.centroids[0].Mean {
+		return 0
+	}
+	if x >= d.centroids[len(d.centroids)-1].Mean {
+		return 1
+	}
+
+	cumulative := 0.0
+	for i := 1; i < len(d.centroids); i++ {
+		prev := d.centroids[i-1]
+		cur := d.centroids[i]
+		cumulative += prev.Weight
+
+		if x < cur.Mean {
+			frac := (x - prev.Mean) / (cur.Mean - prev.Mean)
+			interp := cumulative - prev.Weight/2 + frac*(prev.Weight/2+cur.Weight/2)
+			return clamp01(interp / d.count)
+		}
+	}
+
+	return 1
+}
+
+// Quantile returns the value at quantile q (0-1), the inverse of CDF.
+func (d *TDigest) Quantile(q float64) float64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	if len(d.centroids) == 1 {
+		return d.centroids[0].Mean
+	}
+
+	target := q * d.count
+	cumulative := 0.0
+	for i, c := range d.centroids {
+		next := cumulative + c.Weight
+		if target <= next || i == len(d.centroids)-1 {
+			if i == 0 {
+				return c.Mean
+			}
+			prev := d.centroids[i-1]
+			frac := (target - cumulative) / c.Weight
+			return prev.Mean + frac*(c.Mean-prev.Mean)
+		}
+		cumulative = next
+	}
+
+	return d.centroids[len(d.centroids)-1].Mean
+}
+
+// insertLocked merges (mean, weight) into the nearest centroid whose
+// weight stays under kSizeBound, or inserts a new centroid in sorted
+// position otherwise. Callers must hold d.mu.
+func (d *TDigest) insertLocked(mean, weight float64) {
+	if len(d.centroids) == 0 {
+		d.centroids = append(d.centroids, tdigestCentroid{Mean: mean, Weight: weight})
+		d.count += weight
+		return
+	}
+
+	idx := sort.Search(len(d.centroids), func(i int) bool { return d.centroids[i].Mean >= mean })
+	best := idx
+	if best == len(d.centroids) {
+		best = len(d.centroids) - 1
+	}
+	if best > 0 && math.Abs(d.centroids[best-1].Mean-mean) < math.Abs(d.centroids[best].Mean-mean) {
+		best = best - 1
+	}
+
+	cumulative := 0.0
+	for i := 0; i < best; i++ {
+		cumulative += d.centroids[i].Weight
+	}
+	q := (cumulative + d.centroids[best].Weight/2) / (d.count + weight)
+	maxWeight := d.kSizeBound(q)
+
+	if d.centroids[best].Weight+weight <= maxWeight {
+		c := &d.centroids[best]
+		c.Mean += (mean - c.Mean) * weight / (c.Weight + weight)
+		c.Weight += weight
+		d.count += weight
+		return
+	}
+
+	d.centroids = append(d.centroids, tdigestCentroid{})
+	copy(d.centroids[idx+1:], d.centroids[idx:])
+	d.centroids[idx] = tdigestCentroid{Mean: mean, Weight: weight}
+	d.count += weight
+}
+
+// kSizeBound returns how much weight a centroid at quantile q may hold
+// before a new point must start its own centroid instead of merging: the
+// count of points between k(q)-0.5 and k(q)+0.5 on the k-scale function
+// k(q) = (compression/2*pi) * asin(2q-1), inverted back into quantile
+// space. This is what keeps centroids thin near the tails (q near 0 or 1)
+// and coarser near the median. The window must be exactly 1 unit wide
+// (+/-0.5, not +/-1) - a 2-unit window lets every centroid hold roughly
+// double the weight a correctly-scaled t-digest allows, which is worst at
+// the tails and defeats the whole point of the k-scale transform.
+func (d *TDigest) kSizeBound(q float64) float64 {
+	if q < 0.0001 {
+		q = 0.0001
+	}
+	if q > 0.9999 {
+		q = 0.9999
+	}
+
+	k := (d.compression / (2 * math.Pi)) * math.Asin(2*q-1)
+	invK := func(k float64) float64 {
+		return (math.Sin(k*2*math.Pi/d.compression) + 1) / 2
+	}
+
+	bound := (invK(k+0.5) - invK(k-0.5)) * d.count
+	if bound < 1 {
+		bound = 1
+	}
+
+	return bound
+}
+
+// compressLocked rebuilds the centroid list by re-inserting every existing
+// centroid's mean (weighted) in weight-descending order - the standard
+// t-digest compress step, which tends to produce a more balanced digest
+// than the incremental inserts that grew it. Callers must hold d.mu.
+func (d *TDigest) compressLocked() {
+	old := d.centroids
+	sort.Slice(old, func(i, j int) bool { return old[i].Weight > old[j].Weight })
+
+	d.centroids = nil
+	d.count = 0
+	for _, c := range old {
+		d.insertLocked(c.Mean, c.Weight)
+	}
+
+	sort.Slice(d.centroids, func(i, j int) bool { return d.centroids[i].Mean < d.centroids[j].Mean })
+}
+
+// tdigestSnapshot is the gob-serializable form of a TDigest's state.
+type tdigestSnapshot struct {
+	Compression float64
+	Count       float64
+	Centroids   []tdigestCentroid
+}
+
+// MarshalSnapshot serializes the digest's current state for persistence to
+// problem_digest_snapshots, so a restart can warm straight from it instead
+// of replaying every accepted submission.
+func (d *TDigest) MarshalSnapshot() ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var buf bytes.Buffer
+	snapshot := tdigestSnapshot{Compression: d.compression, Count: d.count, Centroids: d.centroids}
+	if err := gob.NewEncoder(&buf).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode t-digest snapshot: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LoadSnapshot replaces the digest's state with a previously-marshaled
+// snapshot.
+func (d *TDigest) LoadSnapshot(data []byte) error {
+	var snapshot tdigestSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode t-digest snapshot: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.compression = snapshot.Compression
+	d.count = snapshot.Count
+	d.centroids = snapshot.Centroids
+
+	return nil
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// tdigestKey identifies one problem/language/metric's TDigest.
+type tdigestKey struct {
+	ProblemID string
+	Language  string
+	Metric    DistributionMetric
+}
+
+// TDigestStore owns one TDigest per (problemID, language, metric)
+// combination, creating them lazily, plus a record of which (problemID,
+// language) pairs have been hydrated from the database in this process.
+type TDigestStore struct {
+	mu       sync.RWMutex
+	digests  map[tdigestKey]*TDigest
+	hydrated map[string]bool
+}
+
+// NewTDigestStore creates an empty TDigestStore.
+func NewTDigestStore() *TDigestStore {
+	return &TDigestStore{
+		digests:  make(map[tdigestKey]*TDigest),
+		hydrated: make(map[string]bool),
+	}
+}
+
+// Get returns the TDigest for (problemID, language, metric), creating an
+// empty one on first use.
+func (s *TDigestStore) Get(problemID, language string, metric DistributionMetric) *TDigest {
+	key := tdigestKey{ProblemID: problemID, Language: language, Metric: metric}
+
+	s.mu.RLock()
+	digest, ok := s.digests[key]
+	s.mu.RUnlock()
+	if ok {
+		return digest
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if digest, ok := s.digests[key]; ok {
+		return digest
+	}
+	digest = NewTDigest()
+	s.digests[key] = digest
+	return digest
+}
+
+// IsHydrated reports whether (problemID, language)'s digests have already
+// been seeded in this process, from either a snapshot or the database.
+func (s *TDigestStore) IsHydrated(problemID, language string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hydrated[hydrationKey(problemID, language)]
+}
+
+// MarkHydrated records that (problemID, language)'s digests have been
+// seeded, so later calls skip re-hydrating them.
+func (s *TDigestStore) MarkHydrated(problemID, language string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hydrated[hydrationKey(problemID, language)] = true
+}
+
+// ForEach calls fn once for every digest currently tracked, for
+// SubmissionService.SnapshotAllDigests to persist at shutdown.
+func (s *TDigestStore) ForEach(fn func(problemID, language string, metric DistributionMetric, digest *TDigest)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for key, digest := range s.digests {
+		fn(key.ProblemID, key.Language, key.Metric, digest)
+	}
+}
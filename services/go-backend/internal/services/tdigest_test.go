@@ -0,0 +1,120 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// tdigestQuantileTolerance is how far off (as a fraction of the true
+// value) TDigest.Quantile is allowed to be on a uniform distribution,
+// where every quantile is equally well-resolved.
+const tdigestQuantileTolerance = 0.05
+
+func TestTDigest_QuantileOnUniformDistribution(t *testing.T) {
+	digest := NewTDigest()
+	values := make([]float64, 0, 100000)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		v := r.Float64() * 1000
+		values = append(values, v)
+		digest.Add(v)
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+		want := values[int(q*float64(len(values)))]
+		got := digest.Quantile(q)
+		if rel := math.Abs(got-want) / want; rel > tdigestQuantileTolerance {
+			t.Errorf("Quantile(%v) = %v, want ~%v (relative error %.3f > %v)", q, got, want, rel, tdigestQuantileTolerance)
+		}
+	}
+}
+
+func TestTDigest_CDFIsInverseOfQuantile(t *testing.T) {
+	digest := NewTDigest()
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 50000; i++ {
+		digest.Add(r.NormFloat64()*50 + 200)
+	}
+
+	for _, q := range []float64{0.05, 0.25, 0.5, 0.75, 0.95} {
+		x := digest.Quantile(q)
+		gotQ := digest.CDF(x)
+		if math.Abs(gotQ-q) > 0.03 {
+			t.Errorf("CDF(Quantile(%v)) = %v, want ~%v", q, gotQ, q)
+		}
+	}
+}
+
+func TestTDigest_ResolvesExtremeQuantilesAccurately(t *testing.T) {
+	// A N(200, 50) population, same shape used to catch the kSizeBound
+	// regression that doubled every centroid's allowed weight and blew
+	// out p1/p99 accuracy - this test exists to catch a recurrence.
+	digest := NewTDigest()
+	values := make([]float64, 0, 200000)
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 200000; i++ {
+		v := r.NormFloat64()*50 + 200
+		values = append(values, v)
+		digest.Add(v)
+	}
+	sort.Float64s(values)
+
+	truP1 := values[int(0.01*float64(len(values)))]
+	gotP1 := digest.Quantile(0.01)
+	if rel := math.Abs(gotP1-truP1) / truP1; rel > 0.1 {
+		t.Errorf("Quantile(0.01) = %v, want ~%v (relative error %.3f > 0.1)", gotP1, truP1, rel)
+	}
+
+	truP99 := values[int(0.99*float64(len(values)))]
+	gotP99 := digest.Quantile(0.99)
+	if rel := math.Abs(gotP99-truP99) / truP99; rel > 0.1 {
+		t.Errorf("Quantile(0.99) = %v, want ~%v (relative error %.3f > 0.1)", gotP99, truP99, rel)
+	}
+}
+
+func TestTDigest_SnapshotRoundTrip(t *testing.T) {
+	digest := NewTDigest()
+	r := rand.New(rand.NewSource(4))
+	for i := 0; i < 10000; i++ {
+		digest.Add(r.NormFloat64()*10 + 50)
+	}
+
+	data, err := digest.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("MarshalSnapshot() error = %v", err)
+	}
+
+	restored := NewTDigest()
+	if err := restored.LoadSnapshot(data); err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	if restored.Count() != digest.Count() {
+		t.Errorf("restored Count() = %v, want %v", restored.Count(), digest.Count())
+	}
+
+	for _, q := range []float64{0.1, 0.5, 0.9} {
+		want := digest.Quantile(q)
+		got := restored.Quantile(q)
+		if want != got {
+			t.Errorf("restored Quantile(%v) = %v, want %v (exact match expected - same centroids)", q, got, want)
+		}
+	}
+}
+
+func TestTDigest_EmptyDigestReturnsZeroValues(t *testing.T) {
+	digest := NewTDigest()
+
+	if got := digest.CDF(100); got != 0 {
+		t.Errorf("CDF() on empty digest = %v, want 0", got)
+	}
+	if got := digest.Quantile(0.5); got != 0 {
+		t.Errorf("Quantile() on empty digest = %v, want 0", got)
+	}
+	if got := digest.Count(); got != 0 {
+		t.Errorf("Count() on empty digest = %v, want 0", got)
+	}
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/nahtao97/scribble/internal/k8s"
 )
 
@@ -13,8 +16,12 @@ import (
 const (
 	DefaultWorkerCount = 10
 	DefaultQueueSize   = 100
-	DefaultRateLimit   = 5  // submissions per minute per user
+	DefaultRateLimit   = 5 // submissions per minute per user
 	DefaultRateWindow  = time.Minute
+
+	// DefaultQueueName is the queue used by Submit for backwards compatibility
+	// with callers that don't care about queue isolation.
+	DefaultQueueName = "submissions"
 )
 
 // Common errors
@@ -22,9 +29,11 @@ var (
 	ErrQueueFull     = errors.New("submission queue is full")
 	ErrRateLimited   = errors.New("rate limit exceeded")
 	ErrPoolShutdown  = errors.New("worker pool is shutting down")
+	ErrQueueNotFound = errors.New("queue not registered")
+	ErrQueueExists   = errors.New("queue already registered")
 )
 
-// ExecutionJob represents a job in the worker queue
+// ExecutionJob represents a job in a worker queue
 type ExecutionJob struct {
 	Params     k8s.ExecutionJobParams
 	ResultChan chan *ExecutionJobResult
@@ -37,206 +46,395 @@ type ExecutionJobResult struct {
 	Error  error
 }
 
-// RateLimiter tracks request rates per user
-type RateLimiter struct {
-	mu       sync.RWMutex
-	requests map[string][]time.Time
-	limit    int
-	window   time.Duration
+// namedQueue is a single worker queue with its own backend and worker pool.
+// Queues are isolated so that a backlog in one (e.g. "reports") can't starve
+// another (e.g. "submissions"). The backend defaults to an InMemoryQueue but
+// can be swapped for a RedisQueue so multiple scribble replicas share one
+// backlog.
+type namedQueue struct {
+	name     string
+	backend  Queue
+	broker   ResultBroker
+	workers  int
+	inFlight int32
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+}
+
+// QueueConfig configures a single named queue within the Dispatcher
+type QueueConfig struct {
+	Workers int
+	Size    int
+}
+
+// WorkerPool manages concurrent code execution across one or more named
+// queues with per-user rate limiting. It used to own a single unnamed
+// channel; it is now a thin wrapper around a Dispatcher so existing callers
+// of Submit keep working unchanged against the "submissions" queue.
+type WorkerPool struct {
+	*Dispatcher
+	rateLimiter *RateLimiter
+}
+
+// WorkerPoolConfig configures the worker pool
+type WorkerPoolConfig struct {
+	WorkerCount int
+	QueueSize   int
+	RateLimit   int           // requests per window, per user
+	RateWindow  time.Duration // rate limit window
+
+	// GlobalRateLimit/GlobalBurst bound pool-wide throughput across every
+	// user, checked before RateLimit/RateWindow's per-user limiting.
+	GlobalRateLimit int
+	GlobalBurst     int
+
+	// MaxUserEntries/UserIdleTimeout bound the per-user rate limiter LRU so
+	// a long-lived server doesn't accumulate one limiter per distinct user
+	// forever.
+	MaxUserEntries  int
+	UserIdleTimeout time.Duration
+
+	// Queues configures additional named queues beyond the default
+	// "submissions" queue, e.g. {"daily": {Workers: 2, Size: 50}}.
+	Queues map[string]QueueConfig
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
+// DefaultWorkerPoolConfig returns default configuration.
+// The default queue is CPU-scaled: half of runtime.NumCPU() (minimum 1)
+// workers, since code execution is mostly spent waiting on the k8s job
+// rather than burning local CPU. Report-style queues should be configured
+// with a single worker so they never crowd out interactive submissions.
+func DefaultWorkerPoolConfig() WorkerPoolConfig {
+	processingWorkers := runtime.NumCPU() / 2
+	if processingWorkers < 1 {
+		processingWorkers = 1
+	}
+
+	return WorkerPoolConfig{
+		WorkerCount:     processingWorkers,
+		QueueSize:       DefaultQueueSize,
+		RateLimit:       DefaultRateLimit,
+		RateWindow:      DefaultRateWindow,
+		GlobalRateLimit: DefaultGlobalRateLimit,
+		GlobalBurst:     DefaultGlobalBurst,
+		MaxUserEntries:  DefaultMaxUserEntries,
+		UserIdleTimeout: DefaultUserIdleTimeout,
+		Queues: map[string]QueueConfig{
+			"daily":       {Workers: 1, Size: 10},
+			"reports":     {Workers: 1, Size: 50},
+			"aggregation": {Workers: 1, Size: 50},
+		},
 	}
 }
 
-// Allow checks if a request from userID is allowed
-func (r *RateLimiter) Allow(userID string) bool {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// NewWorkerPool creates a new worker pool backed by a Dispatcher with the
+// default "submissions" queue plus any queues in config.Queues.
+func NewWorkerPool(jobManager JobExecutor, config WorkerPoolConfig) *WorkerPool {
+	dispatcher := NewDispatcher(jobManager)
+
+	workerCount := config.WorkerCount
+	if workerCount <= 0 {
+		workerCount = DefaultWorkerCount
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
 
-	now := time.Now()
-	windowStart := now.Add(-r.window)
+	// RegisterQueue can only fail on a duplicate name, which can't happen
+	// for the first queue on a freshly created dispatcher.
+	_ = dispatcher.RegisterQueue(DefaultQueueName, workerCount, queueSize)
 
-	// Get existing requests and filter out old ones
-	reqs := r.requests[userID]
-	validReqs := make([]time.Time, 0, len(reqs))
-	for _, t := range reqs {
-		if t.After(windowStart) {
-			validReqs = append(validReqs, t)
+	for name, qc := range config.Queues {
+		workers := qc.Workers
+		if workers <= 0 {
+			workers = 1
 		}
+		size := qc.Size
+		if size <= 0 {
+			size = DefaultQueueSize
+		}
+		_ = dispatcher.RegisterQueue(name, workers, size)
 	}
 
-	// Check if under limit
-	if len(validReqs) >= r.limit {
-		r.requests[userID] = validReqs
-		return false
+	return &WorkerPool{
+		Dispatcher: dispatcher,
+		rateLimiter: NewRateLimiter(RateLimiterConfig{
+			PerUserRateLimit: config.RateLimit,
+			PerUserWindow:    config.RateWindow,
+			PerUserBurst:     config.RateLimit,
+			GlobalRateLimit:  config.GlobalRateLimit,
+			GlobalBurst:      config.GlobalBurst,
+			MaxUserEntries:   config.MaxUserEntries,
+			UserIdleTimeout:  config.UserIdleTimeout,
+		}),
 	}
+}
 
-	// Add current request
-	validReqs = append(validReqs, now)
-	r.requests[userID] = validReqs
-	return true
+// Submit submits a job to the default "submissions" queue.
+// Returns ErrQueueFull if the queue is at capacity (circuit breaker)
+// Returns ErrRateLimited if the user has exceeded their rate limit
+func (wp *WorkerPool) Submit(ctx context.Context, userID string, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	if ok, _ := wp.rateLimiter.Allow(userID, DefaultQueueName); !ok {
+		return nil, ErrRateLimited
+	}
+	return wp.Dispatcher.Submit(ctx, DefaultQueueName, params)
 }
 
-// Reset clears the rate limiter (useful for testing)
-func (r *RateLimiter) Reset() {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.requests = make(map[string][]time.Time)
+// Dispatcher owns a set of named job queues, each with its own worker count
+// and buffer size. Latency-sensitive queues (e.g. interactive submissions)
+// are registered with more workers so they don't starve behind long-running
+// aggregation or report jobs sharing the same worker pool.
+type Dispatcher struct {
+	jobManager JobExecutor
+
+	mu         sync.RWMutex
+	queues     map[string]*namedQueue
+	shutdown   chan struct{}
+	isShutdown bool
 }
 
-// WorkerPool manages concurrent code execution with rate limiting
-type WorkerPool struct {
-	jobManager  *k8s.JobManager
-	jobQueue    chan *ExecutionJob
-	rateLimiter *RateLimiter
-	workerCount int
-	wg          sync.WaitGroup
-	shutdown    chan struct{}
-	isShutdown  bool
-	mu          sync.RWMutex
+// NewDispatcher creates an empty Dispatcher. Use RegisterQueue to add queues,
+// or use NewWorkerPool which registers a sensible default set.
+func NewDispatcher(jobManager JobExecutor) *Dispatcher {
+	return &Dispatcher{
+		jobManager: jobManager,
+		queues:     make(map[string]*namedQueue),
+		shutdown:   make(chan struct{}),
+	}
 }
 
-// WorkerPoolConfig configures the worker pool
-type WorkerPoolConfig struct {
-	WorkerCount int
-	QueueSize   int
-	RateLimit   int           // requests per window
-	RateWindow  time.Duration // rate limit window
+// RegisterQueue creates a new named queue with the given worker count and
+// buffer size, backed by an InMemoryQueue, and starts its workers. Returns
+// ErrQueueExists if a queue with that name is already registered.
+func (d *Dispatcher) RegisterQueue(name string, workers, size int) error {
+	return d.RegisterQueueWithBackend(name, workers, NewInMemoryQueue(size), newLocalResultBroker())
 }
 
-// DefaultWorkerPoolConfig returns default configuration
-func DefaultWorkerPoolConfig() WorkerPoolConfig {
-	return WorkerPoolConfig{
-		WorkerCount: DefaultWorkerCount,
-		QueueSize:   DefaultQueueSize,
-		RateLimit:   DefaultRateLimit,
-		RateWindow:  DefaultRateWindow,
+// RegisterQueueWithBackend creates a new named queue using a caller-supplied
+// Queue backend (e.g. a RedisQueue shared across replicas) and ResultBroker,
+// and starts its workers. Returns ErrQueueExists if a queue with that name
+// is already registered.
+func (d *Dispatcher) RegisterQueueWithBackend(name string, workers int, backend Queue, broker ResultBroker) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.queues[name]; exists {
+		return fmt.Errorf("queue %q: %w", name, ErrQueueExists)
 	}
-}
 
-// NewWorkerPool creates a new worker pool
-func NewWorkerPool(jobManager *k8s.JobManager, config WorkerPoolConfig) *WorkerPool {
-	wp := &WorkerPool{
-		jobManager:  jobManager,
-		jobQueue:    make(chan *ExecutionJob, config.QueueSize),
-		rateLimiter: NewRateLimiter(config.RateLimit, config.RateWindow),
-		workerCount: config.WorkerCount,
-		shutdown:    make(chan struct{}),
+	q := &namedQueue{
+		name:    name,
+		backend: backend,
+		broker:  broker,
+		workers: workers,
 	}
+	d.queues[name] = q
 
-	// Start workers
-	for i := 0; i < config.WorkerCount; i++ {
-		wp.wg.Add(1)
-		go wp.worker(i)
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go d.worker(q, i)
 	}
 
-	return wp
+	return nil
 }
 
-// worker processes jobs from the queue
-func (wp *WorkerPool) worker(id int) {
-	defer wp.wg.Done()
+// worker pulls jobs from a single named queue's backend until shutdown
+func (d *Dispatcher) worker(q *namedQueue, id int) {
+	defer q.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-d.shutdown
+		cancel()
+	}()
 
 	for {
 		select {
-		case <-wp.shutdown:
+		case <-d.shutdown:
 			return
-		case job, ok := <-wp.jobQueue:
-			if !ok {
-				return
-			}
-			wp.processJob(job)
+		default:
+		}
+
+		job, err := q.backend.Dequeue(ctx)
+		if err != nil || job == nil {
+			// Either cancelled by shutdown or the backend's poll window
+			// elapsed with nothing available - loop and check shutdown.
+			continue
 		}
+
+		q.mu.Lock()
+		q.inFlight++
+		q.mu.Unlock()
+
+		d.processJob(q, job)
+
+		q.mu.Lock()
+		q.inFlight--
+		q.mu.Unlock()
 	}
 }
 
-// processJob executes a single job
-func (wp *WorkerPool) processJob(job *ExecutionJob) {
-	result, err := wp.jobManager.ExecuteAndWait(job.Ctx, job.Params)
-	job.ResultChan <- &ExecutionJobResult{
-		Result: result,
-		Error:  err,
+// processJob executes a single job against the queue's JobExecutor and
+// publishes the result via the queue's ResultBroker, then acks or nacks the
+// job on its backend.
+func (d *Dispatcher) processJob(q *namedQueue, job *QueuedJob) {
+	execCtx := job.ctx
+	if execCtx == nil {
+		execCtx = context.Background()
+	}
+	result, err := d.jobManager.ExecuteAndWait(execCtx, job.Params)
+	jobResult := &ExecutionJobResult{Result: result, Error: err}
+
+	publishCtx := context.Background()
+	if pubErr := q.broker.Publish(publishCtx, job.ID, jobResult); pubErr != nil {
+		// Nothing more we can do - the caller waiting on Submit will time
+		// out via its own context.
+		_ = pubErr
+	}
+
+	if err != nil {
+		_ = q.backend.Nack(publishCtx, job.ID)
+		return
 	}
-	close(job.ResultChan)
+	_ = q.backend.Ack(publishCtx, job.ID)
 }
 
-// Submit submits a job to the worker pool
-// Returns ErrQueueFull if the queue is at capacity (circuit breaker)
-// Returns ErrRateLimited if the user has exceeded their rate limit
-func (wp *WorkerPool) Submit(ctx context.Context, userID string, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
-	wp.mu.RLock()
-	if wp.isShutdown {
-		wp.mu.RUnlock()
+// Submit enqueues a job onto the named queue and blocks until it completes
+// or ctx is cancelled. Returns ErrQueueNotFound if the queue isn't
+// registered, and ErrQueueFull if the queue is at capacity (circuit
+// breaker).
+func (d *Dispatcher) Submit(ctx context.Context, queueName string, params k8s.ExecutionJobParams) (*k8s.ExecutionResult, error) {
+	d.mu.RLock()
+	if d.isShutdown {
+		d.mu.RUnlock()
 		return nil, ErrPoolShutdown
 	}
-	wp.mu.RUnlock()
+	q, ok := d.queues[queueName]
+	d.mu.RUnlock()
 
-	// Check rate limit
-	if !wp.rateLimiter.Allow(userID) {
-		return nil, ErrRateLimited
+	if !ok {
+		return nil, fmt.Errorf("queue %q: %w", queueName, ErrQueueNotFound)
 	}
 
-	// Create result channel
-	resultChan := make(chan *ExecutionJobResult, 1)
+	job := &QueuedJob{
+		ID:     uuid.NewString(),
+		Params: params,
+		ctx:    ctx,
+	}
 
-	// Create job
-	job := &ExecutionJob{
-		Params:     params,
-		ResultChan: resultChan,
-		Ctx:        ctx,
+	if err := q.backend.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("queue %q: %w", queueName, err)
 	}
 
-	// Try to add to queue (non-blocking)
-	select {
-	case wp.jobQueue <- job:
-		// Job queued successfully
-	default:
-		// Queue is full - circuit breaker trips
-		return nil, ErrQueueFull
+	result, err := q.broker.Wait(ctx, job.ID)
+	if err != nil {
+		return nil, err
 	}
+	return result.Result, result.Error
+}
 
-	// Wait for result
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case result := <-resultChan:
-		return result.Result, result.Error
+// QueueStats describes the state of a single named queue
+type QueueStats struct {
+	Name        string `json:"name"`
+	WorkerCount int    `json:"worker_count"`
+	Length      int    `json:"length"`
+	Capacity    int    `json:"capacity"`
+	InFlight    int32  `json:"in_flight"`
+}
+
+// PoolStats aggregates statistics across all queues owned by the dispatcher
+type PoolStats struct {
+	IsShutdown bool                  `json:"is_shutdown"`
+	Queues     map[string]QueueStats `json:"queues"`
+}
+
+// Stats returns current statistics for every registered queue
+func (d *Dispatcher) Stats() PoolStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := PoolStats{
+		IsShutdown: d.isShutdown,
+		Queues:     make(map[string]QueueStats, len(d.queues)),
+	}
+
+	for name, q := range d.queues {
+		q.mu.Lock()
+		inFlight := q.inFlight
+		q.mu.Unlock()
+
+		length, _ := q.backend.Len(context.Background())
+		capacity := 0
+		if im, ok := q.backend.(*InMemoryQueue); ok {
+			capacity = im.Capacity()
+		}
+
+		stats.Queues[name] = QueueStats{
+			Name:        name,
+			WorkerCount: q.workers,
+			Length:      length,
+			Capacity:    capacity,
+			InFlight:    inFlight,
+		}
 	}
+
+	return stats
 }
 
-// QueueLength returns the current number of jobs in the queue
+// QueueLength returns the current number of jobs queued on the default queue
 func (wp *WorkerPool) QueueLength() int {
-	return len(wp.jobQueue)
+	wp.mu.RLock()
+	q, ok := wp.queues[DefaultQueueName]
+	wp.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	length, _ := q.backend.Len(context.Background())
+	return length
 }
 
-// QueueCapacity returns the maximum queue capacity
+// QueueCapacity returns the buffer capacity of the default queue. Only
+// meaningful for the in-memory backend; Redis-backed queues are unbounded
+// from the process's point of view, so this returns 0.
 func (wp *WorkerPool) QueueCapacity() int {
-	return cap(wp.jobQueue)
+	wp.mu.RLock()
+	q, ok := wp.queues[DefaultQueueName]
+	wp.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	if im, ok := q.backend.(*InMemoryQueue); ok {
+		return im.Capacity()
+	}
+	return 0
 }
 
-// Shutdown gracefully shuts down the worker pool
-func (wp *WorkerPool) Shutdown(ctx context.Context) error {
-	wp.mu.Lock()
-	if wp.isShutdown {
-		wp.mu.Unlock()
+// Shutdown gracefully shuts down every queue in the dispatcher
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if d.isShutdown {
+		d.mu.Unlock()
 		return nil
 	}
-	wp.isShutdown = true
-	wp.mu.Unlock()
+	d.isShutdown = true
+	d.mu.Unlock()
 
-	// Signal workers to stop
-	close(wp.shutdown)
+	close(d.shutdown)
 
-	// Wait for workers to finish with timeout
 	done := make(chan struct{})
 	go func() {
-		wp.wg.Wait()
+		d.mu.RLock()
+		queues := make([]*namedQueue, 0, len(d.queues))
+		for _, q := range d.queues {
+			queues = append(queues, q)
+		}
+		d.mu.RUnlock()
+
+		for _, q := range queues {
+			q.wg.Wait()
+		}
 		close(done)
 	}()
 
@@ -247,24 +445,3 @@ func (wp *WorkerPool) Shutdown(ctx context.Context) error {
 		return ctx.Err()
 	}
 }
-
-// Stats returns current worker pool statistics
-type PoolStats struct {
-	WorkerCount   int `json:"worker_count"`
-	QueueLength   int `json:"queue_length"`
-	QueueCapacity int `json:"queue_capacity"`
-	IsShutdown    bool `json:"is_shutdown"`
-}
-
-// Stats returns current pool statistics
-func (wp *WorkerPool) Stats() PoolStats {
-	wp.mu.RLock()
-	defer wp.mu.RUnlock()
-
-	return PoolStats{
-		WorkerCount:   wp.workerCount,
-		QueueLength:   len(wp.jobQueue),
-		QueueCapacity: cap(wp.jobQueue),
-		IsShutdown:    wp.isShutdown,
-	}
-}
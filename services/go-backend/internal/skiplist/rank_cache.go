@@ -0,0 +1,125 @@
+package skiplist
+
+import (
+	"sync"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+// RankCache holds one List per models.MetricType, letting callers answer
+// RankOf/Range queries in O(log n) instead of re-scanning submissions, and
+// keep them current by calling Upsert/Remove synchronously from write
+// paths (submission acceptance, streak updates) instead of waiting on the
+// next full ComputeAllLeaderboards run.
+//
+// Every mutation goes through mu, a single mutex shared across all
+// metrics, since the number of metrics is small and fixed (see
+// models.AllMetricTypes) and every operation here is already O(log n) - so
+// a Remove racing an in-flight Upsert for the same user can never leave
+// the list pointing at a stale node: whichever call acquires the lock
+// second sees the other's already-applied change and reconciles against
+// it before touching the list. That's the same tradeoff Counters
+// (internal/counters) and DashboardService's widget cache make - a coarse
+// mutex over a hand-rolled lock-free structure - because the underlying
+// work per call is cheap enough that the lock is never held long.
+type RankCache struct {
+	mu     sync.Mutex
+	lists  map[models.MetricType]*List
+	byUser map[models.MetricType]map[uint]Key
+}
+
+// NewRankCache creates an empty RankCache.
+func NewRankCache() *RankCache {
+	return &RankCache{
+		lists:  make(map[models.MetricType]*List),
+		byUser: make(map[models.MetricType]map[uint]Key),
+	}
+}
+
+func (c *RankCache) listFor(metric models.MetricType) (*List, map[uint]Key) {
+	list, ok := c.lists[metric]
+	if !ok {
+		list = NewList()
+		c.lists[metric] = list
+	}
+	users, ok := c.byUser[metric]
+	if !ok {
+		users = make(map[uint]Key)
+		c.byUser[metric] = users
+	}
+	return list, users
+}
+
+// Upsert records userID's current value for metric, replacing any prior
+// value. Safe to call concurrently - e.g. once per accepted submission.
+func (c *RankCache) Upsert(metric models.MetricType, userID uint, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list, users := c.listFor(metric)
+	key := Key{Value: value, UserID: userID}
+
+	if existing, ok := users[userID]; ok {
+		if existing == key {
+			return
+		}
+		list.Delete(existing)
+	}
+
+	list.Insert(key)
+	users[userID] = key
+}
+
+// Remove deletes userID's entry from metric, if present.
+func (c *RankCache) Remove(metric models.MetricType, userID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	users, ok := c.byUser[metric]
+	if !ok {
+		return
+	}
+	key, ok := users[userID]
+	if !ok {
+		return
+	}
+	delete(users, userID)
+
+	if list, ok := c.lists[metric]; ok {
+		list.Delete(key)
+	}
+}
+
+// RankOf returns userID's 1-based rank within metric, and false if userID
+// has no entry for metric.
+func (c *RankCache) RankOf(metric models.MetricType, userID uint) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	users, ok := c.byUser[metric]
+	if !ok {
+		return 0, false
+	}
+	key, ok := users[userID]
+	if !ok {
+		return 0, false
+	}
+	list, ok := c.lists[metric]
+	if !ok {
+		return 0, false
+	}
+	return list.Rank(key)
+}
+
+// Range returns up to limit entries starting at the 0-based offset, in
+// rank order, for paginated leaderboard reads.
+func (c *RankCache) Range(metric models.MetricType, offset, limit int) []Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list, ok := c.lists[metric]
+	if !ok {
+		return nil
+	}
+	return list.Range(offset, limit)
+}
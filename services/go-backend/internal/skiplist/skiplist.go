@@ -0,0 +1,197 @@
+// Package skiplist implements an order-statistics skip list: an
+// insert/delete/search structure that can also answer "what is this key's
+// 1-based rank in sorted order" in O(log n), by tracking how many nodes
+// each forward pointer skips over (the same span-counter technique Redis's
+// sorted sets use). RankCache builds on List to give LeaderboardService an
+// incrementally maintained alternative to re-scanning submissions for
+// every GetUserRank call.
+package skiplist
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	maxLevel    = 32
+	probability = 0.25
+)
+
+// Key orders entries ascending by Value, then by UserID as a stable
+// tie-break - the same tie-break LeaderboardService's batch computations
+// use (see the "Stable tie-break by user ID" comment in
+// internal/services/leaderboard_service.go). Callers ranking "higher is
+// better" metrics negate Value before inserting, as
+// LeaderboardService.applyIncrementalUpdate already does for rankStore.
+type Key struct {
+	Value  float64
+	UserID uint
+}
+
+func (a Key) less(b Key) bool {
+	if a.Value != b.Value {
+		return a.Value < b.Value
+	}
+	return a.UserID < b.UserID
+}
+
+type node struct {
+	key     Key
+	forward []*node
+	span    []int
+}
+
+// List is a skip list ordered by Key. It is not safe for concurrent use by
+// itself - RankCache serializes access with its own per-metric mutex
+// rather than making List lock-free, since every operation here is already
+// O(log n) and cheap enough that a coarse lock doesn't hurt throughput.
+type List struct {
+	head   *node
+	level  int
+	length int
+	rng    *rand.Rand
+}
+
+// NewList creates an empty List.
+func NewList() *List {
+	return &List{
+		head:  &node{forward: make([]*node, maxLevel), span: make([]int, maxLevel)},
+		level: 1,
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Len returns the number of keys currently in the list.
+func (l *List) Len() int {
+	return l.length
+}
+
+func (l *List) randomLevel() int {
+	level := 1
+	for level < maxLevel && l.rng.Float64() < probability {
+		level++
+	}
+	return level
+}
+
+// Insert adds key to the list. Callers are responsible for ensuring key
+// isn't already present (RankCache does this by deleting a user's old key
+// before inserting their new one).
+func (l *List) Insert(key Key) {
+	update := make([]*node, maxLevel)
+	rank := make([]int, maxLevel)
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i == l.level-1 {
+			rank[i] = 0
+		} else {
+			rank[i] = rank[i+1]
+		}
+		for x.forward[i] != nil && x.forward[i].key.less(key) {
+			rank[i] += x.span[i]
+			x = x.forward[i]
+		}
+		update[i] = x
+	}
+
+	level := l.randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = l.head
+			update[i].span[i] = l.length
+		}
+		l.level = level
+	}
+
+	newNode := &node{key: key, forward: make([]*node, level), span: make([]int, level)}
+	for i := 0; i < level; i++ {
+		newNode.forward[i] = update[i].forward[i]
+		update[i].forward[i] = newNode
+
+		newNode.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = (rank[0] - rank[i]) + 1
+	}
+
+	for i := level; i < l.level; i++ {
+		update[i].span[i]++
+	}
+
+	l.length++
+}
+
+// Delete removes key from the list, reporting whether it was present.
+func (l *List) Delete(key Key) bool {
+	update := make([]*node, maxLevel)
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && x.forward[i].key.less(key) {
+			x = x.forward[i]
+		}
+		update[i] = x
+	}
+
+	x = x.forward[0]
+	if x == nil || x.key != key {
+		return false
+	}
+
+	for i := 0; i < l.level; i++ {
+		if update[i].forward[i] == x {
+			update[i].span[i] += x.span[i] - 1
+			update[i].forward[i] = x.forward[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for l.level > 1 && l.head.forward[l.level-1] == nil {
+		l.level--
+	}
+	l.length--
+	return true
+}
+
+// Rank returns key's 1-based position in ascending order, and false if key
+// isn't present.
+func (l *List) Rank(key Key) (int, bool) {
+	x := l.head
+	rank := 0
+	for i := l.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && x.forward[i].key.less(key) {
+			rank += x.span[i]
+			x = x.forward[i]
+		}
+	}
+	x = x.forward[0]
+	if x == nil || x.key != key {
+		return 0, false
+	}
+	return rank + 1, true
+}
+
+// Range returns up to limit keys starting at the 0-based offset, in
+// ascending order.
+func (l *List) Range(offset, limit int) []Key {
+	if offset < 0 || limit <= 0 {
+		return nil
+	}
+
+	x := l.head
+	traversed := 0
+	for i := l.level - 1; i >= 0; i-- {
+		for x.forward[i] != nil && traversed+x.span[i] <= offset {
+			traversed += x.span[i]
+			x = x.forward[i]
+		}
+	}
+
+	x = x.forward[0]
+	result := make([]Key, 0, limit)
+	for x != nil && len(result) < limit {
+		result = append(result, x.key)
+		x = x.forward[0]
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package skiplist
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/nahtao97/scribble/internal/models"
+)
+
+func TestListInsertRank(t *testing.T) {
+	l := NewList()
+	keys := []Key{{Value: 3, UserID: 1}, {Value: 1, UserID: 2}, {Value: 2, UserID: 3}}
+	for _, k := range keys {
+		l.Insert(k)
+	}
+
+	for want, k := range []Key{{Value: 1, UserID: 2}, {Value: 2, UserID: 3}, {Value: 3, UserID: 1}} {
+		rank, ok := l.Rank(k)
+		if !ok {
+			t.Fatalf("Rank(%v): not found", k)
+		}
+		if rank != want+1 {
+			t.Errorf("Rank(%v) = %d, want %d", k, rank, want+1)
+		}
+	}
+}
+
+func TestListTieBreaksByUserID(t *testing.T) {
+	l := NewList()
+	l.Insert(Key{Value: 5, UserID: 2})
+	l.Insert(Key{Value: 5, UserID: 1})
+
+	rank, ok := l.Rank(Key{Value: 5, UserID: 1})
+	if !ok || rank != 1 {
+		t.Errorf("Rank(user 1) = %d, %v, want 1, true", rank, ok)
+	}
+	rank, ok = l.Rank(Key{Value: 5, UserID: 2})
+	if !ok || rank != 2 {
+		t.Errorf("Rank(user 2) = %d, %v, want 2, true", rank, ok)
+	}
+}
+
+func TestListDeleteThenRankMisses(t *testing.T) {
+	l := NewList()
+	k := Key{Value: 1, UserID: 1}
+	l.Insert(k)
+	if !l.Delete(k) {
+		t.Fatal("Delete reported key absent")
+	}
+	if _, ok := l.Rank(k); ok {
+		t.Error("Rank found a key after it was deleted")
+	}
+	if l.Len() != 0 {
+		t.Errorf("Len() = %d after deleting the only key, want 0", l.Len())
+	}
+}
+
+func TestListRange(t *testing.T) {
+	l := NewList()
+	for i := 0; i < 10; i++ {
+		l.Insert(Key{Value: float64(i), UserID: uint(i)})
+	}
+
+	got := l.Range(3, 4)
+	if len(got) != 4 {
+		t.Fatalf("Range(3, 4) returned %d keys, want 4", len(got))
+	}
+	for i, k := range got {
+		if k.UserID != uint(3+i) {
+			t.Errorf("Range(3, 4)[%d].UserID = %d, want %d", i, k.UserID, 3+i)
+		}
+	}
+}
+
+// oracleRank computes userID's 1-based rank the obvious O(n log n) way, to
+// check List/RankCache against.
+func oracleRank(entries map[uint]float64, userID uint) (int, bool) {
+	value, ok := entries[userID]
+	if !ok {
+		return 0, false
+	}
+
+	type pair struct {
+		userID uint
+		value  float64
+	}
+	sorted := make([]pair, 0, len(entries))
+	for id, v := range entries {
+		sorted = append(sorted, pair{id, v})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].value != sorted[j].value {
+			return sorted[i].value < sorted[j].value
+		}
+		return sorted[i].userID < sorted[j].userID
+	})
+
+	for i, p := range sorted {
+		if p.userID == userID && p.value == value {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// TestRankCacheChaos interleaves random Upsert/Remove/RankOf calls from
+// many goroutines against a single RankCache, then checks every surviving
+// user's rank against a sorted-slice oracle built from the same operation
+// log. It exercises the invariant from the motivating bug report: a
+// concurrent delete-then-insert for one user must never leave the cache
+// reporting a stale rank for anyone.
+func TestRankCacheChaos(t *testing.T) {
+	const (
+		users      = 64
+		goroutines = 16
+		opsPerGo   = 500
+		metric     = models.MetricFastestAvg
+	)
+
+	cache := NewRankCache()
+	oracle := make(map[uint]float64)
+	var oracleMu sync.Mutex
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < opsPerGo; i++ {
+				userID := uint(rng.Intn(users)) + 1
+				switch rng.Intn(3) {
+				case 0, 1:
+					value := rng.Float64() * 1000
+					oracleMu.Lock()
+					oracle[userID] = value
+					oracleMu.Unlock()
+					cache.Upsert(metric, userID, value)
+				case 2:
+					oracleMu.Lock()
+					delete(oracle, userID)
+					oracleMu.Unlock()
+					cache.Remove(metric, userID)
+				}
+				cache.RankOf(metric, userID)
+			}
+		}(int64(g) + 1)
+	}
+	wg.Wait()
+
+	for userID := uint(1); userID <= users; userID++ {
+		wantRank, wantOK := oracleRank(oracle, userID)
+		gotRank, gotOK := cache.RankOf(metric, userID)
+		if gotOK != wantOK {
+			t.Fatalf("user %d: RankOf ok = %v, want %v", userID, gotOK, wantOK)
+		}
+		if gotOK && gotRank != wantRank {
+			t.Errorf("user %d: RankOf = %d, want %d", userID, gotRank, wantRank)
+		}
+	}
+}
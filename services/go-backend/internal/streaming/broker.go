@@ -0,0 +1,169 @@
+// Package streaming fans a submission's execution events out to however
+// many clients are currently watching it, and keeps a short replay buffer
+// per submission so a client that (re)connects after some events already
+// fired doesn't join a live-only feed and miss them - it resumes from the
+// first buffered event instead.
+package streaming
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nahtao97/scribble/internal/k8s"
+)
+
+const (
+	// DefaultReplaySize bounds how many of a submission's most recent
+	// events a newly (re)joined subscriber is replayed.
+	DefaultReplaySize = 64
+
+	// DefaultRetention is how long after a submission's last event its
+	// topic - and replay buffer - stays around for a reconnecting
+	// subscriber before the Broker reclaims it.
+	DefaultRetention = 2 * time.Minute
+
+	// subscriberBuffer is how many events a slow subscriber can fall
+	// behind the broadcast before Publish drops the oldest unread one for
+	// it, so one stalled client can't block delivery to the others.
+	subscriberBuffer = 32
+)
+
+// topic holds one submission's replay buffer and live subscribers.
+type topic struct {
+	mu       sync.Mutex
+	buffer   []k8s.ExecutionEvent
+	subs     map[chan k8s.ExecutionEvent]struct{}
+	terminal bool
+}
+
+// Broker fans out execution events to subscribers, keyed by submission ID.
+type Broker struct {
+	replaySize int
+	retention  time.Duration
+
+	mu     sync.Mutex
+	topics map[string]*topic
+}
+
+// NewBroker creates a Broker that replays up to replaySize events per
+// topic and retains a topic for retention after its last event. Zero
+// values fall back to DefaultReplaySize/DefaultRetention.
+func NewBroker(replaySize int, retention time.Duration) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Broker{
+		replaySize: replaySize,
+		retention:  retention,
+		topics:     make(map[string]*topic),
+	}
+}
+
+// EnsureTopic returns whether submissionID already had a topic - creating
+// one if not. Callers use this to decide whether they're the first
+// subscriber responsible for driving execution (false) or one joining a
+// submission another caller already started streaming (true).
+func (b *Broker) EnsureTopic(submissionID string) (existed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.topics[submissionID]; ok {
+		return true
+	}
+	b.topics[submissionID] = &topic{subs: make(map[chan k8s.ExecutionEvent]struct{})}
+	return false
+}
+
+// Publish appends event to submissionID's replay buffer and broadcasts it
+// to every current subscriber. A terminal event (completed or error)
+// closes every live subscriber channel - callers have already received
+// it by the time their range over the channel ends - and schedules the
+// topic's reclamation after Broker's retention.
+func (b *Broker) Publish(submissionID string, event k8s.ExecutionEvent) {
+	b.mu.Lock()
+	t, ok := b.topics[submissionID]
+	if !ok {
+		t = &topic{subs: make(map[chan k8s.ExecutionEvent]struct{})}
+		b.topics[submissionID] = t
+	}
+	b.mu.Unlock()
+
+	t.mu.Lock()
+	t.buffer = append(t.buffer, event)
+	if len(t.buffer) > b.replaySize {
+		t.buffer = t.buffer[len(t.buffer)-b.replaySize:]
+	}
+	isTerminal := event.Type == k8s.EventCompleted || event.Type == k8s.EventError
+	for ch := range t.subs {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber: drop the event for it rather than block
+			// every other subscriber's delivery on it.
+		}
+	}
+	if isTerminal {
+		t.terminal = true
+		for ch := range t.subs {
+			close(ch)
+		}
+		t.subs = make(map[chan k8s.ExecutionEvent]struct{})
+	}
+	t.mu.Unlock()
+
+	if isTerminal {
+		time.AfterFunc(b.retention, func() { b.evict(submissionID, t) })
+	}
+}
+
+// Subscribe returns a channel that first replays submissionID's buffered
+// events, then streams new ones live, and an unsubscribe func the caller
+// must call (typically deferred) once it stops reading. If the topic has
+// already gone terminal, the returned channel is just the replay buffer,
+// already closed.
+func (b *Broker) Subscribe(submissionID string) (<-chan k8s.ExecutionEvent, func()) {
+	b.mu.Lock()
+	t, ok := b.topics[submissionID]
+	if !ok {
+		t = &topic{subs: make(map[chan k8s.ExecutionEvent]struct{})}
+		b.topics[submissionID] = t
+	}
+	b.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(chan k8s.ExecutionEvent, subscriberBuffer+len(t.buffer))
+	for _, e := range t.buffer {
+		out <- e
+	}
+	if t.terminal {
+		close(out)
+		return out, func() {}
+	}
+
+	t.subs[out] = struct{}{}
+	unsubscribe := func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.subs[out]; ok {
+			delete(t.subs, out)
+			close(out)
+		}
+	}
+	return out, unsubscribe
+}
+
+// evict removes submissionID's topic, unless it was replaced (e.g. the
+// submission was resubmitted under the same ID) by the time retention
+// elapsed.
+func (b *Broker) evict(submissionID string, t *topic) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.topics[submissionID] == t {
+		delete(b.topics, submissionID)
+	}
+}
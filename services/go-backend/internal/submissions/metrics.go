@@ -0,0 +1,53 @@
+package submissions
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// InFlight reports how many submissions currently hold a Scheduler slot
+// (admitted and running), per language.
+var InFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "scribble",
+	Subsystem: "submission_queue",
+	Name:      "in_flight",
+	Help:      "Number of submissions currently holding a scheduler slot, per language.",
+}, []string{"language"})
+
+// QueueDepth reports how many submissions are currently waiting for a
+// slot, per language.
+var QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "scribble",
+	Subsystem: "submission_queue",
+	Name:      "queue_depth",
+	Help:      "Number of submissions currently waiting for a scheduler slot, per language.",
+}, []string{"language"})
+
+// WaitDuration measures how long an admitted submission waited in the
+// queue before acquiring a slot.
+var WaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "scribble",
+	Subsystem: "submission_queue",
+	Name:      "wait_duration_seconds",
+	Help:      "Time an admitted submission spent waiting for a scheduler slot, per language.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"language"})
+
+// AdmittedTotal counts submissions that were granted a slot, immediately
+// or after queueing, per language.
+var AdmittedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "submission_queue",
+	Name:      "admitted_total",
+	Help:      "Number of submissions admitted to run, per language.",
+}, []string{"language"})
+
+// Rejections counts submissions turned away with ErrQueueFull because the
+// wait queue was already at Config.MaxQueue, per language.
+var Rejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "scribble",
+	Subsystem: "submission_queue",
+	Name:      "rejections_total",
+	Help:      "Number of submissions rejected because the wait queue was full, per language.",
+}, []string{"language"})
+
+func init() {
+	prometheus.MustRegister(InFlight, QueueDepth, WaitDuration, AdmittedTotal, Rejections)
+}
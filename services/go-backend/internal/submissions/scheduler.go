@@ -0,0 +1,390 @@
+// Package submissions provides admission control in front of the
+// submissions handler: a global cap on how many submissions may run at
+// once, plus a per-language cap weighted by how expensive that language's
+// sandbox is, and a bounded FIFO queue for everything waiting on either.
+// Without it the handler would hand every request straight to the
+// executor (or the acquirer's durable queue) with no limit on how many
+// run concurrently, which either overloads the sandbox or serializes
+// submissions implicitly with no visibility into queue depth or wait
+// time.
+package submissions
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for Scheduler's admission policy.
+const (
+	// DefaultGlobalCapacity bounds how many submissions, across every
+	// language combined, may run at once.
+	DefaultGlobalCapacity = 16
+
+	// DefaultLanguageCapacity bounds how many weighted units of a single
+	// language may run at once - see DefaultLanguageWeights.
+	DefaultLanguageCapacity = 8
+
+	// DefaultMaxQueue bounds how many submissions may wait for a slot
+	// before new ones are rejected with ErrQueueFull.
+	DefaultMaxQueue = 64
+)
+
+// DefaultLanguageWeights assigns each language a relative cost against its
+// own per-language budget: a heavier language (more CPU/memory per
+// sandbox, largely due to compilation) consumes more of that budget per
+// submission than a lighter, interpreted one, so fewer of them can run
+// concurrently for the same LanguageCapacity.
+func DefaultLanguageWeights() map[string]int64 {
+	return map[string]int64{
+		"python":     1,
+		"javascript": 1,
+		"typescript": 1,
+		"go":         1,
+		"c":          2,
+		"cpp":        2,
+		"java":       2,
+	}
+}
+
+// Config tunes a Scheduler's admission policy.
+type Config struct {
+	// GlobalCapacity bounds how many submissions may run at once, across
+	// every language combined.
+	GlobalCapacity int
+
+	// LanguageCapacity bounds how many weighted units of a single
+	// language may run at once.
+	LanguageCapacity int64
+
+	// LanguageWeights maps a language to how many units of its
+	// LanguageCapacity one submission in it consumes. A language missing
+	// from the map defaults to weight 1.
+	LanguageWeights map[string]int64
+
+	// MaxQueue bounds how many submissions may wait for a slot before
+	// Acquire starts returning ErrQueueFull instead of queueing them.
+	MaxQueue int
+}
+
+// DefaultConfig returns DefaultGlobalCapacity/DefaultLanguageCapacity/
+// DefaultMaxQueue and DefaultLanguageWeights.
+func DefaultConfig() Config {
+	return Config{
+		GlobalCapacity:   DefaultGlobalCapacity,
+		LanguageCapacity: DefaultLanguageCapacity,
+		LanguageWeights:  DefaultLanguageWeights(),
+		MaxQueue:         DefaultMaxQueue,
+	}
+}
+
+// ConfigFromEnv returns DefaultConfig with GlobalCapacity, LanguageCapacity
+// and MaxQueue overridden by the SUBMISSION_GLOBAL_CAPACITY,
+// SUBMISSION_LANGUAGE_CAPACITY and SUBMISSION_MAX_QUEUE environment
+// variables, when set to valid positive integers.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	if v := envInt("SUBMISSION_GLOBAL_CAPACITY"); v > 0 {
+		cfg.GlobalCapacity = v
+	}
+	if v := envInt("SUBMISSION_LANGUAGE_CAPACITY"); v > 0 {
+		cfg.LanguageCapacity = int64(v)
+	}
+	if v := envInt("SUBMISSION_MAX_QUEUE"); v > 0 {
+		cfg.MaxQueue = v
+	}
+	return cfg
+}
+
+func envInt(key string) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// ErrQueueFull is returned by Acquire when the wait queue is already at
+// Config.MaxQueue.
+var ErrQueueFull = errors.New("submission queue is full")
+
+// Ticket is returned by Acquire/TryAcquire. Position and EstimatedWait
+// reflect the queue state at the moment the submission was admitted to
+// the queue (both zero if a slot was free immediately). Release must be
+// called exactly once, when the submission finishes running, to free the
+// global and per-language slots it holds.
+type Ticket struct {
+	Position      int
+	EstimatedWait time.Duration
+
+	language string
+	weight   int64
+	start    time.Time
+	sched    *Scheduler
+}
+
+// Release frees t's global and per-language slots, admitting the next
+// queued submission (if any) that now fits. Safe to call at most once per
+// Ticket.
+func (t *Ticket) Release() {
+	t.sched.release(t)
+}
+
+// Scheduler admits submissions under a global concurrency cap and a
+// per-language cap, queueing whatever doesn't fit (FIFO, bounded by
+// Config.MaxQueue) instead of rejecting it outright, and rejecting only
+// once the queue itself is full.
+type Scheduler struct {
+	cfg    Config
+	global *weighted
+
+	langMu sync.Mutex
+	langs  map[string]*weighted
+
+	mu     sync.Mutex
+	queued int
+	avgRun time.Duration
+}
+
+// NewScheduler creates a Scheduler enforcing cfg. Zero-valued Config
+// fields fall back to DefaultConfig's.
+func NewScheduler(cfg Config) *Scheduler {
+	if cfg.GlobalCapacity <= 0 {
+		cfg.GlobalCapacity = DefaultGlobalCapacity
+	}
+	if cfg.LanguageCapacity <= 0 {
+		cfg.LanguageCapacity = DefaultLanguageCapacity
+	}
+	if cfg.LanguageWeights == nil {
+		cfg.LanguageWeights = DefaultLanguageWeights()
+	}
+	if cfg.MaxQueue <= 0 {
+		cfg.MaxQueue = DefaultMaxQueue
+	}
+
+	return &Scheduler{
+		cfg:    cfg,
+		global: newWeighted(int64(cfg.GlobalCapacity)),
+		langs:  make(map[string]*weighted),
+		avgRun: time.Second, // seed estimate until Release has observed a real run
+	}
+}
+
+// languageWeight returns how many units of LanguageCapacity one submission
+// in language consumes.
+func (s *Scheduler) languageWeight(language string) int64 {
+	if w, ok := s.cfg.LanguageWeights[language]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// languageSemaphore returns the weighted semaphore for language, creating
+// it on first use.
+func (s *Scheduler) languageSemaphore(language string) *weighted {
+	s.langMu.Lock()
+	defer s.langMu.Unlock()
+	sem, ok := s.langs[language]
+	if !ok {
+		sem = newWeighted(s.cfg.LanguageCapacity)
+		s.langs[language] = sem
+	}
+	return sem
+}
+
+// TryAcquire admits a submission in language only if both the global and
+// per-language slots are free right now, without consulting or growing
+// the wait queue.
+func (s *Scheduler) TryAcquire(language string) (*Ticket, bool) {
+	weight := s.languageWeight(language)
+	sem := s.languageSemaphore(language)
+
+	if !s.global.TryAcquire(1) {
+		return nil, false
+	}
+	if !sem.TryAcquire(weight) {
+		s.global.Release(1)
+		return nil, false
+	}
+
+	AdmittedTotal.WithLabelValues(language).Inc()
+	InFlight.WithLabelValues(language).Inc()
+	return &Ticket{language: language, weight: weight, start: time.Now(), sched: s}, true
+}
+
+// Acquire admits a submission in language, queueing it (FIFO, bounded by
+// Config.MaxQueue) if no slot is free immediately. It returns
+// ErrQueueFull without queueing if the wait queue is already full, and
+// ctx.Err() if ctx is cancelled while queued.
+func (s *Scheduler) Acquire(ctx context.Context, language string) (*Ticket, error) {
+	if ticket, ok := s.TryAcquire(language); ok {
+		return ticket, nil
+	}
+
+	s.mu.Lock()
+	if s.queued >= s.cfg.MaxQueue {
+		s.mu.Unlock()
+		Rejections.WithLabelValues(language).Inc()
+		return nil, ErrQueueFull
+	}
+	s.queued++
+	position := s.queued
+	estimatedWait := s.estimatedWaitLocked(position)
+	s.mu.Unlock()
+
+	QueueDepth.WithLabelValues(language).Inc()
+	defer QueueDepth.WithLabelValues(language).Dec()
+	defer func() {
+		s.mu.Lock()
+		s.queued--
+		s.mu.Unlock()
+	}()
+
+	waitStart := time.Now()
+	weight := s.languageWeight(language)
+	sem := s.languageSemaphore(language)
+
+	if err := s.global.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	if err := sem.Acquire(ctx, weight); err != nil {
+		s.global.Release(1)
+		return nil, err
+	}
+
+	WaitDuration.WithLabelValues(language).Observe(time.Since(waitStart).Seconds())
+	AdmittedTotal.WithLabelValues(language).Inc()
+	InFlight.WithLabelValues(language).Inc()
+
+	return &Ticket{
+		Position:      position,
+		EstimatedWait: estimatedWait,
+		language:      language,
+		weight:        weight,
+		start:         time.Now(),
+		sched:         s,
+	}, nil
+}
+
+// release frees t's global and per-language slots and folds its actual
+// run time into the rolling average estimatedWaitLocked uses to project
+// how long a newly queued submission should expect to wait.
+func (s *Scheduler) release(t *Ticket) {
+	s.languageSemaphore(t.language).Release(t.weight)
+	s.global.Release(1)
+	InFlight.WithLabelValues(t.language).Dec()
+
+	elapsed := time.Since(t.start)
+	s.mu.Lock()
+	// Exponential moving average so a handful of slow outliers (or a cold
+	// start) don't dominate the estimate forever.
+	const alpha = 0.2
+	s.avgRun = time.Duration(float64(s.avgRun)*(1-alpha) + float64(elapsed)*alpha)
+	s.mu.Unlock()
+}
+
+// estimatedWaitLocked projects how long a submission at position in the
+// queue should expect to wait, assuming GlobalCapacity submissions finish
+// every avgRun. Callers must hold s.mu.
+func (s *Scheduler) estimatedWaitLocked(position int) time.Duration {
+	batches := (position + s.cfg.GlobalCapacity - 1) / s.cfg.GlobalCapacity
+	return time.Duration(batches) * s.avgRun
+}
+
+// weighted is a minimal FIFO weighted semaphore: Acquire blocks until n
+// units are available and grants waiters in first-come-first-served
+// order, even when an earlier, larger request is still waiting - the same
+// fairness property golang.org/x/sync/semaphore.Weighted provides. It's
+// reimplemented here (rather than taking that dependency) so capacity can
+// be fixed per language at construction while the weight per acquisition
+// varies per call.
+type weighted struct {
+	mu       sync.Mutex
+	capacity int64
+	used     int64
+	waiters  *list.List // of *weightedWaiter
+}
+
+type weightedWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newWeighted(capacity int64) *weighted {
+	return &weighted{capacity: capacity, waiters: list.New()}
+}
+
+// TryAcquire grants n units without blocking, only if they're available
+// and nothing is already waiting ahead (so a late, small request can't
+// jump a long-waiting larger one).
+func (w *weighted) TryAcquire(n int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.waiters.Len() == 0 && w.used+n <= w.capacity {
+		w.used += n
+		return true
+	}
+	return false
+}
+
+// Acquire blocks until n units are available, or ctx is cancelled first.
+func (w *weighted) Acquire(ctx context.Context, n int64) error {
+	w.mu.Lock()
+	if w.waiters.Len() == 0 && w.used+n <= w.capacity {
+		w.used += n
+		w.mu.Unlock()
+		return nil
+	}
+	waiter := &weightedWaiter{n: n, ready: make(chan struct{})}
+	elem := w.waiters.PushBack(waiter)
+	w.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+		w.mu.Lock()
+		select {
+		case <-waiter.ready:
+			// Acquired concurrently with cancellation; give the units back.
+			w.used -= n
+			w.admitWaitersLocked()
+		default:
+			w.waiters.Remove(elem)
+		}
+		w.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Release returns n units, admitting queued waiters (in FIFO order) that
+// now fit.
+func (w *weighted) Release(n int64) {
+	w.mu.Lock()
+	w.used -= n
+	w.admitWaitersLocked()
+	w.mu.Unlock()
+}
+
+// admitWaitersLocked wakes as many waiters at the front of the queue as
+// now fit, stopping at the first one that doesn't - a waiter never jumps
+// ahead of one it arrived after. Callers must hold w.mu.
+func (w *weighted) admitWaitersLocked() {
+	for {
+		front := w.waiters.Front()
+		if front == nil {
+			return
+		}
+		waiter := front.Value.(*weightedWaiter)
+		if w.used+waiter.n > w.capacity {
+			return
+		}
+		w.used += waiter.n
+		w.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
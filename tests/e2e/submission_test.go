@@ -1,11 +1,17 @@
 package e2e
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -43,6 +49,12 @@ type SubmissionResponse struct {
 	TestsTotal    int    `json:"testsTotal"`
 	Message       string `json:"message"`
 	Error         string `json:"error,omitempty"`
+
+	// QueuePosition and EstimatedWaitNs mirror the backend's admission
+	// scheduler (see services/go-backend/internal/submissions) and are
+	// only populated once it's fronting this endpoint.
+	QueuePosition   int   `json:"queuePosition,omitempty"`
+	EstimatedWaitNs int64 `json:"estimatedWaitNs,omitempty"`
 }
 
 // TestLanguageSubmissions tests submission flow for all 6 supported languages
@@ -262,59 +274,281 @@ func TestEdgeCases(t *testing.T) {
 	}
 }
 
-// TestConcurrentSubmissions tests load with concurrent submissions
+// pythonWallTime mirrors languageDefaults["python"].WallTime in
+// services/go-backend/internal/limits - there isn't a cross-module import
+// path from this test binary into the backend, so TestResourceLimitVerdicts
+// keeps its own copy and the two need to be kept in sync by hand.
+const pythonWallTime = 8 * time.Second
+
+// TestResourceLimitVerdicts submits code that deliberately exceeds one of
+// the per-language sandbox limits (see services/go-backend/internal/limits)
+// and checks the backend reports the matching verdict rather than just
+// timing out the HTTP request or returning a generic error.
+func TestResourceLimitVerdicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E tests in short mode")
+	}
+
+	t.Run("TLE", func(t *testing.T) {
+		resp, err := submitCode("while True: pass", "python", 1)
+		if err != nil {
+			t.Fatalf("Failed to submit code: %v", err)
+		}
+		if resp.Verdict != "TLE" {
+			t.Fatalf("expected verdict TLE, got %q (status %q)", resp.Verdict, resp.Status)
+		}
+
+		execTime, err := time.ParseDuration(strings.TrimSuffix(resp.ExecutionTime, "ms") + "ms")
+		if err != nil {
+			t.Fatalf("failed to parse execution time %q: %v", resp.ExecutionTime, err)
+		}
+		if tolerance := pythonWallTime / 10; execTime < pythonWallTime-tolerance || execTime > pythonWallTime+tolerance {
+			t.Errorf("execution time %v not within 10%% of the %v wall limit", execTime, pythonWallTime)
+		}
+	})
+
+	t.Run("MLE", func(t *testing.T) {
+		const code = `
+x = bytearray(2 * 1024 * 1024 * 1024)
+print(len(x))
+`
+		resp, err := submitCode(code, "python", 1)
+		if err != nil {
+			t.Fatalf("Failed to submit code: %v", err)
+		}
+		if resp.Verdict != "MLE" {
+			t.Fatalf("expected verdict MLE, got %q (status %q)", resp.Verdict, resp.Status)
+		}
+	})
+
+	t.Run("OLE", func(t *testing.T) {
+		const code = `
+for _ in range(1024 * 1024 * 1024):
+    print("x", end="")
+`
+		resp, err := submitCode(code, "python", 1)
+		if err != nil {
+			t.Fatalf("Failed to submit code: %v", err)
+		}
+		if resp.Verdict != "OLE" {
+			t.Fatalf("expected verdict OLE, got %q (status %q)", resp.Verdict, resp.Status)
+		}
+	})
+}
+
+// Admission-control limits the backend's submissions.Scheduler applies to
+// this endpoint (see services/go-backend/internal/submissions), overridable
+// via env so this test stays in sync with however the server under test is
+// configured.
+var (
+	submissionGlobalCapacity = getEnvInt("TEST_SUBMISSION_GLOBAL_CAPACITY", 16)
+	submissionMaxQueue       = getEnvInt("TEST_SUBMISSION_MAX_QUEUE", 64)
+)
+
+func getEnvInt(key string, defaultVal int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultVal
+	}
+	return v
+}
+
+// TestConcurrentSubmissions fires enough concurrent submissions to exceed
+// the scheduler's global capacity but stay within its wait queue, then one
+// batch past the queue too, and asserts the admission control this implies:
+// every request in the first two batches is admitted (none silently
+// dropped), with the ones beyond submissionGlobalCapacity reporting a
+// nonzero QueuePosition, and every request in the final, queue-busting
+// batch is rejected with 429.
 func TestConcurrentSubmissions(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping E2E tests in short mode")
 	}
 
-	const numConcurrent = 10
-	const code = `print(sum(map(int, input().split())))`
+	// Code that runs long enough for admitted-but-queued submissions to
+	// still be outstanding when we inspect the results.
+	const code = `
+import time
+time.sleep(2)
+print(sum(map(int, input().split())))
+`
 
-	var wg sync.WaitGroup
-	results := make(chan *SubmissionResponse, numConcurrent)
-	errors := make(chan error, numConcurrent)
+	withinQueue := submissionGlobalCapacity + submissionMaxQueue
+	overQueue := 10
 
 	startTime := time.Now()
+	admitted := submitConcurrent(t, code, withinQueue)
+	duration := time.Since(startTime)
+
+	queuedCount := 0
+	for i, r := range admitted {
+		if r.err != nil {
+			t.Errorf("request %d: expected admission, got error: %v", i, r.err)
+			continue
+		}
+		if r.status != http.StatusAccepted && r.status != http.StatusOK {
+			t.Errorf("request %d: expected 200/202 within capacity+queue, got %d", i, r.status)
+			continue
+		}
+		if r.resp.QueuePosition > 0 {
+			queuedCount++
+		}
+	}
+	t.Logf("Admitted %d requests in %v (%d queued behind the global capacity)", withinQueue, duration, queuedCount)
+	if queuedCount == 0 {
+		t.Errorf("expected some of %d requests to exceed the global capacity (%d) and queue, none did", withinQueue, submissionGlobalCapacity)
+	}
+
+	rejected := submitConcurrent(t, code, overQueue)
+	for i, r := range rejected {
+		if r.err != nil {
+			t.Errorf("request %d: unexpected transport error: %v", i, r.err)
+			continue
+		}
+		if r.status != http.StatusTooManyRequests {
+			t.Errorf("request %d: expected 429 once the wait queue is full, got %d", i, r.status)
+		}
+	}
+}
+
+// submissionAttempt is one submitCode call's outcome, status alongside the
+// decoded body so callers can distinguish a rejection (valid response,
+// non-2xx status) from a transport failure (err set).
+type submissionAttempt struct {
+	status int
+	resp   *SubmissionResponse
+	err    error
+}
+
+// submitConcurrent fires n concurrent submissions of code and returns once
+// all have responded.
+func submitConcurrent(t *testing.T, code string, n int) []submissionAttempt {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	out := make([]submissionAttempt, n)
 
-	for i := 0; i < numConcurrent; i++ {
+	for i := 0; i < n; i++ {
 		wg.Add(1)
-		go func(id int) {
+		go func(i int) {
 			defer wg.Done()
-			resp, err := submitCode(code, "python", 1)
-			if err != nil {
-				errors <- fmt.Errorf("request %d failed: %w", id, err)
-				return
-			}
-			results <- resp
+			status, resp, err := submitCodeWithStatus(code, "python", 1)
+			out[i] = submissionAttempt{status: status, resp: resp, err: err}
 		}(i)
 	}
-
 	wg.Wait()
-	close(results)
-	close(errors)
 
-	duration := time.Since(startTime)
+	return out
+}
+
+// streamEvent is one decoded `event: <type>\ndata: <json>` frame read off
+// an SSE stream.
+type streamEvent struct {
+	Type string
+	Data map[string]interface{}
+}
+
+// TestSubmissionStream connects to /api/submissions/:id/stream for a
+// multi-case submission and asserts the events arrive in the order the
+// judge actually produces them - job_created, then one test_case_result
+// per case, then a terminal completed - and that reconnecting partway
+// through (within the broker's replay window) resumes from the start of
+// the sequence instead of picking up mid-stream.
+func TestSubmissionStream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E tests in short mode")
+	}
+
+	submissionID := fmt.Sprintf("stream-test-%d", time.Now().UnixNano())
+	const code = `print(sum(map(int, input().split())))`
+
+	first, err := streamSubmission(submissionID, code, "python", 1)
+	if err != nil {
+		t.Skipf("Backend not available: %v", err)
+		return
+	}
+	assertWellOrderedStream(t, first)
+
+	// A second connection to the same submission ID, after at least one
+	// event already fired, should be replayed the same sequence from the
+	// start rather than only whatever's left live.
+	second, err := streamSubmission(submissionID, code, "python", 1)
+	if err != nil {
+		t.Fatalf("reconnect failed: %v", err)
+	}
+	assertWellOrderedStream(t, second)
+
+	if len(second) < len(first) {
+		t.Errorf("reconnect got %d events, expected at least the %d the first connection saw (replay)", len(second), len(first))
+	}
+	for i := range first {
+		if i >= len(second) || second[i].Type != first[i].Type {
+			t.Errorf("reconnect event %d = %q, want %q (replay should match the original sequence)", i, second[i].Type, first[i].Type)
+			break
+		}
+	}
+}
 
-	// Count results
-	successCount := 0
-	for range results {
-		successCount++
+// assertWellOrderedStream checks events starts with job_created, ends with
+// exactly one terminal completed/error, and has no events after it.
+func assertWellOrderedStream(t *testing.T, events []streamEvent) {
+	t.Helper()
+	if len(events) == 0 {
+		t.Fatal("expected at least one event")
+	}
+	if events[0].Type != "job_created" {
+		t.Errorf("first event = %q, want job_created", events[0].Type)
+	}
+	for i, e := range events[:len(events)-1] {
+		if e.Type == "completed" || e.Type == "error" {
+			t.Errorf("terminal event %q at index %d, want it last (index %d)", e.Type, i, len(events)-1)
+		}
+	}
+	last := events[len(events)-1].Type
+	if last != "completed" && last != "error" {
+		t.Errorf("last event = %q, want completed or error", last)
+	}
+}
+
+// streamSubmission opens /api/submissions/:id/stream for submissionID and
+// reads SSE frames until the connection closes (the server closes it once
+// it publishes a terminal event).
+func streamSubmission(submissionID, code, language string, problemID int) ([]streamEvent, error) {
+	body, err := json.Marshal(SubmissionRequest{Code: code, Language: language, ProblemID: problemID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	errorCount := 0
-	for err := range errors {
-		errorCount++
-		t.Logf("Error: %v", err)
+	url := fmt.Sprintf("%s/api/submissions/%s/stream", baseURL, submissionID)
+	req, err := http.NewRequest(http.MethodGet, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	t.Logf("Concurrent test completed in %v", duration)
-	t.Logf("Successes: %d, Errors: %d", successCount, errorCount)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer resp.Body.Close()
 
-	// At least some should succeed
-	if successCount == 0 && errorCount == numConcurrent {
-		t.Error("All concurrent requests failed")
+	var events []streamEvent
+	var eventType string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &payload); err != nil {
+				continue
+			}
+			events = append(events, streamEvent{Type: eventType, Data: payload})
+		}
 	}
+	return events, nil
 }
 
 // TestSubmissionHistory tests the submission history endpoint
@@ -382,6 +616,109 @@ func TestProblemEndpoints(t *testing.T) {
 	}
 }
 
+// TestImportProblemsFixture loads a small fixture problem set through
+// POST /internal/problems/import and checks it comes back out through the
+// read endpoints the rest of this file already exercises.
+func TestImportProblemsFixture(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping E2E tests in short mode")
+	}
+
+	tarball, err := buildFixtureProblemsTarball()
+	if err != nil {
+		t.Fatalf("failed to build fixture tarball: %v", err)
+	}
+
+	url := backendURL + "/internal/problems/import"
+	resp, err := http.Post(url, "application/gzip", bytes.NewReader(tarball))
+	if err != nil {
+		t.Skipf("Backend not available: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Logf("Import response status: %d (expected 200)", resp.StatusCode)
+		return
+	}
+
+	var result struct {
+		Imported []string `json:"imported"`
+		Failures []string `json:"failures"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode import response: %v", err)
+	}
+
+	if len(result.Failures) > 0 {
+		t.Errorf("expected no import failures, got: %v", result.Failures)
+	}
+
+	found := false
+	for _, slug := range result.Imported {
+		if slug == "fixture-reverse-string" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected fixture-reverse-string in imported slugs, got: %v", result.Imported)
+	}
+}
+
+// buildFixtureProblemsTarball builds a gzipped tarball containing a single
+// problem directory, matching the layout problems.Loader reads: a
+// problem.yaml plus a tests/ subdirectory of input/expected pairs.
+func buildFixtureProblemsTarball() ([]byte, error) {
+	files := map[string]string{
+		"fixture-reverse-string/problem.yaml": `
+slug: fixture-reverse-string
+title: Reverse a String
+difficulty: easy
+description: Given a string, return it reversed.
+judge: exact
+limits:
+  python:
+    time_ms: 2000
+    memory_kb: 65536
+`,
+		"fixture-reverse-string/tests/case001.in":  "hello\n",
+		"fixture-reverse-string/tests/case001.out": "olleh\n",
+	}
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o644,
+			Size: int64(len(content)),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // TestLeaderboardEndpoints tests the leaderboard endpoints
 func TestLeaderboardEndpoints(t *testing.T) {
 	if testing.Short() {
@@ -444,6 +781,14 @@ func TestAntiCheatEndpoints(t *testing.T) {
 // Helper functions
 
 func submitCode(code, language string, problemID int) (*SubmissionResponse, error) {
+	_, resp, err := submitCodeWithStatus(code, language, problemID)
+	return resp, err
+}
+
+// submitCodeWithStatus is submitCode plus the response's HTTP status, so
+// callers can tell a rejection (e.g. 429 from the admission scheduler) from
+// a successful submission without inspecting the body.
+func submitCodeWithStatus(code, language string, problemID int) (int, *SubmissionResponse, error) {
 	req := SubmissionRequest{
 		Code:      code,
 		Language:  language,
@@ -452,22 +797,22 @@ func submitCode(code, language string, problemID int) (*SubmissionResponse, erro
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := baseURL + "/api/submissions"
 	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return 0, nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var result SubmissionResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return resp.StatusCode, nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return resp.StatusCode, &result, nil
 }
 
 func generateLongCode(length int) string {